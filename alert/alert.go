@@ -0,0 +1,542 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+// Incident lifecycle statuses
+const (
+	StatusOpen         = "open"
+	StatusAcknowledged = "acknowledged"
+	StatusResolved     = "resolved"
+)
+
+// Notification events
+const (
+	EventOpened       = "opened"
+	EventUpdated      = "updated"
+	EventAcknowledged = "acknowledged"
+	EventResolved     = "resolved"
+	EventRepeat       = "repeat"
+)
+
+// Alert is a single failing check of a node
+type Alert struct {
+	ChainName  string    `json:"chain_name"`
+	HostName   string    `json:"hostname"`
+	Check      string    `json:"check"`
+	Firing     bool      `json:"firing"`
+	StartedAt  time.Time `json:"started_at"`
+	ResolvedAt time.Time `json:"resolved_at,omitempty"`
+	Severity   string    `json:"severity"`
+	// Flapping is set when the check changed health too often to alert on every change
+	Flapping bool `json:"flapping,omitempty"`
+	// Error is the error of the failing check, if any
+	Error string `json:"error,omitempty"`
+
+	// repeat overrides the repeat interval of the incident, see Rule
+	repeat time.Duration
+}
+
+func (a *Alert) key() string {
+	return a.ChainName + "/" + a.HostName + "/" + a.Check
+}
+
+// TimelineEntry is an event in the life of an incident
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Incident groups related alerts on the same node or failure domain
+type Incident struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	// Severity is the highest severity of the alerts of the incident
+	Severity       string          `json:"severity"`
+	FailureDomain  string          `json:"failure_domain,omitempty"`
+	OpenedAt       time.Time       `json:"opened_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	AcknowledgedAt time.Time       `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty"`
+	ResolvedAt     time.Time       `json:"resolved_at,omitempty"`
+	Alerts         []*Alert        `json:"alerts"`
+	Timeline       []TimelineEntry `json:"timeline"`
+
+	notifiedAt time.Time
+}
+
+func (i *Incident) addTimeline(t time.Time, format string, args ...interface{}) {
+	i.UpdatedAt = t
+	i.Timeline = append(i.Timeline, TimelineEntry{Time: t, Message: fmt.Sprintf(format, args...)})
+}
+
+func (i *Incident) alert(key string) *Alert {
+	for _, a := range i.Alerts {
+		if a.key() == key {
+			return a
+		}
+	}
+	return nil
+}
+
+// repeatInterval returns the shortest repeat interval of the firing alerts,
+// or fallback if none of them has one
+func (i *Incident) repeatInterval(fallback time.Duration) time.Duration {
+	repeat := time.Duration(0)
+	for _, a := range i.Alerts {
+		if a.Firing && a.repeat > 0 && (repeat == 0 || a.repeat < repeat) {
+			repeat = a.repeat
+		}
+	}
+	if repeat == 0 {
+		return fallback
+	}
+	return repeat
+}
+
+// updateSeverity raises the severity of the incident to that of its most
+// severe alert, it never lowers it so resolution reaches the same notifiers
+func (i *Incident) updateSeverity() {
+	for _, a := range i.Alerts {
+		if severityRank[a.Severity] > severityRank[i.Severity] {
+			i.Severity = a.Severity
+		}
+	}
+}
+
+func (i *Incident) firing() int {
+	count := 0
+	for _, a := range i.Alerts {
+		if a.Firing {
+			count++
+		}
+	}
+	return count
+}
+
+// matches reports whether an alert on the given node belongs to the incident
+func (i *Incident) matches(t base.HealthTransition) bool {
+	if t.FailureDomain != "" && t.FailureDomain == i.FailureDomain {
+		return true
+	}
+	for _, a := range i.Alerts {
+		if a.ChainName == t.ChainName && a.HostName == t.HostName {
+			return true
+		}
+	}
+	return false
+}
+
+// copy returns a deep copy safe to hand out of the manager lock
+func (i *Incident) copy() Incident {
+	c := *i
+	c.Alerts = make([]*Alert, len(i.Alerts))
+	for idx, a := range i.Alerts {
+		alert := *a
+		c.Alerts[idx] = &alert
+	}
+	c.Timeline = append([]TimelineEntry(nil), i.Timeline...)
+	return c
+}
+
+// Notification is delivered to notifiers on incident lifecycle events
+type Notification struct {
+	Event    string   `json:"event"`
+	Incident Incident `json:"incident"`
+}
+
+// Manager turns health transitions into incidents and notifies about them
+type Manager struct {
+	mu        sync.RWMutex
+	window    time.Duration
+	repeat    time.Duration
+	incidents map[string]*Incident
+	// open holds the incidents not yet resolved in opening order, resolved
+	// the resolved ones in resolution order until they expire
+	open     []*Incident
+	resolved []*Incident
+	nextID   int
+	audit    *AuditLog
+
+	retention   time.Duration
+	maxResolved int
+
+	rules  []*Rule
+	checks map[string]*checkStatus
+	// standby is set while another replica is HA leader, transitions are
+	// ignored and nothing is notified
+	standby bool
+
+	notifiers []Notifier
+	routes    []*Route
+	queue     chan Notification
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Resolved incidents are kept this long, and at most this many, by default
+const (
+	defaultRetention   = 24 * time.Hour
+	defaultMaxResolved = 1000
+)
+
+// WithRetention keeps resolved incidents for age, and at most count of them,
+// zero keeps the default
+func WithRetention(age time.Duration, count int) Option {
+	return func(m *Manager) {
+		if age > 0 {
+			m.retention = age
+		}
+		if count > 0 {
+			m.maxResolved = count
+		}
+	}
+}
+
+func NewManager(window, repeat time.Duration, notifiers []Notifier, audit *AuditLog, opts ...Option) *Manager {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	if audit == nil {
+		audit = &AuditLog{}
+	}
+	m := &Manager{
+		window:      window,
+		repeat:      repeat,
+		audit:       audit,
+		incidents:   make(map[string]*Incident),
+		retention:   defaultRetention,
+		maxResolved: defaultMaxResolved,
+		checks:      make(map[string]*checkStatus),
+		notifiers:   notifiers,
+		queue:       make(chan Notification, 256),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// HandleTransition groups a health transition into an incident. Alerts
+// fire and resolve according to the rule of the check, at once without one.
+func (m *Manager) HandleTransition(t base.HealthTransition) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.standby {
+		return
+	}
+
+	key := t.ChainName + "/" + t.HostName + "/" + t.Check
+	m.evaluate(key, m.track(key, t), t.Time)
+}
+
+// fireAlert adds the alert of a failing check to an incident at now, it
+// must be called with the lock held
+func (m *Manager) fireAlert(t base.HealthTransition, now time.Time, rule *Rule, flapping bool) {
+	alert := &Alert{
+		ChainName: t.ChainName,
+		HostName:  t.HostName,
+		Check:     t.Check,
+		Firing:    true,
+		StartedAt: t.Time,
+		Severity:  rule.Severity,
+		Flapping:  flapping,
+		Error:     t.Error,
+		repeat:    rule.Repeat,
+	}
+	status := "failing"
+	if flapping {
+		status = "flapping"
+	}
+	// Chain wide checks, such as block interval anomalies, have no host
+	where := t.HostName
+	if where == "" {
+		where = "all nodes"
+	}
+
+	// Of several matching incidents, the alert joins the most recently
+	// updated, the most recently opened on a tie
+	var match *Incident
+	for _, incident := range m.open {
+		if now.Sub(incident.UpdatedAt) > m.window || !incident.matches(t) {
+			continue
+		}
+		if match == nil || !incident.UpdatedAt.Before(match.UpdatedAt) {
+			match = incident
+		}
+	}
+	if incident := match; incident != nil {
+		if existing := incident.alert(alert.key()); existing != nil {
+			*existing = *alert
+		} else {
+			incident.Alerts = append(incident.Alerts, alert)
+		}
+		incident.updateSeverity()
+		incident.addTimeline(now, "%s %s on %s", t.Check, status, where)
+		m.notify(EventUpdated, incident)
+		return
+	}
+
+	m.nextID++
+	incident := &Incident{
+		ID:            fmt.Sprintf("%d", m.nextID),
+		Title:         fmt.Sprintf("%s %s on %s (%s)", t.Check, status, where, t.ChainName),
+		Status:        StatusOpen,
+		FailureDomain: t.FailureDomain,
+		OpenedAt:      now,
+		Alerts:        []*Alert{alert},
+	}
+	incident.updateSeverity()
+	incident.addTimeline(now, "incident opened: %s %s on %s", t.Check, status, where)
+	m.incidents[incident.ID] = incident
+	m.open = append(m.open, incident)
+	m.expire(now)
+	glog.Warningf("[alert] Incident %s opened: %s", incident.ID, incident.Title)
+	m.notify(EventOpened, incident)
+}
+
+// resolveAlert resolves the alert of a recovered check at now, it must be
+// called with the lock held
+func (m *Manager) resolveAlert(t base.HealthTransition, now time.Time) {
+	key := t.ChainName + "/" + t.HostName + "/" + t.Check
+	open := m.open[:0]
+	for _, incident := range m.open {
+		alert := incident.alert(key)
+		if alert == nil || !alert.Firing {
+			open = append(open, incident)
+			continue
+		}
+		alert.Firing = false
+		alert.ResolvedAt = now
+		incident.addTimeline(now, "%s recovered on %s", t.Check, t.HostName)
+
+		if incident.firing() == 0 {
+			incident.Status = StatusResolved
+			incident.ResolvedAt = now
+			incident.addTimeline(now, "incident resolved")
+			glog.Infof("[alert] Incident %s resolved: %s", incident.ID, incident.Title)
+			m.notify(EventResolved, incident)
+			m.resolved = append(m.resolved, incident)
+		} else {
+			open = append(open, incident)
+			m.notify(EventUpdated, incident)
+		}
+	}
+	clear(m.open[len(open):])
+	m.open = open
+	m.expire(now)
+}
+
+// expire forgets the resolved incidents older than the retention, and the
+// oldest beyond the maximum count, it must be called with the lock held
+func (m *Manager) expire(now time.Time) {
+	n := 0
+	for n < len(m.resolved) && (len(m.resolved)-n > m.maxResolved || now.Sub(m.resolved[n].ResolvedAt) > m.retention) {
+		delete(m.incidents, m.resolved[n].ID)
+		n++
+	}
+	if n > 0 {
+		m.resolved = append([]*Incident(nil), m.resolved[n:]...)
+	}
+}
+
+// Acknowledge marks an open incident as being handled by actor
+func (m *Manager) Acknowledge(id, actor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	incident, ok := m.incidents[id]
+	if !ok {
+		return fmt.Errorf("incident %s not found", id)
+	}
+	if incident.Status != StatusOpen {
+		return fmt.Errorf("incident %s is %s", id, incident.Status)
+	}
+
+	now := time.Now()
+	incident.Status = StatusAcknowledged
+	incident.AcknowledgedAt = now
+	incident.AcknowledgedBy = actor
+	incident.addTimeline(now, "acknowledged by %s", actor)
+	m.audit.Record(actor, "acknowledge", "incident/"+id, incident.Title)
+	glog.Infof("[alert] Incident %s acknowledged by %s", id, actor)
+	m.notify(EventAcknowledged, incident)
+	return nil
+}
+
+// Audit returns the audit log of operator actions
+func (m *Manager) Audit() *AuditLog {
+	return m.audit
+}
+
+// repeatNotifications notifies again about open incidents nobody acknowledged
+func (m *Manager) repeatNotifications(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(now)
+	for _, incident := range m.open {
+		repeat := incident.repeatInterval(m.repeat)
+		if incident.Status == StatusOpen && repeat > 0 && now.Sub(incident.notifiedAt) >= repeat {
+			m.notify(EventRepeat, incident)
+		}
+	}
+}
+
+// Incidents returns all incidents, most recently opened first
+func (m *Manager) Incidents() []Incident {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Incident, 0, len(m.incidents))
+	for _, incident := range m.incidents {
+		result = append(result, incident.copy())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OpenedAt.After(result[j].OpenedAt)
+	})
+	return result
+}
+
+// Incident returns the incident with the given id
+func (m *Manager) Incident(id string) (Incident, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	incident, ok := m.incidents[id]
+	if !ok {
+		return Incident{}, false
+	}
+	return incident.copy(), true
+}
+
+// notify queues a notification, it must be called with the lock held
+func (m *Manager) notify(event string, incident *Incident) {
+	if len(m.notifiers) == 0 || m.standby {
+		return
+	}
+	incident.notifiedAt = time.Now()
+	select {
+	case m.queue <- Notification{Event: event, Incident: incident.copy()}:
+	default:
+		glog.Errorf("[alert] Notification queue full, dropping %s of incident %s", event, incident.ID)
+	}
+}
+
+// SetActive switches between HA leader and standby. A standby manager
+// ignores transitions and drops its open incidents and queued
+// notifications, the leader notifies about the nodes from now on. Resolved
+// incidents are kept for the API.
+func (m *Manager) SetActive(active bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.standby == !active {
+		return
+	}
+	m.standby = !active
+	if active {
+		return
+	}
+
+	glog.Infof("[alert] Standing by, dropping %d open incidents", len(m.open))
+	for _, incident := range m.open {
+		delete(m.incidents, incident.ID)
+	}
+	m.open = nil
+	clear(m.checks)
+	for {
+		select {
+		case <-m.queue:
+		default:
+			return
+		}
+	}
+}
+
+// Start delivers queued notifications until Stop is called
+func (m *Manager) Start(parent context.Context) {
+	m.ctx, m.cancel = context.WithCancel(parent)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		var repeat <-chan time.Time
+		if interval := m.repeatTick(); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			repeat = ticker.C
+		}
+
+		// Alerts held back by rules are evaluated every second
+		var evaluate <-chan time.Time
+		if m.delayed() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			evaluate = ticker.C
+		}
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case now := <-repeat:
+				m.repeatNotifications(now)
+			case now := <-evaluate:
+				m.evaluateAll(now)
+			case n := <-m.queue:
+				for _, notifier := range m.notifiersFor(n.Incident.Severity, time.Now()) {
+					if err := notifier.Notify(m.ctx, n); err != nil {
+						glog.Errorf("[alert] Notifier %s failed for incident %s: %v", notifier.Name(), n.Incident.ID, err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// repeatTick returns how often open incidents are checked for repeat
+// notifications, 0 if they are not repeated
+func (m *Manager) repeatTick() time.Duration {
+	tick := time.Duration(0)
+	for _, interval := range append([]time.Duration{m.repeat}, m.ruleRepeats()...) {
+		if interval > 0 && (tick == 0 || interval < tick) {
+			tick = interval
+		}
+	}
+	if tick == 0 {
+		return 0
+	}
+	return min(tick, time.Minute)
+}
+
+func (m *Manager) ruleRepeats() []time.Duration {
+	repeats := make([]time.Duration, 0, len(m.rules))
+	for _, rule := range m.rules {
+		repeats = append(repeats, rule.Repeat)
+	}
+	return repeats
+}
+
+// Stop stops delivering notifications
+func (m *Manager) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	if err := m.audit.Close(); err != nil {
+		glog.Errorf("[alert] Failed to close audit log: %v", err)
+	}
+}
@@ -0,0 +1,545 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/events"
+)
+
+func transition(host, domain, check string, healthy bool, t time.Time) base.HealthTransition {
+	return base.HealthTransition{
+		ChainName:     "story",
+		HostName:      host,
+		FailureDomain: domain,
+		Check:         check,
+		Healthy:       healthy,
+		Time:          t,
+	}
+}
+
+func TestIncidentGrouping(t *testing.T) {
+	m := NewManager(time.Minute, 0, nil, nil)
+	now := time.Now()
+
+	m.HandleTransition(transition("node-01", "dc1", "http", false, now))
+	m.HandleTransition(transition("node-01", "", "block_retrieval", false, now.Add(time.Second)))
+	m.HandleTransition(transition("node-02", "dc1", "http", false, now.Add(2*time.Second)))
+	m.HandleTransition(transition("node-03", "dc2", "http", false, now.Add(3*time.Second)))
+
+	incidents := m.Incidents()
+	if len(incidents) != 2 {
+		t.Fatalf("expected 2 incidents, got %d", len(incidents))
+	}
+	first, _ := m.Incident("1")
+	if len(first.Alerts) != 3 {
+		t.Errorf("expected 3 alerts in first incident, got %d", len(first.Alerts))
+	}
+}
+
+func TestIncidentWindow(t *testing.T) {
+	m := NewManager(time.Minute, 0, nil, nil)
+	now := time.Now()
+
+	m.HandleTransition(transition("node-01", "", "http", false, now))
+	m.HandleTransition(transition("node-01", "", "ws", false, now.Add(2*time.Minute)))
+
+	if len(m.Incidents()) != 2 {
+		t.Errorf("expected alerts outside the window to open a new incident")
+	}
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	m := NewManager(time.Minute, 0, nil, nil)
+	now := time.Now()
+
+	m.HandleTransition(transition("node-01", "", "http", false, now))
+	m.HandleTransition(transition("node-01", "", "ws", false, now))
+
+	if err := m.Acknowledge("1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Acknowledge("1", "bob"); err == nil {
+		t.Error("expected acknowledging twice to fail")
+	}
+
+	m.HandleTransition(transition("node-01", "", "http", true, now.Add(time.Second)))
+	incident, _ := m.Incident("1")
+	if incident.Status != StatusAcknowledged {
+		t.Errorf("expected incident to stay acknowledged while alerts fire, got %s", incident.Status)
+	}
+
+	m.HandleTransition(transition("node-01", "", "ws", true, now.Add(2*time.Second)))
+	incident, _ = m.Incident("1")
+	if incident.Status != StatusResolved || incident.AcknowledgedBy != "alice" {
+		t.Errorf("expected resolved incident acknowledged by alice, got %s by %s", incident.Status, incident.AcknowledgedBy)
+	}
+	if len(incident.Timeline) != 6 {
+		t.Errorf("expected 6 timeline entries, got %d", len(incident.Timeline))
+	}
+}
+
+func TestIncidentMatching(t *testing.T) {
+	m := NewManager(time.Minute, 0, nil, nil)
+	now := time.Now()
+
+	m.HandleTransition(transition("node-01", "dc1", "http", false, now))
+	m.HandleTransition(transition("node-02", "dc2", "http", false, now.Add(time.Second)))
+	// Matches incident 1 by host and incident 2 by failure domain
+	m.HandleTransition(transition("node-01", "dc2", "ws", false, now.Add(2*time.Second)))
+
+	first, _ := m.Incident("1")
+	second, _ := m.Incident("2")
+	if len(first.Alerts) != 1 || len(second.Alerts) != 2 {
+		t.Errorf("expected the alert to join the most recently updated incident, got %d and %d alerts", len(first.Alerts), len(second.Alerts))
+	}
+}
+
+func TestIncidentRetention(t *testing.T) {
+	m := NewManager(time.Minute, 0, nil, nil, WithRetention(time.Hour, 2))
+	now := time.Now()
+
+	for i, host := range []string{"node-01", "node-02", "node-03"} {
+		at := now.Add(time.Duration(i) * time.Second)
+		m.HandleTransition(transition(host, "", "http", false, at))
+		m.HandleTransition(transition(host, "", "http", true, at))
+	}
+	if _, ok := m.Incident("1"); ok || len(m.Incidents()) != 2 {
+		t.Errorf("expected the oldest resolved incident beyond the count to be dropped, got %d incidents", len(m.Incidents()))
+	}
+
+	m.HandleTransition(transition("node-04", "", "http", false, now.Add(2*time.Hour)))
+	incidents := m.Incidents()
+	if len(incidents) != 1 || incidents[0].ID != "4" {
+		t.Errorf("expected expired resolved incidents to be dropped, got %+v", incidents)
+	}
+}
+
+func TestStandby(t *testing.T) {
+	notifier := &recordingNotifier{name: "slack", got: make(chan Notification, 8)}
+	m := NewManager(time.Minute, time.Minute, []Notifier{notifier}, nil)
+
+	now := time.Now()
+	m.HandleTransition(transition("node-01", "", "http", false, now))
+	<-m.queue
+
+	m.SetActive(false)
+	if got := m.Incidents(); len(got) != 0 {
+		t.Fatalf("standby manager kept %d open incidents", len(got))
+	}
+	m.HandleTransition(transition("node-02", "", "http", false, now))
+	m.repeatNotifications(now.Add(time.Hour))
+	if got := m.Incidents(); len(got) != 0 {
+		t.Errorf("standby manager opened %d incidents", len(got))
+	}
+	select {
+	case n := <-m.queue:
+		t.Errorf("standby manager queued %s of incident %s", n.Event, n.Incident.ID)
+	default:
+	}
+
+	m.SetActive(true)
+	m.HandleTransition(transition("node-02", "", "http", false, now))
+	if n := <-m.queue; n.Event != EventOpened {
+		t.Errorf("expected opened notification after taking over, got %s", n.Event)
+	}
+}
+
+func TestRuleForAndHold(t *testing.T) {
+	m := NewManager(time.Minute, 0, nil, nil, WithRules([]*Rule{{Check: "http", For: time.Minute, Hold: time.Minute}}))
+	now := time.Now()
+
+	// A short failure does not alert
+	m.HandleTransition(transition("node-01", "", "http", false, now))
+	m.evaluateAll(now.Add(30 * time.Second))
+	m.HandleTransition(transition("node-01", "", "http", true, now.Add(40*time.Second)))
+	m.evaluateAll(now.Add(2 * time.Minute))
+	if n := len(m.Incidents()); n != 0 {
+		t.Fatalf("expected no incident for a failure shorter than for, got %d", n)
+	}
+
+	start := now.Add(3 * time.Minute)
+	m.HandleTransition(transition("node-01", "", "http", false, start))
+	m.evaluateAll(start.Add(time.Minute))
+	incident, ok := m.Incident("1")
+	if !ok || incident.Status != StatusOpen || !incident.Alerts[0].StartedAt.Equal(start) {
+		t.Fatalf("expected open incident started at %v, got %+v", start, incident)
+	}
+
+	// A short recovery does not resolve
+	m.HandleTransition(transition("node-01", "", "http", true, start.Add(2*time.Minute)))
+	m.evaluateAll(start.Add(150 * time.Second))
+	if incident, _ := m.Incident("1"); incident.Status != StatusOpen {
+		t.Errorf("expected incident to stay open during hold, got %s", incident.Status)
+	}
+	m.evaluateAll(start.Add(3 * time.Minute))
+	if incident, _ := m.Incident("1"); incident.Status != StatusResolved {
+		t.Errorf("expected incident resolved after hold, got %s", incident.Status)
+	}
+}
+
+func TestRuleFlapping(t *testing.T) {
+	m := NewManager(time.Hour, 0, nil, nil, WithRules([]*Rule{{FlapThreshold: 4, FlapWindow: 10 * time.Minute, For: time.Minute}}))
+	now := time.Now()
+
+	for i := 0; i < 6; i++ {
+		m.HandleTransition(transition("node-01", "", "http", i%2 == 1, now.Add(time.Duration(i)*10*time.Second)))
+	}
+	incidents := m.Incidents()
+	if len(incidents) != 1 || !incidents[0].Alerts[0].Flapping || incidents[0].Status != StatusOpen {
+		t.Fatalf("expected one open incident with a flapping alert, got %+v", incidents)
+	}
+
+	last := now.Add(50 * time.Second)
+	m.evaluateAll(last.Add(5 * time.Minute))
+	if incident, _ := m.Incident("1"); incident.Status != StatusOpen {
+		t.Errorf("expected flapping alert to stay open until stable, got %s", incident.Status)
+	}
+	m.evaluateAll(last.Add(10 * time.Minute))
+	if incident, _ := m.Incident("1"); incident.Status != StatusResolved {
+		t.Errorf("expected incident resolved after the check was stable, got %s", incident.Status)
+	}
+}
+
+type recordingNotifier struct {
+	name string
+	got  chan Notification
+}
+
+func (r *recordingNotifier) Name() string {
+	return r.name
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.got <- n
+	return nil
+}
+
+func TestSeverityRouting(t *testing.T) {
+	slack := &recordingNotifier{name: "slack", got: make(chan Notification, 8)}
+	pager := &recordingNotifier{name: "pagerduty", got: make(chan Notification, 8)}
+	notifiers := []Notifier{slack, pager}
+
+	rules, err := NewRules([]*conf.AlertingRule{{Check: "peers", Severity: SeverityWarning}, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes, err := NewRoutes([]*conf.AlertRoute{{Severity: SeverityWarning, Notifiers: []string{"slack"}}}, notifiers, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRoutes([]*conf.AlertRoute{{Severity: SeverityCritical, Notifiers: []string{"email"}}}, notifiers, ""); err == nil {
+		t.Error("expected route to an unknown notifier to fail")
+	}
+
+	m := NewManager(time.Minute, 0, notifiers, nil, WithRules(rules), WithRoutes(routes))
+	m.Start(context.Background())
+	defer m.Stop()
+
+	now := time.Now()
+	m.HandleTransition(transition("node-01", "", "peers", false, now))
+	if n := <-slack.got; n.Incident.Severity != SeverityWarning {
+		t.Errorf("expected warning incident, got %s", n.Incident.Severity)
+	}
+
+	// A critical alert joining the incident raises its severity and reaches all notifiers
+	m.HandleTransition(transition("node-01", "", "http", false, now.Add(time.Second)))
+	for _, r := range []*recordingNotifier{slack, pager} {
+		select {
+		case n := <-r.got:
+			if n.Incident.Severity != SeverityCritical {
+				t.Errorf("%s: expected critical incident, got %s", r.name, n.Incident.Severity)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: critical incident not delivered", r.name)
+		}
+	}
+	select {
+	case n := <-pager.got:
+		t.Errorf("pagerduty received %s of a warning incident", n.Event)
+	default:
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	notifier, err := NewNotifier(&conf.Notifier{Name: "ops-email", Type: "email", SMTP: &conf.SMTP{
+		Host:    "smtp.example.com",
+		From:    "monitor@example.com",
+		To:      []string{"ops@example.com"},
+		Subject: "{{.Incident.Severity}}\n{{.Incident.Title}}",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := notifier.(*EmailNotifier)
+	if email.addr != "smtp.example.com:587" {
+		t.Errorf("expected default starttls port, got %s", email.addr)
+	}
+
+	routes, err := NewRoutes([]*conf.AlertRoute{{Severity: SeverityCritical, Notifiers: []string{"ops-email"}, To: []string{"oncall@example.com"}}}, []Notifier{notifier}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	routed := routes[0].Notifiers[0].(*EmailNotifier)
+
+	m := NewManager(time.Minute, 0, nil, nil)
+	m.HandleTransition(transition("node-01", "", "http", false, time.Now()))
+	incident, _ := m.Incident("1")
+	msg, err := routed.message(Notification{Event: EventOpened, Incident: incident})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"To: oncall@example.com\r\n",
+		"Subject: critical http failing on node-01 (story)\r\n",
+		"- http on node-01 (story): firing\r\n",
+	} {
+		if !strings.Contains(string(msg), want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+	if len(email.to) != 1 || email.to[0] != "ops@example.com" {
+		t.Errorf("route changed the recipients of the notifier: %v", email.to)
+	}
+}
+
+func TestDiscordMessage(t *testing.T) {
+	rules, err := NewRules([]*conf.AlertingRule{{Severity: SeverityWarning}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(time.Minute, 0, nil, nil, WithRules(rules))
+	now := time.Now()
+	m.HandleTransition(transition("node-01", "", "http", false, now))
+
+	incident, _ := m.Incident("1")
+	n := Notification{Event: EventOpened, Incident: incident}
+	msg := discordMessageOf(n, discordAlerts(n))
+	if len(msg.Embeds) != 1 || msg.Embeds[0].Color != discordColorWarning {
+		t.Fatalf("expected one warning colored embed, got %+v", msg.Embeds)
+	}
+	if !strings.Contains(msg.Embeds[0].Description, "`http` on **node-01** (story): firing") {
+		t.Errorf("unexpected description %q", msg.Embeds[0].Description)
+	}
+
+	m.HandleTransition(transition("node-01", "", "http", true, now.Add(time.Second)))
+	incident, _ = m.Incident("1")
+	if color := discordMessageOf(Notification{Event: EventResolved, Incident: incident}, "").Embeds[0].Color; color != discordColorResolved {
+		t.Errorf("expected resolved color, got %x", color)
+	}
+}
+
+func TestFeishuSign(t *testing.T) {
+	// The key is the timestamp and secret separated by a newline, the message is empty
+	mac := hmac.New(sha256.New, []byte("1599360473\nsecret"))
+	want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if got := feishuSign(1599360473, "secret"); got != want {
+		t.Errorf("feishuSign = %s, want %s", got, want)
+	}
+
+	f := &FeishuNotifier{name: "feishu", secret: "secret"}
+	msg := f.message(incidentText(Notification{Event: EventOpened, Incident: Incident{ID: "1", Title: "http failing on node-01 (story)", Severity: SeverityCritical, Status: StatusOpen}}), time.Unix(1599360473, 0))
+	if msg.Timestamp != "1599360473" || msg.Sign != want || msg.MsgType != "text" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if !strings.HasPrefix(msg.Content.Text, "[critical] opened: http failing on node-01 (story)") {
+		t.Errorf("unexpected text %q", msg.Content.Text)
+	}
+}
+
+func TestOpsGenie(t *testing.T) {
+	type request struct {
+		path, auth string
+	}
+	requests := make(chan request, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- request{path: r.URL.RequestURI(), auth: r.Header.Get("Authorization")}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(&conf.Notifier{Name: "opsgenie", Type: "opsgenie", URL: server.URL, APIKey: "key", Teams: []string{"node-ops"}, Priorities: map[string]string{SeverityWarning: "P2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opsgenie := notifier.(*OpsGenieNotifier)
+
+	incident := Incident{ID: "7", Title: "http failing on node-01 (story)", Severity: SeverityWarning, Status: StatusOpen, OpenedAt: time.Unix(1700000000, 0)}
+	if a, _ := opsgenie.alert(Notification{Event: EventOpened, Incident: incident}); a.Priority != "P2" || a.Alias != "storymonitor-7-1700000000" || a.Responders[0].Name != "node-ops" {
+		t.Errorf("unexpected alert %+v", a)
+	}
+
+	for _, event := range []string{EventOpened, EventResolved} {
+		if err := opsgenie.Notify(context.Background(), Notification{Event: event, Incident: incident}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r := <-requests; r.path != "/v2/alerts" || r.auth != "GenieKey key" {
+		t.Errorf("unexpected create request %+v", r)
+	}
+	if r := <-requests; r.path != "/v2/alerts/storymonitor-7-1700000000/close?identifierType=alias" {
+		t.Errorf("unexpected close request %+v", r)
+	}
+
+	if _, err := NewNotifier(&conf.Notifier{Type: "opsgenie", APIKey: "key", Priorities: map[string]string{SeverityCritical: "P0"}}); err == nil {
+		t.Error("expected invalid priority to fail")
+	}
+}
+
+func TestTwilioRateLimit(t *testing.T) {
+	notifier, err := NewNotifier(&conf.Notifier{Name: "sms", Type: "twilio", Twilio: &conf.Twilio{
+		AccountSID: "AC123",
+		AuthToken:  "token",
+		From:       "+15550000000",
+		To:         []string{"+15551111111"},
+		MaxPerHour: 2,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms := notifier.(*TwilioNotifier)
+
+	now := time.Now()
+	for i, want := range []bool{true, true, false} {
+		if got := sms.allow("+15551111111", now.Add(time.Duration(i)*time.Minute)); got != want {
+			t.Errorf("message %d allowed = %v, want %v", i, got, want)
+		}
+	}
+	if !sms.allow("+15552222222", now) {
+		t.Error("expected the limit to apply per recipient")
+	}
+	if !sms.allow("+15551111111", now.Add(time.Hour)) {
+		t.Error("expected messages older than an hour not to count")
+	}
+
+	// Warnings are not sent by SMS
+	if err := sms.Notify(context.Background(), Notification{Event: EventOpened, Incident: Incident{Severity: SeverityWarning}}); err != nil {
+		t.Errorf("expected warning incident to be skipped, got %v", err)
+	}
+}
+
+func TestRouteSchedule(t *testing.T) {
+	slack := &recordingNotifier{name: "slack"}
+	pager := &recordingNotifier{name: "pagerduty"}
+	routes, err := NewRoutes([]*conf.AlertRoute{
+		{Severity: SeverityCritical, Notifiers: []string{"slack"}},
+		// Page at night only
+		{Severity: SeverityCritical, Notifiers: []string{"slack", "pagerduty"}, Schedule: []*conf.MaintenanceWindow{{Cron: "0 22 * * *", DurationMinute: 600}}},
+		// Suppress warnings on weekends
+		{Severity: SeverityWarning, Notifiers: []string{"slack"}, Schedule: []*conf.MaintenanceWindow{{Cron: "0 0 * * 1-5", DurationMinute: 1440}}},
+	}, []Notifier{slack, pager}, "Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(time.Minute, 0, []Notifier{slack, pager}, nil, WithRoutes(routes))
+
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	names := func(severity string, at time.Time) []string {
+		var result []string
+		for _, n := range m.notifiersFor(severity, at) {
+			result = append(result, n.Name())
+		}
+		return result
+	}
+	// Saturday 2024-06-01
+	if got := names(SeverityCritical, time.Date(2024, 6, 1, 23, 0, 0, 0, shanghai)); len(got) != 2 {
+		t.Errorf("expected slack and pagerduty at night, got %v", got)
+	}
+	if got := names(SeverityCritical, time.Date(2024, 6, 1, 12, 0, 0, 0, shanghai)); len(got) != 1 || got[0] != "slack" {
+		t.Errorf("expected slack only at noon, got %v", got)
+	}
+	if got := names(SeverityWarning, time.Date(2024, 6, 1, 12, 0, 0, 0, shanghai)); len(got) != 0 {
+		t.Errorf("expected warnings suppressed on weekends, got %v", got)
+	}
+	if got := names(SeverityWarning, time.Date(2024, 6, 3, 12, 0, 0, 0, shanghai)); len(got) != 1 {
+		t.Errorf("expected warnings on weekdays, got %v", got)
+	}
+	if got := names(SeverityInfo, time.Date(2024, 6, 3, 12, 0, 0, 0, shanghai)); len(got) != 2 {
+		t.Errorf("expected severities without route to reach all notifiers, got %v", got)
+	}
+}
+
+func TestMessageTemplate(t *testing.T) {
+	tmpl, err := parseTemplate("test", `{{range .Alerts}}{{.Labels.check | upper}} on {{.Labels.hostname}}: {{.Error}} {{$.Vars.runbook}}/{{.Check}}{{end}}`, map[string]string{"runbook": "https://wiki/runbooks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(time.Minute, 0, nil, nil)
+	tr := transition("node-01", "", "http", false, time.Now())
+	tr.Error = "connection refused"
+	m.HandleTransition(tr)
+
+	incident, _ := m.Incident("1")
+	got, err := tmpl.render(Notification{Event: EventOpened, Incident: incident})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "HTTP on node-01: connection refused https://wiki/runbooks/http"; got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+
+	if _, err := parseTemplate("test", "{{.Incident", nil); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestThresholdWatcher(t *testing.T) {
+	w, err := NewThresholdWatcher([]*conf.Threshold{
+		{MaxResponseTimeMs: 1000},
+		{ChainName: "story", MaxBlockDelaySecond: 10, MaxHeightLagBlocks: 5},
+		{ChainName: "story", HostName: "node-02", MaxHeightLagBlocks: 50},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Thresholds("story", "node-02"); got.MaxBlockDelay != 10*time.Second || got.MaxHeightLag != 50 || got.MaxResponseTime != time.Second {
+		t.Errorf("unexpected thresholds %+v", got)
+	}
+
+	w.bus = events.NewBus()
+	sub := w.bus.Subscribe("test", 16, events.KindTransition)
+	next := func() events.Event {
+		select {
+		case e := <-sub.C():
+			return e
+		default:
+			return events.Event{}
+		}
+	}
+
+	now := time.Now()
+	w.AppendHead("story", "node-01", 100, [32]byte{}, now, now)
+	w.AppendHead("story", "node-02", 90, [32]byte{}, now, now)
+	if e := next(); e.Check != "" {
+		t.Fatalf("expected no transition within thresholds, got %+v", e)
+	}
+	w.AppendHead("story", "node-01", 120, [32]byte{}, now.Add(-time.Minute), now)
+	if e := next(); e.Check != CheckBlockDelay || e.Healthy || !e.Initial {
+		t.Errorf("expected a block delay breach, got %+v", e)
+	}
+	if e := next(); e.Check != "" {
+		t.Errorf("expected node-02 within its own lag threshold, got %+v", e)
+	}
+	w.AppendHead("story", "node-01", 121, [32]byte{}, now, now)
+	if e := next(); e.Check != CheckBlockDelay || !e.Healthy {
+		t.Errorf("expected the block delay breach to resolve, got %+v", e)
+	}
+
+	w.AppendCheck("story", "node-01", "http", true, 2*time.Second, now)
+	if e := next(); e.Check != "http_response_time" || e.Healthy {
+		t.Errorf("expected a response time breach, got %+v", e)
+	}
+
+	if _, err := NewThresholdWatcher([]*conf.Threshold{{HostName: "node-01"}}); err == nil {
+		t.Error("expected an error for a hostname without chain_name")
+	}
+}
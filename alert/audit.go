@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const auditHistory = 1000
+
+// AuditEntry records an action taken by an operator
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	Target string    `json:"target"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// AuditLog keeps recent audit entries in memory and appends them to a file as JSON lines
+type AuditLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	entries []AuditEntry
+}
+
+// NewAuditLog opens the audit log file, an empty path keeps entries in memory only
+func NewAuditLog(path string) (*AuditLog, error) {
+	a := &AuditLog{}
+	if path == "" {
+		return a, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	a.file = f
+	return a, nil
+}
+
+// Record appends an audit entry
+func (a *AuditLog) Record(actor, action, target, detail string) {
+	entry := AuditEntry{
+		Time:   time.Now(),
+		Actor:  actor,
+		Action: action,
+		Target: target,
+		Detail: detail,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.entries = append(a.entries, entry)
+	if len(a.entries) > auditHistory {
+		a.entries = a.entries[len(a.entries)-auditHistory:]
+	}
+
+	if a.file != nil {
+		line, _ := json.Marshal(entry)
+		if _, err := a.file.Write(append(line, '\n')); err != nil {
+			glog.Errorf("[audit] Failed to write audit entry: %v", err)
+		}
+	}
+}
+
+// Entries returns the recent audit entries, oldest first
+func (a *AuditLog) Entries() []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]AuditEntry(nil), a.entries...)
+}
+
+// Close closes the audit log file
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	return a.file.Close()
+}
@@ -0,0 +1,146 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// incidentText formats a notification as plain text for chat bots
+func incidentText(n Notification) string {
+	incident := n.Incident
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s: %s\n", incident.Severity, n.Event, incident.Title)
+	fmt.Fprintf(&b, "Incident %s is %s", incident.ID, incident.Status)
+	if incident.AcknowledgedBy != "" {
+		fmt.Fprintf(&b, ", acknowledged by %s", incident.AcknowledgedBy)
+	}
+	b.WriteString("\n")
+	for _, a := range incident.Alerts {
+		status := "resolved"
+		if a.Firing {
+			status = "firing"
+			if a.Flapping {
+				status = "flapping"
+			}
+		}
+		fmt.Fprintf(&b, "- %s on %s (%s): %s\n", a.Check, a.HostName, a.ChainName, status)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// FeishuNotifier posts notifications to a Feishu or Lark custom bot webhook
+type FeishuNotifier struct {
+	name     string
+	url      string
+	secret   string
+	template *messageTemplate
+	cli      *http.Client
+}
+
+type feishuMessage struct {
+	Timestamp string        `json:"timestamp,omitempty"`
+	Sign      string        `json:"sign,omitempty"`
+	MsgType   string        `json:"msg_type"`
+	Content   feishuContent `json:"content"`
+}
+
+type feishuContent struct {
+	Text string `json:"text"`
+}
+
+func (f *FeishuNotifier) Name() string {
+	return f.name
+}
+
+// feishuSign computes the signature of a Feishu/Lark bot request: the
+// HMAC-SHA256 of an empty message keyed with timestamp, newline and secret
+func feishuSign(timestamp int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(strconv.FormatInt(timestamp, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (f *FeishuNotifier) message(text string, now time.Time) feishuMessage {
+	msg := feishuMessage{
+		MsgType: "text",
+		Content: feishuContent{Text: text},
+	}
+	if f.secret != "" {
+		ts := now.Unix()
+		msg.Timestamp = strconv.FormatInt(ts, 10)
+		msg.Sign = feishuSign(ts, f.secret)
+	}
+	return msg
+}
+
+func (f *FeishuNotifier) Notify(ctx context.Context, n Notification) error {
+	text, err := renderOr(f.template, n, incidentText)
+	if err != nil {
+		return err
+	}
+	return postJSONChecked(ctx, f.cli, f.name, f.url, nil, f.message(text, time.Now()), func(body []byte) error {
+		var resp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		if resp.Code != 0 {
+			return fmt.Errorf("feishu error %d: %s", resp.Code, resp.Msg)
+		}
+		return nil
+	})
+}
+
+// WeComNotifier posts notifications to a WeCom (WeChat Work) group bot
+// webhook. Group bots authenticate with the key in the webhook URL and do not
+// sign requests.
+type WeComNotifier struct {
+	name     string
+	url      string
+	template *messageTemplate
+	cli      *http.Client
+}
+
+type wecomMessage struct {
+	MsgType string       `json:"msgtype"`
+	Text    wecomContent `json:"text"`
+}
+
+type wecomContent struct {
+	Content string `json:"content"`
+}
+
+func (w *WeComNotifier) Name() string {
+	return w.name
+}
+
+func (w *WeComNotifier) Notify(ctx context.Context, n Notification) error {
+	text, err := renderOr(w.template, n, incidentText)
+	if err != nil {
+		return err
+	}
+	msg := wecomMessage{MsgType: "text", Text: wecomContent{Content: text}}
+	return postJSONChecked(ctx, w.cli, w.name, w.url, nil, msg, func(body []byte) error {
+		var resp struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		if resp.ErrCode != 0 {
+			return fmt.Errorf("wecom error %d: %s", resp.ErrCode, resp.ErrMsg)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Embed colors of Discord notifications
+const (
+	discordColorCritical = 0xE74C3C
+	discordColorWarning  = 0xF39C12
+	discordColorInfo     = 0x3498DB
+	discordColorResolved = 0x2ECC71
+)
+
+// DiscordNotifier posts notifications as embeds to a Discord webhook
+type DiscordNotifier struct {
+	name     string
+	url      string
+	template *messageTemplate
+	cli      *http.Client
+}
+
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (d *DiscordNotifier) Name() string {
+	return d.name
+}
+
+// discordColor color-codes an incident by severity, resolved incidents are green
+func discordColor(incident Incident) int {
+	if incident.Status == StatusResolved {
+		return discordColorResolved
+	}
+	switch incident.Severity {
+	case SeverityWarning:
+		return discordColorWarning
+	case SeverityInfo:
+		return discordColorInfo
+	}
+	return discordColorCritical
+}
+
+// discordAlerts lists the alerts of a notification in Discord markdown
+func discordAlerts(n Notification) string {
+	var alerts strings.Builder
+	for _, a := range n.Incident.Alerts {
+		status := "resolved"
+		if a.Firing {
+			status = "firing"
+			if a.Flapping {
+				status = "flapping"
+			}
+		}
+		fmt.Fprintf(&alerts, "`%s` on **%s** (%s): %s\n", a.Check, a.HostName, a.ChainName, status)
+	}
+	return alerts.String()
+}
+
+// discordMessageOf formats a notification as a Discord embed with description
+func discordMessageOf(n Notification, description string) discordMessage {
+	incident := n.Incident
+
+	fields := []discordField{
+		{Name: "Status", Value: incident.Status, Inline: true},
+		{Name: "Severity", Value: incident.Severity, Inline: true},
+		{Name: "Incident", Value: incident.ID, Inline: true},
+	}
+	if incident.AcknowledgedBy != "" {
+		fields = append(fields, discordField{Name: "Acknowledged by", Value: incident.AcknowledgedBy, Inline: true})
+	}
+	if incident.FailureDomain != "" {
+		fields = append(fields, discordField{Name: "Failure domain", Value: incident.FailureDomain, Inline: true})
+	}
+
+	return discordMessage{
+		Username: "storymonitor",
+		Embeds: []discordEmbed{{
+			Title:       fmt.Sprintf("[%s] %s", n.Event, incident.Title),
+			Description: description,
+			Color:       discordColor(incident),
+			Fields:      fields,
+			Timestamp:   incident.UpdatedAt.UTC().Format(time.RFC3339),
+		}},
+	}
+}
+
+func (d *DiscordNotifier) Notify(ctx context.Context, n Notification) error {
+	description, err := renderOr(d.template, n, discordAlerts)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, d.cli, d.name, d.url, discordMessageOf(n, description))
+}
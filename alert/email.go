@@ -0,0 +1,217 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+// SMTP TLS modes
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNone     = "none"
+)
+
+const (
+	defaultEmailSubject = `[{{.Incident.Severity}}] {{.Event}}: {{.Incident.Title}}`
+	defaultEmailBody    = `Incident {{.Incident.ID}} {{.Event}}: {{.Incident.Title}}
+Status: {{.Incident.Status}}
+Severity: {{.Incident.Severity}}
+Opened: {{.Incident.OpenedAt.Format "2006-01-02T15:04:05Z07:00"}}
+
+Alerts:
+{{range .Incident.Alerts}}- {{.Check}} on {{.HostName}} ({{.ChainName}}): {{if .Firing}}firing{{else}}resolved{{end}}
+{{end}}
+Timeline:
+{{range .Incident.Timeline}}- {{.Time.Format "15:04:05"}} {{.Message}}
+{{end}}`
+)
+
+// EmailNotifier sends notifications as plain text email through an SMTP server
+type EmailNotifier struct {
+	name    string
+	addr    string
+	host    string
+	tlsMode string
+	auth    smtp.Auth
+	from    string
+	to      []string
+	subject *messageTemplate
+	body    *messageTemplate
+}
+
+func newEmailNotifier(name string, n *conf.Notifier) (*EmailNotifier, error) {
+	c := n.SMTP
+	if c == nil || c.Host == "" {
+		return nil, fmt.Errorf("notifier %s: smtp.host is required", name)
+	}
+	if c.From == "" || len(c.To) == 0 {
+		return nil, fmt.Errorf("notifier %s: smtp.from and smtp.to are required", name)
+	}
+
+	tlsMode := c.TLS
+	if tlsMode == "" {
+		tlsMode = SMTPStartTLS
+	}
+	port := c.Port
+	switch tlsMode {
+	case SMTPStartTLS, SMTPNone:
+		if port == 0 {
+			port = 587
+		}
+	case SMTPTLS:
+		if port == 0 {
+			port = 465
+		}
+	default:
+		return nil, fmt.Errorf("notifier %s: unknown smtp.tls %q, expected starttls, tls or none", name, c.TLS)
+	}
+
+	// smtp.body takes precedence over the template of the notifier
+	subject, body := c.Subject, c.Body
+	if subject == "" {
+		subject = defaultEmailSubject
+	}
+	if body == "" {
+		body = n.Template
+	}
+	if body == "" {
+		body = defaultEmailBody
+	}
+	subjectTmpl, err := parseTemplate("subject", subject, n.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("notifier %s: smtp.subject: %w", name, err)
+	}
+	bodyTmpl, err := parseTemplate("body", body, n.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("notifier %s: smtp.body: %w", name, err)
+	}
+
+	e := &EmailNotifier{
+		name:    name,
+		addr:    net.JoinHostPort(c.Host, strconv.Itoa(port)),
+		host:    c.Host,
+		tlsMode: tlsMode,
+		from:    c.From,
+		to:      c.To,
+		subject: subjectTmpl,
+		body:    bodyTmpl,
+	}
+	if c.Username != "" {
+		e.auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	base.RegisterEndpoint("notifier", "", name, "smtp://"+e.addr)
+	return e, nil
+}
+
+func (e *EmailNotifier) Name() string {
+	return e.name
+}
+
+// withRecipients returns a copy of the notifier sending to other recipients
+func (e *EmailNotifier) withRecipients(to []string) *EmailNotifier {
+	c := *e
+	c.to = to
+	return &c
+}
+
+// message renders the email of a notification
+func (e *EmailNotifier) message(n Notification) ([]byte, error) {
+	subject, err := e.subject.render(n)
+	if err != nil {
+		return nil, err
+	}
+	body, err := e.body.render(n)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	// Header values must not contain line breaks
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.Join(strings.Fields(subject), " "))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	msg, err := e.message(n)
+	if err != nil {
+		return err
+	}
+	err = e.send(ctx, msg)
+	base.UpdateEndpointStatus("notifier", "", e.name, err == nil)
+	return err
+}
+
+// send delivers a message through the SMTP server
+func (e *EmailNotifier) send(ctx context.Context, msg []byte) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+	tlsConfig := &tls.Config{ServerName: e.host, MinVersion: tls.VersionTLS12}
+	if e.tlsMode == SMTPTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.tlsMode == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server %s does not support STARTTLS", e.addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if e.auth != nil {
+		if err := client.Auth(e.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.from); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,138 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+// Notifier delivers incident notifications to an external channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NewNotifier creates a notifier from its configuration
+func NewNotifier(c *conf.Notifier) (Notifier, error) {
+	name := c.Name
+	if name == "" {
+		name = c.Type
+	}
+	tmpl, err := parseTemplate(name, c.Template, c.Vars)
+	if err != nil {
+		return nil, fmt.Errorf("notifier %s: %w", name, err)
+	}
+
+	switch c.Type {
+	case "webhook":
+		if c.URL == "" {
+			return nil, fmt.Errorf("notifier %s: url is required", name)
+		}
+		base.RegisterEndpoint("notifier", "", name, c.URL)
+		return &WebhookNotifier{name: name, url: c.URL, template: tmpl, cli: &http.Client{Timeout: 10 * time.Second, Transport: base.NewProxyTransport("")}}, nil
+	case "discord":
+		if c.URL == "" {
+			return nil, fmt.Errorf("notifier %s: url is required", name)
+		}
+		base.RegisterEndpoint("notifier", "", name, c.URL)
+		return &DiscordNotifier{name: name, url: c.URL, template: tmpl, cli: &http.Client{Timeout: 10 * time.Second, Transport: base.NewProxyTransport("")}}, nil
+	case "feishu", "lark":
+		if c.URL == "" {
+			return nil, fmt.Errorf("notifier %s: url is required", name)
+		}
+		base.RegisterEndpoint("notifier", "", name, c.URL)
+		return &FeishuNotifier{name: name, url: c.URL, secret: c.Secret, template: tmpl, cli: &http.Client{Timeout: 10 * time.Second, Transport: base.NewProxyTransport("")}}, nil
+	case "wecom":
+		if c.URL == "" {
+			return nil, fmt.Errorf("notifier %s: url is required", name)
+		}
+		base.RegisterEndpoint("notifier", "", name, c.URL)
+		return &WeComNotifier{name: name, url: c.URL, template: tmpl, cli: &http.Client{Timeout: 10 * time.Second, Transport: base.NewProxyTransport("")}}, nil
+	case "opsgenie":
+		return newOpsGenieNotifier(name, c, tmpl)
+	case "email":
+		return newEmailNotifier(name, c)
+	case "twilio":
+		return newTwilioNotifier(name, c.Twilio, tmpl)
+	}
+	return nil, fmt.Errorf("notifier %s: unknown type %q", name, c.Type)
+}
+
+// WebhookNotifier posts notifications as JSON to a URL
+type WebhookNotifier struct {
+	name     string
+	url      string
+	template *messageTemplate
+	cli      *http.Client
+}
+
+// webhookPayload is the notification with the rendered template, if any
+type webhookPayload struct {
+	Notification
+	Message string `json:"message,omitempty"`
+}
+
+func (w *WebhookNotifier) Name() string {
+	return w.name
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	payload := webhookPayload{Notification: n}
+	if w.template != nil {
+		message, err := w.template.render(n)
+		if err != nil {
+			return err
+		}
+		payload.Message = message
+	}
+	return postJSON(ctx, w.cli, w.name, w.url, payload)
+}
+
+// postJSON posts body as JSON and fails on non-2xx responses
+func postJSON(ctx context.Context, cli *http.Client, name, url string, body interface{}) error {
+	return postJSONChecked(ctx, cli, name, url, nil, body, nil)
+}
+
+// postJSONChecked posts body as JSON with additional headers and fails on
+// non-2xx responses or when check rejects the response body, for APIs
+// reporting errors with status 200
+func postJSONChecked(ctx context.Context, cli *http.Client, name, url string, header http.Header, body interface{}, check func([]byte) error) error {
+	client := base.NewHTTPClient(cli)
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	client.Payload = payload
+
+	resp, err := client.Req(ctx, url, http.MethodPost, header)
+	if err != nil {
+		base.UpdateEndpointStatus("notifier", "", name, false)
+		return err
+	}
+	defer resp.Body.Close()
+
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	base.UpdateEndpointStatus("notifier", "", name, ok)
+	if !ok {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, msg)
+	}
+	if check == nil {
+		return nil
+	}
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if err := check(respBody); err != nil {
+		base.UpdateEndpointStatus("notifier", "", name, false)
+		return err
+	}
+	return nil
+}
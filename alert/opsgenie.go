@@ -0,0 +1,151 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+const defaultOpsGenieURL = "https://api.opsgenie.com"
+
+// defaultOpsGeniePriorities maps severities to OpsGenie priorities
+var defaultOpsGeniePriorities = map[string]string{
+	SeverityCritical: "P1",
+	SeverityWarning:  "P3",
+	SeverityInfo:     "P5",
+}
+
+// OpsGenieNotifier creates an OpsGenie alert per incident and acknowledges
+// and closes it with the incident
+type OpsGenieNotifier struct {
+	name       string
+	url        string
+	apiKey     string
+	teams      []string
+	priorities map[string]string
+	template   *messageTemplate
+	cli        *http.Client
+}
+
+type opsGenieResponder struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type opsGenieAlert struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Responders  []opsGenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Source      string              `json:"source"`
+	Priority    string              `json:"priority"`
+}
+
+type opsGenieAction struct {
+	Source string `json:"source"`
+	User   string `json:"user,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+func newOpsGenieNotifier(name string, c *conf.Notifier, tmpl *messageTemplate) (*OpsGenieNotifier, error) {
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("notifier %s: api_key is required", name)
+	}
+	o := &OpsGenieNotifier{
+		name:       name,
+		url:        strings.TrimSuffix(c.URL, "/"),
+		apiKey:     c.APIKey,
+		teams:      c.Teams,
+		priorities: make(map[string]string, len(defaultOpsGeniePriorities)),
+		template:   tmpl,
+		cli:        &http.Client{Timeout: 10 * time.Second, Transport: base.NewProxyTransport("")},
+	}
+	if o.url == "" {
+		o.url = defaultOpsGenieURL
+	}
+	for severity, priority := range defaultOpsGeniePriorities {
+		o.priorities[severity] = priority
+	}
+	for severity, priority := range c.Priorities {
+		if _, ok := severityRank[severity]; !ok {
+			return nil, fmt.Errorf("notifier %s: unknown severity %q in priorities", name, severity)
+		}
+		switch priority {
+		case "P1", "P2", "P3", "P4", "P5":
+		default:
+			return nil, fmt.Errorf("notifier %s: invalid priority %q, expected P1 to P5", name, priority)
+		}
+		o.priorities[severity] = priority
+	}
+	base.RegisterEndpoint("notifier", "", name, o.url)
+	return o, nil
+}
+
+func (o *OpsGenieNotifier) Name() string {
+	return o.name
+}
+
+// alias identifies the OpsGenie alert of an incident, incident IDs restart
+// with the monitor so the opening time is part of it
+func opsGenieAlias(incident Incident) string {
+	return fmt.Sprintf("storymonitor-%s-%d", incident.ID, incident.OpenedAt.Unix())
+}
+
+func (o *OpsGenieNotifier) alert(n Notification) (opsGenieAlert, error) {
+	incident := n.Incident
+	message := incident.Title
+	// OpsGenie truncates messages beyond 130 characters
+	if len(message) > 130 {
+		message = message[:127] + "..."
+	}
+
+	description, err := renderOr(o.template, n, incidentText)
+	if err != nil {
+		return opsGenieAlert{}, err
+	}
+	a := opsGenieAlert{
+		Message:     message,
+		Alias:       opsGenieAlias(incident),
+		Description: description,
+		Tags:        []string{"storymonitor", incident.Severity},
+		Details:     map[string]string{"incident": incident.ID},
+		Source:      "storymonitor",
+		Priority:    o.priorities[incident.Severity],
+	}
+	if incident.FailureDomain != "" {
+		a.Details["failure_domain"] = incident.FailureDomain
+	}
+	for _, team := range o.teams {
+		a.Responders = append(a.Responders, opsGenieResponder{Name: team, Type: "team"})
+	}
+	return a, nil
+}
+
+func (o *OpsGenieNotifier) Notify(ctx context.Context, n Notification) error {
+	header := http.Header{}
+	header.Set("Authorization", "GenieKey "+o.apiKey)
+
+	alias := url.PathEscape(opsGenieAlias(n.Incident))
+	switch n.Event {
+	case EventAcknowledged:
+		return postJSONChecked(ctx, o.cli, o.name, o.url+"/v2/alerts/"+alias+"/acknowledge?identifierType=alias", header,
+			opsGenieAction{Source: "storymonitor", User: n.Incident.AcknowledgedBy}, nil)
+	case EventResolved:
+		return postJSONChecked(ctx, o.cli, o.name, o.url+"/v2/alerts/"+alias+"/close?identifierType=alias", header,
+			opsGenieAction{Source: "storymonitor", Note: "incident resolved"}, nil)
+	}
+	// Alerts with the alias of an open alert are deduplicated by OpsGenie
+	a, err := o.alert(n)
+	if err != nil {
+		return err
+	}
+	return postJSONChecked(ctx, o.cli, o.name, o.url+"/v2/alerts", header, a, nil)
+}
@@ -0,0 +1,108 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"storymonitor/conf"
+	"storymonitor/maintenance"
+)
+
+// Route sends the incidents of a severity to its notifiers, while its
+// schedule is active
+type Route struct {
+	Severity  string
+	Notifiers []Notifier
+	// Schedule limits when the route notifies, nil notifies at any time
+	Schedule *maintenance.Schedule
+}
+
+// NewRoutes resolves the notifier names of the configured routes. Schedule
+// windows without a timezone are evaluated in timezone, default UTC.
+func NewRoutes(configs []*conf.AlertRoute, notifiers []Notifier, timezone string) ([]*Route, error) {
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	byName := make(map[string]Notifier, len(notifiers))
+	for _, n := range notifiers {
+		byName[n.Name()] = n
+	}
+
+	routes := make([]*Route, 0, len(configs))
+	for i, c := range configs {
+		if c == nil {
+			continue
+		}
+		if _, ok := severityRank[c.Severity]; !ok {
+			return nil, fmt.Errorf("routes[%d]: unknown severity %q, expected info, warning or critical", i, c.Severity)
+		}
+		route := &Route{Severity: c.Severity}
+		for _, name := range c.Notifiers {
+			n, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("routes[%d]: unknown notifier %q", i, name)
+			}
+			// Email notifiers of the route send to the recipients of the route
+			if e, ok := n.(*EmailNotifier); ok && len(c.To) > 0 {
+				n = e.withRecipients(c.To)
+			}
+			route.Notifiers = append(route.Notifiers, n)
+		}
+
+		if len(c.Schedule) > 0 {
+			windows := make([]*conf.MaintenanceWindow, 0, len(c.Schedule))
+			for _, w := range c.Schedule {
+				if w != nil && w.Timezone == "" {
+					copied := *w
+					copied.Timezone = timezone
+					w = &copied
+				}
+				windows = append(windows, w)
+			}
+			if err := maintenance.Validate(windows); err != nil {
+				return nil, fmt.Errorf("routes[%d].schedule: %w", i, err)
+			}
+			route.Schedule = maintenance.New(windows)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// WithRoutes sends the incidents of each severity to the notifiers of its
+// routes, severities without a route are sent to all notifiers
+func WithRoutes(routes []*Route) Option {
+	return func(m *Manager) {
+		m.routes = routes
+	}
+}
+
+// notifiersFor returns the notifiers of the routes of a severity that are
+// active at now, each notifier once
+func (m *Manager) notifiersFor(severity string, now time.Time) []Notifier {
+	routed := false
+	seen := make(map[string]bool)
+	var notifiers []Notifier
+	for _, route := range m.routes {
+		if route.Severity != severity {
+			continue
+		}
+		routed = true
+		if route.Schedule != nil && !route.Schedule.Active(now) {
+			continue
+		}
+		for _, n := range route.Notifiers {
+			if !seen[n.Name()] {
+				seen[n.Name()] = true
+				notifiers = append(notifiers, n)
+			}
+		}
+	}
+	if !routed {
+		return m.notifiers
+	}
+	return notifiers
+}
@@ -0,0 +1,201 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+// Alert severities, from least to most severe
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+var severityRank = map[string]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// Rule holds back and deduplicates the alerts of matching checks
+type Rule struct {
+	Check     string
+	ChainName string
+	Severity  string
+	// For is how long a check fails before its alert fires
+	For time.Duration
+	// Hold is how long a check stays healthy before its alert resolves
+	Hold time.Duration
+	// Repeat overrides the repeat interval of incidents with alerts of the rule
+	Repeat time.Duration
+	// FlapThreshold health changes within FlapWindow mark a check as flapping
+	FlapThreshold int
+	FlapWindow    time.Duration
+}
+
+// NewRules converts the configured alerting rules
+func NewRules(configs []*conf.AlertingRule) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(configs))
+	for i, c := range configs {
+		if c == nil {
+			continue
+		}
+		if c.ForSecond < 0 || c.HoldSecond < 0 || c.RepeatIntervalSecond < 0 || c.FlapThreshold < 0 || c.FlapWindowSecond < 0 {
+			return nil, fmt.Errorf("rules[%d]: durations and flap_threshold must not be negative", i)
+		}
+		if c.FlapThreshold == 1 {
+			return nil, fmt.Errorf("rules[%d]: flap_threshold must be at least 2", i)
+		}
+		severity := c.Severity
+		if severity == "" {
+			severity = SeverityCritical
+		}
+		if _, ok := severityRank[severity]; !ok {
+			return nil, fmt.Errorf("rules[%d]: unknown severity %q, expected info, warning or critical", i, c.Severity)
+		}
+		rule := &Rule{
+			Check:         c.Check,
+			ChainName:     c.ChainName,
+			Severity:      severity,
+			For:           time.Duration(c.ForSecond) * time.Second,
+			Hold:          time.Duration(c.HoldSecond) * time.Second,
+			Repeat:        time.Duration(c.RepeatIntervalSecond) * time.Second,
+			FlapThreshold: c.FlapThreshold,
+			FlapWindow:    10 * time.Minute,
+		}
+		if c.FlapWindowSecond > 0 {
+			rule.FlapWindow = time.Duration(c.FlapWindowSecond) * time.Second
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *Rule) matches(t base.HealthTransition) bool {
+	return (r.Check == "" || r.Check == t.Check) && (r.ChainName == "" || r.ChainName == t.ChainName)
+}
+
+// delayed reports whether the rule evaluates alerts after the transition
+func (r *Rule) delayed() bool {
+	return r.For > 0 || r.Hold > 0 || r.FlapThreshold > 0
+}
+
+// defaultRule fires critical alerts on every transition
+var defaultRule = &Rule{Severity: SeverityCritical}
+
+// Option configures a Manager
+type Option func(*Manager)
+
+// WithRules applies rules to the alerts, checks without a matching rule alert
+// on every transition
+func WithRules(rules []*Rule) Option {
+	return func(m *Manager) {
+		m.rules = rules
+	}
+}
+
+// checkStatus is the health of a check as seen by the rules
+type checkStatus struct {
+	rule *Rule
+	// last is the latest transition, its time is when the current health started
+	last     base.HealthTransition
+	fired    bool
+	flapping bool
+	changes  []time.Time
+}
+
+func (m *Manager) ruleFor(t base.HealthTransition) *Rule {
+	for _, rule := range m.rules {
+		if rule.matches(t) {
+			return rule
+		}
+	}
+	return defaultRule
+}
+
+// track records a transition of a check, it must be called with the lock held
+func (m *Manager) track(key string, t base.HealthTransition) *checkStatus {
+	st, ok := m.checks[key]
+	if !ok {
+		st = &checkStatus{rule: m.ruleFor(t)}
+		m.checks[key] = st
+	}
+	st.last = t
+
+	if rule := st.rule; rule.FlapThreshold > 0 {
+		st.changes = append(st.changes, t.Time)
+		kept := st.changes[:0]
+		for _, at := range st.changes {
+			if t.Time.Sub(at) <= rule.FlapWindow {
+				kept = append(kept, at)
+			}
+		}
+		st.changes = kept
+		if !st.flapping && len(st.changes) >= rule.FlapThreshold {
+			st.flapping = true
+			if st.fired {
+				m.timeline(t, t.Time, "%s flapping on %s, resolving after it is stable for %s", t.Check, t.HostName, rule.FlapWindow)
+			}
+		}
+	}
+	return st
+}
+
+// evaluate fires or resolves the alert of a check, it must be called with the lock held
+func (m *Manager) evaluate(key string, st *checkStatus, now time.Time) {
+	rule := st.rule
+	failing := !st.last.Healthy
+	elapsed := now.Sub(st.last.Time)
+
+	switch {
+	case failing && !st.fired && (st.flapping || elapsed >= rule.For):
+		st.fired = true
+		m.fireAlert(st.last, now, rule, st.flapping)
+	case !failing && st.fired && st.flapping && elapsed >= rule.FlapWindow:
+		st.fired = false
+		st.flapping = false
+		st.changes = nil
+		m.resolveAlert(st.last, now)
+	case !failing && st.fired && !st.flapping && elapsed >= rule.Hold:
+		st.fired = false
+		m.resolveAlert(st.last, now)
+	}
+
+	// Healthy checks are kept while their changes may still count as flapping
+	if !failing && !st.fired && (rule.FlapThreshold == 0 || elapsed > rule.FlapWindow) {
+		delete(m.checks, key)
+	}
+}
+
+// evaluateAll fires and resolves alerts whose for or hold duration elapsed
+func (m *Manager) evaluateAll(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, st := range m.checks {
+		m.evaluate(key, st, now)
+	}
+}
+
+// delayed reports whether any rule evaluates alerts after the transition
+func (m *Manager) delayed() bool {
+	for _, rule := range m.rules {
+		if rule.delayed() {
+			return true
+		}
+	}
+	return false
+}
+
+// timeline adds an entry to the open incident with the alert of t, it must be called with the lock held
+func (m *Manager) timeline(t base.HealthTransition, at time.Time, format string, args ...interface{}) {
+	key := t.ChainName + "/" + t.HostName + "/" + t.Check
+	for _, incident := range m.open {
+		if incident.alert(key) != nil {
+			incident.addTimeline(at, format, args...)
+		}
+	}
+}
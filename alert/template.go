@@ -0,0 +1,93 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is passed to notification templates
+type TemplateData struct {
+	Event    string
+	Incident Incident
+	// Alerts are the alerts of the incident with their labels
+	Alerts []TemplateAlert
+	// Vars are the vars of the notifier, e.g. a runbook base URL
+	Vars map[string]string
+}
+
+// TemplateAlert is an alert together with its labels: chain_name, hostname,
+// check, severity and failure_domain
+type TemplateAlert struct {
+	Alert
+	Labels map[string]string
+}
+
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// since formats the time elapsed since t, e.g. how long an alert fires
+	"since": func(t time.Time) string {
+		return time.Since(t).Round(time.Second).String()
+	},
+}
+
+func newTemplateData(n Notification, vars map[string]string) TemplateData {
+	data := TemplateData{
+		Event:    n.Event,
+		Incident: n.Incident,
+		Alerts:   make([]TemplateAlert, 0, len(n.Incident.Alerts)),
+		Vars:     vars,
+	}
+	for _, a := range n.Incident.Alerts {
+		data.Alerts = append(data.Alerts, TemplateAlert{
+			Alert: *a,
+			Labels: map[string]string{
+				"chain_name":     a.ChainName,
+				"hostname":       a.HostName,
+				"check":          a.Check,
+				"severity":       a.Severity,
+				"failure_domain": n.Incident.FailureDomain,
+			},
+		})
+	}
+	return data
+}
+
+// messageTemplate renders the message of a notification
+type messageTemplate struct {
+	tmpl *template.Template
+	vars map[string]string
+}
+
+// parseTemplate parses a notification template, it returns nil for an empty text
+func parseTemplate(name, text string, vars map[string]string) (*messageTemplate, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &messageTemplate{tmpl: tmpl, vars: vars}, nil
+}
+
+func (t *messageTemplate) render(n Notification) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, newTemplateData(n, t.vars)); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// renderOr renders the notification with t, or formats it with fallback if
+// t is nil
+func renderOr(t *messageTemplate, n Notification, fallback func(Notification) string) (string, error) {
+	if t == nil {
+		return fallback(n), nil
+	}
+	return t.render(n)
+}
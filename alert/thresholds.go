@@ -0,0 +1,192 @@
+package alert
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/events"
+)
+
+// Checks of the alerts raised by thresholds. Response time alerts are raised
+// per check of a node, e.g. http_response_time.
+const (
+	CheckBlockDelay         = "block_delay"
+	CheckHeightLag          = "height_lag"
+	checkResponseTimeSuffix = "_response_time"
+)
+
+// Thresholds are the limits of a node, zero disables a limit
+type Thresholds struct {
+	MaxBlockDelay   time.Duration
+	MaxResponseTime time.Duration
+	MaxHeightLag    uint64
+}
+
+// merge overrides the limits of t with the limits set in c
+func (t *Thresholds) merge(c *conf.Threshold) {
+	if c.MaxBlockDelaySecond > 0 {
+		t.MaxBlockDelay = time.Duration(c.MaxBlockDelaySecond) * time.Second
+	}
+	if c.MaxResponseTimeMs > 0 {
+		t.MaxResponseTime = time.Duration(c.MaxResponseTimeMs) * time.Millisecond
+	}
+	if c.MaxHeightLagBlocks > 0 {
+		t.MaxHeightLag = c.MaxHeightLagBlocks
+	}
+}
+
+// ThresholdWatcher compares the heads and check durations of all nodes with
+// their thresholds. A node crossing a threshold publishes a health transition
+// on the event bus, so it alerts like a failing check.
+//
+// ThresholdWatcher implements base.HeadSink and base.CheckSink.
+type ThresholdWatcher struct {
+	configs []*conf.Threshold
+	bus     *events.Bus
+
+	mu         sync.Mutex
+	thresholds map[nodeKey]*Thresholds
+	heights    map[string]map[string]uint64
+	breached   map[string]bool
+}
+
+type nodeKey struct {
+	chainName, hostName string
+}
+
+// NewThresholdWatcher validates the configured thresholds
+func NewThresholdWatcher(configs []*conf.Threshold) (*ThresholdWatcher, error) {
+	for i, c := range configs {
+		if c == nil {
+			continue
+		}
+		if c.MaxBlockDelaySecond < 0 || c.MaxResponseTimeMs < 0 {
+			return nil, fmt.Errorf("thresholds[%d]: thresholds must not be negative", i)
+		}
+		if c.HostName != "" && c.ChainName == "" {
+			return nil, fmt.Errorf("thresholds[%d]: hostname requires chain_name", i)
+		}
+	}
+	return &ThresholdWatcher{
+		configs:    configs,
+		bus:        events.Default,
+		thresholds: make(map[nodeKey]*Thresholds),
+		heights:    make(map[string]map[string]uint64),
+		breached:   make(map[string]bool),
+	}, nil
+}
+
+// Thresholds returns the limits of a node: the entries without a chain, then
+// those of its chain and finally those of the node itself
+func (w *ThresholdWatcher) Thresholds(chainName, hostName string) Thresholds {
+	var t Thresholds
+	for _, specific := range []func(*conf.Threshold) bool{
+		func(c *conf.Threshold) bool { return c.ChainName == "" },
+		func(c *conf.Threshold) bool { return c.ChainName == chainName && c.HostName == "" },
+		func(c *conf.Threshold) bool { return c.ChainName == chainName && c.HostName == hostName },
+	} {
+		for _, c := range w.configs {
+			if c != nil && specific(c) {
+				t.merge(c)
+			}
+		}
+	}
+	return t
+}
+
+// thresholdsOf returns the cached limits of a node, exporting them on first
+// use. It is called with w.mu held.
+func (w *ThresholdWatcher) thresholdsOf(chainName, hostName string) *Thresholds {
+	key := nodeKey{chainName, hostName}
+	if t, ok := w.thresholds[key]; ok {
+		return t
+	}
+	t := w.Thresholds(chainName, hostName)
+	w.thresholds[key] = &t
+
+	if t.MaxBlockDelay > 0 {
+		base.BlockDelayThreshold.WithLabelValues(chainName, hostName).Set(t.MaxBlockDelay.Seconds())
+	}
+	if t.MaxResponseTime > 0 {
+		base.ResponseTimeThreshold.WithLabelValues(chainName, hostName).Set(float64(t.MaxResponseTime.Milliseconds()))
+	}
+	if t.MaxHeightLag > 0 {
+		base.HeightLagThreshold.WithLabelValues(chainName, hostName).Set(float64(t.MaxHeightLag))
+	}
+	return &t
+}
+
+// AppendHead checks the block delay of the head and the height lag of all
+// nodes of its chain
+func (w *ThresholdWatcher) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if t := w.thresholdsOf(chainName, hostName); t.MaxBlockDelay > 0 && !blockTime.IsZero() {
+		delay := receivedAt.Sub(blockTime)
+		w.observe(chainName, hostName, CheckBlockDelay, delay <= t.MaxBlockDelay, receivedAt,
+			"block %d arrived %s after its timestamp, threshold %s", height, delay.Round(time.Millisecond), t.MaxBlockDelay)
+	}
+
+	heights, ok := w.heights[chainName]
+	if !ok {
+		heights = make(map[string]uint64)
+		w.heights[chainName] = heights
+	}
+	heights[hostName] = height
+
+	var highest uint64
+	for _, h := range heights {
+		highest = max(highest, h)
+	}
+	for host, h := range heights {
+		if t := w.thresholdsOf(chainName, host); t.MaxHeightLag > 0 {
+			lag := highest - h
+			w.observe(chainName, host, CheckHeightLag, lag <= t.MaxHeightLag, receivedAt,
+				"%d blocks behind the highest node of the chain, threshold %d", lag, t.MaxHeightLag)
+		}
+	}
+}
+
+// AppendCheck checks the response time of a check
+func (w *ThresholdWatcher) AppendCheck(chainName, hostName, check string, healthy bool, duration time.Duration, at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if t := w.thresholdsOf(chainName, hostName); t.MaxResponseTime > 0 {
+		w.observe(chainName, hostName, check+checkResponseTimeSuffix, duration <= t.MaxResponseTime, at,
+			"%s check took %s, threshold %s", check, duration.Round(time.Millisecond), t.MaxResponseTime)
+	}
+}
+
+// observe publishes a transition when a node crosses a threshold. Like the
+// checks, a node within its threshold on first observation publishes nothing
+// and silenced nodes are ignored. It is called with w.mu held.
+func (w *ThresholdWatcher) observe(chainName, hostName, check string, healthy bool, at time.Time, format string, args ...interface{}) {
+	if base.IsSilenced(chainName, hostName) {
+		return
+	}
+	key := chainName + "/" + hostName + "/" + check
+	breached, known := w.breached[key]
+	if (known && breached == !healthy) || (!known && healthy) {
+		return
+	}
+	w.breached[key] = !healthy
+
+	e := events.Event{
+		Kind:      events.KindTransition,
+		Time:      at,
+		ChainName: chainName,
+		HostName:  hostName,
+		Check:     check,
+		Healthy:   healthy,
+		Initial:   !known,
+	}
+	if !healthy {
+		e.Error = fmt.Sprintf(format, args...)
+	}
+	w.bus.Publish(e)
+}
@@ -0,0 +1,139 @@
+package alert
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+)
+
+const defaultTwilioURL = "https://api.twilio.com"
+
+// TwilioNotifier sends critical incidents as SMS through Twilio, as a last
+// resort when chat based paging fails. Messages to each recipient are rate
+// limited.
+type TwilioNotifier struct {
+	name       string
+	url        string
+	accountSID string
+	authToken  string
+	from       string
+	to         []string
+	maxPerHour int
+	template   *messageTemplate
+	cli        *http.Client
+
+	mu   sync.Mutex
+	sent map[string][]time.Time
+}
+
+func newTwilioNotifier(name string, c *conf.Twilio, tmpl *messageTemplate) (*TwilioNotifier, error) {
+	if c == nil || c.AccountSID == "" || c.AuthToken == "" {
+		return nil, fmt.Errorf("notifier %s: twilio.account_sid and twilio.auth_token are required", name)
+	}
+	if c.From == "" || len(c.To) == 0 {
+		return nil, fmt.Errorf("notifier %s: twilio.from and twilio.to are required", name)
+	}
+	if c.MaxPerHour < 0 {
+		return nil, fmt.Errorf("notifier %s: twilio.max_per_hour must not be negative", name)
+	}
+	t := &TwilioNotifier{
+		name:       name,
+		url:        fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", defaultTwilioURL, url.PathEscape(c.AccountSID)),
+		accountSID: c.AccountSID,
+		authToken:  c.AuthToken,
+		from:       c.From,
+		to:         c.To,
+		maxPerHour: c.MaxPerHour,
+		template:   tmpl,
+		cli:        &http.Client{Timeout: 10 * time.Second, Transport: base.NewProxyTransport("")},
+		sent:       make(map[string][]time.Time),
+	}
+	if t.maxPerHour == 0 {
+		t.maxPerHour = 5
+	}
+	base.RegisterEndpoint("notifier", "", name, defaultTwilioURL)
+	return t, nil
+}
+
+func (t *TwilioNotifier) Name() string {
+	return t.name
+}
+
+// allow reports whether another message may be sent to a recipient and
+// records it if so
+func (t *TwilioNotifier) allow(to string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	kept := t.sent[to][:0]
+	for _, at := range t.sent[to] {
+		if now.Sub(at) < time.Hour {
+			kept = append(kept, at)
+		}
+	}
+	if len(kept) >= t.maxPerHour {
+		t.sent[to] = kept
+		return false
+	}
+	t.sent[to] = append(kept, now)
+	return true
+}
+
+// smsText formats a notification as a short SMS
+func smsText(n Notification) string {
+	incident := n.Incident
+	return fmt.Sprintf("[%s] %s: %s (incident %s, %d firing)", incident.Severity, n.Event, incident.Title, incident.ID, incident.firing())
+}
+
+func (t *TwilioNotifier) Notify(ctx context.Context, n Notification) error {
+	// Only critical incidents page by SMS, acknowledgements need no page
+	if n.Incident.Severity != SeverityCritical || n.Event == EventAcknowledged {
+		return nil
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(t.accountSID+":"+t.authToken)))
+	text, err := renderOr(t.template, n, smsText)
+	if err != nil {
+		return err
+	}
+	// Longer messages are split into several billed segments
+	if len(text) > 320 {
+		text = text[:317] + "..."
+	}
+
+	var errs []error
+	for _, to := range t.to {
+		if !t.allow(to, time.Now()) {
+			glog.Warningf("[alert] Notifier %s rate limited, not sending %s of incident %s to %s", t.name, n.Event, n.Incident.ID, to)
+			continue
+		}
+
+		client := base.NewHTTPClient(t.cli)
+		client.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+		client.Payload = []byte(url.Values{"From": {t.from}, "To": {to}, "Body": {text}}.Encode())
+		resp, err := client.Req(ctx, t.url, http.MethodPost, header)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", to, err))
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			errs = append(errs, fmt.Errorf("%s: unexpected status %d", to, resp.StatusCode))
+		}
+	}
+	base.UpdateEndpointStatus("notifier", "", t.name, len(errs) == 0)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send %d of %d messages: %v", len(errs), len(t.to), errs)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package anomaly scores the block intervals of every chain against a rolling
+// baseline, catching a chain that degrades gradually without ever crossing a
+// fixed threshold.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/events"
+
+	"github.com/golang/glog"
+)
+
+// CheckBlockInterval is the check of the alerts raised for anomalous chains
+const CheckBlockInterval = "block_interval_anomaly"
+
+// minStddev keeps the score finite on chains with perfectly regular blocks,
+// block timestamps have a resolution of a second
+const minStddev = 0.5
+
+// Detector keeps an exponentially weighted moving average and variance of the
+// block intervals of every chain, from the timestamps of the first node to
+// report each height. It implements base.HeadSink.
+type Detector struct {
+	alpha  float64
+	factor float64
+	warmup int
+	alert  bool
+	bus    *events.Bus
+
+	mu     sync.Mutex
+	chains map[string]*baseline
+}
+
+type baseline struct {
+	height    uint64
+	blockTime time.Time
+
+	mean     float64
+	variance float64
+	samples  int
+	// anomalous is the state of the alert, nil until the chain is scored
+	anomalous *bool
+}
+
+// Validate checks the anomaly detection config, a nil config is valid
+func Validate(c *conf.Anomaly) error {
+	if c == nil {
+		return nil
+	}
+	if c.Alpha < 0 || c.Alpha > 1 {
+		return fmt.Errorf("anomaly.alpha must be between 0 and 1, got %v", c.Alpha)
+	}
+	if c.Factor < 0 || c.WarmupBlocks < 0 {
+		return fmt.Errorf("anomaly.factor and anomaly.warmup_blocks must not be negative")
+	}
+	return nil
+}
+
+// NewDetector creates a detector, c must be valid
+func NewDetector(c *conf.Anomaly) *Detector {
+	d := &Detector{
+		alpha:  0.05,
+		factor: 4,
+		warmup: 100,
+		alert:  c.Alert,
+		bus:    events.Default,
+		chains: make(map[string]*baseline),
+	}
+	if c.Alpha > 0 {
+		d.alpha = c.Alpha
+	}
+	if c.Factor > 0 {
+		d.factor = c.Factor
+	}
+	if c.WarmupBlocks > 0 {
+		d.warmup = c.WarmupBlocks
+	}
+	return d
+}
+
+// AppendHead scores the interval of a new height of the chain. Heights
+// already reported by another node are ignored, skipped heights share the
+// interval evenly.
+func (d *Detector) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b, ok := d.chains[chainName]
+	if !ok {
+		d.chains[chainName] = &baseline{height: height, blockTime: blockTime}
+		return
+	}
+	if height <= b.height {
+		return
+	}
+	interval := blockTime.Sub(b.blockTime).Seconds() / float64(height-b.height)
+	b.height, b.blockTime = height, blockTime
+	if interval < 0 {
+		return
+	}
+
+	score, scored := b.observe(interval, d.alpha, d.warmup)
+	base.BlockIntervalBaseline.WithLabelValues(chainName).Set(b.mean)
+	base.BlockIntervalStddev.WithLabelValues(chainName).Set(math.Sqrt(b.variance))
+	if !scored {
+		return
+	}
+	base.BlockIntervalAnomalyScore.WithLabelValues(chainName).Set(score)
+
+	anomalous := score > d.factor
+	if b.anomalous != nil && *b.anomalous == anomalous {
+		return
+	}
+	initial := b.anomalous == nil
+	b.anomalous = &anomalous
+	if initial && !anomalous {
+		return
+	}
+	if anomalous {
+		glog.Warningf("[anomaly] Block interval of %s is %.1fs, %.1f standard deviations from its baseline of %.1fs", chainName, interval, score, b.mean)
+	}
+	if d.alert {
+		d.publish(chainName, anomalous, initial, receivedAt, interval, score)
+	}
+}
+
+// observe scores interval against the baseline and then adds it. An interval
+// is only scored after warmup intervals have been added.
+func (b *baseline) observe(interval, alpha float64, warmup int) (float64, bool) {
+	b.samples++
+	if b.samples == 1 {
+		b.mean = interval
+		return 0, false
+	}
+
+	score := math.Abs(interval-b.mean) / math.Max(math.Sqrt(b.variance), minStddev)
+	diff := interval - b.mean
+	increment := alpha * diff
+	b.mean += increment
+	b.variance = (1 - alpha) * (b.variance + diff*increment)
+	return score, b.samples > warmup
+}
+
+// publish raises or resolves the alert of a chain, which applies to all of its nodes
+func (d *Detector) publish(chainName string, anomalous, initial bool, at time.Time, interval, score float64) {
+	e := events.Event{
+		Kind:      events.KindTransition,
+		Time:      at,
+		ChainName: chainName,
+		Check:     CheckBlockInterval,
+		Healthy:   !anomalous,
+		Initial:   initial,
+	}
+	if anomalous {
+		e.Error = fmt.Sprintf("block interval %.1fs is %.1f standard deviations from the baseline", interval, score)
+	}
+	d.bus.Publish(e)
+}
@@ -0,0 +1,76 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+	"storymonitor/events"
+)
+
+func TestDetector(t *testing.T) {
+	d := NewDetector(&conf.Anomaly{WarmupBlocks: 20, Alert: true})
+	d.bus = events.NewBus()
+	sub := d.bus.Subscribe("test", 16, events.KindTransition)
+	next := func() events.Event {
+		select {
+		case e := <-sub.C():
+			return e
+		default:
+			return events.Event{}
+		}
+	}
+
+	blockTime := time.Unix(1700000000, 0)
+	height := uint64(100)
+	head := func(host string, interval time.Duration) {
+		height++
+		blockTime = blockTime.Add(interval)
+		d.AppendHead("story", host, height, [32]byte{}, blockTime, time.Now())
+	}
+	d.AppendHead("story", "node-01", height, [32]byte{}, blockTime, time.Now())
+	for i := 0; i < 50; i++ {
+		head("node-01", time.Duration(2+i%2)*time.Second)
+		// The same height reported by another node is ignored
+		d.AppendHead("story", "node-02", height, [32]byte{}, blockTime, time.Now())
+	}
+	if e := next(); e.Check != "" {
+		t.Fatalf("expected no alert for regular blocks, got %+v", e)
+	}
+
+	head("node-01", 20*time.Second)
+	e := next()
+	if e.Check != CheckBlockInterval || e.Healthy || e.Initial || e.HostName != "" {
+		t.Fatalf("expected an anomaly alert, got %+v", e)
+	}
+
+	head("node-02", 2*time.Second)
+	if e := next(); e.Check != CheckBlockInterval || !e.Healthy {
+		t.Errorf("expected the anomaly to resolve, got %+v", e)
+	}
+}
+
+func TestBaselineWarmup(t *testing.T) {
+	var b baseline
+	for i := 0; i < 10; i++ {
+		if _, scored := b.observe(2, 0.1, 10); scored {
+			t.Fatalf("interval %d scored during warmup", i+1)
+		}
+	}
+	score, scored := b.observe(2, 0.1, 10)
+	if !scored || score != 0 {
+		t.Errorf("expected a zero score after warmup, got %v scored=%v", score, scored)
+	}
+	if b.mean != 2 {
+		t.Errorf("expected a baseline of 2s, got %v", b.mean)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(&conf.Anomaly{Alpha: 1.5}); err == nil {
+		t.Error("expected an error for alpha above 1")
+	}
+	if err := Validate(nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// admin wraps a handler so it requires the configured admin bearer token
+func (s *Server) admin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminConf == nil || s.adminConf.Token == "" {
+			writeError(w, http.StatusForbidden, fmt.Errorf("admin api is disabled, set admin.token to enable it"))
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminConf.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid admin token"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+type ackRequest struct {
+	Actor string `json:"actor"`
+}
+
+// acknowledge marks an incident as being handled, pausing repeat notifications
+func (s *Server) acknowledge(w http.ResponseWriter, r *http.Request) {
+	var req ackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Actor == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("actor is required"))
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := s.alerts.Acknowledge(id, req.Actor); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	incident, _ := s.alerts.Incident(id)
+	writeJSON(w, http.StatusOK, incident)
+}
+
+// audit lists recent operator actions
+func (s *Server) audit(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": s.alerts.Audit().Entries(),
+	})
+}
+
+// verifySlackSignature checks the v0 request signature Slack attaches to slash commands
+func verifySlackSignature(secret string, r *http.Request, body []byte) error {
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp")
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > 5*time.Minute || age < -5*time.Minute {
+		return fmt.Errorf("request timestamp too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("invalid request signature")
+	}
+	return nil
+}
+
+// slackCommand handles Slack slash commands such as "/storymonitor ack 12"
+func (s *Server) slackCommand(w http.ResponseWriter, r *http.Request) {
+	if s.adminConf == nil || s.adminConf.SlackSigningSecret == "" {
+		writeError(w, http.StatusForbidden, fmt.Errorf("slack commands are disabled"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := verifySlackSignature(s.adminConf.SlackSigningSecret, r, body); err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	actor := "slack:" + form.Get("user_name")
+	reply := s.runChatCommand(actor, form.Get("text"))
+	glog.Infof("[api] Slack command %q from %s", form.Get("text"), actor)
+	writeJSON(w, http.StatusOK, map[string]string{
+		"response_type": "in_channel",
+		"text":          reply,
+	})
+}
+
+// runChatCommand executes a chat bot command and returns the reply text
+func (s *Server) runChatCommand(actor, text string) string {
+	args := strings.Fields(text)
+	if len(args) == 0 {
+		return "usage: ack <incident id> | incidents"
+	}
+
+	switch args[0] {
+	case "ack":
+		if len(args) != 2 {
+			return "usage: ack <incident id>"
+		}
+		if err := s.alerts.Acknowledge(args[1], actor); err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("incident %s acknowledged by %s", args[1], actor)
+	case "incidents":
+		var lines []string
+		for _, incident := range s.alerts.Incidents() {
+			if incident.Status != "resolved" {
+				lines = append(lines, fmt.Sprintf("#%s [%s] %s", incident.ID, incident.Status, incident.Title))
+			}
+		}
+		if len(lines) == 0 {
+			return "no active incidents"
+		}
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("unknown command %q", args[0])
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"storymonitor/alert"
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+const (
+	testToken         = "admin-token"
+	testSigningSecret = "slack-secret"
+)
+
+// newTestServer serves the API of an alert manager with one open incident
+func newTestServer(t *testing.T) (*httptest.Server, *alert.Manager) {
+	t.Helper()
+	alerts := alert.NewManager(time.Minute, 0, nil, nil)
+	alerts.HandleTransition(base.HealthTransition{ChainName: "story", HostName: "node-01", Check: "http", Time: time.Now()})
+
+	mux := http.NewServeMux()
+	NewServer(nil, nil, alerts, nil, nil, nil, &conf.Admin{Token: testToken, SlackSigningSecret: testSigningSecret}).Register(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, alerts
+}
+
+func do(t *testing.T, method, url, authorization, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAdminToken(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	for _, authorization := range []string{"", testToken, "Basic " + testToken, "Bearer wrong"} {
+		if resp := do(t, "GET", server.URL+"/api/v1/audit", authorization, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want %d", authorization, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+	if resp := do(t, "GET", server.URL+"/api/v1/audit", "Bearer "+testToken, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("status %d with the bearer token, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	server, alerts := newTestServer(t)
+
+	if resp := do(t, "POST", server.URL+"/api/v1/incidents/1/ack", "Bearer "+testToken, `{}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status %d without an actor, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	resp := do(t, "POST", server.URL+"/api/v1/incidents/1/ack", "Bearer "+testToken, `{"actor":"alice"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var incident alert.Incident
+	if err := json.NewDecoder(resp.Body).Decode(&incident); err != nil {
+		t.Fatal(err)
+	}
+	if incident.Status != alert.StatusAcknowledged || incident.AcknowledgedBy != "alice" {
+		t.Errorf("incident %s acknowledged by %q", incident.Status, incident.AcknowledgedBy)
+	}
+	if resp := do(t, "POST", server.URL+"/api/v1/incidents/1/ack", "Bearer "+testToken, `{"actor":"bob"}`); resp.StatusCode != http.StatusConflict {
+		t.Errorf("status %d acknowledging twice, want %d", resp.StatusCode, http.StatusConflict)
+	}
+
+	resp = do(t, "GET", server.URL+"/api/v1/audit", "Bearer "+testToken, "")
+	var audit struct {
+		Entries []alert.AuditEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&audit); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Entries) != 1 || audit.Entries[0].Actor != "alice" || audit.Entries[0].Action != "acknowledge" || audit.Entries[0].Target != "incident/1" {
+		t.Errorf("audit entries = %+v", audit.Entries)
+	}
+	if len(alerts.Audit().Entries()) != 1 {
+		t.Errorf("expected the acknowledgement in the audit log")
+	}
+}
+
+// slackRequest signs a slash command like Slack
+func slackRequest(t *testing.T, serverURL, secret string, at time.Time, text string) *http.Response {
+	t.Helper()
+	body := url.Values{"user_name": {"carol"}, "text": {text}}.Encode()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req, err := http.NewRequest("POST", serverURL+"/api/v1/chat/slack", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestSlackCommand(t *testing.T) {
+	server, alerts := newTestServer(t)
+
+	if resp := slackRequest(t, server.URL, "wrong-secret", time.Now(), "ack 1"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %d with a wrong signature, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if resp := slackRequest(t, server.URL, testSigningSecret, time.Now().Add(-10*time.Minute), "ack 1"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %d with an old timestamp, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if incident, _ := alerts.Incident("1"); incident.Status != alert.StatusOpen {
+		t.Fatalf("unsigned commands changed the incident to %s", incident.Status)
+	}
+
+	resp := slackRequest(t, server.URL, testSigningSecret, time.Now(), "ack 1")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var reply map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["text"] != "incident 1 acknowledged by slack:carol" {
+		t.Errorf("reply = %q", reply["text"])
+	}
+	if incident, _ := alerts.Incident("1"); incident.AcknowledgedBy != "slack:carol" {
+		t.Errorf("incident acknowledged by %q", incident.AcknowledgedBy)
+	}
+}
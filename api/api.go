@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"storymonitor/alert"
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/events"
+	"storymonitor/heads"
+	"storymonitor/history"
+	"storymonitor/sched"
+	"storymonitor/sla"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/glog"
+)
+
+// Server serves the monitor's JSON API under /api/v1
+type Server struct {
+	controller *sched.Controller
+	heads      *heads.Tracker
+	alerts     *alert.Manager
+	downtime   *sla.Tracker
+	history    *history.Store
+	events     *events.Recorder
+	adminConf  *conf.Admin
+}
+
+// NewServer creates the API server, store is nil when history is disabled
+func NewServer(controller *sched.Controller, tracker *heads.Tracker, alerts *alert.Manager, downtime *sla.Tracker, store *history.Store, recent *events.Recorder, adminConf *conf.Admin) *Server {
+	return &Server{
+		controller: controller,
+		heads:      tracker,
+		alerts:     alerts,
+		downtime:   downtime,
+		history:    store,
+		events:     recent,
+		adminConf:  adminConf,
+	}
+}
+
+// Register adds the API routes to mux
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/chains/{chain}/head", s.chainHead)
+	mux.HandleFunc("GET /api/v1/inventory", s.inventory)
+	mux.HandleFunc("GET /stats", s.stats)
+	mux.HandleFunc("GET /api/v1/nodes/{host}/status", s.nodeStatus)
+	mux.HandleFunc("GET /api/v1/incidents", s.incidents)
+	mux.HandleFunc("GET /api/v1/incidents/{id}", s.incident)
+	mux.HandleFunc("GET /api/silences", s.silences)
+	mux.HandleFunc("GET /api/v1/uptime", s.uptime)
+	mux.HandleFunc("GET /api/v1/history", s.historyExport)
+	mux.HandleFunc("GET /api/v1/events", s.recentEvents)
+	mux.HandleFunc("GET /ui/incidents", s.incidentsPage)
+
+	// Admin API
+	mux.HandleFunc("POST /api/v1/incidents/{id}/ack", s.admin(s.acknowledge))
+	mux.HandleFunc("GET /api/v1/audit", s.admin(s.audit))
+	mux.HandleFunc("POST /api/silences", s.admin(s.createSilence))
+	mux.HandleFunc("DELETE /api/silences/{id}", s.admin(s.expireSilence))
+	mux.HandleFunc("POST /api/targets/{chain}/{host}/restart", s.admin(s.restartTarget))
+	mux.HandleFunc("POST /api/v1/chat/slack", s.slackCommand)
+
+	// Heartbeats of pushing nodes, authenticated by their signature
+	mux.HandleFunc("POST /api/heartbeat/{host}", s.heartbeat)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		glog.Errorf("[api] Failed to encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+type chainHeadResponse struct {
+	Chain       string   `json:"chain"`
+	Height      uint64   `json:"height"`
+	Hash        string   `json:"hash"`
+	Nodes       int      `json:"nodes"`
+	Required    int      `json:"required"`
+	Agreeing    []string `json:"agreeing"`
+	Disagreeing []string `json:"disagreeing"`
+	Missing     []string `json:"missing"`
+}
+
+// chainHead returns the quorum-agreed head of a chain
+func (s *Server) chainHead(w http.ResponseWriter, r *http.Request) {
+	chain := r.PathValue("chain")
+	q, err := s.heads.Quorum(chain)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, chainHeadResponse{
+		Chain:       q.Chain,
+		Height:      q.Height,
+		Hash:        hexutil.Encode(q.Hash[:]),
+		Nodes:       q.Nodes,
+		Required:    q.Required,
+		Agreeing:    q.Agreeing,
+		Disagreeing: q.Disagreeing,
+		Missing:     q.Missing,
+	})
+}
+
+// inventory lists every external endpoint the monitor talks to
+func (s *Server) inventory(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"endpoints": base.Endpoints(),
+	})
+}
+
+// stats returns the controller statistics with per-checker detail
+func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.controller.GetStats())
+}
+
+// incidents lists all incidents, most recently opened first
+func (s *Server) incidents(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	result := make([]alert.Incident, 0)
+	for _, incident := range s.alerts.Incidents() {
+		if status == "" || incident.Status == status {
+			result = append(result, incident)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"incidents": result,
+	})
+}
+
+// incident returns a single incident with its timeline
+func (s *Server) incident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	incident, ok := s.alerts.Incident(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("incident %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, incident)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"storymonitor/events"
+)
+
+// recentEvents lists recent transitions, state changes and check errors of
+// all checkers, most recent first, filtered by ?kind=, ?chain_name=,
+// ?hostname= and limited by ?limit=
+func (s *Server) recentEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+
+	recorded := s.events.Events()
+	result := make([]events.Event, 0, limit)
+	for i := len(recorded) - 1; i >= 0 && len(result) < limit; i-- {
+		e := recorded[i]
+		if kind := q.Get("kind"); kind != "" && string(e.Kind) != kind {
+			continue
+		}
+		if chain := q.Get("chain_name"); chain != "" && e.ChainName != chain {
+			continue
+		}
+		if host := q.Get("hostname"); host != "" && e.HostName != host {
+			continue
+		}
+		result = append(result, e)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"events": result,
+	})
+}
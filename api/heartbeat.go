@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"storymonitor/heartbeat"
+
+	"github.com/golang/glog"
+)
+
+// heartbeat accepts a signed heartbeat pushed by a node
+func (s *Server) heartbeat(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	host := r.PathValue("host")
+	err = heartbeat.Receive(host, body, r.Header.Get(heartbeat.SignatureHeader))
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, heartbeat.ErrUnknownHost):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, heartbeat.ErrInvalidSignature):
+		glog.Warningf("[api] Rejected heartbeat of %s from %s: %v", host, r.RemoteAddr, err)
+		writeError(w, http.StatusUnauthorized, err)
+	default:
+		writeError(w, http.StatusBadRequest, err)
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"storymonitor/history"
+
+	"github.com/golang/glog"
+)
+
+var historyColumns = []string{"time", "chain_name", "hostname", "type", "check", "healthy", "latency_ms", "height"}
+
+// parseHistoryFilter reads the query of a history request. target is a
+// hostname or chain_name/hostname, from and to are RFC3339 and default to the
+// last 24 hours.
+func parseHistoryFilter(r *http.Request) (history.Filter, error) {
+	query := r.URL.Query()
+	f := history.Filter{
+		ChainName: query.Get("chain_name"),
+		HostName:  query.Get("hostname"),
+		Type:      query.Get("type"),
+		To:        time.Now(),
+	}
+	if target := query.Get("target"); target != "" {
+		if chain, host, ok := strings.Cut(target, "/"); ok {
+			f.ChainName, f.HostName = chain, host
+		} else {
+			f.HostName = target
+		}
+	}
+	switch f.Type {
+	case "", history.TypeCheck, history.TypeHead, history.TypeTransition:
+	default:
+		return f, fmt.Errorf("unknown type %q", f.Type)
+	}
+
+	var err error
+	if to := query.Get("to"); to != "" {
+		if f.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return f, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	f.From = f.To.Add(-24 * time.Hour)
+	if from := query.Get("from"); from != "" {
+		if f.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return f, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if !f.To.After(f.From) {
+		return f, fmt.Errorf("to must be after from")
+	}
+	return f, nil
+}
+
+// historyExport exports recorded samples and state transitions as JSON or, with
+// ?format=csv, as CSV
+func (s *Server) historyExport(w http.ResponseWriter, r *http.Request) {
+	if s.history == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("history is disabled, set history.path to enable it"))
+		return
+	}
+	f, err := parseHistoryFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	samples, err := s.history.Query(f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		if samples == nil {
+			samples = []history.Sample{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"from":    f.From,
+			"to":      f.To,
+			"samples": samples,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="history.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Write(historyColumns)
+	for _, sample := range samples {
+		height := ""
+		if sample.Type == history.TypeHead {
+			height = strconv.FormatUint(sample.Height, 10)
+		}
+		latency := ""
+		if sample.Type == history.TypeCheck {
+			latency = strconv.FormatFloat(sample.LatencyMs, 'f', -1, 64)
+		}
+		healthy := ""
+		if sample.Type != history.TypeHead {
+			healthy = strconv.FormatBool(sample.Healthy)
+		}
+		cw.Write([]string{
+			sample.Time.Format(time.RFC3339Nano),
+			sample.ChainName,
+			sample.HostName,
+			sample.Type,
+			sample.Check,
+			healthy,
+			latency,
+			height,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		glog.Errorf("[api] Failed to write history csv: %v", err)
+	}
+}
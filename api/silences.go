@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+type silenceRequest struct {
+	ChainName string `json:"chain_name"`
+	HostName  string `json:"hostname"`
+	// Duration is a Go duration such as "30m" or "2h"
+	Duration string `json:"duration"`
+	Comment  string `json:"comment"`
+	Actor    string `json:"actor"`
+	Metrics  bool   `json:"metrics"`
+}
+
+// silences lists the active silences
+func (s *Server) silences(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"silences": base.Silences(),
+	})
+}
+
+// createSilence suppresses alerting, and optionally availability metrics, of
+// matching nodes for a duration
+func (s *Server) createSilence(w http.ResponseWriter, r *http.Request) {
+	var req silenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Actor == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("actor is required"))
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid duration %q: %w", req.Duration, err))
+		return
+	}
+
+	now := time.Now()
+	silence, err := base.AddSilence(base.Silence{
+		ChainName: req.ChainName,
+		HostName:  req.HostName,
+		Comment:   req.Comment,
+		CreatedBy: req.Actor,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		Metrics:   req.Metrics,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.saveSilences()
+
+	target := fmt.Sprintf("silence/%s", silence.ID)
+	s.alerts.Audit().Record(req.Actor, "silence", target,
+		fmt.Sprintf("chain_name=%q hostname=%q until %s: %s", req.ChainName, req.HostName, silence.EndsAt.Format(time.RFC3339), req.Comment))
+	glog.Infof("[api] Silence %s created by %s for chain %q host %q until %s",
+		silence.ID, req.Actor, req.ChainName, req.HostName, silence.EndsAt.Format(time.RFC3339))
+	writeJSON(w, http.StatusCreated, silence)
+}
+
+// expireSilence ends a silence early
+func (s *Server) expireSilence(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !base.ExpireSilence(id) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("silence %s not found", id))
+		return
+	}
+	s.saveSilences()
+
+	actor := r.URL.Query().Get("actor")
+	if actor == "" {
+		actor = "admin"
+	}
+	s.alerts.Audit().Record(actor, "expire_silence", "silence/"+id, "")
+	glog.Infof("[api] Silence %s expired by %s", id, actor)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// saveSilences stores the active silences in the history database, if
+// enabled, so they survive restarts
+func (s *Server) saveSilences() {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.SaveSilences(base.Silences()); err != nil {
+		glog.Errorf("[api] Failed to save silences: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"storymonitor/alert"
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/history"
+)
+
+func TestSilences(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(store.Stop)
+	alerts := alert.NewManager(time.Minute, 0, nil, nil)
+	mux := http.NewServeMux()
+	NewServer(nil, nil, alerts, nil, store, nil, &conf.Admin{Token: testToken}).Register(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	body := `{"chain_name": "story", "hostname": "silenced-node", "duration": "1h", "actor": "alice", "comment": "disk swap"}`
+	if resp := do(t, "POST", server.URL+"/api/silences", "", body); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status %d without a token, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if resp := do(t, "POST", server.URL+"/api/silences", "Bearer "+testToken, `{"hostname": "silenced-node", "duration": "soon", "actor": "alice"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status %d with an invalid duration, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	resp := do(t, "POST", server.URL+"/api/silences", "Bearer "+testToken, body)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var created base.Silence
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if !base.IsSilenced("story", "silenced-node") {
+		t.Error("expected the node to be silenced")
+	}
+
+	var list struct {
+		Silences []base.Silence `json:"silences"`
+	}
+	if err := json.NewDecoder(do(t, "GET", server.URL+"/api/silences", "", "").Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Silences) != 1 || list.Silences[0].ID != created.ID || list.Silences[0].CreatedBy != "alice" {
+		t.Errorf("silences = %+v", list.Silences)
+	}
+	if saved, err := store.Silences(); err != nil || len(saved) != 1 || saved[0].ID != created.ID {
+		t.Errorf("saved silences = %+v, %v", saved, err)
+	}
+
+	if resp := do(t, "DELETE", server.URL+"/api/silences/"+created.ID+"?actor=bob", "Bearer "+testToken, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status %d expiring, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if resp := do(t, "DELETE", server.URL+"/api/silences/"+created.ID, "Bearer "+testToken, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status %d expiring twice, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if base.IsSilenced("story", "silenced-node") {
+		t.Error("expected the silence to be expired")
+	}
+	if saved, err := store.Silences(); err != nil || len(saved) != 0 {
+		t.Errorf("saved silences after expiry = %+v, %v", saved, err)
+	}
+
+	entries := alerts.Audit().Entries()
+	if len(entries) != 2 || entries[0].Action != "silence" || entries[1].Action != "expire_silence" || entries[1].Actor != "bob" {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
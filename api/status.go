@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"storymonitor/base"
+)
+
+// The status types mirror the CometBFT /status RPC response used by community dashboards
+
+type rpcStatusResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  rpcStatusResult `json:"result"`
+}
+
+type rpcStatusResult struct {
+	NodeInfo      rpcNodeInfo      `json:"node_info"`
+	SyncInfo      rpcSyncInfo      `json:"sync_info"`
+	ValidatorInfo rpcValidatorInfo `json:"validator_info"`
+}
+
+type rpcNodeInfo struct {
+	Moniker string `json:"moniker"`
+	Network string `json:"network"`
+	Version string `json:"version"`
+}
+
+type rpcSyncInfo struct {
+	LatestBlockHash     string `json:"latest_block_hash"`
+	LatestAppHash       string `json:"latest_app_hash"`
+	LatestBlockHeight   string `json:"latest_block_height"`
+	LatestBlockTime     string `json:"latest_block_time"`
+	EarliestBlockHeight string `json:"earliest_block_height"`
+	CatchingUp          bool   `json:"catching_up"`
+}
+
+type rpcValidatorInfo struct {
+	Address     string `json:"address"`
+	VotingPower string `json:"voting_power"`
+}
+
+// nodeStatus serves the cached status of a CometBFT node in the /status RPC shape,
+// so dashboards can poll the monitor instead of the validator node
+func (s *Server) nodeStatus(w http.ResponseWriter, r *http.Request) {
+	host := r.PathValue("host")
+	for _, checker := range s.controller.Checkers() {
+		provider, ok := checker.(base.StatusProvider)
+		if !ok || checker.GetHostName() != host {
+			continue
+		}
+		status, ok := provider.NodeStatus()
+		if !ok {
+			writeError(w, http.StatusServiceUnavailable, fmt.Errorf("status of %s not available yet", host))
+			return
+		}
+
+		w.Header().Set("X-Status-Updated-At", status.UpdatedAt.UTC().Format(time.RFC3339))
+		writeJSON(w, http.StatusOK, rpcStatusResponse{
+			JSONRPC: "2.0",
+			ID:      -1,
+			Result: rpcStatusResult{
+				NodeInfo: rpcNodeInfo{
+					Moniker: status.Moniker,
+					Network: status.Network,
+					Version: status.Version,
+				},
+				SyncInfo: rpcSyncInfo{
+					LatestBlockHash:     status.LatestBlockHash,
+					LatestAppHash:       status.LatestAppHash,
+					LatestBlockHeight:   strconv.FormatInt(status.LatestBlockHeight, 10),
+					LatestBlockTime:     status.LatestBlockTime.UTC().Format(time.RFC3339Nano),
+					EarliestBlockHeight: strconv.FormatInt(status.EarliestBlockHeight, 10),
+					CatchingUp:          status.CatchingUp,
+				},
+				ValidatorInfo: rpcValidatorInfo{
+					Address:     status.ValidatorAddress,
+					VotingPower: strconv.FormatInt(status.VotingPower, 10),
+				},
+			},
+		})
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("no cometbft node %s", host))
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"storymonitor/sched"
+
+	"github.com/golang/glog"
+)
+
+// restartTarget re-creates the checkers of one node, e.g. when its WS
+// connection is wedged, without restarting the monitor
+func (s *Server) restartTarget(w http.ResponseWriter, r *http.Request) {
+	chain, host := r.PathValue("chain"), r.PathValue("host")
+	restarted, err := s.controller.Restart(chain, host)
+	if errors.Is(err, sched.ErrTargetNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no targets of chain %s host %s", chain, host))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	actor := r.URL.Query().Get("actor")
+	if actor == "" {
+		actor = "admin"
+	}
+	s.alerts.Audit().Record(actor, "restart", fmt.Sprintf("target/%s/%s", chain, host), fmt.Sprintf("%d checkers", restarted))
+	glog.Infof("[api] %d checkers of %s (%s) restarted by %s", restarted, host, chain, actor)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"chain_name": chain,
+		"hostname":   host,
+		"restarted":  restarted,
+	})
+}
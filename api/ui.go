@@ -0,0 +1,47 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/golang/glog"
+)
+
+var incidentsTemplate = template.Must(template.New("incidents").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Incidents - Story Node Monitor</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.incident { border: 1px solid #ccc; border-radius: 4px; margin-bottom: 1em; padding: 0.5em 1em; }
+.open { border-left: 6px solid #d9534f; }
+.acknowledged { border-left: 6px solid #f0ad4e; }
+.resolved { border-left: 6px solid #5cb85c; }
+.timeline { font-size: 0.9em; color: #444; }
+</style>
+</head>
+<body>
+<h1>Incidents</h1>
+{{range .}}
+<div class="incident {{.Status}}">
+<h3>#{{.ID}} {{.Title}} <small>({{.Status}})</small></h3>
+{{if .AcknowledgedBy}}<p>Acknowledged by {{.AcknowledgedBy}}</p>{{end}}
+<ul class="timeline">
+{{range .Timeline}}<li>{{.Time.Format "2006-01-02 15:04:05 MST"}} - {{.Message}}</li>
+{{end}}</ul>
+</div>
+{{else}}
+<p>No incidents.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// incidentsPage renders the incident timeline view
+func (s *Server) incidentsPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := incidentsTemplate.Execute(w, s.alerts.Incidents()); err != nil {
+		glog.Errorf("[api] Failed to render incidents page: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"storymonitor/sla"
+)
+
+// uptime reports per-node uptime over a range, either ?range=24h|7d|30d
+// (default 24h) ending now or an explicit ?from=&to= in RFC3339
+func (s *Server) uptime(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	to := time.Now()
+	var from time.Time
+
+	if query.Get("from") != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, query.Get("from")); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid from: %w", err))
+			return
+		}
+		if query.Get("to") != "" {
+			if to, err = time.Parse(time.RFC3339, query.Get("to")); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid to: %w", err))
+				return
+			}
+		}
+	} else {
+		rangeParam := query.Get("range")
+		if rangeParam == "" {
+			rangeParam = "24h"
+		}
+		d, err := sla.ParseRange(rangeParam)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		from = to.Add(-d)
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("to must be after from"))
+		return
+	}
+
+	var targets []sla.Target
+	for _, checker := range s.controller.Checkers() {
+		targets = append(targets, sla.Target{ChainName: checker.GetChainName(), HostName: checker.GetHostName()})
+	}
+
+	chain, host := query.Get("chain_name"), query.Get("hostname")
+	result := make([]sla.Uptime, 0)
+	for _, u := range s.downtime.Report(targets, from, to) {
+		if (chain == "" || u.ChainName == chain) && (host == "" || u.HostName == host) {
+			result = append(result, u)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"from":    from,
+		"to":      to,
+		"targets": result,
+	})
+}
@@ -2,8 +2,14 @@ package base
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"storymonitor/events"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -12,68 +18,417 @@ var (
 	labelsWithInfo = []string{"chain_name", "hostname", "chain_id", "node_version", "protocol_name"}
 
 	// BlockLastUpdateTime tracks the last time a block was processed (seconds since epoch)
-	BlockLastUpdateTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "story_node_last_block_timestamp_seconds",
+	BlockLastUpdateTime = newGaugeVec(prometheus.GaugeOpts{
+		Name: "last_block_timestamp_seconds",
 		Help: "Timestamp of the last processed block in seconds since epoch",
 	}, labelsWithInfo)
 
 	// BlockProcessingDelay measures the delay between block creation and processing
-	BlockProcessingDelay = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "story_node_block_processing_delay_seconds",
+	BlockProcessingDelay = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_processing_delay_seconds",
 		Help: "Delay between block creation and processing in seconds",
 	}, labels)
 
 	// BlockProcessingDelayHistogram provides histogram of block processing delays
-	BlockProcessingDelayHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "story_node_block_processing_delay_histogram_seconds",
+	BlockProcessingDelayHistogram = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "block_processing_delay_histogram_seconds",
 		Help:    "Histogram of block processing delays in seconds",
 		Buckets: []float64{0.1, 0.3, 0.5, 1, 3, 5, 10, 30, 60, 120, 180},
 	}, labels)
 
 	// RPCConnectionAttempts counts successful and failed RPC connection attempts
-	RPCConnectionAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "story_node_rpc_connections_count",
+	RPCConnectionAttempts = newCounterVec(prometheus.CounterOpts{
+		Name: "rpc_connections_count",
 		Help: "Total number of RPC connection attempts by type and result",
 	}, append(labels, "connection_type", "result"))
 
 	// NodeHealthStatus indicates the health status of various node endpoints
-	NodeHealthStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "story_node_health_status",
+	NodeHealthStatus = newGaugeVec(prometheus.GaugeOpts{
+		Name: "health_status",
 		Help: "Health status of node endpoints (1=healthy, 0=unhealthy)",
 	}, append(labels, "endpoint_type"))
 
 	// EndpointResponseTime measures current response time for different endpoints
-	EndpointResponseTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "story_node_endpoint_response_time_milliseconds",
+	EndpointResponseTime = newGaugeVec(prometheus.GaugeOpts{
+		Name: "endpoint_response_time_milliseconds",
 		Help: "Current response time for node endpoints in milliseconds",
 	}, append(labels, "endpoint_type"))
 
 	// EndpointResponseTimeHistogram provides histogram of endpoint response times
-	EndpointResponseTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "story_node_endpoint_response_time_histogram_milliseconds",
+	EndpointResponseTimeHistogram = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "endpoint_response_time_histogram_milliseconds",
 		Help:    "Histogram of endpoint response times in milliseconds",
 		Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
 	}, append(labels, "endpoint_type"))
+
+	// LatestBlockHeight tracks the latest block height reported by polled endpoints
+	LatestBlockHeight = newGaugeVec(prometheus.GaugeOpts{
+		Name: "latest_block_height",
+		Help: "Latest block height reported by the endpoint",
+	}, labels)
+
+	// NodeSyncing indicates whether the node reports it is still syncing
+	NodeSyncing = newGaugeVec(prometheus.GaugeOpts{
+		Name: "syncing",
+		Help: "Whether the node reports it is syncing (1=syncing, 0=synced)",
+	}, labels)
+
+	// StakingValidatorTokens tracks the total stake delegated to a validator
+	StakingValidatorTokens = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_validator_tokens",
+		Help: "Total tokens delegated to the validator",
+	}, append(labels, "validator"))
+
+	// StakingCommissionRate tracks the validator commission rate
+	StakingCommissionRate = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_commission_rate",
+		Help: "Current commission rate of the validator (0-1)",
+	}, append(labels, "validator"))
+
+	// StakingUnbondingTokens tracks tokens in the validator unbonding queue
+	StakingUnbondingTokens = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_unbonding_tokens",
+		Help: "Total tokens currently unbonding from the validator",
+	}, append(labels, "validator"))
+
+	// StakingUnbondingEntries tracks the number of entries in the validator unbonding queue
+	StakingUnbondingEntries = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_unbonding_entries",
+		Help: "Number of unbonding entries for the validator",
+	}, append(labels, "validator"))
+
+	// StakingPendingRewards tracks outstanding rewards of the validator by denom
+	StakingPendingRewards = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_pending_rewards",
+		Help: "Outstanding rewards of the validator by denom",
+	}, append(labels, "validator", "denom"))
+
+	// StakingValidatorJailed indicates whether the validator is jailed
+	StakingValidatorJailed = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_validator_jailed",
+		Help: "Whether the validator is jailed (1=jailed, 0=not jailed)",
+	}, append(labels, "validator"))
+
+	// StakingValidatorTombstoned indicates whether the validator is tombstoned
+	StakingValidatorTombstoned = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_validator_tombstoned",
+		Help: "Whether the validator is tombstoned (1=tombstoned, 0=not tombstoned)",
+	}, append(labels, "validator"))
+
+	// StakingMissedBlocks tracks the missed blocks counter from the validator signing info
+	StakingMissedBlocks = newGaugeVec(prometheus.GaugeOpts{
+		Name: "staking_missed_blocks",
+		Help: "Missed blocks counter in the current signing window of the validator",
+	}, append(labels, "validator"))
+
+	// AccountBalanceWei tracks the balance of configured addresses in wei
+	AccountBalanceWei = newGaugeVec(prometheus.GaugeOpts{
+		Name: "account_balance_wei",
+		Help: "Balance of configured account addresses in wei",
+	}, append(labels, "address"))
+
+	// AccountBalanceEther tracks the balance of configured addresses in ether
+	AccountBalanceEther = newGaugeVec(prometheus.GaugeOpts{
+		Name: "account_balance_ether",
+		Help: "Balance of configured account addresses in ether",
+	}, append(labels, "address"))
+
+	// ContractProbeSuccess indicates whether a synthetic eth_call probe returned the expected result
+	ContractProbeSuccess = newGaugeVec(prometheus.GaugeOpts{
+		Name: "contract_probe_success",
+		Help: "Result of synthetic eth_call probes (1=success, 0=failure)",
+	}, append(labels, "probe"))
+
+	// ContractProbeDuration measures the latency of synthetic eth_call probes
+	ContractProbeDuration = newGaugeVec(prometheus.GaugeOpts{
+		Name: "contract_probe_duration_milliseconds",
+		Help: "Latency of synthetic eth_call probes in milliseconds",
+	}, append(labels, "probe"))
+
+	// ReadCallSuccess indicates whether the last read benchmark call succeeded
+	ReadCallSuccess = newGaugeVec(prometheus.GaugeOpts{
+		Name: "read_call_success",
+		Help: "Result of the last read benchmark call (1=success, 0=failure)",
+	}, append(labels, "call"))
+
+	// ReadCallDuration provides histogram of read benchmark call latencies
+	ReadCallDuration = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "read_call_duration_seconds",
+		Help:    "Histogram of read benchmark call latencies in seconds",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, append(labels, "call"))
+
+	// RPCMethodAvailable indicates whether a JSON-RPC method is served by the node
+	RPCMethodAvailable = newGaugeVec(prometheus.GaugeOpts{
+		Name: "rpc_method_available",
+		Help: "Whether a JSON-RPC method is served by the node (1=available, 0=unavailable)",
+	}, append(labels, "method"))
+
+	// LogEventsReceived counts log events received through the logs subscription
+	LogEventsReceived = newCounterVec(prometheus.CounterOpts{
+		Name: "log_events_received_total",
+		Help: "Total number of log events received through the logs subscription",
+	}, labels)
+
+	// LogLastEventAge tracks the time since the last log event was received
+	LogLastEventAge = newGaugeVec(prometheus.GaugeOpts{
+		Name: "log_last_event_age_seconds",
+		Help: "Seconds since the last log event was received through the logs subscription",
+	}, labels)
+
+	// MempoolTxs tracks the number of unconfirmed transactions in the node mempool
+	MempoolTxs = newGaugeVec(prometheus.GaugeOpts{
+		Name: "mempool_txs",
+		Help: "Number of unconfirmed transactions in the mempool",
+	}, labels)
+
+	// MempoolBytes tracks the total size of unconfirmed transactions in the node mempool
+	MempoolBytes = newGaugeVec(prometheus.GaugeOpts{
+		Name: "mempool_bytes",
+		Help: "Total size of unconfirmed transactions in the mempool in bytes",
+	}, labels)
+
+	// EvidenceCommitted counts misbehaviour evidence committed on chain by type
+	EvidenceCommitted = newCounterVec(prometheus.CounterOpts{
+		Name: "evidence_total",
+		Help: "Total number of committed evidence by type (duplicate_vote, light_client_attack)",
+	}, append(labels, "type"))
+
+	// EvidenceValidatorInvolved counts committed evidence involving the configured validator
+	EvidenceValidatorInvolved = newCounterVec(prometheus.CounterOpts{
+		Name: "evidence_validator_involved_total",
+		Help: "Total number of committed evidence involving the configured validator",
+	}, append(labels, "validator", "type"))
+
+	// PeersConnected tracks the number of peers of a CometBFT node
+	PeersConnected = newGaugeVec(prometheus.GaugeOpts{
+		Name: "peers",
+		Help: "Number of peers reported by /net_info",
+	}, labels)
+
+	// PeersAdded counts peers that appeared between successive /net_info calls
+	PeersAdded = newCounterVec(prometheus.CounterOpts{
+		Name: "peers_added_total",
+		Help: "Number of peers that connected between successive /net_info calls",
+	}, labels)
+
+	// PeersRemoved counts peers that disappeared between successive /net_info calls
+	PeersRemoved = newCounterVec(prometheus.CounterOpts{
+		Name: "peers_removed_total",
+		Help: "Number of peers that disconnected between successive /net_info calls",
+	}, labels)
+
+	// PeerChurnRate tracks the rate of peer set changes over the churn window
+	PeerChurnRate = newGaugeVec(prometheus.GaugeOpts{
+		Name: "peer_churn_per_minute",
+		Help: "Peers added or removed per minute over the churn window",
+	}, labels)
+
+	// AbciAppVersion tracks the application protocol version reported by /abci_info
+	AbciAppVersion = newGaugeVec(prometheus.GaugeOpts{
+		Name: "abci_app_version",
+		Help: "Application protocol version reported by /abci_info",
+	}, append(labels, "version"))
+
+	// AbciLastBlockHeight tracks the last block height committed by the application
+	AbciLastBlockHeight = newGaugeVec(prometheus.GaugeOpts{
+		Name: "abci_last_block_height",
+		Help: "Last block height committed by the application",
+	}, labels)
+
+	// AbciAppHashChanges counts changes of the application last block app hash
+	AbciAppHashChanges = newCounterVec(prometheus.CounterOpts{
+		Name: "abci_app_hash_changes_total",
+		Help: "Total number of last_block_app_hash changes observed",
+	}, labels)
+
+	// AbciAppHashStaleBlocks tracks consensus blocks produced since the app hash last changed
+	AbciAppHashStaleBlocks = newGaugeVec(prometheus.GaugeOpts{
+		Name: "abci_app_hash_stale_blocks",
+		Help: "Number of consensus blocks since last_block_app_hash last changed",
+	}, labels)
+
+	// ArchiveAvailable indicates whether a node can serve historical blocks and state
+	ArchiveAvailable = newGaugeVec(prometheus.GaugeOpts{
+		Name: "archive_available",
+		Help: "Whether the node can serve deep history (1=available, 0=unavailable)",
+	}, labels)
+
+	// EarliestBlockHeight tracks the earliest block height a node retains
+	EarliestBlockHeight = newGaugeVec(prometheus.GaugeOpts{
+		Name: "earliest_block_height",
+		Help: "Earliest block height retained by the node",
+	}, labels)
+
+	// RetainedBlocks tracks the number of blocks between the earliest and latest height of a node
+	RetainedBlocks = newGaugeVec(prometheus.GaugeOpts{
+		Name: "retained_blocks",
+		Help: "Number of blocks retained by the node, from its earliest to its latest height",
+	}, labels)
+
+	// TCPReachable indicates whether a TCP port such as a P2P port accepts connections
+	TCPReachable = newGaugeVec(prometheus.GaugeOpts{
+		Name: "tcp_reachable",
+		Help: "Whether the TCP address accepts connections (1=reachable, 0=unreachable)",
+	}, append(labels, "address"))
+
+	// TCPConnectDuration measures the time to establish a TCP connection
+	TCPConnectDuration = newGaugeVec(prometheus.GaugeOpts{
+		Name: "tcp_connect_duration_milliseconds",
+		Help: "Time to establish a TCP connection in milliseconds",
+	}, append(labels, "address"))
+
+	// PluginMetric tracks the values reported by plugin checkers
+	PluginMetric = newGaugeVec(prometheus.GaugeOpts{
+		Name: "plugin_metric",
+		Help: "Value reported by a plugin checker, by metric name",
+	}, append(labels, "metric"))
+
+	// DeadManPings counts the pings of dead man's switches by result
+	DeadManPings = newCounterVec(prometheus.CounterOpts{
+		Name: "dead_man_pings_total",
+		Help: "Pings of dead man's switch URLs by result (success, fail, withheld)",
+	}, []string{"switch", "result"})
+
+	// ReferenceBlockHeight tracks the latest height reported by a reference endpoint
+	ReferenceBlockHeight = newGaugeVec(prometheus.GaugeOpts{
+		Name: "reference_block_height",
+		Help: "Latest block height reported by a reference endpoint",
+	}, []string{"chain_name", "reference"})
+
+	// LagVsReferenceBlocks tracks how many blocks a node is behind the reference endpoints
+	LagVsReferenceBlocks = newGaugeVec(prometheus.GaugeOpts{
+		Name: "lag_vs_reference_blocks",
+		Help: "Blocks between the most advanced reference endpoint and the node head",
+	}, labels)
+
+	// LagVsReferenceSeconds tracks the block time difference to the reference endpoints
+	LagVsReferenceSeconds = newGaugeVec(prometheus.GaugeOpts{
+		Name: "lag_vs_reference_seconds",
+		Help: "Block timestamp difference between the most advanced reference endpoint and the node head",
+	}, labels)
+
+	// FinalityBlockHeight tracks the height of the latest, safe and finalized heads of EVM nodes
+	FinalityBlockHeight = newGaugeVec(prometheus.GaugeOpts{
+		Name: "finality_block_height",
+		Help: "Height of the block with the given tag (latest, safe, finalized)",
+	}, append(labels, "tag"))
+
+	// FinalityLagBlocks tracks how many blocks the safe and finalized heads are behind latest
+	FinalityLagBlocks = newGaugeVec(prometheus.GaugeOpts{
+		Name: "finality_lag_blocks",
+		Help: "Blocks between the latest head and the head with the given tag (safe, finalized)",
+	}, append(labels, "tag"))
+
+	// FinalityLagSeconds tracks the block time difference between latest and the safe and finalized heads
+	FinalityLagSeconds = newGaugeVec(prometheus.GaugeOpts{
+		Name: "finality_lag_seconds",
+		Help: "Block timestamp difference between the latest head and the head with the given tag (safe, finalized)",
+	}, append(labels, "tag"))
+
+	// BlockHashDivergent indicates whether a node disagrees on a block hash with the nodes of its chain ID
+	BlockHashDivergent = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_hash_divergent",
+		Help: "Whether the node reported a different block hash than the majority of nodes with the same chain ID at the last compared height (1=divergent)",
+	}, labels)
+
+	// BlockHashDivergences counts how often a node started disagreeing on a block hash
+	BlockHashDivergences = newCounterVec(prometheus.CounterOpts{
+		Name: "block_hash_divergences_total",
+		Help: "Number of times the node started reporting block hashes differing from the nodes with the same chain ID",
+	}, labels)
+
+	// HALeader indicates whether this monitor replica is the HA leader
+	HALeader = newGauge(prometheus.GaugeOpts{
+		Name: "ha_leader",
+		Help: "Whether this monitor replica holds the HA lease and runs checks (1=leader, 0=standby)",
+	})
+
+	// EventsDropped counts events of the internal event bus dropped because a consumer lagged behind
+	EventsDropped = newCounterFunc(prometheus.CounterOpts{
+		Name: "events_dropped_total",
+		Help: "Number of internal events dropped because a consumer lagged behind",
+	}, func() float64 {
+		return float64(events.Default.Dropped())
+	})
 )
 
 func init() {
-	prometheus.MustRegister(BlockLastUpdateTime)
-	prometheus.MustRegister(BlockProcessingDelay)
-	prometheus.MustRegister(BlockProcessingDelayHistogram)
-	prometheus.MustRegister(RPCConnectionAttempts)
-	prometheus.MustRegister(NodeHealthStatus)
-	prometheus.MustRegister(EndpointResponseTime)
-	prometheus.MustRegister(EndpointResponseTimeHistogram)
+	addCollectors(
+		BlockLastUpdateTime,
+		BlockProcessingDelay,
+		BlockProcessingDelayHistogram,
+		RPCConnectionAttempts,
+		NodeHealthStatus,
+		EndpointResponseTime,
+		EndpointResponseTimeHistogram,
+		LatestBlockHeight,
+		NodeSyncing,
+		StakingValidatorTokens,
+		StakingCommissionRate,
+		StakingUnbondingTokens,
+		StakingUnbondingEntries,
+		StakingPendingRewards,
+		StakingValidatorJailed,
+		StakingValidatorTombstoned,
+		StakingMissedBlocks,
+		AccountBalanceWei,
+		AccountBalanceEther,
+		ContractProbeSuccess,
+		ContractProbeDuration,
+		ReadCallSuccess,
+		ReadCallDuration,
+		RPCMethodAvailable,
+		LogEventsReceived,
+		LogLastEventAge,
+		MempoolTxs,
+		MempoolBytes,
+		EvidenceCommitted,
+		EvidenceValidatorInvolved,
+		AbciAppVersion,
+		AbciLastBlockHeight,
+		AbciAppHashChanges,
+		AbciAppHashStaleBlocks,
+		ArchiveAvailable,
+		EarliestBlockHeight,
+		PeersConnected,
+		PeersAdded,
+		PeersRemoved,
+		PeerChurnRate,
+		RetainedBlocks,
+		TCPReachable,
+		TCPConnectDuration,
+		PluginMetric,
+		DeadManPings,
+		ReferenceBlockHeight,
+		LagVsReferenceBlocks,
+		LagVsReferenceSeconds,
+		HALeader,
+		FinalityBlockHeight,
+		FinalityLagBlocks,
+		FinalityLagSeconds,
+		BlockHashDivergent,
+		BlockHashDivergences,
+		EventsDropped,
+	)
 }
 
 type CheckerTrait interface {
 	Start()
 
+	GetState() string
+	FlushStateDuration()
+
 	GetChainName() string
 	GetHostName() string
 	GetChainId() string
 	GetNodeVersion() string
 	GetProtocolName() string
+
+	// OnHealthCheck adds a callback receiving the check outcomes, see BaseChecker
+	OnHealthCheck(fn HealthCallback)
+	// LastError returns the most recent failed check, false if no check failed
+	LastError() (CheckError, bool)
 }
 
 // BaseChecker provides common functionality for all checker implementations
@@ -83,6 +438,48 @@ type BaseChecker struct {
 	ChainId      string
 	NodeVersion  string
 	ProtocolName string
+
+	// ExpectedChainId is the configured chain_id, compared with the chain ID the node reports
+	ExpectedChainId string
+	chainIdMismatch atomic.Bool
+
+	// MinVersion is the oldest expected version, see SetNodeVersion
+	MinVersion string
+	// reportedVersion is the last version reported by the node
+	versionMu       sync.Mutex
+	reportedVersion string
+
+	// FailureDomain groups nodes that tend to fail together, e.g. a datacenter
+	FailureDomain string
+	// Maintenance holds the planned maintenance windows, nil if there are none
+	Maintenance *maintenance.Schedule
+
+	// DelaySource selects how block delay is measured, see RecordBlockDelay
+	DelaySource string
+	lastArrival time.Time
+	intervals   blockIntervals
+
+	state checkerState
+
+	healthCallbacks []HealthCallback
+
+	lastErrMu sync.Mutex
+	lastErr   *CheckError
+
+	// responseTimes are the recent response times by endpoint type
+	responseMu    sync.Mutex
+	responseTimes map[string]*slidingWindow
+
+	// subscribed holds the subscriptions established at least once
+	subscriptionMu sync.Mutex
+	subscribed     map[string]bool
+}
+
+// CheckError is a failed check of a checker
+type CheckError struct {
+	Check string    `json:"check"`
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
 }
 
 // AddLabelValues creates label values array for basic metrics (chain_name, hostname)
@@ -104,28 +501,37 @@ func (b *BaseChecker) RecordConnectionAttempt(connectionType string, success boo
 		result = "success"
 	}
 	RPCConnectionAttempts.WithLabelValues(b.AddLabelValues(connectionType, result)...).Add(1)
+	UpdateEndpointStatus(connectionType, b.ChainName, b.HostName, success)
 }
 
 // RecordHealthStatus records health status for an endpoint type
 func (b *BaseChecker) RecordHealthStatus(endpointType string, healthy bool) {
+	b.recordHealthStatus(endpointType, healthy, nil)
+}
+
+// recordHealthStatus records health status together with the error of a failed check
+func (b *BaseChecker) recordHealthStatus(endpointType string, healthy bool, err error) {
 	status := float64(0)
 	if healthy {
 		status = 1
 	}
 	NodeHealthStatus.WithLabelValues(b.AddLabelValues(endpointType)...).Set(status)
+	UpdateEndpointStatus(endpointType, b.ChainName, b.HostName, healthy)
+	b.recordTransition(endpointType, healthy, err)
 }
 
 // RecordResponseTime records response time metrics for an endpoint
 func (b *BaseChecker) RecordResponseTime(endpointType string, duration time.Duration) {
 	milliseconds := float64(duration.Milliseconds())
 	EndpointResponseTime.WithLabelValues(b.AddLabelValues(endpointType)...).Set(milliseconds)
-	EndpointResponseTimeHistogram.WithLabelValues(b.AddLabelValues(endpointType)...).Observe(milliseconds)
+	b.observeWithTrace(EndpointResponseTimeHistogram.WithLabelValues(b.AddLabelValues(endpointType)...), endpointType, milliseconds)
+	b.recordResponsePercentiles(endpointType, milliseconds, time.Now())
 }
 
 // RecordBlockProcessingDelay records block processing delay metrics
 func (b *BaseChecker) RecordBlockProcessingDelay(delaySeconds float64) {
 	BlockProcessingDelay.WithLabelValues(b.AddLabelValues()...).Set(delaySeconds)
-	BlockProcessingDelayHistogram.WithLabelValues(b.AddLabelValues()...).Observe(delaySeconds)
+	b.observeWithTrace(BlockProcessingDelayHistogram.WithLabelValues(b.AddLabelValues()...), "block", delaySeconds)
 }
 
 // UpdateLastBlockTime updates the last block timestamp
@@ -133,14 +539,65 @@ func (b *BaseChecker) UpdateLastBlockTime() {
 	BlockLastUpdateTime.WithLabelValues(b.AddLabelValuesWithInfo()...).Set(0)
 }
 
-// HealthCheckOperation represents a health check operation with timing
+// RecordArchiveAvailable records whether the node can serve deep history
+func (b *BaseChecker) RecordArchiveAvailable(available bool) {
+	value := float64(0)
+	if available {
+		value = 1
+	}
+	ArchiveAvailable.WithLabelValues(b.AddLabelValues()...).Set(value)
+}
+
+// RecordRetainedBlocks records the range of blocks a node retains, a
+// shrinking range shows a node pruning more than expected
+func (b *BaseChecker) RecordRetainedBlocks(earliest, latest int64) {
+	LatestBlockHeight.WithLabelValues(b.AddLabelValues()...).Set(float64(latest))
+	EarliestBlockHeight.WithLabelValues(b.AddLabelValues()...).Set(float64(earliest))
+	if earliest > 0 && latest >= earliest {
+		RetainedBlocks.WithLabelValues(b.AddLabelValues()...).Set(float64(latest - earliest + 1))
+	}
+}
+
+// HealthCheckOperation represents a health check operation with timing. An
+// operation that gets an answer failing validation returns an
+// UnhealthyResponseError, which is counted apart from failed calls.
 func (b *BaseChecker) HealthCheckOperation(endpointType string, operation func() error) {
 	startTime := time.Now()
 	err := operation()
 	duration := time.Since(startTime)
 
-	b.RecordHealthStatus(endpointType, err == nil)
+	if CheckResult(err) == CheckResultUnhealthyResponse {
+		glog.Warningf("[HealthCheckOperation] Node %s (%s) %s: %v", b.HostName, b.ChainName, endpointType, err)
+	}
+	HealthCheckResults.WithLabelValues(b.AddLabelValues(endpointType, CheckResult(err))...).Inc()
+	b.recordHealthStatus(endpointType, err == nil, err)
 	b.RecordResponseTime(endpointType, duration)
+	b.publishCheck(endpointType, err == nil, duration, startTime)
+	if err != nil {
+		b.lastErrMu.Lock()
+		b.lastErr = &CheckError{Check: endpointType, Error: err.Error(), At: startTime}
+		b.lastErrMu.Unlock()
+
+		b.publish(events.Event{
+			Kind:          events.KindError,
+			Time:          startTime,
+			ChainName:     b.ChainName,
+			HostName:      b.HostName,
+			FailureDomain: b.FailureDomain,
+			Check:         endpointType,
+			Error:         err.Error(),
+		})
+	}
+}
+
+// LastError returns the most recent failed health check
+func (b *BaseChecker) LastError() (CheckError, bool) {
+	b.lastErrMu.Lock()
+	defer b.lastErrMu.Unlock()
+	if b.lastErr == nil {
+		return CheckError{}, false
+	}
+	return *b.lastErr, true
 }
 
 // CheckSecondToTicker converts check_second to ticker, with default fallback
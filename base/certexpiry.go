@@ -0,0 +1,41 @@
+package base
+
+import (
+	"crypto/tls"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TLSCertExpiry tracks when the leaf certificate served by an endpoint expires
+var TLSCertExpiry = newGaugeVec(prometheus.GaugeOpts{
+	Name: "tls_cert_expiry_timestamp_seconds",
+	Help: "Expiry of the TLS certificate served by the endpoint as a unix timestamp",
+}, append(labels, "endpoint_type"))
+
+func init() {
+	addCollectors(
+		TLSCertExpiry,
+	)
+}
+
+// RecordCertExpiry records the expiry of the leaf certificate of a TLS connection
+func (b *BaseChecker) RecordCertExpiry(endpointType string, state *tls.ConnectionState) {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return
+	}
+	TLSCertExpiry.WithLabelValues(b.AddLabelValues(endpointType)...).Set(float64(state.PeerCertificates[0].NotAfter.Unix()))
+}
+
+// WatchCertExpiry records the certificate expiry on every handshake made with
+// tlsConfig, for clients such as the RPC clients that hide the TLS state of
+// their responses
+func (b *BaseChecker) WatchCertExpiry(endpointType string, tlsConfig *tls.Config) {
+	verify := tlsConfig.VerifyConnection
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		b.RecordCertExpiry(endpointType, &state)
+		if verify != nil {
+			return verify(state)
+		}
+		return nil
+	}
+}
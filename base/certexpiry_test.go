@@ -0,0 +1,26 @@
+package base
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWatchCertExpiry(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "tls-node"}
+	tlsConfig := &tls.Config{}
+	b.WatchCertExpiry("ws", tlsConfig)
+
+	notAfter := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{{NotAfter: notAfter}}}
+	if err := tlsConfig.VerifyConnection(state); err != nil {
+		t.Fatal(err)
+	}
+	got := testutil.ToFloat64(TLSCertExpiry.WithLabelValues(b.AddLabelValues("ws")...))
+	if got != float64(notAfter.Unix()) {
+		t.Errorf("cert expiry = %v, want %d", got, notAfter.Unix())
+	}
+}
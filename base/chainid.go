@@ -0,0 +1,41 @@
+package base
+
+import (
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ChainIdMismatch indicates whether a node reports a different chain ID than configured
+var ChainIdMismatch = newGaugeVec(prometheus.GaugeOpts{
+	Name: "chain_id_mismatch",
+	Help: "Whether the chain ID reported by the node differs from the configured chain_id (1=mismatch)",
+}, labels)
+
+func init() {
+	addCollectors(
+		ChainIdMismatch,
+	)
+}
+
+// CheckChainId compares the chain ID reported by the node with the configured
+// chain_id, catching endpoints that point at the wrong network. Without a
+// configured chain_id the reported one is accepted.
+func (b *BaseChecker) CheckChainId(reported string) {
+	if b.ExpectedChainId == "" || reported == "" {
+		return
+	}
+
+	mismatch := reported != b.ExpectedChainId
+	previous := b.chainIdMismatch.Swap(mismatch)
+	if mismatch && !previous {
+		glog.Errorf("[CheckChainId] Node %s (%s) reports chain ID %s, expected %s", b.HostName, b.ChainName, reported, b.ExpectedChainId)
+	} else if !mismatch && previous {
+		glog.Infof("[CheckChainId] Node %s (%s) reports the expected chain ID %s again", b.HostName, b.ChainName, reported)
+	}
+
+	value := float64(0)
+	if mismatch {
+		value = 1
+	}
+	ChainIdMismatch.WithLabelValues(b.AddLabelValues()...).Set(value)
+}
@@ -0,0 +1,48 @@
+package base
+
+import (
+	"sync"
+	"time"
+)
+
+// CheckSink receives the outcome of every health check. Implementations are
+// called synchronously from the checker loops and must not block.
+type CheckSink interface {
+	AppendCheck(chainName, hostName, check string, healthy bool, duration time.Duration, at time.Time)
+}
+
+// HealthCallback receives the outcome of every health check of one checker. It
+// is called synchronously from the checker loops and must not block.
+type HealthCallback func(check string, healthy bool, duration time.Duration, at time.Time)
+
+var (
+	checkSinksMu sync.RWMutex
+	checkSinks   []CheckSink
+)
+
+// RegisterCheckSink adds a sink that receives check outcomes from all checkers
+func RegisterCheckSink(sink CheckSink) {
+	checkSinksMu.Lock()
+	defer checkSinksMu.Unlock()
+	checkSinks = append(checkSinks, sink)
+}
+
+// OnHealthCheck adds a callback receiving the check outcomes of the checker,
+// for programs embedding it. Callbacks must be added before the checker starts.
+func (b *BaseChecker) OnHealthCheck(fn HealthCallback) {
+	b.healthCallbacks = append(b.healthCallbacks, fn)
+}
+
+// publishCheck publishes a check outcome to all registered sinks and the
+// callbacks of the checker
+func (b *BaseChecker) publishCheck(check string, healthy bool, duration time.Duration, at time.Time) {
+	for _, fn := range b.healthCallbacks {
+		fn(check, healthy, duration, at)
+	}
+
+	checkSinksMu.RLock()
+	defer checkSinksMu.RUnlock()
+	for _, sink := range checkSinks {
+		sink.AppendCheck(b.ChainName, b.HostName, check, healthy, duration, at)
+	}
+}
@@ -3,6 +3,7 @@ package base
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 )
@@ -14,7 +15,7 @@ type Client struct {
 
 func NewClient(ctx context.Context, cli *http.Client) *Client {
 	return &Client{
-		cli: cli,
+		cli: LimitClient(cli),
 		ctx: ctx,
 	}
 }
@@ -40,5 +41,11 @@ func (c *Client) Fetch(url, method string, body interface{}, params map[string]s
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		if len(respBody) > 256 {
+			respBody = respBody[:256]
+		}
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
 	return respBody, nil
 }
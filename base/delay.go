@@ -0,0 +1,157 @@
+package base
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Block delay measurement sources
+const (
+	// DelaySourceBlockTime measures delay as wall clock minus block header timestamp
+	DelaySourceBlockTime = "block_time"
+	// DelaySourceArrival measures delay as the time between consecutive head arrivals
+	DelaySourceArrival = "arrival"
+	// DelaySourceBoth is a deprecated alias of block_time, arrival intervals
+	// are exported with either source
+	DelaySourceBoth = "both"
+
+	// intervalWindow is the number of block intervals in the rolling average
+	intervalWindow = 20
+)
+
+var (
+	// BlockArrivalInterval measures the time between consecutive head arrivals
+	BlockArrivalInterval = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_arrival_interval_seconds",
+		Help: "Time between the arrival of consecutive block heads in seconds",
+	}, labels)
+
+	// BlockArrivalIntervalHistogram provides histogram of head inter-arrival times
+	BlockArrivalIntervalHistogram = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "block_arrival_interval_histogram_seconds",
+		Help:    "Histogram of time between the arrival of consecutive block heads in seconds",
+		Buckets: []float64{0.1, 0.3, 0.5, 1, 2, 3, 5, 10, 30, 60, 120},
+	}, labels)
+
+	// BlockArrivalIntervalAverage tracks the rolling average time between head arrivals
+	BlockArrivalIntervalAverage = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_arrival_interval_avg_seconds",
+		Help: "Rolling average of the time between consecutive block head arrivals in seconds",
+	}, labels)
+
+	// BlockPropagationDelay measures how long after the first node of its chain a node saw a block
+	BlockPropagationDelay = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_propagation_delay_seconds",
+		Help: "Time between the first monitored node of the chain and this node observing the latest block in seconds",
+	}, labels)
+
+	// BlockPropagationDelayHistogram provides histogram of block propagation delays
+	BlockPropagationDelayHistogram = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "block_propagation_delay_histogram_seconds",
+		Help:    "Histogram of the time between the first monitored node of the chain and this node observing a block in seconds",
+		Buckets: []float64{0.05, 0.1, 0.2, 0.3, 0.5, 1, 2, 3, 5, 10},
+	}, labels)
+
+	// BlockIntervalBaseline tracks the moving average of the block intervals of a chain
+	BlockIntervalBaseline = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_interval_baseline_seconds",
+		Help: "Exponentially weighted moving average of the block timestamp intervals of the chain in seconds",
+	}, []string{"chain_name"})
+
+	// BlockIntervalStddev tracks the moving standard deviation of the block intervals of a chain
+	BlockIntervalStddev = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_interval_stddev_seconds",
+		Help: "Exponentially weighted moving standard deviation of the block timestamp intervals of the chain in seconds",
+	}, []string{"chain_name"})
+
+	// BlockIntervalAnomalyScore tracks how unusual the latest block interval of a chain is
+	BlockIntervalAnomalyScore = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_interval_anomaly_score",
+		Help: "Standard deviations between the latest block interval of the chain and its baseline",
+	}, []string{"chain_name"})
+)
+
+func init() {
+	addCollectors(
+		BlockArrivalInterval,
+		BlockArrivalIntervalHistogram,
+		BlockArrivalIntervalAverage,
+		BlockPropagationDelay,
+		BlockPropagationDelayHistogram,
+		BlockIntervalBaseline,
+		BlockIntervalStddev,
+		BlockIntervalAnomalyScore,
+	)
+}
+
+// blockIntervals is a fixed-size window of recent block arrival intervals
+type blockIntervals struct {
+	values []float64
+	next   int
+	sum    float64
+}
+
+// add records an interval and returns the average of the window
+func (w *blockIntervals) add(seconds float64) float64 {
+	if len(w.values) < intervalWindow {
+		w.values = append(w.values, seconds)
+	} else {
+		w.sum -= w.values[w.next]
+		w.values[w.next] = seconds
+		w.next = (w.next + 1) % intervalWindow
+	}
+	w.sum += seconds
+	return w.sum / float64(len(w.values))
+}
+
+// ValidateDelaySource checks a configured delay source, empty selects the default
+func ValidateDelaySource(source string) error {
+	switch source {
+	case "", DelaySourceBlockTime, DelaySourceArrival:
+		return nil
+	case DelaySourceBoth:
+		glog.Warningf("[ValidateDelaySource] delay_source %q is deprecated, use %s", source, DelaySourceBlockTime)
+		return nil
+	}
+	return fmt.Errorf("unknown delay_source %q, expected %s or %s",
+		source, DelaySourceBlockTime, DelaySourceArrival)
+}
+
+// RecordBlockDelay records the interval since the previous head and the delay of
+// the new head according to the configured delay source. It returns the value
+// written to the block processing delay metric.
+func (b *BaseChecker) RecordBlockDelay(blockTime time.Time) float64 {
+	now := time.Now()
+	previous := b.lastArrival
+	b.lastArrival = now
+
+	var interval float64
+	if !previous.IsZero() {
+		interval = now.Sub(previous).Seconds()
+	}
+
+	if !previous.IsZero() {
+		b.recordArrivalInterval(interval)
+	}
+
+	if b.DelaySource == DelaySourceArrival {
+		if previous.IsZero() {
+			return 0
+		}
+		b.RecordBlockProcessingDelay(interval)
+		return interval
+	}
+
+	delaySecond := float64(now.Unix() - blockTime.Unix())
+	b.RecordBlockProcessingDelay(delaySecond)
+	return delaySecond
+}
+
+func (b *BaseChecker) recordArrivalInterval(seconds float64) {
+	BlockArrivalInterval.WithLabelValues(b.AddLabelValues()...).Set(seconds)
+	BlockArrivalIntervalHistogram.WithLabelValues(b.AddLabelValues()...).Observe(seconds)
+	BlockArrivalIntervalAverage.WithLabelValues(b.AddLabelValues()...).Set(b.intervals.add(seconds))
+}
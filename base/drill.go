@@ -0,0 +1,127 @@
+package base
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ReconnectDrills counts reconnect drills by result
+	ReconnectDrills = newCounterVec(prometheus.CounterOpts{
+		Name: "reconnect_drills_total",
+		Help: "Total number of subscription reconnect drills by result",
+	}, append(labels, "result"))
+
+	// ReconnectDrillRecoverySeconds tracks how long the last drill took to recover
+	ReconnectDrillRecoverySeconds = newGaugeVec(prometheus.GaugeOpts{
+		Name: "reconnect_drill_recovery_seconds",
+		Help: "Time from dropping the subscription to the next received head in the last drill",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		ReconnectDrills,
+		ReconnectDrillRecoverySeconds,
+	)
+}
+
+// Drill tracks scheduled reconnect drills of a checker's head subscription
+type Drill struct {
+	c <-chan time.Time
+
+	sla     time.Duration
+	started time.Time
+}
+
+// NewDrill schedules reconnect drills, it returns nil when drills are not configured.
+// The first drill fires after a random fraction of the interval so nodes are not
+// all drilled at once.
+func NewDrill(ctx context.Context, c *conf.ReconnectDrill) *Drill {
+	if c == nil {
+		return nil
+	}
+	interval := time.Duration(c.IntervalSecond) * time.Second
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	sla := time.Duration(c.SlaSecond) * time.Second
+	if sla <= 0 {
+		sla = 30 * time.Second
+	}
+
+	d := &Drill{sla: sla}
+	ch := make(chan time.Time, 1)
+	d.c = ch
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(rand.Int63n(int64(interval)))):
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case ch <- time.Now():
+			default:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return d
+}
+
+// Begin marks the subscription as deliberately dropped
+func (d *Drill) Begin(b *BaseChecker) {
+	d.started = time.Now()
+	glog.Infof("[Drill] Node %s (%s) dropping subscription for reconnect drill", b.HostName, b.ChainName)
+}
+
+// Recovered is called for every received head and completes a pending drill
+func (d *Drill) Recovered(b *BaseChecker) {
+	if d == nil || d.started.IsZero() {
+		return
+	}
+	recovery := time.Since(d.started)
+	d.started = time.Time{}
+
+	result := "success"
+	if recovery > d.sla {
+		result = "sla_breach"
+	}
+	ReconnectDrills.WithLabelValues(b.AddLabelValues(result)...).Inc()
+	ReconnectDrillRecoverySeconds.WithLabelValues(b.AddLabelValues()...).Set(recovery.Seconds())
+	glog.Infof("[Drill] Node %s (%s) recovered in %s: %s", b.HostName, b.ChainName, recovery, result)
+}
+
+// CheckTimeout fails a pending drill that did not recover within the SLA
+func (d *Drill) CheckTimeout(b *BaseChecker) {
+	if d == nil || d.started.IsZero() || time.Since(d.started) <= d.sla {
+		return
+	}
+	recovery := time.Since(d.started)
+	d.started = time.Time{}
+
+	ReconnectDrills.WithLabelValues(b.AddLabelValues("failed")...).Inc()
+	ReconnectDrillRecoverySeconds.WithLabelValues(b.AddLabelValues()...).Set(recovery.Seconds())
+	glog.Errorf("[Drill] Node %s (%s) did not recover within %s", b.HostName, b.ChainName, d.sla)
+}
+
+// Channel returns the drill trigger channel, nil when drills are disabled
+func (d *Drill) Channel() <-chan time.Time {
+	if d == nil {
+		return nil
+	}
+	return d.c
+}
@@ -0,0 +1,39 @@
+package base
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TraceIDFunc returns the trace ID of the span around an observation of a
+// checker, or empty outside of a span. endpointType is the endpoint type of
+// response times and "block" for block delays.
+type TraceIDFunc func(chainName, hostName, endpointType string) string
+
+var traceIDFunc atomic.Pointer[TraceIDFunc]
+
+// SetTraceIDFunc attaches trace ID exemplars to the response time and block
+// delay histograms, nil detaches them. Exemplars are only exposed when
+// /metrics is scraped in the OpenMetrics format.
+func SetTraceIDFunc(f TraceIDFunc) {
+	if f == nil {
+		traceIDFunc.Store(nil)
+		return
+	}
+	traceIDFunc.Store(&f)
+}
+
+// observeWithTrace observes v, with a trace_id exemplar if the observation
+// belongs to a span
+func (b *BaseChecker) observeWithTrace(observer prometheus.Observer, endpointType string, v float64) {
+	if f := traceIDFunc.Load(); f != nil {
+		if traceID := (*f)(b.ChainName, b.HostName, endpointType); traceID != "" {
+			if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+				eo.ObserveWithExemplar(v, prometheus.Labels{"trace_id": traceID})
+				return
+			}
+		}
+	}
+	observer.Observe(v)
+}
@@ -0,0 +1,43 @@
+package base
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestObserveWithTrace(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "traced-node"}
+	SetTraceIDFunc(func(chainName, hostName, endpointType string) string {
+		if endpointType == "http" {
+			return "4bf92f3577b34da6a3ce929d0e0e4736"
+		}
+		return ""
+	})
+	t.Cleanup(func() { SetTraceIDFunc(nil) })
+
+	b.RecordResponseTime("http", 120*time.Millisecond)
+	b.RecordResponseTime("ws", 80*time.Millisecond)
+
+	exemplars := func(endpointType string) []*dto.Exemplar {
+		var m dto.Metric
+		if err := EndpointResponseTimeHistogram.WithLabelValues(b.AddLabelValues(endpointType)...).(prometheus.Metric).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		var result []*dto.Exemplar
+		for _, bucket := range m.GetHistogram().GetBucket() {
+			if bucket.Exemplar != nil {
+				result = append(result, bucket.Exemplar)
+			}
+		}
+		return result
+	}
+	if got := exemplars("http"); len(got) != 1 || got[0].GetLabel()[0].GetValue() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("http exemplars = %v", got)
+	}
+	if got := exemplars("ws"); len(got) != 0 {
+		t.Errorf("expected no exemplars outside of a span, got %v", got)
+	}
+}
@@ -0,0 +1,126 @@
+package base
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// URL selection strategies of targets with several URLs
+const (
+	// URLStrategyFailover tries the URLs in the configured order, so the first healthy one is used
+	URLStrategyFailover = "failover"
+	// URLStrategyRoundRobin starts with the URL after the active one on every reconnect
+	URLStrategyRoundRobin = "round_robin"
+)
+
+var (
+	// EndpointURLActive indicates which of the URLs of a target is in use
+	EndpointURLActive = newGaugeVec(prometheus.GaugeOpts{
+		Name: "endpoint_url_active",
+		Help: "Whether the URL is the one currently used by the target (1=active)",
+	}, append(labels, "connection_type", "url"))
+
+	// EndpointURLConnections counts connection attempts per URL
+	EndpointURLConnections = newCounterVec(prometheus.CounterOpts{
+		Name: "endpoint_url_connections_total",
+		Help: "Connection attempts per URL of the target by result",
+	}, append(labels, "connection_type", "url", "result"))
+)
+
+func init() {
+	addCollectors(
+		EndpointURLActive,
+		EndpointURLConnections,
+	)
+}
+
+// ValidateURLStrategy returns an error if strategy is not a known URL strategy
+func ValidateURLStrategy(strategy string) error {
+	switch strategy {
+	case "", URLStrategyFailover, URLStrategyRoundRobin:
+		return nil
+	}
+	return fmt.Errorf("unknown url_strategy %q, expected %s or %s",
+		strategy, URLStrategyFailover, URLStrategyRoundRobin)
+}
+
+// Failover selects the URL a target connects to among its configured URLs
+type Failover struct {
+	connectionType string
+	urls           []string
+	strategy       string
+
+	mu     sync.Mutex
+	active int
+}
+
+// NewFailover returns the URL selection of a connection type, empty URLs are skipped
+func NewFailover(connectionType string, urls []string, strategy string) *Failover {
+	f := &Failover{connectionType: connectionType, strategy: strategy, active: -1}
+	for _, url := range urls {
+		if url != "" {
+			f.urls = append(f.urls, url)
+		}
+	}
+	return f
+}
+
+// Candidates returns the URLs in the order they should be tried
+func (f *Failover) Candidates() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.strategy != URLStrategyRoundRobin || f.active < 0 {
+		return f.urls
+	}
+	start := (f.active + 1) % len(f.urls)
+	return append(append([]string{}, f.urls[start:]...), f.urls[:start]...)
+}
+
+// Record records a connection attempt to url, a successful one makes it the active URL
+func (f *Failover) Record(b *BaseChecker, url string, success bool) {
+	result := "fail"
+	if success {
+		result = "success"
+	}
+	EndpointURLConnections.WithLabelValues(b.AddLabelValues(f.connectionType, url, result)...).Inc()
+	if !success {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, u := range f.urls {
+		active := float64(0)
+		if u == url {
+			f.active = i
+			active = 1
+		}
+		EndpointURLActive.WithLabelValues(b.AddLabelValues(f.connectionType, u)...).Set(active)
+	}
+}
+
+// Active returns the URL of the last successful connection, the first URL before any
+func (f *Failover) Active() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.urls) == 0 {
+		return ""
+	}
+	if f.active < 0 {
+		return f.urls[0]
+	}
+	return f.urls[f.active]
+}
+
+// Configured reports whether there is any URL
+func (f *Failover) Configured() bool {
+	return len(f.urls) > 0
+}
+
+// Multiple reports whether there is more than one URL to choose from
+func (f *Failover) Multiple() bool {
+	return len(f.urls) > 1
+}
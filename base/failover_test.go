@@ -0,0 +1,30 @@
+package base
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFailoverCandidates(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "node"}
+	urls := []string{"https://a", "", "https://b", "https://c"}
+
+	failover := NewFailover("http", urls, URLStrategyFailover)
+	failover.Record(b, "https://b", true)
+	if got, want := failover.Candidates(), []string{"https://a", "https://b", "https://c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("failover candidates = %v, want %v", got, want)
+	}
+
+	roundRobin := NewFailover("http", urls, URLStrategyRoundRobin)
+	if got, want := roundRobin.Candidates(), []string{"https://a", "https://b", "https://c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("initial round robin candidates = %v, want %v", got, want)
+	}
+	roundRobin.Record(b, "https://c", true)
+	if got, want := roundRobin.Candidates(), []string{"https://a", "https://b", "https://c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("round robin candidates = %v, want %v", got, want)
+	}
+	roundRobin.Record(b, "https://a", true)
+	if got, want := roundRobin.Candidates(), []string{"https://b", "https://c", "https://a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("round robin candidates = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package base
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"storymonitor/events"
+)
+
+// HeadSink receives every new head observed by a checker. Implementations are
+// called synchronously from the subscription loop and must not block.
+type HeadSink interface {
+	AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time)
+}
+
+var (
+	headSinksMu sync.RWMutex
+	headSinks   []HeadSink
+)
+
+// RegisterHeadSink adds a sink that receives new heads from all checkers
+func RegisterHeadSink(sink HeadSink) {
+	headSinksMu.Lock()
+	defer headSinksMu.Unlock()
+	headSinks = append(headSinks, sink)
+}
+
+// UnregisterHeadSink removes a sink. Once it returns, no call of the sink
+// is in progress and none follows, even if checkers are still running.
+func UnregisterHeadSink(sink HeadSink) {
+	headSinksMu.Lock()
+	defer headSinksMu.Unlock()
+	for i, s := range headSinks {
+		if s == sink {
+			headSinks = append(headSinks[:i:i], headSinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// RecordHead publishes a new head to all registered sinks
+func (b *BaseChecker) RecordHead(height uint64, hash [32]byte, blockTime time.Time) {
+	receivedAt := time.Now()
+
+	b.publish(events.Event{
+		Kind:          events.KindBlock,
+		Time:          receivedAt,
+		ChainName:     b.ChainName,
+		HostName:      b.HostName,
+		FailureDomain: b.FailureDomain,
+		Height:        height,
+		Hash:          "0x" + hex.EncodeToString(hash[:]),
+		BlockTime:     blockTime,
+	})
+
+	headSinksMu.RLock()
+	defer headSinksMu.RUnlock()
+	for _, sink := range headSinks {
+		sink.AppendHead(b.ChainName, b.HostName, height, hash, blockTime, receivedAt)
+	}
+}
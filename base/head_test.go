@@ -0,0 +1,30 @@
+package base
+
+import (
+	"testing"
+	"time"
+)
+
+type countingSink struct {
+	heads int
+}
+
+func (s *countingSink) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	s.heads++
+}
+
+func TestUnregisterHeadSink(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "sink-node"}
+	kept, removed := &countingSink{}, &countingSink{}
+	RegisterHeadSink(kept)
+	RegisterHeadSink(removed)
+	defer UnregisterHeadSink(kept)
+
+	b.RecordHead(1, [32]byte{}, time.Now())
+	UnregisterHeadSink(removed)
+	b.RecordHead(2, [32]byte{}, time.Now())
+
+	if kept.heads != 2 || removed.heads != 1 {
+		t.Errorf("heads = %d kept and %d removed, want 2 and 1", kept.heads, removed.heads)
+	}
+}
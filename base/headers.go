@@ -0,0 +1,50 @@
+package base
+
+import (
+	"net/http"
+
+	"storymonitor/conf"
+)
+
+// TargetHeader returns the headers sent to a target, bearer_token and
+// basic_auth set the Authorization header
+func TargetHeader(headers map[string]string, bearerToken string, basicAuth *conf.BasicAuth) http.Header {
+	header := make(http.Header, len(headers)+1)
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	switch {
+	case bearerToken != "":
+		header.Set("Authorization", "Bearer "+bearerToken)
+	case basicAuth != nil:
+		req := http.Request{Header: header}
+		req.SetBasicAuth(basicAuth.Username, basicAuth.Password)
+	}
+	return header
+}
+
+// headerTransport sets headers on every request before sending it
+type headerTransport struct {
+	next   http.RoundTripper
+	header http.Header
+}
+
+// HeaderTransport wraps a transport so that every request carries header, a
+// nil transport stands for http.DefaultTransport
+func HeaderTransport(next http.RoundTripper, header http.Header) http.RoundTripper {
+	if len(header) == 0 {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &headerTransport{next: next, header: header}
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range t.header {
+		req.Header[key] = values
+	}
+	return t.next.RoundTrip(req)
+}
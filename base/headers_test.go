@@ -0,0 +1,35 @@
+package base
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"storymonitor/conf"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer server.Close()
+
+	header := TargetHeader(map[string]string{"x-api-key": "secret"}, "", &conf.BasicAuth{Username: "monitor", Password: "pass"})
+	cli := &http.Client{Transport: HeaderTransport(nil, header)}
+	resp, err := cli.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got.Get("X-Api-Key") != "secret" {
+		t.Errorf("X-Api-Key = %q, want secret", got.Get("X-Api-Key"))
+	}
+	if got.Get("Authorization") != "Basic bW9uaXRvcjpwYXNz" {
+		t.Errorf("Authorization = %q", got.Get("Authorization"))
+	}
+	if bearer := TargetHeader(nil, "token", nil).Get("Authorization"); bearer != "Bearer token" {
+		t.Errorf("bearer Authorization = %q", bearer)
+	}
+}
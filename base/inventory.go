@@ -0,0 +1,153 @@
+package base
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Endpoint connection statuses
+const (
+	EndpointStatusUnknown   = "unknown"
+	EndpointStatusConnected = "connected"
+	EndpointStatusFailed    = "failed"
+)
+
+// Endpoint is an external endpoint the monitor talks to
+type Endpoint struct {
+	Kind string `json:"kind"`
+	// Target is the kind of the target owning the endpoint, empty for
+	// endpoints of the monitor itself such as notifiers
+	Target      string    `json:"target,omitempty"`
+	ChainName   string    `json:"chain_name,omitempty"`
+	Owner       string    `json:"owner"`
+	URL         string    `json:"url"`
+	Scheme      string    `json:"scheme"`
+	Host        string    `json:"host"`
+	Port        string    `json:"port"`
+	Status      string    `json:"status"`
+	LastChecked time.Time `json:"last_checked,omitempty"`
+}
+
+type endpointKey struct {
+	kind, chainName, owner string
+}
+
+var (
+	inventoryMu sync.RWMutex
+	// inventory holds the endpoints by key and target kind, targets of
+	// different kinds may monitor the same endpoint of a node
+	inventory = make(map[endpointKey]map[string]*Endpoint)
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"ws":    "80",
+	"https": "443",
+	"wss":   "443",
+}
+
+// RegisterEndpoint adds an external endpoint of the monitor itself to the
+// inventory. Credentials in the URL are redacted before it is stored.
+func RegisterEndpoint(kind, chainName, owner, rawURL string) {
+	RegisterTargetEndpoint("", kind, chainName, owner, rawURL)
+}
+
+// RegisterTargetEndpoint adds an endpoint of a target of the given kind to
+// the inventory, see RegisterEndpoint
+func RegisterTargetEndpoint(target, kind, chainName, owner, rawURL string) {
+	ep := &Endpoint{
+		Kind:      kind,
+		Target:    target,
+		ChainName: chainName,
+		Owner:     owner,
+		URL:       rawURL,
+		Status:    EndpointStatusUnknown,
+	}
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		if u.User != nil {
+			u.User = url.User("redacted")
+		}
+		u.RawQuery = ""
+		ep.URL = u.String()
+		ep.Scheme = u.Scheme
+		ep.Host = u.Hostname()
+		ep.Port = u.Port()
+		if ep.Port == "" {
+			ep.Port = defaultPorts[u.Scheme]
+		}
+	} else if host, port, err := net.SplitHostPort(rawURL); err == nil {
+		ep.Host, ep.Port = host, port
+	}
+
+	inventoryMu.Lock()
+	defer inventoryMu.Unlock()
+	key := endpointKey{kind, chainName, owner}
+	if inventory[key] == nil {
+		inventory[key] = make(map[string]*Endpoint)
+	}
+	inventory[key][target] = ep
+}
+
+// UnregisterEndpoints removes the endpoints a target of the given kind
+// registered for an owner, e.g. when the target is removed. Endpoints of
+// targets of other kinds on the same node are kept.
+func UnregisterEndpoints(target, chainName, owner string) {
+	inventoryMu.Lock()
+	defer inventoryMu.Unlock()
+
+	for key, byTarget := range inventory {
+		if key.chainName != chainName || key.owner != owner {
+			continue
+		}
+		delete(byTarget, target)
+		if len(byTarget) == 0 {
+			delete(inventory, key)
+		}
+	}
+}
+
+// UpdateEndpointStatus records the result of talking to a registered endpoint
+func UpdateEndpointStatus(kind, chainName, owner string, connected bool) {
+	inventoryMu.Lock()
+	defer inventoryMu.Unlock()
+
+	status := EndpointStatusFailed
+	if connected {
+		status = EndpointStatusConnected
+	}
+	now := time.Now()
+	for _, ep := range inventory[endpointKey{kind, chainName, owner}] {
+		ep.Status = status
+		ep.LastChecked = now
+	}
+}
+
+// Endpoints returns a snapshot of the inventory sorted by kind, chain, owner and target
+func Endpoints() []Endpoint {
+	inventoryMu.RLock()
+	defer inventoryMu.RUnlock()
+
+	result := make([]Endpoint, 0, len(inventory))
+	for _, byTarget := range inventory {
+		for _, ep := range byTarget {
+			result = append(result, *ep)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.ChainName != b.ChainName {
+			return a.ChainName < b.ChainName
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Target < b.Target
+	})
+	return result
+}
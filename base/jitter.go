@@ -0,0 +1,56 @@
+package base
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// phaseOffsetDisabled turns off the random phase offsets of periodic checks
+var phaseOffsetDisabled atomic.Bool
+
+// SetPhaseOffset enables or disables random phase offsets of periodic checks
+func SetPhaseOffset(enabled bool) {
+	phaseOffsetDisabled.Store(!enabled)
+}
+
+// Jitter returns a random duration in [0, max)
+func Jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// WaitForJitter waits a random duration up to max, it returns false if the
+// context is cancelled first
+func WaitForJitter(ctx context.Context, max time.Duration) bool {
+	delay := Jitter(max)
+	if delay == 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// WaitForPhaseOffset delays the first run of a periodic check by a random offset
+// within its interval, so checks of many targets sharing the same check_second
+// are spread over the interval instead of firing together. It must be called
+// before the ticker is created and returns false if the context is cancelled.
+func WaitForPhaseOffset(ctx context.Context, checkSecond int, defaultSeconds int) bool {
+	if phaseOffsetDisabled.Load() {
+		return ctx.Err() == nil
+	}
+	if checkSecond <= 0 {
+		checkSecond = defaultSeconds
+	}
+	return WaitForJitter(ctx, time.Duration(checkSecond)*time.Second)
+}
@@ -0,0 +1,148 @@
+package base
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// OutboundRequestsInFlight tracks outbound requests holding a slot of the concurrency limiter
+	OutboundRequestsInFlight = newGauge(prometheus.GaugeOpts{
+		Name: "outbound_requests_in_flight",
+		Help: "Number of outbound check requests currently in flight",
+	})
+
+	// OutboundRequestWaitSeconds tracks how long outbound requests waited for a free slot
+	OutboundRequestWaitSeconds = newHistogram(prometheus.HistogramOpts{
+		Name:    "outbound_request_wait_seconds",
+		Help:    "Time outbound check requests waited for the concurrency limiter",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	})
+)
+
+func init() {
+	addCollectors(
+		OutboundRequestsInFlight,
+		OutboundRequestWaitSeconds,
+	)
+}
+
+// outboundLimiter is the semaphore shared by all checkers, nil means unlimited
+var outboundLimiter atomic.Pointer[chan struct{}]
+
+// SetConcurrencyLimit limits the number of concurrent outbound requests of all
+// checkers, a value <= 0 removes the limit
+func SetConcurrencyLimit(limit int) {
+	if limit <= 0 {
+		outboundLimiter.Store(nil)
+		return
+	}
+	slots := make(chan struct{}, limit)
+	outboundLimiter.Store(&slots)
+}
+
+// AcquireOutbound waits for a free slot for an outbound request. The returned
+// function releases the slot, calling it again is a no-op.
+func AcquireOutbound(ctx context.Context) (func(), error) {
+	slots := outboundLimiter.Load()
+	if slots != nil {
+		start := time.Now()
+		select {
+		case *slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		OutboundRequestWaitSeconds.Observe(time.Since(start).Seconds())
+	}
+	OutboundRequestsInFlight.Inc()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			OutboundRequestsInFlight.Dec()
+			if slots != nil {
+				<-*slots
+			}
+		})
+	}, nil
+}
+
+// limitedTransport holds a limiter slot from sending a request until its
+// response body is closed
+type limitedTransport struct {
+	next http.RoundTripper
+}
+
+// defaultTransport is http.DefaultTransport using the global proxy
+var defaultTransport = NewProxyTransport("")
+
+// LimitTransport wraps a transport with the outbound concurrency limiter, a nil
+// transport stands for http.DefaultTransport using the global proxy
+func LimitTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = defaultTransport
+	}
+	if _, ok := next.(*limitedTransport); ok {
+		return next
+	}
+	return &limitedTransport{next: next}
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := AcquireOutbound(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+// LimitClient returns a copy of cli whose requests go through the outbound
+// concurrency limiter
+func LimitClient(cli *http.Client) *http.Client {
+	if cli == nil {
+		cli = &http.Client{}
+	}
+	limited := *cli
+	limited.Transport = LimitTransport(cli.Transport)
+	return &limited
+}
+
+// LimitDial wraps a dial function so that establishing a connection holds a
+// limiter slot, long-lived connections such as websockets release it once connected
+func LimitDial(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		release, err := AcquireOutbound(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return dial(ctx, network, addr)
+	}
+}
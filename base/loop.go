@@ -0,0 +1,108 @@
+package base
+
+import (
+	"context"
+	"time"
+
+	"storymonitor/events"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// CheckLoopDuration tracks how long an iteration of a check loop takes
+	CheckLoopDuration = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "check_loop_duration_seconds",
+		Help:    "Duration of an iteration of a check loop of the monitor in seconds",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, append(labels, "loop"))
+
+	// CheckLoopTickerLag tracks how late a check loop handles the ticks of its ticker
+	CheckLoopTickerLag = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "check_loop_ticker_lag_seconds",
+		Help:    "Delay between a tick of a check loop's ticker and the loop handling it in seconds",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	}, append(labels, "loop"))
+
+	// CheckerGoroutines counts the running check loops of a checker
+	CheckerGoroutines = newGaugeVec(prometheus.GaugeOpts{
+		Name: "checker_goroutines",
+		Help: "Number of check loop goroutines running for the target",
+	}, labels)
+
+	// CheckerEventsDropped counts the events of a checker dropped by the internal event bus
+	CheckerEventsDropped = newCounterVec(prometheus.CounterOpts{
+		Name: "checker_events_dropped_total",
+		Help: "Number of internal events of the target dropped because a consumer lagged behind",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		CheckLoopDuration,
+		CheckLoopTickerLag,
+		CheckerGoroutines,
+		CheckerEventsDropped,
+	)
+}
+
+// Loop instruments a check loop of a checker, so a monitor that cannot keep
+// up with its checks shows in the metrics rather than as stale node metrics.
+// A loop is used by a single goroutine.
+type Loop struct {
+	b    *BaseChecker
+	name string
+
+	// started is the start of the running iteration, zero between iterations
+	started time.Time
+}
+
+// StartLoop counts a check loop goroutine of the checker until Stop.
+// Iterations are measured from the first tick on.
+func (b *BaseChecker) StartLoop(name string) *Loop {
+	CheckerGoroutines.WithLabelValues(b.AddLabelValues()...).Inc()
+	return &Loop{b: b, name: name}
+}
+
+// Stop ends the loop when its goroutine exits
+func (l *Loop) Stop() {
+	CheckerGoroutines.WithLabelValues(l.b.AddLabelValues()...).Dec()
+}
+
+// Tick records the lag of a tick received at tickAt, the time sent by the
+// ticker, and starts an iteration
+func (l *Loop) Tick(tickAt time.Time) {
+	now := time.Now()
+	CheckLoopTickerLag.WithLabelValues(l.b.AddLabelValues(l.name)...).Observe(max(now.Sub(tickAt), 0).Seconds())
+	l.started = now
+}
+
+// End records the duration of the running iteration
+func (l *Loop) End() {
+	if l.started.IsZero() {
+		return
+	}
+	CheckLoopDuration.WithLabelValues(l.b.AddLabelValues(l.name)...).Observe(time.Since(l.started).Seconds())
+	l.started = time.Time{}
+}
+
+// Wait ends the running iteration and waits like WaitForContextOrTicker,
+// starting the next iteration on a tick
+func (l *Loop) Wait(ctx context.Context, ticker *time.Ticker) bool {
+	l.End()
+	select {
+	case <-ctx.Done():
+		return false
+	case tickAt := <-ticker.C:
+		l.Tick(tickAt)
+		return true
+	}
+}
+
+// publish publishes an event of the checker, counting the deliveries dropped
+// by lagging consumers
+func (b *BaseChecker) publish(e events.Event) {
+	if dropped := events.Publish(e); dropped > 0 {
+		CheckerEventsDropped.WithLabelValues(b.AddLabelValues()...).Add(float64(dropped))
+	}
+}
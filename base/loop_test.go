@@ -0,0 +1,74 @@
+package base
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"storymonitor/events"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func histogramOf(t *testing.T, vec *prometheus.HistogramVec, lvs ...string) *dto.Histogram {
+	t.Helper()
+	var m dto.Metric
+	if err := vec.WithLabelValues(lvs...).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram()
+}
+
+func TestLoop(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "loop-node"}
+	goroutines := CheckerGoroutines.WithLabelValues(b.AddLabelValues()...)
+
+	loop := b.StartLoop("check")
+	if got := testutil.ToFloat64(goroutines); got != 1 {
+		t.Errorf("goroutines after start = %v, want 1", got)
+	}
+
+	ticker := time.NewTicker(time.Millisecond)
+	// The tick waits in the channel while the loop is busy
+	time.Sleep(20 * time.Millisecond)
+	if !loop.Wait(context.Background(), ticker) {
+		t.Fatal("Wait returned false on a tick")
+	}
+	ticker.Stop()
+
+	lag := histogramOf(t, CheckLoopTickerLag, b.AddLabelValues("check")...)
+	if lag.GetSampleCount() != 1 || lag.GetSampleSum() < 0.015 {
+		t.Errorf("ticker lag = %d samples summing to %vs, want 1 sample of at least 15ms", lag.GetSampleCount(), lag.GetSampleSum())
+	}
+	// No iteration ran before the first tick
+	if n := histogramOf(t, CheckLoopDuration, b.AddLabelValues("check")...).GetSampleCount(); n != 0 {
+		t.Errorf("iterations before the first tick = %d, want 0", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if loop.Wait(ctx, ticker) {
+		t.Fatal("Wait returned true on a done context")
+	}
+	if n := histogramOf(t, CheckLoopDuration, b.AddLabelValues("check")...).GetSampleCount(); n != 1 {
+		t.Errorf("iterations = %d, want 1", n)
+	}
+
+	loop.Stop()
+	if got := testutil.ToFloat64(goroutines); got != 0 {
+		t.Errorf("goroutines after stop = %v, want 0", got)
+	}
+}
+
+func TestPublishCountsDrops(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "drop-node"}
+	sub := events.Subscribe("test", 0, events.KindError)
+	defer events.Default.Unsubscribe(sub)
+
+	b.publish(events.Event{Kind: events.KindError, ChainName: b.ChainName, HostName: b.HostName})
+	if got := testutil.ToFloat64(CheckerEventsDropped.WithLabelValues(b.AddLabelValues()...)); got != 1 {
+		t.Errorf("dropped events = %v, want 1", got)
+	}
+}
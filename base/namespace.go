@@ -0,0 +1,94 @@
+package base
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultNamespace is the prefix of the metrics exported by the checkers
+const DefaultNamespace = "story_node"
+
+// namePattern matches valid namespaces and label names
+var namePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ValidateMetricsNamespace returns an error if namespace is not a valid metric
+// name prefix or a constant label would clash with the labels of the checkers
+func ValidateMetricsNamespace(namespace string, constLabels map[string]string) error {
+	if namespace != "" && !namePattern.MatchString(namespace) {
+		return fmt.Errorf("invalid metrics namespace %q", namespace)
+	}
+	for name := range constLabels {
+		if !namePattern.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid constant label name %q", name)
+		}
+		for _, reserved := range labelsWithInfo {
+			if name == reserved {
+				return fmt.Errorf("constant label %q is set by the checkers", name)
+			}
+		}
+	}
+	return nil
+}
+
+// RenameMetric replaces the default namespace of a metric name, or of the
+// metric names in a PromQL expression, with namespace
+func RenameMetric(name, namespace string) string {
+	if namespace == "" || namespace == DefaultNamespace {
+		return name
+	}
+	return strings.ReplaceAll(name, DefaultNamespace+"_", namespace+"_")
+}
+
+// constLabelsGatherer adds constant labels to all gathered metrics
+type constLabelsGatherer struct {
+	gatherer    prometheus.Gatherer
+	constLabels []*dto.LabelPair
+}
+
+// ConstLabelsGatherer wraps gatherer so that all metrics, including those
+// not registered by the checkers, carry constLabels. A metric with a label of
+// the same name keeps its own value. The namespace of the checkers' metrics
+// is set by RegisterMetrics.
+func ConstLabelsGatherer(gatherer prometheus.Gatherer, constLabels map[string]string) prometheus.Gatherer {
+	if len(constLabels) == 0 {
+		return gatherer
+	}
+	g := &constLabelsGatherer{gatherer: gatherer}
+	for name, value := range constLabels {
+		g.constLabels = append(g.constLabels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+	}
+	return g
+}
+
+func (g *constLabelsGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			m.Label = withConstLabels(m.GetLabel(), g.constLabels)
+		}
+	}
+	return families, err
+}
+
+func withConstLabels(labelPairs, constLabels []*dto.LabelPair) []*dto.LabelPair {
+	if len(constLabels) == 0 {
+		return labelPairs
+	}
+	present := make(map[string]bool, len(labelPairs))
+	for _, l := range labelPairs {
+		present[l.GetName()] = true
+	}
+	for _, l := range constLabels {
+		if !present[l.GetName()] {
+			labelPairs = append(labelPairs, l)
+		}
+	}
+	sort.Slice(labelPairs, func(i, j int) bool { return labelPairs[i].GetName() < labelPairs[j].GetName() })
+	return labelPairs
+}
@@ -0,0 +1,48 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestConstLabelsGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "story_node_test_height"}, []string{"chain_name", "env"})
+	registry.MustRegister(gauge)
+	gauge.WithLabelValues("story", "node").Set(1)
+
+	gatherer := ConstLabelsGatherer(registry, map[string]string{"env": "prod", "region": "eu"})
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].GetName() != "story_node_test_height" {
+		t.Fatalf("unexpected families %v", families)
+	}
+	got := map[string]string{}
+	for _, l := range families[0].GetMetric()[0].GetLabel() {
+		got[l.GetName()] = l.GetValue()
+	}
+	want := map[string]string{"chain_name": "story", "env": "node", "region": "eu"}
+	if len(got) != len(want) {
+		t.Fatalf("labels %v, want %v", got, want)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("label %s = %q, want %q", name, got[name], value)
+		}
+	}
+}
+
+func TestValidateMetricsNamespace(t *testing.T) {
+	if err := ValidateMetricsNamespace("eth_node", map[string]string{"env": "prod"}); err != nil {
+		t.Errorf("valid namespace: %v", err)
+	}
+	if err := ValidateMetricsNamespace("eth-node", nil); err == nil {
+		t.Error("namespace with a dash accepted")
+	}
+	if err := ValidateMetricsNamespace("", map[string]string{"hostname": "x"}); err == nil {
+		t.Error("constant label hostname accepted")
+	}
+}
@@ -0,0 +1,194 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// nativeMetrics re-exposes the metrics scraped from the nodes' own metrics
+// endpoints, keyed by chain_name, hostname and endpoint URL, as the execution
+// and consensus clients of a node usually share the labels
+var nativeMetrics = &nativeMetricsCollector{families: make(map[[3]string][]*dto.MetricFamily)}
+
+func init() {
+	addCollectors(
+		nativeMetrics,
+	)
+}
+
+type nativeMetricsCollector struct {
+	mu       sync.RWMutex
+	families map[[3]string][]*dto.MetricFamily
+}
+
+func (c *nativeMetricsCollector) set(chainName, hostName, url string, families []*dto.MetricFamily) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := [3]string{chainName, hostName, url}
+	if families == nil {
+		delete(c.families, key)
+		return
+	}
+	c.families[key] = families
+}
+
+// Describe sends no descriptors, the collector is unchecked as the proxied
+// metrics are only known once scraped
+func (c *nativeMetricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect emits the proxied metrics with the chain_name and hostname labels of their target
+func (c *nativeMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key, families := range c.families {
+		for _, family := range families {
+			for _, m := range family.GetMetric() {
+				metric, err := relabelMetric(family, m, key[0], key[1])
+				if err != nil {
+					glog.V(5).Infof("[nativeMetrics] Skip %s of %s: %v", family.GetName(), key[1], err)
+					continue
+				}
+				ch <- metric
+			}
+		}
+	}
+}
+
+// relabelMetric converts a scraped metric into a const metric carrying the
+// chain_name and hostname labels, which replace labels of the same name
+func relabelMetric(family *dto.MetricFamily, m *dto.Metric, chainName, hostName string) (prometheus.Metric, error) {
+	names := append([]string{}, labels...)
+	values := []string{chainName, hostName}
+	for _, label := range m.GetLabel() {
+		if label.GetName() == "chain_name" || label.GetName() == "hostname" {
+			continue
+		}
+		names = append(names, label.GetName())
+		values = append(values, label.GetValue())
+	}
+	// Help texts can differ between node versions, a fixed one keeps the families of all targets consistent
+	desc := prometheus.NewDesc(family.GetName(), "Metric of the node's own metrics endpoint, proxied by the monitor", names, nil)
+
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), values...)
+	case dto.MetricType_GAUGE:
+		return prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), values...)
+	case dto.MetricType_UNTYPED:
+		return prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), values...)
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		buckets := make(map[float64]uint64, len(h.GetBucket()))
+		for _, b := range h.GetBucket() {
+			buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+		return prometheus.NewConstHistogram(desc, h.GetSampleCount(), h.GetSampleSum(), buckets, values...)
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		quantiles := make(map[float64]float64, len(s.GetQuantile()))
+		for _, q := range s.GetQuantile() {
+			quantiles[q.GetQuantile()] = q.GetValue()
+		}
+		return prometheus.NewConstSummary(desc, s.GetSampleCount(), s.GetSampleSum(), quantiles, values...)
+	}
+	return nil, fmt.Errorf("unsupported metric type %s", family.GetType())
+}
+
+// MatchMetric reports whether name is selected by the whitelist, whose entries
+// are metric names or prefixes ending with *
+func MatchMetric(whitelist []string, name string) bool {
+	for _, pattern := range whitelist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ScrapeNativeMetrics fetches the metrics endpoint at url and keeps the
+// families selected by the whitelist
+func ScrapeNativeMetrics(ctx context.Context, cli *http.Client, url string, whitelist []string) ([]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	families := []*dto.MetricFamily{}
+	for name, family := range parsed {
+		if MatchMetric(whitelist, name) {
+			families = append(families, family)
+		}
+	}
+	return families, nil
+}
+
+// NativeMetricsCheck periodically scrapes the node's own metrics endpoint and
+// re-exposes the whitelisted metrics with the target's labels, reported as
+// endpoint_type="native_metrics". Without configured metrics the defaults of
+// the node type are used. The metrics of a failed scrape are dropped rather
+// than re-exposed stale.
+func (b *BaseChecker) NativeMetricsCheck(ctx context.Context, c *conf.NativeMetrics, proxy string, defaults []string) {
+	whitelist := c.Metrics
+	if len(whitelist) == 0 {
+		whitelist = defaults
+	}
+	cli := LimitClient(&http.Client{Transport: NewProxyTransport(proxy), Timeout: 10 * time.Second})
+	defer nativeMetrics.set(b.ChainName, b.HostName, c.URL, nil)
+
+	if !WaitForPhaseOffset(ctx, c.CheckSecond, 15) {
+		return
+	}
+
+	ticker := CheckSecondToTicker(c.CheckSecond, 15)
+	defer ticker.Stop()
+	loop := b.StartLoop("native_metrics")
+	defer loop.Stop()
+
+	for {
+		b.HealthCheckOperation("native_metrics", func() error {
+			families, err := ScrapeNativeMetrics(ctx, cli, c.URL, whitelist)
+			if err != nil {
+				glog.Errorf("[NativeMetricsCheck] Node %s scrape %s fail: %v", b.HostName, c.URL, err)
+				nativeMetrics.set(b.ChainName, b.HostName, c.URL, nil)
+				return err
+			}
+			nativeMetrics.set(b.ChainName, b.HostName, c.URL, families)
+			return nil
+		})
+
+		if !loop.Wait(ctx, ticker) {
+			glog.V(5).Info("[NativeMetricsCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package base
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const cometbftSample = `# HELP cometbft_consensus_height Height of the chain.
+# TYPE cometbft_consensus_height gauge
+cometbft_consensus_height{chain_id="story-1"} 1234
+# HELP cometbft_consensus_rounds Number of rounds.
+# TYPE cometbft_consensus_rounds gauge
+cometbft_consensus_rounds{chain_id="story-1"} 0
+# HELP cometbft_p2p_message_send_bytes_total Bytes sent.
+# TYPE cometbft_p2p_message_send_bytes_total counter
+cometbft_p2p_message_send_bytes_total{chain_id="story-1",message_type="vote"} 99
+# HELP go_goroutines Number of goroutines.
+# TYPE go_goroutines gauge
+go_goroutines 42
+`
+
+func TestMatchMetric(t *testing.T) {
+	whitelist := []string{"cometbft_consensus_height", "cometbft_p2p_*"}
+	for name, want := range map[string]bool{
+		"cometbft_consensus_height":             true,
+		"cometbft_consensus_height_total":       false,
+		"cometbft_p2p_message_send_bytes_total": true,
+		"go_goroutines":                         false,
+	} {
+		if got := MatchMetric(whitelist, name); got != want {
+			t.Errorf("MatchMetric(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestNativeMetricsRelabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cometbftSample))
+	}))
+	defer server.Close()
+
+	families, err := ScrapeNativeMetrics(context.Background(), server.Client(), server.URL, []string{"cometbft_consensus_*"})
+	if err != nil {
+		t.Fatalf("ScrapeNativeMetrics: %v", err)
+	}
+	if len(families) != 2 {
+		t.Fatalf("ScrapeNativeMetrics kept %d families, want 2", len(families))
+	}
+
+	collector := &nativeMetricsCollector{families: make(map[[3]string][]*dto.MetricFamily)}
+	collector.set("story", "node1", server.URL, families)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	gathered, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	for _, family := range gathered {
+		if family.GetName() != "cometbft_consensus_height" {
+			continue
+		}
+		got := map[string]string{}
+		for _, label := range family.GetMetric()[0].GetLabel() {
+			got[label.GetName()] = label.GetValue()
+		}
+		want := map[string]string{"chain_name": "story", "hostname": "node1", "chain_id": "story-1"}
+		for name, value := range want {
+			if got[name] != value {
+				t.Errorf("label %s = %q, want %q", name, got[name], value)
+			}
+		}
+		if v := family.GetMetric()[0].GetGauge().GetValue(); v != 1234 {
+			t.Errorf("cometbft_consensus_height = %v, want 1234", v)
+		}
+		return
+	}
+	t.Fatal("cometbft_consensus_height not gathered")
+}
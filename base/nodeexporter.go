@@ -0,0 +1,178 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+var (
+	// HostFilesystemAvail tracks the free space of the chain data volume
+	HostFilesystemAvail = newGaugeVec(prometheus.GaugeOpts{
+		Name: "host_filesystem_avail_bytes",
+		Help: "Free space available to the node on the chain data volume, scraped from node_exporter",
+	}, append(labels, "mountpoint"))
+
+	// HostFilesystemSize tracks the size of the chain data volume
+	HostFilesystemSize = newGaugeVec(prometheus.GaugeOpts{
+		Name: "host_filesystem_size_bytes",
+		Help: "Size of the chain data volume, scraped from node_exporter",
+	}, append(labels, "mountpoint"))
+
+	// HostMemoryAvailable tracks the memory available on the node host
+	HostMemoryAvailable = newGaugeVec(prometheus.GaugeOpts{
+		Name: "host_memory_available_bytes",
+		Help: "Memory available on the node host, scraped from node_exporter",
+	}, labels)
+
+	// HostMemoryTotal tracks the memory of the node host
+	HostMemoryTotal = newGaugeVec(prometheus.GaugeOpts{
+		Name: "host_memory_total_bytes",
+		Help: "Total memory of the node host, scraped from node_exporter",
+	}, labels)
+
+	// HostLoad tracks the load averages of the node host
+	HostLoad = newGaugeVec(prometheus.GaugeOpts{
+		Name: "host_load",
+		Help: "Load average of the node host over the period (1m, 5m, 15m), scraped from node_exporter",
+	}, append(labels, "period"))
+)
+
+func init() {
+	addCollectors(
+		HostFilesystemAvail,
+		HostFilesystemSize,
+		HostMemoryAvailable,
+		HostMemoryTotal,
+		HostLoad,
+	)
+}
+
+// HostStats is the subset of node_exporter metrics re-exported for a target
+type HostStats struct {
+	FilesystemAvail float64
+	FilesystemSize  float64
+	MemoryAvailable float64
+	MemoryTotal     float64
+	// Load holds the 1, 5 and 15 minute load averages
+	Load [3]float64
+}
+
+var loadPeriods = [3]string{"1m", "5m", "15m"}
+
+// ParseNodeExporter extracts the host stats from node_exporter metrics in the
+// text exposition format, taking the filesystem mounted at mountpoint
+func ParseNodeExporter(r io.Reader, mountpoint string) (HostStats, error) {
+	var stats HostStats
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return stats, err
+	}
+
+	gauge := func(name string, match func(*dto.Metric) bool) (float64, bool) {
+		family, ok := families[name]
+		if !ok {
+			return 0, false
+		}
+		for _, m := range family.GetMetric() {
+			if match == nil || match(m) {
+				return m.GetGauge().GetValue(), true
+			}
+		}
+		return 0, false
+	}
+	onMountpoint := func(m *dto.Metric) bool {
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "mountpoint" && label.GetValue() == mountpoint {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ok bool
+	if stats.FilesystemAvail, ok = gauge("node_filesystem_avail_bytes", onMountpoint); !ok {
+		return stats, fmt.Errorf("no node_filesystem_avail_bytes for mountpoint %s", mountpoint)
+	}
+	stats.FilesystemSize, _ = gauge("node_filesystem_size_bytes", onMountpoint)
+	stats.MemoryAvailable, _ = gauge("node_memory_MemAvailable_bytes", nil)
+	stats.MemoryTotal, _ = gauge("node_memory_MemTotal_bytes", nil)
+	for i, name := range []string{"node_load1", "node_load5", "node_load15"} {
+		stats.Load[i], _ = gauge(name, nil)
+	}
+	return stats, nil
+}
+
+// ScrapeNodeExporter fetches the host stats from the node_exporter at url
+func ScrapeNodeExporter(ctx context.Context, cli *http.Client, url, mountpoint string) (HostStats, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return HostStats{}, err
+	}
+	// Ask for the text format, node_exporter may otherwise negotiate protobuf
+	req.Header.Set("Accept", string(expfmt.FmtText))
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return HostStats{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return HostStats{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ParseNodeExporter(resp.Body, mountpoint)
+}
+
+// NodeExporterCheck periodically scrapes the node_exporter paired with a
+// target and re-exports the host stats under the target's labels, reported
+// as endpoint_type="node_exporter"
+func (b *BaseChecker) NodeExporterCheck(ctx context.Context, c *conf.NodeExporter, proxy string) {
+	mountpoint := c.Mountpoint
+	if mountpoint == "" {
+		mountpoint = "/"
+	}
+	cli := LimitClient(&http.Client{Transport: NewProxyTransport(proxy), Timeout: 10 * time.Second})
+
+	if !WaitForPhaseOffset(ctx, c.CheckSecond, 30) {
+		return
+	}
+
+	ticker := CheckSecondToTicker(c.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := b.StartLoop("node_exporter")
+	defer loop.Stop()
+
+	for {
+		b.HealthCheckOperation("node_exporter", func() error {
+			stats, err := ScrapeNodeExporter(ctx, cli, c.URL, mountpoint)
+			if err != nil {
+				glog.Errorf("[NodeExporterCheck] Node %s scrape %s fail: %v", b.HostName, c.URL, err)
+				return err
+			}
+			HostFilesystemAvail.WithLabelValues(b.AddLabelValues(mountpoint)...).Set(stats.FilesystemAvail)
+			HostFilesystemSize.WithLabelValues(b.AddLabelValues(mountpoint)...).Set(stats.FilesystemSize)
+			HostMemoryAvailable.WithLabelValues(b.AddLabelValues()...).Set(stats.MemoryAvailable)
+			HostMemoryTotal.WithLabelValues(b.AddLabelValues()...).Set(stats.MemoryTotal)
+			for i, period := range loadPeriods {
+				HostLoad.WithLabelValues(b.AddLabelValues(period)...).Set(stats.Load[i])
+			}
+			return nil
+		})
+
+		if !loop.Wait(ctx, ticker) {
+			glog.V(5).Info("[NodeExporterCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
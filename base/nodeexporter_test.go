@@ -0,0 +1,45 @@
+package base
+
+import (
+	"strings"
+	"testing"
+)
+
+const nodeExporterSample = `# TYPE node_filesystem_avail_bytes gauge
+node_filesystem_avail_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 1.5e+10
+node_filesystem_avail_bytes{device="/dev/nvme0n1",fstype="xfs",mountpoint="/data"} 2.5e+11
+# TYPE node_filesystem_size_bytes gauge
+node_filesystem_size_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 5e+10
+node_filesystem_size_bytes{device="/dev/nvme0n1",fstype="xfs",mountpoint="/data"} 1e+12
+# TYPE node_memory_MemAvailable_bytes gauge
+node_memory_MemAvailable_bytes 8e+09
+# TYPE node_memory_MemTotal_bytes gauge
+node_memory_MemTotal_bytes 3.2e+10
+# TYPE node_load1 gauge
+node_load1 1.5
+# TYPE node_load5 gauge
+node_load5 1.25
+# TYPE node_load15 gauge
+node_load15 0.75
+`
+
+func TestParseNodeExporter(t *testing.T) {
+	stats, err := ParseNodeExporter(strings.NewReader(nodeExporterSample), "/data")
+	if err != nil {
+		t.Fatalf("ParseNodeExporter: %v", err)
+	}
+	want := HostStats{
+		FilesystemAvail: 2.5e11,
+		FilesystemSize:  1e12,
+		MemoryAvailable: 8e9,
+		MemoryTotal:     3.2e10,
+		Load:            [3]float64{1.5, 1.25, 0.75},
+	}
+	if stats != want {
+		t.Fatalf("ParseNodeExporter = %+v, want %+v", stats, want)
+	}
+
+	if _, err := ParseNodeExporter(strings.NewReader(nodeExporterSample), "/missing"); err == nil {
+		t.Fatal("ParseNodeExporter with an unknown mountpoint succeeded")
+	}
+}
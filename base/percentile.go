@@ -0,0 +1,108 @@
+package base
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// responseWindow is the window of the rolling response time percentiles
+	responseWindow = 5 * time.Minute
+	// maxResponseSamples bounds the samples kept per endpoint type, checks
+	// running more often than every 300ms shorten the window
+	maxResponseSamples = 1000
+)
+
+// responseQuantiles are the exported percentiles by their quantile label
+var responseQuantiles = []struct {
+	label    string
+	quantile float64
+}{
+	{"0.5", 0.5},
+	{"0.95", 0.95},
+	{"0.99", 0.99},
+}
+
+// EndpointResponseTimePercentile tracks percentiles of the recent response
+// times of a node, which unlike histogram quantiles are exact per target
+var EndpointResponseTimePercentile = newGaugeVec(prometheus.GaugeOpts{
+	Name: "endpoint_response_time_percentile_milliseconds",
+	Help: "Percentiles of the response times of node endpoints over the last 5 minutes in milliseconds",
+}, append(labels, "endpoint_type", "quantile"))
+
+func init() {
+	addCollectors(
+		EndpointResponseTimePercentile,
+	)
+}
+
+type timedSample struct {
+	at    time.Time
+	value float64
+}
+
+// slidingWindow keeps the samples of the recent window
+type slidingWindow struct {
+	window  time.Duration
+	max     int
+	samples []timedSample
+}
+
+// add records a sample and drops those that left the window
+func (w *slidingWindow) add(at time.Time, value float64) {
+	w.samples = append(w.samples, timedSample{at, value})
+
+	from := at.Add(-w.window)
+	drop := 0
+	for drop < len(w.samples) && (w.samples[drop].at.Before(from) || len(w.samples)-drop > w.max) {
+		drop++
+	}
+	w.samples = w.samples[drop:]
+}
+
+// percentiles returns the nearest-rank percentiles of the samples in the window
+func (w *slidingWindow) percentiles(quantiles ...float64) []float64 {
+	values := make([]float64, len(w.samples))
+	for i, s := range w.samples {
+		values[i] = s.value
+	}
+	sort.Float64s(values)
+
+	result := make([]float64, len(quantiles))
+	if len(values) == 0 {
+		return result
+	}
+	for i, q := range quantiles {
+		rank := int(math.Ceil(q*float64(len(values)))) - 1
+		result[i] = values[max(rank, 0)]
+	}
+	return result
+}
+
+// recordResponsePercentiles adds a response time to the window of its
+// endpoint type and exports the percentiles of the window
+func (b *BaseChecker) recordResponsePercentiles(endpointType string, milliseconds float64, at time.Time) {
+	b.responseMu.Lock()
+	defer b.responseMu.Unlock()
+
+	if b.responseTimes == nil {
+		b.responseTimes = make(map[string]*slidingWindow)
+	}
+	w, ok := b.responseTimes[endpointType]
+	if !ok {
+		w = &slidingWindow{window: responseWindow, max: maxResponseSamples}
+		b.responseTimes[endpointType] = w
+	}
+	w.add(at, milliseconds)
+
+	quantiles := make([]float64, len(responseQuantiles))
+	for i, q := range responseQuantiles {
+		quantiles[i] = q.quantile
+	}
+	for i, value := range w.percentiles(quantiles...) {
+		EndpointResponseTimePercentile.WithLabelValues(b.AddLabelValues(endpointType, responseQuantiles[i].label)...).Set(value)
+	}
+}
@@ -0,0 +1,31 @@
+package base
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowPercentiles(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	w := &slidingWindow{window: time.Minute, max: 100}
+
+	for i := 1; i <= 100; i++ {
+		w.add(start.Add(time.Duration(i)*100*time.Millisecond), float64(i))
+	}
+	got := w.percentiles(0.5, 0.95, 0.99)
+	if got[0] != 50 || got[1] != 95 || got[2] != 99 {
+		t.Errorf("percentiles = %v, want [50 95 99]", got)
+	}
+
+	// The cap drops the oldest samples first
+	w.add(start.Add(11*time.Second), 1000)
+	if len(w.samples) != 100 || w.samples[0].value != 2 {
+		t.Errorf("expected 100 samples starting at 2, got %d starting at %v", len(w.samples), w.samples[0].value)
+	}
+
+	// Samples older than the window are dropped
+	w.add(start.Add(2*time.Minute), 7)
+	if got := w.percentiles(0.5); len(w.samples) != 1 || got[0] != 7 {
+		t.Errorf("expected a single sample after the window, got %d with p50 %v", len(w.samples), got[0])
+	}
+}
@@ -0,0 +1,182 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+var (
+	// PingRTT tracks the average ICMP echo round trip time of the last probe
+	PingRTT = newGaugeVec(prometheus.GaugeOpts{
+		Name: "ping_rtt_milliseconds",
+		Help: "Average ICMP echo round trip time of the last ping probe in milliseconds",
+	}, labels)
+
+	// PingPacketLoss tracks the fraction of lost echo requests in the last probe
+	PingPacketLoss = newGaugeVec(prometheus.GaugeOpts{
+		Name: "ping_packet_loss_ratio",
+		Help: "Fraction of ICMP echo requests without reply in the last ping probe (0-1)",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		PingRTT,
+		PingPacketLoss,
+	)
+}
+
+// PingResult summarises one ping probe
+type PingResult struct {
+	Sent     int
+	Received int
+	AvgRTT   time.Duration
+}
+
+// Loss returns the fraction of echo requests without reply
+func (r PingResult) Loss() float64 {
+	if r.Sent == 0 {
+		return 1
+	}
+	return float64(r.Sent-r.Received) / float64(r.Sent)
+}
+
+// Ping sends count ICMP echo requests to an IPv4 host, waiting up to timeout
+// for each reply. Unprivileged mode uses datagram ICMP sockets, which on Linux
+// require the process group to be allowed by net.ipv4.ping_group_range.
+func Ping(ctx context.Context, host string, count int, timeout time.Duration, privileged bool) (PingResult, error) {
+	var result PingResult
+
+	ip, err := net.ResolveIPAddr("ip4", host)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+
+	network, dst := "udp4", net.Addr(&net.UDPAddr{IP: ip.IP})
+	if privileged {
+		network, dst = "ip4:icmp", ip
+	}
+	conn, err := icmp.ListenPacket(network, "0.0.0.0")
+	if err != nil {
+		return result, fmt.Errorf("failed to open icmp socket: %w", err)
+	}
+	defer conn.Close()
+
+	id := os.Getpid() & 0xffff
+	buf := make([]byte, 1500)
+	var total time.Duration
+	for seq := 0; seq < count; seq++ {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		msg := icmp.Message{
+			Type: ipv4.ICMPTypeEcho,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("storymonitor")},
+		}
+		payload, err := msg.Marshal(nil)
+		if err != nil {
+			return result, err
+		}
+
+		sentAt := time.Now()
+		if _, err := conn.WriteTo(payload, dst); err != nil {
+			return result, fmt.Errorf("failed to send echo request: %w", err)
+		}
+		result.Sent++
+
+		deadline := sentAt.Add(timeout)
+		conn.SetReadDeadline(deadline)
+		for time.Now().Before(deadline) {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				break
+			}
+			reply, err := icmp.ParseMessage(ipv4.ICMPTypeEcho.Protocol(), buf[:n])
+			if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+				continue
+			}
+			// The kernel rewrites the ID of unprivileged sockets, so match on sequence only there
+			echo, ok := reply.Body.(*icmp.Echo)
+			if !ok || echo.Seq != seq || (privileged && echo.ID != id) {
+				continue
+			}
+			result.Received++
+			total += time.Since(sentAt)
+			break
+		}
+	}
+
+	if result.Received > 0 {
+		result.AvgRTT = total / time.Duration(result.Received)
+	}
+	return result, nil
+}
+
+// PingHost returns the configured ping host, or the host of the first non-empty endpoint URL
+func PingHost(c *conf.Ping, endpointURLs ...string) string {
+	if c.Host != "" {
+		return c.Host
+	}
+	for _, rawURL := range endpointURLs {
+		if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	return ""
+}
+
+// PingCheck periodically pings the host and exports RTT and packet loss, so network
+// degradation can be told apart from node slowness. It blocks until ctx is done.
+func (b *BaseChecker) PingCheck(ctx context.Context, c *conf.Ping, host string) {
+	count := c.Count
+	if count <= 0 {
+		count = 3
+	}
+	timeout := time.Duration(c.TimeoutSecond) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	if !WaitForPhaseOffset(ctx, c.CheckSecond, 30) {
+		return
+	}
+
+	ticker := CheckSecondToTicker(c.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := b.StartLoop("ping")
+	defer loop.Stop()
+
+	for {
+		b.HealthCheckOperation("ping", func() error {
+			result, err := Ping(ctx, host, count, timeout, c.Privileged)
+			if err != nil {
+				glog.Errorf("[PingCheck] Node %s ping %s fail: %v", b.HostName, host, err)
+				return err
+			}
+			PingPacketLoss.WithLabelValues(b.AddLabelValues()...).Set(result.Loss())
+			if result.Received == 0 {
+				glog.Warningf("[PingCheck] Node %s no echo reply from %s", b.HostName, host)
+				return fmt.Errorf("no echo reply from %s", host)
+			}
+			PingRTT.WithLabelValues(b.AddLabelValues()...).Set(float64(result.AvgRTT.Microseconds()) / 1000)
+			return nil
+		})
+
+		if !loop.Wait(ctx, ticker) {
+			glog.V(5).Info("[PingCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package base
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// globalProxy is the proxy of outbound requests without a proxy of their own
+var globalProxy atomic.Pointer[url.URL]
+
+// ParseProxy parses an HTTP, HTTPS or SOCKS5 proxy URL
+func ParseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: scheme must be http, https or socks5", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: host is required", raw)
+	}
+	return u, nil
+}
+
+// SetProxy sets the proxy of outbound requests, an empty proxy falls back to
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+func SetProxy(raw string) error {
+	if raw == "" {
+		globalProxy.Store(nil)
+		return nil
+	}
+	u, err := ParseProxy(raw)
+	if err != nil {
+		return err
+	}
+	globalProxy.Store(u)
+	return nil
+}
+
+// ProxyFunc returns the proxy selection of a target, its own proxy takes
+// precedence over the global proxy. The config has been validated at startup.
+func ProxyFunc(target string) func(*http.Request) (*url.URL, error) {
+	if target != "" {
+		u, err := ParseProxy(target)
+		return func(*http.Request) (*url.URL, error) { return u, err }
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if u := globalProxy.Load(); u != nil {
+			return u, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+}
+
+// NewProxyTransport returns a copy of http.DefaultTransport using the proxy of target
+func NewProxyTransport(target string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = ProxyFunc(target)
+	return transport
+}
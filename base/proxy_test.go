@@ -0,0 +1,29 @@
+package base
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProxyFunc(t *testing.T) {
+	for _, raw := range []string{"ftp://proxy:21", "http://", "://bad"} {
+		if _, err := ParseProxy(raw); err == nil {
+			t.Errorf("ParseProxy(%q) succeeded, want error", raw)
+		}
+	}
+
+	if err := SetProxy("socks5://global:1080"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetProxy("")
+
+	req, _ := http.NewRequest(http.MethodGet, "https://rpc.example.com", nil)
+	u, err := ProxyFunc("")(req)
+	if err != nil || u.String() != "socks5://global:1080" {
+		t.Errorf("global proxy = %v, %v", u, err)
+	}
+	u, err = ProxyFunc("http://target:3128")(req)
+	if err != nil || u.String() != "http://target:3128" {
+		t.Errorf("target proxy = %v, %v", u, err)
+	}
+}
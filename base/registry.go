@@ -0,0 +1,136 @@
+package base
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// collectors are the metrics of the checkers, added by the init functions of
+// the files declaring them
+var collectors []prometheus.Collector
+
+var (
+	// rebuilds recreate the metrics declared with the constructors below
+	// under another namespace, keyed by collector
+	rebuilds = make(map[prometheus.Collector]func(namespace string))
+	// metricsNamespace is the namespace the metrics are currently named in
+	metricsNamespace = DefaultNamespace
+)
+
+func addCollectors(cs ...prometheus.Collector) {
+	collectors = append(collectors, cs...)
+}
+
+// RegisterMetrics registers the metrics of the checkers with registerer, their
+// names prefixed with namespace, empty for DefaultNamespace. Nothing is
+// registered with the default registry implicitly, so a binary embedding the
+// checkers can choose the registry exposing them. It must be called before
+// the checkers start.
+func RegisterMetrics(registerer prometheus.Registerer, namespace string) error {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	if namespace != metricsNamespace {
+		metricsNamespace = namespace
+		for _, c := range collectors {
+			if rebuild, ok := rebuilds[c]; ok {
+				rebuild(namespace)
+			}
+		}
+	}
+	for _, c := range collectors {
+		if err := registerer.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteNodeMetrics drops the series of the node chainName/hostName from all
+// checker metrics labelled by node, so a node no longer monitored does not
+// keep reporting its last values. It returns the number of deleted series.
+func DeleteNodeMetrics(chainName, hostName string) int {
+	labels := prometheus.Labels{"chain_name": chainName, "hostname": hostName}
+	deleted := 0
+	for _, c := range collectors {
+		if vec, ok := c.(interface {
+			DeletePartialMatch(prometheus.Labels) int
+		}); ok {
+			deleted += vec.DeletePartialMatch(labels)
+		}
+	}
+	return deleted
+}
+
+// newGaugeVec creates a gauge vector named in the default namespace. The
+// vectors are replaced in place, so the variables holding them stay valid.
+func newGaugeVec(opts prometheus.GaugeOpts, labelNames []string) *prometheus.GaugeVec {
+	opts.Namespace = DefaultNamespace
+	vec := prometheus.NewGaugeVec(opts, labelNames)
+	rebuilds[vec] = func(namespace string) {
+		opts.Namespace = namespace
+		*vec = *prometheus.NewGaugeVec(opts, labelNames)
+	}
+	return vec
+}
+
+// newCounterVec creates a counter vector named in the default namespace
+func newCounterVec(opts prometheus.CounterOpts, labelNames []string) *prometheus.CounterVec {
+	opts.Namespace = DefaultNamespace
+	vec := prometheus.NewCounterVec(opts, labelNames)
+	rebuilds[vec] = func(namespace string) {
+		opts.Namespace = namespace
+		*vec = *prometheus.NewCounterVec(opts, labelNames)
+	}
+	return vec
+}
+
+// newHistogramVec creates a histogram vector named in the default namespace
+func newHistogramVec(opts prometheus.HistogramOpts, labelNames []string) *prometheus.HistogramVec {
+	opts.Namespace = DefaultNamespace
+	vec := prometheus.NewHistogramVec(opts, labelNames)
+	rebuilds[vec] = func(namespace string) {
+		opts.Namespace = namespace
+		*vec = *prometheus.NewHistogramVec(opts, labelNames)
+	}
+	return vec
+}
+
+// gauge, histogram and counterFunc hold a metric without labels, which
+// unlike the vectors is an interface and is replaced by swapping the holder's
+type gauge struct{ prometheus.Gauge }
+
+type histogram struct{ prometheus.Histogram }
+
+type counterFunc struct{ prometheus.CounterFunc }
+
+// newGauge creates a gauge named in the default namespace
+func newGauge(opts prometheus.GaugeOpts) *gauge {
+	opts.Namespace = DefaultNamespace
+	g := &gauge{prometheus.NewGauge(opts)}
+	rebuilds[g] = func(namespace string) {
+		opts.Namespace = namespace
+		g.Gauge = prometheus.NewGauge(opts)
+	}
+	return g
+}
+
+// newHistogram creates a histogram named in the default namespace
+func newHistogram(opts prometheus.HistogramOpts) *histogram {
+	opts.Namespace = DefaultNamespace
+	h := &histogram{prometheus.NewHistogram(opts)}
+	rebuilds[h] = func(namespace string) {
+		opts.Namespace = namespace
+		h.Histogram = prometheus.NewHistogram(opts)
+	}
+	return h
+}
+
+// newCounterFunc creates a counter reading its value from function, named in
+// the default namespace
+func newCounterFunc(opts prometheus.CounterOpts, function func() float64) *counterFunc {
+	opts.Namespace = DefaultNamespace
+	c := &counterFunc{prometheus.NewCounterFunc(opts, function)}
+	rebuilds[c] = func(namespace string) {
+		opts.Namespace = namespace
+		c.CounterFunc = prometheus.NewCounterFunc(opts, function)
+	}
+	return c
+}
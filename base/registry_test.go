@@ -0,0 +1,68 @@
+package base
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if err := RegisterMetrics(registry, ""); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+	if err := RegisterMetrics(registry, ""); err == nil {
+		t.Fatal("registering the metrics twice succeeded")
+	}
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+}
+
+func TestRegisterMetricsNamespace(t *testing.T) {
+	t.Cleanup(func() { RegisterMetrics(prometheus.NewRegistry(), "") })
+	registry := prometheus.NewRegistry()
+	if err := RegisterMetrics(registry, "chain_node"); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+	b := &BaseChecker{ChainName: "story", HostName: "namespaced-node"}
+	b.RecordHealthStatus("http", true)
+	HALeader.Set(1)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	names := make(map[string]bool, len(families))
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), "chain_node_") {
+			t.Errorf("metric %s not in the namespace", family.GetName())
+		}
+		names[family.GetName()] = true
+	}
+	for _, name := range []string{"chain_node_health_status", "chain_node_ha_leader"} {
+		if !names[name] {
+			t.Errorf("metric %s not exported", name)
+		}
+	}
+}
+
+func TestDeleteNodeMetrics(t *testing.T) {
+	removed := &BaseChecker{ChainName: "story", HostName: "removed-node"}
+	kept := &BaseChecker{ChainName: "story", HostName: "kept-node"}
+	for _, b := range []*BaseChecker{removed, kept} {
+		b.RecordHealthStatus("http", true)
+		b.RecordResponseTime("http", 10)
+	}
+
+	if n := DeleteNodeMetrics("story", "removed-node"); n == 0 {
+		t.Fatal("no series deleted")
+	}
+	if NodeHealthStatus.DeleteLabelValues(removed.AddLabelValues("http")...) {
+		t.Error("health status of the removed node still exported")
+	}
+	if !NodeHealthStatus.DeleteLabelValues(kept.AddLabelValues("http")...) {
+		t.Error("health status of another node deleted")
+	}
+}
@@ -0,0 +1,73 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results of a health check operation
+const (
+	CheckResultSuccess           = "success"
+	CheckResultError             = "error"
+	CheckResultUnhealthyResponse = "unhealthy_response"
+)
+
+// HealthCheckResults counts health check operations by result, telling a
+// failing call apart from a node that answers with a wrong value
+var HealthCheckResults = newCounterVec(prometheus.CounterOpts{
+	Name: "health_check_results_total",
+	Help: "Health check operations by result (success, error, unhealthy_response)",
+}, append(labels, "endpoint_type", "result"))
+
+func init() {
+	addCollectors(
+		HealthCheckResults,
+	)
+}
+
+// UnhealthyResponseError is returned by a health check operation whose call
+// succeeded but whose response failed validation
+type UnhealthyResponseError struct {
+	Reason string
+}
+
+func (e *UnhealthyResponseError) Error() string {
+	return "unhealthy response: " + e.Reason
+}
+
+// UnhealthyResponse returns an UnhealthyResponseError with a formatted reason
+func UnhealthyResponse(format string, args ...interface{}) error {
+	return &UnhealthyResponseError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// CheckResult classifies the error of a health check operation
+func CheckResult(err error) string {
+	var unhealthy *UnhealthyResponseError
+	switch {
+	case err == nil:
+		return CheckResultSuccess
+	case errors.As(err, &unhealthy):
+		return CheckResultUnhealthyResponse
+	default:
+		return CheckResultError
+	}
+}
+
+// Monotonic validates that a value reported by a node never decreases, such as
+// the latest block number. A decrease is reported once, the lower value then
+// becomes the new reference.
+type Monotonic struct {
+	last atomic.Uint64
+}
+
+// Check records value and returns an UnhealthyResponseError if it is lower
+// than the previous value
+func (m *Monotonic) Check(name string, value uint64) error {
+	if previous := m.last.Swap(value); value < previous {
+		return UnhealthyResponse("%s went backwards from %d to %d", name, previous, value)
+	}
+	return nil
+}
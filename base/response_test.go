@@ -0,0 +1,39 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCheckResult(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{nil, CheckResultSuccess},
+		{errors.New("connection refused"), CheckResultError},
+		{UnhealthyResponse("network %s", "other"), CheckResultUnhealthyResponse},
+		{fmt.Errorf("status: %w", UnhealthyResponse("stale")), CheckResultUnhealthyResponse},
+	}
+	for _, c := range cases {
+		if got := CheckResult(c.err); got != c.want {
+			t.Errorf("CheckResult(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	var m Monotonic
+	for _, v := range []uint64{10, 10, 12} {
+		if err := m.Check("block number", v); err != nil {
+			t.Fatalf("Check(%d) = %v, want nil", v, err)
+		}
+	}
+	if err := m.Check("block number", 11); CheckResult(err) != CheckResultUnhealthyResponse {
+		t.Fatalf("Check(11) = %v, want an unhealthy response", err)
+	}
+	if err := m.Check("block number", 11); err != nil {
+		t.Fatalf("Check(11) after the decrease = %v, want nil", err)
+	}
+}
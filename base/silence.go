@@ -0,0 +1,125 @@
+package base
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Silence suppresses alerting for the nodes it matches until it ends. An empty
+// ChainName or HostName matches any value.
+type Silence struct {
+	ID        string    `json:"id"`
+	ChainName string    `json:"chain_name,omitempty"`
+	HostName  string    `json:"hostname,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	// Metrics also treats the silenced nodes as in maintenance, so the time
+	// does not count against availability
+	Metrics bool `json:"metrics"`
+}
+
+func (s *Silence) matches(chainName, hostName string, now time.Time) bool {
+	return (s.ChainName == "" || s.ChainName == chainName) &&
+		(s.HostName == "" || s.HostName == hostName) &&
+		now.Before(s.EndsAt)
+}
+
+var (
+	silenceMu     sync.RWMutex
+	silences      = make(map[string]*Silence)
+	nextSilenceID int
+)
+
+// AddSilence registers a silence and returns it with its assigned ID
+func AddSilence(s Silence) (Silence, error) {
+	if s.ChainName == "" && s.HostName == "" {
+		return Silence{}, fmt.Errorf("chain_name or hostname is required")
+	}
+	if s.StartsAt.IsZero() {
+		s.StartsAt = time.Now()
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return Silence{}, fmt.Errorf("silence must end after it starts")
+	}
+
+	silenceMu.Lock()
+	defer silenceMu.Unlock()
+	nextSilenceID++
+	s.ID = fmt.Sprintf("%d", nextSilenceID)
+	silences[s.ID] = &s
+	return s, nil
+}
+
+// RestoreSilences registers silences saved before a restart with their IDs,
+// skipping those that ended since
+func RestoreSilences(restored []Silence) {
+	now := time.Now()
+
+	silenceMu.Lock()
+	defer silenceMu.Unlock()
+	for _, s := range restored {
+		if id, err := strconv.Atoi(s.ID); err == nil && id > nextSilenceID {
+			nextSilenceID = id
+		}
+		if now.Before(s.EndsAt) {
+			silences[s.ID] = &s
+		}
+	}
+}
+
+// ExpireSilence ends a silence before its end time
+func ExpireSilence(id string) bool {
+	silenceMu.Lock()
+	defer silenceMu.Unlock()
+	_, ok := silences[id]
+	delete(silences, id)
+	return ok
+}
+
+// Silences returns the active silences by start time, expired silences are dropped
+func Silences() []Silence {
+	now := time.Now()
+
+	silenceMu.Lock()
+	defer silenceMu.Unlock()
+
+	result := make([]Silence, 0, len(silences))
+	for id, s := range silences {
+		if !now.Before(s.EndsAt) {
+			delete(silences, id)
+			continue
+		}
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartsAt.Before(result[j].StartsAt)
+	})
+	return result
+}
+
+// silenced reports whether alerting, and with metrics also the availability
+// metrics, of a node are silenced
+func silenced(chainName, hostName string, now time.Time, metrics bool) bool {
+	silenceMu.RLock()
+	defer silenceMu.RUnlock()
+
+	for _, s := range silences {
+		if now.Before(s.StartsAt) || !s.matches(chainName, hostName, now) {
+			continue
+		}
+		if !metrics || s.Metrics {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSilenced reports whether alerting of a node is silenced
+func IsSilenced(chainName, hostName string) bool {
+	return silenced(chainName, hostName, time.Now(), false)
+}
@@ -0,0 +1,24 @@
+package base
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// SLOBurnRate tracks how fast a node consumes the error budget of an SLO
+	SLOBurnRate = newGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_burn_rate",
+		Help: "Error rate of the checks of an SLO over the window divided by the error rate the objective allows, 1 consumes the budget exactly",
+	}, append(labels, "slo", "window"))
+
+	// SLOObjective exports the objective of an SLO
+	SLOObjective = newGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_objective_ratio",
+		Help: "Objective of an SLO as the ratio of good checks (0-1)",
+	}, append(labels, "slo"))
+)
+
+func init() {
+	addCollectors(
+		SLOBurnRate,
+		SLOObjective,
+	)
+}
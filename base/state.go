@@ -0,0 +1,125 @@
+package base
+
+import (
+	"sync"
+	"time"
+
+	"storymonitor/events"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Checker states
+const (
+	StateConnecting = "connecting"
+	StateSubscribed = "subscribed"
+	StateDegraded   = "degraded"
+	StateDown       = "down"
+
+	// StateMaintenance is accounted instead of the current state during
+	// planned maintenance, so it does not count against availability
+	StateMaintenance = "maintenance"
+)
+
+var (
+	// CheckerStateSeconds accumulates the time each checker spends in each state
+	CheckerStateSeconds = newCounterVec(prometheus.CounterOpts{
+		Name: "checker_state_seconds_total",
+		Help: "Cumulative seconds the checker spent in each state (connecting, subscribed, degraded, down, maintenance)",
+	}, append(labels, "state"))
+
+	// CheckerLifecycleState is the lifecycle state of each target as seen by the controller
+	CheckerLifecycleState = newGaugeVec(prometheus.GaugeOpts{
+		Name: "checker_lifecycle_state",
+		Help: "Current lifecycle state of the checker (starting, running, reconnecting, failed, stopped), 1 for the current state",
+	}, append(labels, "kind", "state"))
+
+	// MaintenanceActive indicates whether a target is in a planned maintenance window
+	MaintenanceActive = newGaugeVec(prometheus.GaugeOpts{
+		Name: "maintenance",
+		Help: "Whether the target is in a planned maintenance window or silenced including metrics (1=maintenance)",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		CheckerStateSeconds,
+		CheckerLifecycleState,
+		MaintenanceActive,
+	)
+}
+
+// checkerState is the current state of a checker and when it was last accounted
+type checkerState struct {
+	mu        sync.Mutex
+	state     string
+	accounted time.Time
+}
+
+// flush adds the time since the last accounting to the current state, it must be called with the lock held
+func (s *checkerState) flush(b *BaseChecker, now time.Time) {
+	if s.state != "" {
+		state := s.state
+		if b.inMaintenance(now) {
+			state = StateMaintenance
+		}
+		CheckerStateSeconds.WithLabelValues(b.AddLabelValues(state)...).Add(now.Sub(s.accounted).Seconds())
+	}
+	s.accounted = now
+}
+
+// SetState moves the checker to a new state, accounting the time spent in the previous one
+func (b *BaseChecker) SetState(state string) {
+	b.state.mu.Lock()
+	defer b.state.mu.Unlock()
+
+	if b.state.state == state {
+		return
+	}
+	now := time.Now()
+	b.state.flush(b, now)
+	previous := b.state.state
+	b.state.state = state
+
+	b.publish(events.Event{
+		Kind:          events.KindState,
+		Time:          now,
+		ChainName:     b.ChainName,
+		HostName:      b.HostName,
+		FailureDomain: b.FailureDomain,
+		State:         state,
+		PreviousState: previous,
+	})
+}
+
+// GetState returns the current checker state
+func (b *BaseChecker) GetState() string {
+	b.state.mu.Lock()
+	defer b.state.mu.Unlock()
+	return b.state.state
+}
+
+// FlushStateDuration accounts the time spent in the current state so far
+func (b *BaseChecker) FlushStateDuration() {
+	b.state.mu.Lock()
+	defer b.state.mu.Unlock()
+
+	now := time.Now()
+	b.state.flush(b, now)
+	value := float64(0)
+	if b.inMaintenance(now) {
+		value = 1
+	}
+	MaintenanceActive.WithLabelValues(b.AddLabelValues()...).Set(value)
+}
+
+// inMaintenance reports whether the checker is in a planned maintenance window
+// or covered by a silence that includes metrics
+func (b *BaseChecker) inMaintenance(now time.Time) bool {
+	return b.Maintenance.Active(now) || silenced(b.ChainName, b.HostName, now, true)
+}
+
+// InMaintenance reports whether the checker is in maintenance at the moment
+func (b *BaseChecker) InMaintenance() bool {
+	return b.inMaintenance(time.Now())
+}
@@ -0,0 +1,24 @@
+package base
+
+import "time"
+
+// NodeStatus is the latest status reported by a node, cached by its checker
+type NodeStatus struct {
+	Moniker             string
+	Network             string
+	Version             string
+	LatestBlockHash     string
+	LatestAppHash       string
+	LatestBlockHeight   int64
+	LatestBlockTime     time.Time
+	EarliestBlockHeight int64
+	CatchingUp          bool
+	ValidatorAddress    string
+	VotingPower         int64
+	UpdatedAt           time.Time
+}
+
+// StatusProvider is implemented by checkers that cache their node's status
+type StatusProvider interface {
+	NodeStatus() (NodeStatus, bool)
+}
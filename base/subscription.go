@@ -0,0 +1,55 @@
+package base
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Subscriptions of the checkers, exported as the subscription label
+const (
+	SubscriptionHeads    = "heads"
+	SubscriptionLogs     = "logs"
+	SubscriptionEvidence = "evidence"
+)
+
+var (
+	// ActiveSubscriptions indicates which websocket subscriptions of a target are established
+	ActiveSubscriptions = newGaugeVec(prometheus.GaugeOpts{
+		Name: "active_subscriptions",
+		Help: "Whether the websocket subscription of the target is established (1=active)",
+	}, append(labels, "subscription"))
+
+	// SubscriptionReconnects counts subscriptions established again after the first one
+	SubscriptionReconnects = newCounterVec(prometheus.CounterOpts{
+		Name: "subscription_reconnects_total",
+		Help: "Number of times the websocket subscription of the target was re-established",
+	}, append(labels, "subscription"))
+)
+
+func init() {
+	addCollectors(
+		ActiveSubscriptions,
+		SubscriptionReconnects,
+	)
+}
+
+// RecordSubscribed records an established subscription, every one after the
+// first counts as a reconnect
+func (b *BaseChecker) RecordSubscribed(subscription string) {
+	b.subscriptionMu.Lock()
+	if b.subscribed == nil {
+		b.subscribed = make(map[string]bool)
+	}
+	reconnect := b.subscribed[subscription]
+	b.subscribed[subscription] = true
+	b.subscriptionMu.Unlock()
+
+	ActiveSubscriptions.WithLabelValues(b.AddLabelValues(subscription)...).Set(1)
+	// Created on the first subscription so reconnects start from zero
+	reconnects := SubscriptionReconnects.WithLabelValues(b.AddLabelValues(subscription)...)
+	if reconnect {
+		reconnects.Inc()
+	}
+}
+
+// RecordUnsubscribed records a subscription that ended or failed
+func (b *BaseChecker) RecordUnsubscribed(subscription string) {
+	ActiveSubscriptions.WithLabelValues(b.AddLabelValues(subscription)...).Set(0)
+}
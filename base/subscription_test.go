@@ -0,0 +1,35 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordSubscribed(t *testing.T) {
+	b := &BaseChecker{ChainName: "story", HostName: "subscription-node"}
+	active := ActiveSubscriptions.WithLabelValues(b.AddLabelValues(SubscriptionHeads)...)
+	reconnects := SubscriptionReconnects.WithLabelValues(b.AddLabelValues(SubscriptionHeads)...)
+
+	b.RecordSubscribed(SubscriptionHeads)
+	if got := testutil.ToFloat64(active); got != 1 {
+		t.Errorf("active after subscribing = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(reconnects); got != 0 {
+		t.Errorf("reconnects after the first subscription = %v, want 0", got)
+	}
+
+	b.RecordUnsubscribed(SubscriptionHeads)
+	if got := testutil.ToFloat64(active); got != 0 {
+		t.Errorf("active after unsubscribing = %v, want 0", got)
+	}
+
+	b.RecordSubscribed(SubscriptionHeads)
+	b.RecordSubscribed(SubscriptionLogs)
+	if got := testutil.ToFloat64(reconnects); got != 1 {
+		t.Errorf("reconnects after resubscribing = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(SubscriptionReconnects.WithLabelValues(b.AddLabelValues(SubscriptionLogs)...)); got != 0 {
+		t.Errorf("reconnects of another subscription = %v, want 0", got)
+	}
+}
@@ -0,0 +1,31 @@
+package base
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// BlockDelayThreshold exports the configured block delay threshold of a node
+	BlockDelayThreshold = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_delay_threshold_seconds",
+		Help: "Configured maximum delay between a block's timestamp and its arrival in seconds",
+	}, labels)
+
+	// ResponseTimeThreshold exports the configured response time threshold of a node
+	ResponseTimeThreshold = newGaugeVec(prometheus.GaugeOpts{
+		Name: "response_time_threshold_milliseconds",
+		Help: "Configured maximum response time of the checks of a node in milliseconds",
+	}, labels)
+
+	// HeightLagThreshold exports the configured height lag threshold of a node
+	HeightLagThreshold = newGaugeVec(prometheus.GaugeOpts{
+		Name: "height_lag_threshold_blocks",
+		Help: "Configured maximum number of blocks a node may be behind the highest node of its chain",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		BlockDelayThreshold,
+		ResponseTimeThreshold,
+		HeightLagThreshold,
+	)
+}
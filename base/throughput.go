@@ -0,0 +1,130 @@
+package base
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// BlockTxs tracks the number of transactions in the latest block
+	BlockTxs = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_txs",
+		Help: "Number of transactions in the latest block",
+	}, labels)
+
+	// BlockTxsHistogram provides histogram of transactions per block
+	BlockTxsHistogram = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "block_txs_histogram",
+		Help:    "Histogram of the number of transactions per block",
+		Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500},
+	}, labels)
+
+	// BlockTPS tracks the rolling transactions per second over the recent blocks
+	BlockTPS = newGaugeVec(prometheus.GaugeOpts{
+		Name: "tps",
+		Help: "Transactions per second over the recent blocks, computed from block timestamps",
+	}, labels)
+
+	// BlockGasUsed tracks the gas used by the latest block
+	BlockGasUsed = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_gas_used",
+		Help: "Gas used by the latest block",
+	}, labels)
+
+	// BlockGasLimit tracks the gas limit of the latest block
+	BlockGasLimit = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_gas_limit",
+		Help: "Gas limit of the latest block",
+	}, labels)
+
+	// BlockGasUtilization tracks the gas used as a percentage of the gas limit
+	BlockGasUtilization = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_gas_utilization_percent",
+		Help: "Gas used by the latest block as a percentage of its gas limit",
+	}, labels)
+
+	// BlockSize tracks the size of the latest block
+	BlockSize = newGaugeVec(prometheus.GaugeOpts{
+		Name: "block_size_bytes",
+		Help: "Size of the latest block in bytes",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		BlockTxs,
+		BlockTxsHistogram,
+		BlockTPS,
+		BlockSize,
+		BlockGasUsed,
+		BlockGasLimit,
+		BlockGasUtilization,
+	)
+}
+
+type txSample struct {
+	blockTime time.Time
+	txs       int
+}
+
+// TxRate computes the transactions per second over the blocks within a window
+type TxRate struct {
+	window  time.Duration
+	samples []txSample
+}
+
+// NewTxRate returns a TxRate over window
+func NewTxRate(window time.Duration) *TxRate {
+	return &TxRate{window: window}
+}
+
+// Add records the transactions of a block and returns the rate over the
+// window. The transactions of the oldest block are not counted, as the
+// window starts at its timestamp. Until two blocks are seen the rate is 0.
+func (r *TxRate) Add(blockTime time.Time, txs int) float64 {
+	r.samples = append(r.samples, txSample{blockTime: blockTime, txs: txs})
+
+	kept := r.samples[:0]
+	for _, s := range r.samples {
+		if blockTime.Sub(s.blockTime) <= r.window {
+			kept = append(kept, s)
+		}
+	}
+	r.samples = kept
+
+	span := blockTime.Sub(r.samples[0].blockTime).Seconds()
+	if span <= 0 {
+		return 0
+	}
+	total := 0
+	for _, s := range r.samples[1:] {
+		total += s.txs
+	}
+	return float64(total) / span
+}
+
+// RecordBlockTxs records the number of transactions of a new block
+func (b *BaseChecker) RecordBlockTxs(txs int) {
+	BlockTxs.WithLabelValues(b.AddLabelValues()...).Set(float64(txs))
+	BlockTxsHistogram.WithLabelValues(b.AddLabelValues()...).Observe(float64(txs))
+}
+
+// RecordTPS records the rolling transactions per second
+func (b *BaseChecker) RecordTPS(tps float64) {
+	BlockTPS.WithLabelValues(b.AddLabelValues()...).Set(tps)
+}
+
+// RecordBlockGas records the gas used and gas limit of a new block
+func (b *BaseChecker) RecordBlockGas(gasUsed, gasLimit uint64) {
+	BlockGasUsed.WithLabelValues(b.AddLabelValues()...).Set(float64(gasUsed))
+	BlockGasLimit.WithLabelValues(b.AddLabelValues()...).Set(float64(gasLimit))
+	if gasLimit > 0 {
+		BlockGasUtilization.WithLabelValues(b.AddLabelValues()...).Set(float64(gasUsed) / float64(gasLimit) * 100)
+	}
+}
+
+// RecordBlockSize records the size of a new block in bytes
+func (b *BaseChecker) RecordBlockSize(size int) {
+	BlockSize.WithLabelValues(b.AddLabelValues()...).Set(float64(size))
+}
@@ -0,0 +1,28 @@
+package base
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTxRate(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	r := NewTxRate(10 * time.Second)
+
+	if got := r.Add(start, 100); got != 0 {
+		t.Fatalf("first block rate = %v, want 0", got)
+	}
+	if got := r.Add(start.Add(2*time.Second), 10); got != 5 {
+		t.Fatalf("rate = %v, want 5", got)
+	}
+	if got := r.Add(start.Add(4*time.Second), 30); got != 10 {
+		t.Fatalf("rate = %v, want 10", got)
+	}
+	// The first two blocks fall out of the window
+	if got := r.Add(start.Add(14*time.Second), 0); got != 0 {
+		t.Fatalf("rate = %v, want 0", got)
+	}
+	if got := r.Add(start.Add(16*time.Second), 0); got != 0 {
+		t.Fatalf("rate after idle blocks = %v, want 0", got)
+	}
+}
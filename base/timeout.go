@@ -0,0 +1,45 @@
+package base
+
+import (
+	"time"
+
+	"storymonitor/conf"
+)
+
+// Default timeouts of checker operations
+const (
+	DefaultConnectTimeout   = 10 * time.Second
+	DefaultCallTimeout      = 30 * time.Second
+	DefaultHandshakeTimeout = 12 * time.Second
+)
+
+func timeoutOrDefault(second int, defaultTimeout time.Duration) time.Duration {
+	if second <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(second) * time.Second
+}
+
+// ConnectTimeout bounds dialing an endpoint and the calls identifying the node
+func ConnectTimeout(t *conf.Timeouts) time.Duration {
+	if t == nil {
+		return DefaultConnectTimeout
+	}
+	return timeoutOrDefault(t.ConnectSecond, DefaultConnectTimeout)
+}
+
+// CallTimeout bounds a single check
+func CallTimeout(t *conf.Timeouts) time.Duration {
+	if t == nil {
+		return DefaultCallTimeout
+	}
+	return timeoutOrDefault(t.CallSecond, DefaultCallTimeout)
+}
+
+// HandshakeTimeout bounds the websocket handshake and subscription requests
+func HandshakeTimeout(t *conf.Timeouts) time.Duration {
+	if t == nil {
+		return DefaultHandshakeTimeout
+	}
+	return timeoutOrDefault(t.HandshakeSecond, DefaultHandshakeTimeout)
+}
@@ -0,0 +1,29 @@
+package base
+
+import (
+	"testing"
+
+	"storymonitor/conf"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig, err := NewTLSConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.InsecureSkipVerify {
+		t.Error("expected certificates to be verified by default")
+	}
+
+	tlsConfig, err = NewTLSConfig(&conf.TLS{ServerName: "rpc.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ServerName != "rpc.internal" || tlsConfig.InsecureSkipVerify {
+		t.Errorf("unexpected config server_name=%q insecure=%v", tlsConfig.ServerName, tlsConfig.InsecureSkipVerify)
+	}
+
+	if _, err := NewTLSConfig(&conf.TLS{CertFile: "client.pem"}); err == nil {
+		t.Error("expected an error for cert_file without key_file")
+	}
+}
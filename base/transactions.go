@@ -0,0 +1,69 @@
+package base
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// PriorityFee tracks the priority fee percentiles of recent blocks reported by eth_feeHistory
+	PriorityFee = newGaugeVec(prometheus.GaugeOpts{
+		Name: "priority_fee_gwei",
+		Help: "Average priority fee per gas of recent blocks at the given reward percentile in gwei, from eth_feeHistory",
+	}, append(labels, "percentile"))
+
+	// BaseFee tracks the base fee of the next block reported by eth_feeHistory
+	BaseFee = newGaugeVec(prometheus.GaugeOpts{
+		Name: "base_fee_gwei",
+		Help: "Base fee per gas of the next block in gwei, from eth_feeHistory",
+	}, labels)
+
+	// NonceGap tracks the transactions of an address that are pending but not mined
+	NonceGap = newGaugeVec(prometheus.GaugeOpts{
+		Name: "nonce_gap",
+		Help: "Pending nonce minus latest nonce of watched addresses",
+	}, append(labels, "address"))
+
+	// NonceGapAge tracks how long the pending transactions of an address have not been mined
+	NonceGapAge = newGaugeVec(prometheus.GaugeOpts{
+		Name: "nonce_gap_age_seconds",
+		Help: "Seconds the nonce gap of watched addresses has existed without the latest nonce advancing",
+	}, append(labels, "address"))
+
+	// CanaryTransactions counts canary transactions by result
+	CanaryTransactions = newCounterVec(prometheus.CounterOpts{
+		Name: "canary_transactions_total",
+		Help: "Canary transactions submitted by result (success, failure)",
+	}, append(labels, "result"))
+
+	// CanaryInclusionLatency tracks the submit-to-inclusion latency of the last canary transaction
+	CanaryInclusionLatency = newGaugeVec(prometheus.GaugeOpts{
+		Name: "canary_inclusion_seconds",
+		Help: "Time from submitting the last canary transaction to its receipt in seconds",
+	}, labels)
+
+	// CanaryInclusionLatencyHistogram provides histogram of canary inclusion latencies
+	CanaryInclusionLatencyHistogram = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "canary_inclusion_histogram_seconds",
+		Help:    "Histogram of the time from submitting a canary transaction to its receipt in seconds",
+		Buckets: []float64{1, 2, 3, 5, 10, 15, 30, 60, 120},
+	}, labels)
+
+	// SuggestedPriorityFee tracks the priority fee suggested by eth_maxPriorityFeePerGas
+	SuggestedPriorityFee = newGaugeVec(prometheus.GaugeOpts{
+		Name: "suggested_priority_fee_gwei",
+		Help: "Priority fee per gas suggested by the node in gwei, from eth_maxPriorityFeePerGas",
+	}, labels)
+)
+
+func init() {
+	addCollectors(
+		PriorityFee,
+		BaseFee,
+		SuggestedPriorityFee,
+		NonceGap,
+		NonceGapAge,
+		CanaryTransactions,
+		CanaryInclusionLatency,
+		CanaryInclusionLatencyHistogram,
+	)
+}
@@ -0,0 +1,95 @@
+package base
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"storymonitor/events"
+)
+
+// HealthTransition is emitted when a check of a node changes between healthy and unhealthy
+type HealthTransition struct {
+	ChainName     string
+	HostName      string
+	FailureDomain string
+	Check         string
+	Healthy       bool
+	// Initial is set on the first observation of a check, which has no previous state
+	Initial bool
+	// Error is the error of the failed check, empty if unknown
+	Error string
+	Time  time.Time
+}
+
+type transitionKey struct {
+	chainName, hostName, check string
+}
+
+// transitionBuffer is the number of transitions a consumer may lag behind
+// before they queue, transitions are never dropped
+const transitionBuffer = 1024
+
+var (
+	transitionMu sync.Mutex
+	lastHealth   = make(map[transitionKey]bool)
+)
+
+// TransitionOf converts a transition event of the event bus
+func TransitionOf(e events.Event) HealthTransition {
+	return HealthTransition{
+		ChainName:     e.ChainName,
+		HostName:      e.HostName,
+		FailureDomain: e.FailureDomain,
+		Check:         e.Check,
+		Healthy:       e.Healthy,
+		Initial:       e.Initial,
+		Error:         e.Error,
+		Time:          e.Time,
+	}
+}
+
+// ConsumeTransitions calls handler with every health transition published on
+// the event bus, in a goroutine until ctx is done
+func ConsumeTransitions(ctx context.Context, name string, handler func(HealthTransition)) {
+	sub := events.Subscribe(name, transitionBuffer, events.KindTransition)
+	events.Consume(ctx, events.Default, sub, func(e events.Event) {
+		handler(TransitionOf(e))
+	})
+}
+
+// recordTransition emits a transition if the health of a check changed. The
+// first observation of a check only emits when it is unhealthy. Observations
+// during maintenance or a silence are ignored, so a node still failing
+// afterwards alerts and one that recovered resolves.
+func (b *BaseChecker) recordTransition(check string, healthy bool, err error) {
+	if b.InMaintenance() || IsSilenced(b.ChainName, b.HostName) {
+		return
+	}
+
+	key := transitionKey{b.ChainName, b.HostName, check}
+
+	transitionMu.Lock()
+	previous, known := lastHealth[key]
+	lastHealth[key] = healthy
+	transitionMu.Unlock()
+
+	if (known && previous == healthy) || (!known && healthy) {
+		return
+	}
+
+	e := events.Event{
+		Kind:          events.KindTransition,
+		Time:          time.Now(),
+		ChainName:     b.ChainName,
+		HostName:      b.HostName,
+		FailureDomain: b.FailureDomain,
+		Check:         check,
+		Healthy:       healthy,
+		Initial:       !known,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	b.publish(e)
+}
@@ -0,0 +1,44 @@
+package base
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"storymonitor/conf"
+)
+
+// DefaultKeepAlive is the TCP keep-alive interval of target connections
+const DefaultKeepAlive = 30 * time.Second
+
+// ApplyTransport applies the transport settings of a target to transport and
+// dials with connectTimeout. Unset settings keep the values of transport.
+func ApplyTransport(transport *http.Transport, c *conf.Transport, connectTimeout time.Duration) {
+	keepAlive := DefaultKeepAlive
+	if c != nil && c.KeepAliveSecond > 0 {
+		keepAlive = time.Duration(c.KeepAliveSecond) * time.Second
+	}
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: keepAlive}).DialContext
+	if c == nil {
+		return
+	}
+
+	if c.HTTP2 != nil && !*c.HTTP2 {
+		// A non-nil empty map keeps the transport from upgrading to HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	if c.IdleConnTimeoutSecond > 0 {
+		transport.IdleConnTimeout = time.Duration(c.IdleConnTimeoutSecond) * time.Second
+	}
+	if c.TLSHandshakeTimeoutSecond > 0 {
+		transport.TLSHandshakeTimeout = time.Duration(c.TLSHandshakeTimeoutSecond) * time.Second
+	}
+	if c.MaxIdleConns > 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+}
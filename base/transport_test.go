@@ -0,0 +1,29 @@
+package base
+
+import (
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+func TestApplyTransport(t *testing.T) {
+	transport := NewProxyTransport("")
+	ApplyTransport(transport, nil, time.Second)
+	if !transport.ForceAttemptHTTP2 || transport.TLSNextProto != nil || transport.MaxIdleConnsPerHost != 0 {
+		t.Errorf("expected the defaults without transport settings")
+	}
+
+	disabled := false
+	transport = NewProxyTransport("")
+	ApplyTransport(transport, &conf.Transport{HTTP2: &disabled, IdleConnTimeoutSecond: 30, TLSHandshakeTimeoutSecond: 20, MaxIdleConnsPerHost: 16}, time.Second)
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil || len(transport.TLSNextProto) != 0 {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+	if transport.IdleConnTimeout != 30*time.Second || transport.TLSHandshakeTimeout != 20*time.Second || transport.MaxIdleConnsPerHost != 16 {
+		t.Errorf("unexpected transport settings idle=%s handshake=%s per_host=%d", transport.IdleConnTimeout, transport.TLSHandshakeTimeout, transport.MaxIdleConnsPerHost)
+	}
+	if transport.MaxIdleConns != 100 {
+		t.Errorf("expected unset max_idle_conns to keep the default, got %d", transport.MaxIdleConns)
+	}
+}
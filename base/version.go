@@ -0,0 +1,143 @@
+package base
+
+import (
+	"regexp"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// NodeVersionInfo exposes the version reported by a node as a label
+	NodeVersionInfo = newGaugeVec(prometheus.GaugeOpts{
+		Name: "version_info",
+		Help: "Version reported by the node, the value is always 1",
+	}, append(labels, "version"))
+
+	// NodeVersionOutdated indicates whether a node runs an older version than the minimum expected
+	NodeVersionOutdated = newGaugeVec(prometheus.GaugeOpts{
+		Name: "version_outdated",
+		Help: "Whether the version reported by the node is older than the configured min_version (1=outdated)",
+	}, labels)
+
+	// NodeVersionChanges counts changes of the version reported by a node
+	NodeVersionChanges = newCounterVec(prometheus.CounterOpts{
+		Name: "version_changes_total",
+		Help: "Number of times the version reported by the node changed, by direction (upgrade, downgrade, change)",
+	}, append(labels, "direction"))
+)
+
+func init() {
+	addCollectors(
+		NodeVersionInfo,
+		NodeVersionChanges,
+		NodeVersionOutdated,
+	)
+}
+
+// minVersions are the oldest expected versions by chain name
+var minVersions atomic.Pointer[map[string]string]
+
+// SetMinVersions sets the oldest expected versions by chain name, used for
+// checkers without a min_version of their own
+func SetMinVersions(versions map[string]string) {
+	minVersions.Store(&versions)
+}
+
+// minVersion returns the oldest expected version of the checker, empty if none is configured
+func (b *BaseChecker) minVersion() string {
+	if b.MinVersion != "" {
+		return b.MinVersion
+	}
+	if versions := minVersions.Load(); versions != nil {
+		return (*versions)[b.ChainName]
+	}
+	return ""
+}
+
+// semverPattern finds the first semantic version in a version string, such
+// as v1.13.5 in Geth/v1.13.5-stable/linux-amd64/go1.21.4
+var semverPattern = regexp.MustCompile(`v?(\d+)\.(\d+)\.(\d+)`)
+
+// ParseVersion returns the major, minor and patch numbers of the first
+// semantic version in s
+func ParseVersion(s string) ([3]int, bool) {
+	var version [3]int
+	match := semverPattern.FindStringSubmatch(s)
+	if match == nil {
+		return version, false
+	}
+	for i := range version {
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return version, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
+
+// CompareVersions compares the semantic versions in a and b, it returns -1,
+// 0 or 1, and false if either has no semantic version
+func CompareVersions(a, b string) (int, bool) {
+	va, okA := ParseVersion(a)
+	vb, okB := ParseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1, true
+		case va[i] > vb[i]:
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// SetNodeVersion records the version reported by the node and whether it is
+// older than the minimum expected version. A change from a previously reported
+// version is logged and counted by direction.
+func (b *BaseChecker) SetNodeVersion(version string) {
+	if version == "" {
+		return
+	}
+
+	if minimum := b.minVersion(); minimum != "" {
+		outdated := float64(0)
+		if cmp, ok := CompareVersions(version, minimum); ok && cmp < 0 {
+			outdated = 1
+		}
+		NodeVersionOutdated.WithLabelValues(b.AddLabelValues()...).Set(outdated)
+	}
+
+	b.versionMu.Lock()
+	previous := b.reportedVersion
+	b.reportedVersion = version
+	b.NodeVersion = version
+	b.versionMu.Unlock()
+
+	if previous == version {
+		return
+	}
+	if previous != "" {
+		NodeVersionInfo.DeleteLabelValues(b.AddLabelValues(previous)...)
+
+		direction := "change"
+		if cmp, ok := CompareVersions(version, previous); ok && cmp > 0 {
+			direction = "upgrade"
+		} else if ok && cmp < 0 {
+			direction = "downgrade"
+		}
+		NodeVersionChanges.WithLabelValues(b.AddLabelValues(direction)...).Inc()
+		if direction == "downgrade" {
+			glog.Warningf("[SetNodeVersion] Node %s (%s) downgraded from %s to %s", b.HostName, b.ChainName, previous, version)
+		} else {
+			glog.Infof("[SetNodeVersion] Node %s (%s) version changed from %s to %s", b.HostName, b.ChainName, previous, version)
+		}
+	}
+	NodeVersionInfo.WithLabelValues(b.AddLabelValues(version)...).Set(1)
+}
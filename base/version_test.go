@@ -0,0 +1,23 @@
+package base
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+		ok   bool
+	}{
+		{"Geth/v1.13.5-stable/linux-amd64/go1.21.4", "Geth/v1.13.4-stable/linux-amd64/go1.21.4", 1, true},
+		{"0.38.2", "v0.38.10", -1, true},
+		{"v0.10.0", "0.10.0", 0, true},
+		{"v1.0.0", "2.0", 0, false},
+		{"unknown", "v1.0.0", 0, false},
+	}
+	for _, c := range cases {
+		got, ok := CompareVersions(c.a, c.b)
+		if got != c.want || ok != c.ok {
+			t.Errorf("CompareVersions(%q, %q) = %d, %v, want %d, %v", c.a, c.b, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package base
+
+import (
+	"crypto/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"storymonitor/conf"
+)
+
+// Default websocket keepalive of EVM targets
+const (
+	DefaultWsPingInterval = 10 * time.Second
+	DefaultWsPongTimeout  = 5 * time.Second
+)
+
+// WsKeepalive returns the ping interval and pong timeout of a target, false if
+// the keepalive is disabled
+func WsKeepalive(c *conf.WsKeepalive) (time.Duration, time.Duration, bool) {
+	if c == nil {
+		return DefaultWsPingInterval, DefaultWsPongTimeout, true
+	}
+	return timeoutOrDefault(c.PingIntervalSecond, DefaultWsPingInterval),
+		timeoutOrDefault(c.PongTimeoutSecond, DefaultWsPongTimeout),
+		!c.Disabled
+}
+
+// keepaliveConn sends websocket ping frames on a client connection that
+// received nothing for an interval, and closes the connection when the pong
+// or any other data does not arrive within the timeout. A half-open
+// connection then fails the reads of the websocket client at once instead of
+// waiting for the next failed call.
+//
+// The connection must carry the websocket protocol itself, not TLS or a proxy
+// handshake. Pings start after the first read, the handshake response, and
+// are written between the frames of the client, which writes every frame
+// with a single Write.
+type keepaliveConn struct {
+	net.Conn
+	interval  time.Duration
+	timeout   time.Duration
+	onTimeout func()
+
+	writeMu  sync.Mutex
+	lastRead atomic.Int64
+	upgraded atomic.Bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewKeepaliveConn wraps a websocket client connection with a keepalive,
+// onTimeout is called before a connection without answer is closed
+func NewKeepaliveConn(conn net.Conn, interval, timeout time.Duration, onTimeout func()) net.Conn {
+	c := &keepaliveConn{
+		Conn:      conn,
+		interval:  interval,
+		timeout:   timeout,
+		onTimeout: onTimeout,
+		done:      make(chan struct{}),
+	}
+	c.lastRead.Store(time.Now().UnixNano())
+	go c.run()
+	return c
+}
+
+func (c *keepaliveConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.lastRead.Store(time.Now().UnixNano())
+		c.upgraded.Store(true)
+	}
+	return n, err
+}
+
+func (c *keepaliveConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.Write(p)
+}
+
+func (c *keepaliveConn) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return c.Conn.Close()
+}
+
+func (c *keepaliveConn) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		// Recent data proves the connection alive without a ping
+		if !c.upgraded.Load() || time.Since(time.Unix(0, c.lastRead.Load())) < c.interval {
+			continue
+		}
+
+		sentAt := time.Now()
+		if err := c.ping(); err != nil {
+			c.Close()
+			return
+		}
+		select {
+		case <-c.done:
+			return
+		case <-time.After(c.timeout):
+		}
+		if c.lastRead.Load() < sentAt.UnixNano() {
+			if c.onTimeout != nil {
+				c.onTimeout()
+			}
+			c.Close()
+			return
+		}
+	}
+}
+
+// ping writes an empty ping frame, masked as required of clients
+func (c *keepaliveConn) ping() error {
+	frame := make([]byte, 6)
+	frame[0] = 0x89 // FIN and the ping opcode
+	frame[1] = 0x80 // Masked, no payload
+	if _, err := rand.Read(frame[2:]); err != nil {
+		return err
+	}
+	_, err := c.Write(frame)
+	return err
+}
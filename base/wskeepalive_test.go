@@ -0,0 +1,42 @@
+package base
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestKeepaliveConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	timedOut := make(chan struct{})
+	conn := NewKeepaliveConn(client, 20*time.Millisecond, 20*time.Millisecond, func() { close(timedOut) })
+	defer conn.Close()
+
+	// No pings before the handshake response was read
+	go server.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n\r\n"))
+	buf := make([]byte, 64)
+	if _, err := conn.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := make([]byte, 6)
+	if _, err := io.ReadFull(server, frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame[0] != 0x89 || frame[1] != 0x80 {
+		t.Fatalf("expected a masked empty ping frame, got %x", frame)
+	}
+
+	// Without an answer to the ping the connection is closed
+	select {
+	case <-timedOut:
+	case <-time.After(time.Second):
+		t.Fatal("expected the keepalive to time out")
+	}
+	if _, err := conn.Write([]byte{0}); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
@@ -0,0 +1,98 @@
+package cometbft
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+// abciState remembers the last observed app hash and the consensus height it was first seen at
+type abciState struct {
+	appHash       []byte
+	changedHeight int64
+	appVersion    string
+}
+
+// checkAbciInfo exports /abci_info and fails when consensus advanced StallBlocks
+// blocks without the application changing its app hash
+func (chain *CometbftCheckerImpl) checkAbciInfo(state *abciState) error {
+	if chain.client == nil {
+		return fmt.Errorf("client not available")
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	info, err := chain.client.ABCIInfo(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := chain.client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	consensusHeight := status.SyncInfo.LatestBlockHeight
+	resp := info.Response
+
+	appVersion := strconv.FormatUint(resp.AppVersion, 10)
+	if state.appVersion != "" && state.appVersion != appVersion {
+		glog.Warningf("[checkAbciInfo] Node %s app version changed from %s to %s",
+			chain.Cometbft.HostName, state.appVersion, appVersion)
+		base.AbciAppVersion.DeleteLabelValues(chain.AddLabelValues(state.appVersion)...)
+	}
+	state.appVersion = appVersion
+	base.AbciAppVersion.WithLabelValues(chain.AddLabelValues(appVersion)...).Set(float64(resp.AppVersion))
+	base.AbciLastBlockHeight.WithLabelValues(chain.AddLabelValues()...).Set(float64(resp.LastBlockHeight))
+
+	if !bytes.Equal(state.appHash, resp.LastBlockAppHash) {
+		if state.appHash != nil {
+			base.AbciAppHashChanges.WithLabelValues(chain.AddLabelValues()...).Inc()
+		}
+		state.appHash = resp.LastBlockAppHash
+		state.changedHeight = consensusHeight
+	}
+
+	staleBlocks := consensusHeight - state.changedHeight
+	base.AbciAppHashStaleBlocks.WithLabelValues(chain.AddLabelValues()...).Set(float64(staleBlocks))
+
+	stallBlocks := chain.AbciInfo.StallBlocks
+	if stallBlocks <= 0 {
+		stallBlocks = 10
+	}
+	if staleBlocks >= stallBlocks {
+		return fmt.Errorf("app hash unchanged for %d blocks (consensus height %d, app height %d)",
+			staleBlocks, consensusHeight, resp.LastBlockHeight)
+	}
+	return nil
+}
+
+func (chain *CometbftCheckerImpl) abciInfoCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.AbciInfo.CheckSecond, chain.CheckSecond) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.AbciInfo.CheckSecond, chain.CheckSecond)
+	defer ticker.Stop()
+	loop := chain.StartLoop("abci_info")
+	defer loop.Stop()
+
+	state := &abciState{}
+	for {
+		chain.HealthCheckOperation("abci_app", func() error {
+			err := chain.checkAbciInfo(state)
+			if err != nil {
+				glog.Errorf("[abciInfoCheck] Node %s abci check fail: %v", chain.Cometbft.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[abciInfoCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cometbft
+
+import (
+	"fmt"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+// checkArchive verifies the node retains blocks back to the configured height
+// and can serve the block right after its earliest retained height
+func (chain *CometbftCheckerImpl) checkArchive() error {
+	if chain.client == nil {
+		return fmt.Errorf("client not available")
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	status, err := chain.client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	earliest := status.SyncInfo.EarliestBlockHeight
+	chain.RecordRetainedBlocks(earliest, status.SyncInfo.LatestBlockHeight)
+
+	height := earliest + 1
+	if _, err := chain.client.Block(ctx, &height); err != nil {
+		return fmt.Errorf("block %d not available: %w", height, err)
+	}
+
+	required := int64(chain.Archive.BlockNumber)
+	if required == 0 {
+		required = 1
+	}
+	if earliest > required {
+		return fmt.Errorf("earliest retained height %d is after %d", earliest, required)
+	}
+	return nil
+}
+
+func (chain *CometbftCheckerImpl) archiveCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Archive.CheckSecond, 300) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Archive.CheckSecond, 300)
+	defer ticker.Stop()
+	loop := chain.StartLoop("archive")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("archive", func() error {
+			err := chain.checkArchive()
+			if err != nil {
+				glog.Errorf("[archiveCheck] Node %s history check fail: %v", chain.Cometbft.HostName, err)
+			}
+			chain.RecordArchiveAvailable(err == nil)
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[archiveCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -3,13 +3,19 @@ package cometbft
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"storymonitor/base"
 	"storymonitor/conf"
+	"storymonitor/maintenance"
 
 	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/golang/glog"
 )
@@ -21,6 +27,19 @@ type CometbftCheckerImpl struct {
 	ctx context.Context
 
 	client *rpchttp.HTTP
+
+	statusMu   sync.RWMutex
+	lastStatus *base.NodeStatus
+
+	txRate *base.TxRate
+
+	// httpURLs selects the URL among http_url and http_urls
+	httpURLs *base.Failover
+	// switchURL asks the subscription loop to fail over to another URL
+	switchURL atomic.Bool
+
+	// latestHeight validates that the height reported by /status never goes backwards
+	latestHeight base.Monotonic
 }
 
 func NewCometbftCheckerImpl(ctx context.Context, conf *conf.Cometbft) base.CheckerTrait {
@@ -33,9 +52,21 @@ func NewCometbftCheckerImpl(ctx context.Context, conf *conf.Cometbft) base.Check
 			ChainId:      conf.ChainId,
 			NodeVersion:  conf.NodeVersion,
 			ProtocolName: conf.ProtocolName,
+			DelaySource:  conf.DelaySource,
+
+			ExpectedChainId: conf.ChainId,
+			MinVersion:      conf.MinVersion,
+			FailureDomain:   conf.FailureDomain,
+			Maintenance:     maintenance.New(conf.Maintenance),
 		},
 	}
 
+	checker.initURLs()
+	base.RegisterTargetEndpoint("cometbft", "http", conf.ChainName, conf.HostName, checker.httpURLs.Active())
+	if conf.Staking != nil {
+		base.RegisterTargetEndpoint("cometbft", "staking_api", conf.ChainName, conf.HostName, conf.Staking.ApiURL)
+	}
+
 	// Set default values
 	if checker.CheckSecond == 0 {
 		checker.CheckSecond = 5
@@ -43,52 +74,160 @@ func NewCometbftCheckerImpl(ctx context.Context, conf *conf.Cometbft) base.Check
 	if checker.WsEndpoint == "" {
 		checker.WsEndpoint = "/websocket"
 	}
+	if checker.NewBlock {
+		checker.txRate = base.NewTxRate(60 * time.Second)
+	}
 
 	checker.updateClient()
 	return checker
 }
 
+// callContext bounds a check call by the call timeout of the target
+func (chain *CometbftCheckerImpl) callContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(chain.ctx, base.CallTimeout(chain.Timeouts))
+}
+
+// initURLs creates the URL selection from the config unless it exists, so
+// checkers created without NewCometbftCheckerImpl can connect too
+func (chain *CometbftCheckerImpl) initURLs() {
+	if chain.httpURLs == nil {
+		chain.httpURLs = base.NewFailover("http", append([]string{chain.Cometbft.HttpURL}, chain.Cometbft.HttpURLs...), chain.Cometbft.URLStrategy)
+	}
+}
+
 func (chain *CometbftCheckerImpl) updateClient() {
+	chain.initURLs()
+
+	// Try the URLs in the order of the strategy until a node answers /status
+	for _, url := range chain.httpURLs.Candidates() {
+		if chain.connect(url) {
+			return
+		}
+	}
+}
+
+// connect creates the client of url and fetches the node status through it
+func (chain *CometbftCheckerImpl) connect(url string) bool {
 	nodeName := chain.Cometbft.HostName
 
-	client, err := rpchttp.New(chain.HttpURL, chain.WsEndpoint)
+	httpClient, err := jsonrpcclient.DefaultHTTPClient(url)
 	if err != nil {
-		glog.Errorf("[updateClient] Node %s endpoint %s connect fail: %v", nodeName, chain.HttpURL, err)
+		glog.Errorf("[updateClient] Node %s endpoint %s connect fail: %v", nodeName, url, err)
 		chain.RecordConnectionAttempt("http", false)
-		return
+		chain.httpURLs.Record(&chain.BaseChecker, url, false)
+		return false
+	}
+	// The default client dials the node directly, http(s) endpoints use a
+	// transport that can go through a proxy instead
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		transport := base.NewProxyTransport(chain.Proxy)
+		base.ApplyTransport(transport, chain.Transport, base.ConnectTimeout(chain.Timeouts))
+		// Prevents GZIP-bomb DoS attacks like the default client
+		transport.DisableCompression = true
+		if transport.TLSClientConfig, err = base.NewTLSConfig(chain.TLS); err != nil {
+			glog.Errorf("[updateClient] Node %s tls config fail: %v", nodeName, err)
+			chain.RecordConnectionAttempt("http", false)
+			chain.httpURLs.Record(&chain.BaseChecker, url, false)
+			return false
+		}
+		chain.WatchCertExpiry("http", transport.TLSClientConfig)
+		httpClient = &http.Client{Transport: transport}
+	}
+	// The websocket client of CometBFT sends no custom headers, they only apply to RPC calls
+	httpClient.Transport = base.HeaderTransport(httpClient.Transport, base.TargetHeader(chain.Headers, chain.BearerToken, chain.BasicAuth))
+	client, err := rpchttp.NewWithClient(url, chain.WsEndpoint, base.LimitClient(httpClient))
+	if err != nil {
+		glog.Errorf("[updateClient] Node %s endpoint %s connect fail: %v", nodeName, url, err)
+		chain.RecordConnectionAttempt("http", false)
+		chain.httpURLs.Record(&chain.BaseChecker, url, false)
+		return false
 	}
 
 	chain.client = client
 
 	// Get node status and information
-	result, err := chain.client.Status(chain.ctx)
+	ctx, cancel := context.WithTimeout(chain.ctx, base.ConnectTimeout(chain.Timeouts))
+	defer cancel()
+	result, err := chain.client.Status(ctx)
 	if err != nil {
-		glog.Errorf("[updateClient] Node %s status check fail: %v", nodeName, chain.HttpURL, err)
+		glog.Errorf("[updateClient] Node %s endpoint %s status check fail: %v", nodeName, url, err)
 		chain.RecordConnectionAttempt("http", false)
-		return
+		chain.httpURLs.Record(&chain.BaseChecker, url, false)
+		return false
 	}
 
 	chain.RecordConnectionAttempt("http", true)
+	chain.httpURLs.Record(&chain.BaseChecker, url, true)
+	chain.cacheStatus(result)
 	chain.Cometbft.ChainId = result.NodeInfo.Network
 	chain.Cometbft.NodeVersion = result.NodeInfo.Version
 	chain.BaseChecker.ChainId = result.NodeInfo.Network
-	chain.BaseChecker.NodeVersion = result.NodeInfo.Version
+	chain.SetNodeVersion(result.NodeInfo.Version)
+	chain.CheckChainId(result.NodeInfo.Network)
 
 	glog.V(5).Infof("[updateClient] Node %s connected - Chain: %s, Version: %s",
 		nodeName, chain.Cometbft.ChainId, chain.Cometbft.NodeVersion)
+	return true
 }
 
 func (chain *CometbftCheckerImpl) checkStatus() {
 	chain.HealthCheckOperation("node_status", func() error {
-		_, err := chain.client.Status(chain.ctx)
+		ctx, cancel := chain.callContext()
+		defer cancel()
+		result, err := chain.client.Status(ctx)
 		if err != nil {
+			chain.SetState(base.StateDegraded)
+			if chain.httpURLs.Multiple() {
+				chain.switchURL.Store(true)
+			}
 			return err
 		}
 
-		return nil
+		chain.SetState(base.StateSubscribed)
+		chain.cacheStatus(result)
+		chain.CheckChainId(result.NodeInfo.Network)
+		if expected := chain.ExpectedChainId; expected != "" && result.NodeInfo.Network != expected {
+			return base.UnhealthyResponse("network %s, expected %s", result.NodeInfo.Network, expected)
+		}
+		return chain.latestHeight.Check("latest block height", uint64(result.SyncInfo.LatestBlockHeight))
 	})
 }
 
+func (chain *CometbftCheckerImpl) cacheStatus(result *ctypes.ResultStatus) {
+	status := &base.NodeStatus{
+		Moniker:             result.NodeInfo.Moniker,
+		Network:             result.NodeInfo.Network,
+		Version:             result.NodeInfo.Version,
+		LatestBlockHash:     result.SyncInfo.LatestBlockHash.String(),
+		LatestAppHash:       result.SyncInfo.LatestAppHash.String(),
+		LatestBlockHeight:   result.SyncInfo.LatestBlockHeight,
+		LatestBlockTime:     result.SyncInfo.LatestBlockTime,
+		EarliestBlockHeight: result.SyncInfo.EarliestBlockHeight,
+		CatchingUp:          result.SyncInfo.CatchingUp,
+		ValidatorAddress:    result.ValidatorInfo.Address.String(),
+		VotingPower:         result.ValidatorInfo.VotingPower,
+		UpdatedAt:           time.Now(),
+	}
+
+	chain.statusMu.Lock()
+	chain.lastStatus = status
+	chain.statusMu.Unlock()
+
+	chain.RecordRetainedBlocks(status.EarliestBlockHeight, status.LatestBlockHeight)
+	chain.Cometbft.NodeVersion = status.Version
+	chain.SetNodeVersion(status.Version)
+}
+
+// NodeStatus returns the last status fetched from the node
+func (chain *CometbftCheckerImpl) NodeStatus() (base.NodeStatus, bool) {
+	chain.statusMu.RLock()
+	defer chain.statusMu.RUnlock()
+	if chain.lastStatus == nil {
+		return base.NodeStatus{}, false
+	}
+	return *chain.lastStatus, true
+}
+
 func (chain *CometbftCheckerImpl) startAndSubscribe(subscriber string) (<-chan ctypes.ResultEvent, error) {
 	nodeName := chain.Cometbft.HostName
 
@@ -100,9 +239,15 @@ func (chain *CometbftCheckerImpl) startAndSubscribe(subscriber string) (<-chan c
 		return nil, fmt.Errorf("[startAndSubscribe] Node %s client start fail: %v", nodeName, err)
 	}
 
-	// Subscribe to new block header events
-	query := fmt.Sprintf("%s='%s'", tmtypes.EventTypeKey, tmtypes.EventNewBlockHeader)
-	eventCh, err := chain.client.Subscribe(chain.ctx, subscriber, query)
+	// Subscribe to new block header events, or full blocks when block contents are tracked
+	eventType := tmtypes.EventNewBlockHeader
+	if chain.NewBlock {
+		eventType = tmtypes.EventNewBlock
+	}
+	query := fmt.Sprintf("%s='%s'", tmtypes.EventTypeKey, eventType)
+	ctx, cancel := context.WithTimeout(chain.ctx, base.HandshakeTimeout(chain.Timeouts))
+	defer cancel()
+	eventCh, err := chain.client.Subscribe(ctx, subscriber, query)
 	if err != nil {
 		glog.Errorf("[startAndSubscribe] Node %s subscribe fail: %v", nodeName, err)
 		return nil, err
@@ -111,16 +256,31 @@ func (chain *CometbftCheckerImpl) startAndSubscribe(subscriber string) (<-chan c
 	return eventCh, nil
 }
 
+// handleHeader records a new head observed by the subscription
+func (chain *CometbftCheckerImpl) handleHeader(header tmtypes.Header) {
+	chain.UpdateLastBlockTime()
+	var hash [32]byte
+	copy(hash[:], header.Hash())
+	chain.RecordHead(uint64(header.Height), hash, header.Time)
+	delaySecond := chain.RecordBlockDelay(header.Time)
+	glog.V(5).Infof("[subscribe] %s Node BlockNumber %d Delay %.2f s",
+		chain.Cometbft.HostName, header.Height, delaySecond)
+	chain.checkStatus()
+}
+
 func (chain *CometbftCheckerImpl) subscribe() {
 	var (
 		subscriber = "subscriber"
 		nodeName   = chain.Cometbft.HostName
 		eventCh    <-chan ctypes.ResultEvent
+		evidenceCh <-chan ctypes.ResultEvent
 		err        error
 	)
 
 	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
 	defer ticker.Stop()
+	loop := chain.StartLoop("subscribe")
+	defer loop.Stop()
 	defer func() {
 		if chain.client != nil {
 			chain.client.UnsubscribeAll(chain.ctx, subscriber)
@@ -130,12 +290,25 @@ func (chain *CometbftCheckerImpl) subscribe() {
 	}()
 
 	ensureSubscription := func(chain *CometbftCheckerImpl) error {
-		// Initialize subscription
+		// Initialize subscription, replacing any previous one
+		chain.SetState(base.StateConnecting)
+		chain.RecordUnsubscribed(base.SubscriptionHeads)
+		chain.RecordUnsubscribed(base.SubscriptionEvidence)
 		eventCh, err = chain.startAndSubscribe(subscriber)
 		if err != nil {
 			glog.Errorf("[subscribe] Initial subscription failed for %s: %v", nodeName, err)
+			chain.SetState(base.StateDown)
 			return err
 		}
+		chain.SetState(base.StateSubscribed)
+		chain.RecordSubscribed(base.SubscriptionHeads)
+
+		// Evidence is informational, a failed subscription does not fail the checker
+		if evidenceCh, err = chain.subscribeEvidence(subscriber); err != nil {
+			glog.Errorf("[subscribe] Evidence subscription failed for %s: %v", nodeName, err)
+		} else {
+			chain.RecordSubscribed(base.SubscriptionEvidence)
+		}
 		return nil
 	}
 
@@ -143,6 +316,8 @@ func (chain *CometbftCheckerImpl) subscribe() {
 		return
 	}
 
+	drill := base.NewDrill(chain.ctx, chain.ReconnectDrill)
+
 	for {
 		select {
 		case <-chain.ctx.Done():
@@ -150,24 +325,54 @@ func (chain *CometbftCheckerImpl) subscribe() {
 			return
 
 		case event := <-eventCh:
-			if blockHeader, ok := event.Data.(tmtypes.EventDataNewBlockHeader); ok {
-				header := blockHeader.Header
-				chain.UpdateLastBlockTime()
-				delaySecond := float64(time.Now().Unix() - header.Time.Unix())
-				chain.RecordBlockProcessingDelay(delaySecond)
-				glog.V(5).Infof("[subscribe] %s Node BlockNumber %d Delay %.2f s",
-					nodeName, header.Height, delaySecond)
-				chain.checkStatus()
+			switch data := event.Data.(type) {
+			case tmtypes.EventDataNewBlockHeader:
+				drill.Recovered(&chain.BaseChecker)
+				chain.handleHeader(data.Header)
+			case tmtypes.EventDataNewBlock:
+				if data.Block == nil {
+					continue
+				}
+				drill.Recovered(&chain.BaseChecker)
+				chain.RecordBlockTxs(len(data.Block.Txs))
+				chain.RecordTPS(chain.txRate.Add(data.Block.Time, len(data.Block.Txs)))
+				chain.RecordBlockSize(data.Block.Size())
+				glog.V(5).Infof("[subscribe] %s Node BlockNumber %d Txs %d Size %d",
+					nodeName, data.Block.Height, len(data.Block.Txs), data.Block.Size())
+				chain.handleHeader(data.Block.Header)
+			}
+
+		case event := <-evidenceCh:
+			if evidence, ok := event.Data.(tmtypes.EventDataNewEvidence); ok {
+				chain.handleEvidence(evidence)
 			}
 
-		case <-ticker.C:
+		case <-drill.Channel():
+			drill.Begin(&chain.BaseChecker)
+			if chain.client != nil {
+				chain.client.UnsubscribeAll(chain.ctx, subscriber)
+				chain.client.Stop()
+			}
+			chain.updateClient()
+			ensureSubscription(chain)
+
+		case tickAt := <-ticker.C:
+			loop.Tick(tickAt)
+			drill.CheckTimeout(&chain.BaseChecker)
 			// Periodically check connection status
 			if chain.client == nil {
 				chain.updateClient()
 				ensureSubscription(chain)
+			} else if chain.switchURL.Swap(false) {
+				glog.Warningf("[subscribe] Node %s endpoint %s failing, trying the other URLs", nodeName, chain.httpURLs.Active())
+				chain.client.UnsubscribeAll(chain.ctx, subscriber)
+				chain.client.Stop()
+				chain.updateClient()
+				ensureSubscription(chain)
 			} else if !chain.client.IsRunning() {
 				ensureSubscription(chain)
 			}
+			loop.End()
 		}
 	}
 }
@@ -195,6 +400,44 @@ func (chain *CometbftCheckerImpl) GetProtocolName() string {
 func (chain *CometbftCheckerImpl) Start() {
 	glog.Infof("[CometBFT] Starting checker for %s (%s)", chain.Cometbft.HostName, chain.Cometbft.ChainName)
 
+	// Start staking state monitoring
+	if chain.Staking != nil {
+		go chain.stakingCheck()
+	}
+
+	// Start mempool size monitoring
+	go chain.mempoolCheck()
+
+	// Start application-layer stall detection
+	if chain.AbciInfo != nil {
+		go chain.abciInfoCheck()
+	}
+
+	// Start peer churn tracking
+	if chain.Peers != nil {
+		go chain.peersCheck()
+	}
+
+	// Start archive data availability probe
+	if chain.Archive != nil {
+		go chain.archiveCheck()
+	}
+
+	// Start network latency probe
+	if chain.Ping != nil {
+		go chain.PingCheck(chain.ctx, chain.Ping, base.PingHost(chain.Ping, chain.httpURLs.Active(), chain.WsEndpoint))
+	}
+
+	// Start host metrics scraping
+	if chain.NodeExporter != nil {
+		go chain.NodeExporterCheck(chain.ctx, chain.NodeExporter, chain.Proxy)
+	}
+
+	// Start native metrics proxying
+	if chain.NativeMetrics != nil {
+		go chain.NativeMetricsCheck(chain.ctx, chain.NativeMetrics, chain.Proxy, DefaultNativeMetrics)
+	}
+
 	// Start main subscription logic
 	chain.subscribe()
 }
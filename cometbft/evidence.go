@@ -0,0 +1,63 @@
+package cometbft
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"storymonitor/base"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/golang/glog"
+)
+
+const (
+	evidenceDuplicateVote     = "duplicate_vote"
+	evidenceLightClientAttack = "light_client_attack"
+)
+
+// subscribeEvidence subscribes to evidence committed in blocks
+func (chain *CometbftCheckerImpl) subscribeEvidence(subscriber string) (<-chan ctypes.ResultEvent, error) {
+	query := fmt.Sprintf("%s='%s'", tmtypes.EventTypeKey, tmtypes.EventNewEvidence)
+	ctx, cancel := context.WithTimeout(chain.ctx, base.HandshakeTimeout(chain.Timeouts))
+	defer cancel()
+	return chain.client.Subscribe(ctx, subscriber, query)
+}
+
+// evidenceValidators returns the evidence type and the addresses of the validators it accuses
+func evidenceValidators(ev tmtypes.Evidence) (string, []string) {
+	switch e := ev.(type) {
+	case *tmtypes.DuplicateVoteEvidence:
+		if e.VoteA == nil {
+			return evidenceDuplicateVote, nil
+		}
+		return evidenceDuplicateVote, []string{e.VoteA.ValidatorAddress.String()}
+	case *tmtypes.LightClientAttackEvidence:
+		addresses := make([]string, 0, len(e.ByzantineValidators))
+		for _, val := range e.ByzantineValidators {
+			addresses = append(addresses, val.Address.String())
+		}
+		return evidenceLightClientAttack, addresses
+	}
+	return "unknown", nil
+}
+
+func (chain *CometbftCheckerImpl) handleEvidence(data tmtypes.EventDataNewEvidence) {
+	evidenceType, validators := evidenceValidators(data.Evidence)
+	base.EvidenceCommitted.WithLabelValues(chain.AddLabelValues(evidenceType)...).Inc()
+	glog.Warningf("[handleEvidence] Node %s observed %s evidence at height %d against %v",
+		chain.Cometbft.HostName, evidenceType, data.Height, validators)
+
+	watched := chain.ValidatorConsensusAddress
+	if watched == "" {
+		return
+	}
+	for _, address := range validators {
+		if strings.EqualFold(address, watched) {
+			base.EvidenceValidatorInvolved.WithLabelValues(chain.AddLabelValues(watched, evidenceType)...).Inc()
+			glog.Errorf("[handleEvidence] %s evidence at height %d involves validator %s",
+				evidenceType, data.Height, watched)
+		}
+	}
+}
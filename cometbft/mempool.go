@@ -0,0 +1,53 @@
+package cometbft
+
+import (
+	"fmt"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+func (chain *CometbftCheckerImpl) checkMempool() error {
+	if chain.client == nil {
+		return fmt.Errorf("client not available")
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	result, err := chain.client.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return err
+	}
+	base.MempoolTxs.WithLabelValues(chain.AddLabelValues()...).Set(float64(result.Total))
+	base.MempoolBytes.WithLabelValues(chain.AddLabelValues()...).Set(float64(result.TotalBytes))
+	glog.V(5).Infof("[checkMempool] Node %s mempool %d txs %d bytes", chain.Cometbft.HostName, result.Total, result.TotalBytes)
+	return nil
+}
+
+func (chain *CometbftCheckerImpl) mempoolCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 5) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
+	defer ticker.Stop()
+	loop := chain.StartLoop("mempool")
+	defer loop.Stop()
+
+	for {
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[mempoolCheck] Received stop signal, exited")
+			return
+		}
+
+		chain.HealthCheckOperation("mempool", func() error {
+			err := chain.checkMempool()
+			if err != nil {
+				glog.Errorf("[mempoolCheck] Node %s num_unconfirmed_txs fail: %v", chain.Cometbft.HostName, err)
+			}
+			return err
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package cometbft
+
+// DefaultNativeMetrics are the CometBFT metrics proxied when native_metrics
+// lists none, covering consensus progress, validator power and peering
+var DefaultNativeMetrics = []string{
+	"cometbft_consensus_height",
+	"cometbft_consensus_rounds",
+	"cometbft_consensus_validators",
+	"cometbft_consensus_validators_power",
+	"cometbft_consensus_validator_power",
+	"cometbft_consensus_missing_validators",
+	"cometbft_consensus_missing_validators_power",
+	"cometbft_consensus_byzantine_validators",
+	"cometbft_consensus_byzantine_validators_power",
+	"cometbft_consensus_block_interval_seconds",
+	"cometbft_consensus_latest_block_height",
+	"cometbft_p2p_peers",
+	"cometbft_mempool_size",
+}
@@ -0,0 +1,120 @@
+package cometbft
+
+import (
+	"fmt"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+// peerChange is the number of peers added and removed by one /net_info call
+type peerChange struct {
+	at      time.Time
+	changes int
+}
+
+// peerSet keeps the peers of the last /net_info call and the recent changes
+type peerSet struct {
+	known   map[string]struct{}
+	changes []peerChange
+}
+
+// update replaces the peer set and returns the peers added and removed since
+// the previous call. The first call only establishes the set.
+func (p *peerSet) update(ids []string, now time.Time) (added, removed int) {
+	current := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		current[id] = struct{}{}
+	}
+	if p.known != nil {
+		for id := range current {
+			if _, ok := p.known[id]; !ok {
+				added++
+			}
+		}
+		for id := range p.known {
+			if _, ok := current[id]; !ok {
+				removed++
+			}
+		}
+		p.changes = append(p.changes, peerChange{at: now, changes: added + removed})
+	}
+	p.known = current
+	return added, removed
+}
+
+// churnPerMinute returns the peers added or removed per minute within window
+func (p *peerSet) churnPerMinute(now time.Time, window time.Duration) float64 {
+	kept := p.changes[:0]
+	total := 0
+	for _, c := range p.changes {
+		if now.Sub(c.at) <= window {
+			kept = append(kept, c)
+			total += c.changes
+		}
+	}
+	p.changes = kept
+	return float64(total) / window.Minutes()
+}
+
+func (chain *CometbftCheckerImpl) checkPeers(peers *peerSet) error {
+	if chain.client == nil {
+		return fmt.Errorf("client not available")
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	result, err := chain.client.NetInfo(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(result.Peers))
+	for _, peer := range result.Peers {
+		ids = append(ids, string(peer.NodeInfo.DefaultNodeID))
+	}
+
+	window := 600 * time.Second
+	if chain.Peers.ChurnWindowSecond > 0 {
+		window = time.Duration(chain.Peers.ChurnWindowSecond) * time.Second
+	}
+	now := time.Now()
+	added, removed := peers.update(ids, now)
+	churn := peers.churnPerMinute(now, window)
+
+	base.PeersConnected.WithLabelValues(chain.AddLabelValues()...).Set(float64(len(ids)))
+	base.PeersAdded.WithLabelValues(chain.AddLabelValues()...).Add(float64(added))
+	base.PeersRemoved.WithLabelValues(chain.AddLabelValues()...).Add(float64(removed))
+	base.PeerChurnRate.WithLabelValues(chain.AddLabelValues()...).Set(churn)
+	glog.V(5).Infof("[checkPeers] Node %s %d peers, %d added %d removed, churn %.2f/min", chain.Cometbft.HostName, len(ids), added, removed, churn)
+	return nil
+}
+
+func (chain *CometbftCheckerImpl) peersCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Peers.CheckSecond, 30) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Peers.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := chain.StartLoop("peers")
+	defer loop.Stop()
+
+	peers := &peerSet{}
+	for {
+		chain.HealthCheckOperation("net_info", func() error {
+			err := chain.checkPeers(peers)
+			if err != nil {
+				glog.Errorf("[peersCheck] Node %s net_info fail: %v", chain.Cometbft.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[peersCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
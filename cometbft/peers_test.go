@@ -0,0 +1,26 @@
+package cometbft
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeerSetChurn(t *testing.T) {
+	peers := &peerSet{}
+	now := time.Now()
+
+	if added, removed := peers.update([]string{"a", "b"}, now.Add(-20*time.Minute)); added != 0 || removed != 0 {
+		t.Errorf("expected the first call to only establish the set, got +%d -%d", added, removed)
+	}
+	if added, removed := peers.update([]string{"b", "c", "d"}, now.Add(-15*time.Minute)); added != 2 || removed != 1 {
+		t.Errorf("expected +2 -1, got +%d -%d", added, removed)
+	}
+	if added, removed := peers.update([]string{"b", "c"}, now); added != 0 || removed != 1 {
+		t.Errorf("expected +0 -1, got +%d -%d", added, removed)
+	}
+
+	// Only the last change is within the window
+	if churn := peers.churnPerMinute(now, 10*time.Minute); churn != 0.1 {
+		t.Errorf("expected 0.1 changes per minute, got %v", churn)
+	}
+}
@@ -0,0 +1,159 @@
+package cometbft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+type validatorResponse struct {
+	Validator struct {
+		OperatorAddress string `json:"operator_address"`
+		Jailed          bool   `json:"jailed"`
+		Status          string `json:"status"`
+		Tokens          string `json:"tokens"`
+		Commission      struct {
+			CommissionRates struct {
+				Rate string `json:"rate"`
+			} `json:"commission_rates"`
+		} `json:"commission"`
+	} `json:"validator"`
+}
+
+type signingInfoResponse struct {
+	ValSigningInfo struct {
+		Address             string `json:"address"`
+		JailedUntil         string `json:"jailed_until"`
+		Tombstoned          bool   `json:"tombstoned"`
+		MissedBlocksCounter string `json:"missed_blocks_counter"`
+	} `json:"val_signing_info"`
+}
+
+type unbondingResponse struct {
+	UnbondingResponses []struct {
+		Entries []struct {
+			Balance string `json:"balance"`
+		} `json:"entries"`
+	} `json:"unbonding_responses"`
+}
+
+type outstandingRewardsResponse struct {
+	Rewards struct {
+		Rewards []struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"rewards"`
+	} `json:"rewards"`
+}
+
+func (chain *CometbftCheckerImpl) fetchJSON(cli *base.Client, path string, out interface{}) error {
+	url := strings.TrimRight(chain.Staking.ApiURL, "/") + path
+	body, err := cli.Fetch(url, http.MethodGet, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func parseAmount(amount string) float64 {
+	v, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func (chain *CometbftCheckerImpl) checkStaking(cli *base.Client) error {
+	validator := chain.Staking.ValidatorAddress
+	labelValues := chain.AddLabelValues(validator)
+
+	var v validatorResponse
+	if err := chain.fetchJSON(cli, "/cosmos/staking/v1beta1/validators/"+validator, &v); err != nil {
+		return err
+	}
+	base.StakingValidatorTokens.WithLabelValues(labelValues...).Set(parseAmount(v.Validator.Tokens))
+	base.StakingCommissionRate.WithLabelValues(labelValues...).Set(parseAmount(v.Validator.Commission.CommissionRates.Rate))
+	base.StakingValidatorJailed.WithLabelValues(labelValues...).Set(boolToFloat(v.Validator.Jailed))
+	if v.Validator.Jailed {
+		glog.Warningf("[checkStaking] Validator %s is jailed (status %s)", validator, v.Validator.Status)
+	}
+
+	if consensus := chain.Staking.ConsensusAddress; consensus != "" {
+		var info signingInfoResponse
+		if err := chain.fetchJSON(cli, "/cosmos/slashing/v1beta1/signing_infos/"+consensus, &info); err != nil {
+			return err
+		}
+		base.StakingValidatorTombstoned.WithLabelValues(labelValues...).Set(boolToFloat(info.ValSigningInfo.Tombstoned))
+		base.StakingMissedBlocks.WithLabelValues(labelValues...).Set(parseAmount(info.ValSigningInfo.MissedBlocksCounter))
+	}
+
+	var u unbondingResponse
+	if err := chain.fetchJSON(cli, "/cosmos/staking/v1beta1/validators/"+validator+"/unbonding_delegations", &u); err != nil {
+		return err
+	}
+	var unbonding float64
+	var entries int
+	for _, resp := range u.UnbondingResponses {
+		for _, entry := range resp.Entries {
+			unbonding += parseAmount(entry.Balance)
+			entries++
+		}
+	}
+	base.StakingUnbondingTokens.WithLabelValues(labelValues...).Set(unbonding)
+	base.StakingUnbondingEntries.WithLabelValues(labelValues...).Set(float64(entries))
+
+	var r outstandingRewardsResponse
+	if err := chain.fetchJSON(cli, "/cosmos/distribution/v1beta1/validators/"+validator+"/outstanding_rewards", &r); err != nil {
+		return err
+	}
+	for _, reward := range r.Rewards.Rewards {
+		base.StakingPendingRewards.WithLabelValues(chain.AddLabelValues(validator, reward.Denom)...).Set(parseAmount(reward.Amount))
+	}
+
+	glog.V(5).Infof("[checkStaking] Node %s validator %s tokens %s commission %s unbonding %.0f (%d entries)",
+		chain.Cometbft.HostName, validator, v.Validator.Tokens, v.Validator.Commission.CommissionRates.Rate, unbonding, entries)
+	return nil
+}
+
+func (chain *CometbftCheckerImpl) stakingCheck() {
+	cli := base.NewClient(chain.ctx, &http.Client{Timeout: base.CallTimeout(chain.Timeouts)})
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Staking.CheckSecond, 60) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Staking.CheckSecond, 60)
+	defer ticker.Stop()
+	loop := chain.StartLoop("staking")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("staking_api", func() error {
+			err := chain.checkStaking(cli)
+			if err != nil {
+				glog.Errorf("[stakingCheck] Node %s staking query fail: %v", chain.Cometbft.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[stakingCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
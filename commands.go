@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"storymonitor/dashboard"
+	"storymonitor/rules"
+)
+
+// runCommand runs a subcommand that renders an artifact from the loaded
+// config to out, or probes the running monitor, instead of starting the monitor
+func runCommand(name string, args []string, out io.Writer) error {
+	switch name {
+	case "probe":
+		return runProbe(args, out)
+	case "dashboard":
+		title := "Story Monitor"
+		if len(args) > 0 {
+			title = args[0]
+		}
+		data, err := dashboard.Generate(&ac, title)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "rules":
+		namespace := ""
+		if ac.Metrics != nil {
+			namespace = ac.Metrics.Namespace
+		}
+		data, err := rules.Generate(ac.AlertRules, namespace)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
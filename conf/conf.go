@@ -8,7 +8,188 @@ type Evm struct {
 	NodeVersion  string `yaml:"node_version" json:"node_version"`
 	HttpURL      string `yaml:"http_url" json:"http_url"`
 	WsURL        string `yaml:"ws_url" json:"ws_url"`
+	IpcPath      string `yaml:"ipc_path" json:"ipc_path"`
 	CheckSecond  int    `yaml:"check_second" json:"check_second"`
+	DelaySource  string `yaml:"delay_source" json:"delay_source"`
+
+	// HttpURLs and WsURLs are further URLs of the target, tried after http_url and ws_url
+	HttpURLs []string `yaml:"http_urls" json:"http_urls"`
+	WsURLs   []string `yaml:"ws_urls" json:"ws_urls"`
+	// URLStrategy is failover (default), trying the URLs in order, or round_robin
+	URLStrategy string `yaml:"url_strategy" json:"url_strategy"`
+
+	// Proxy is an http, https or socks5 proxy URL of this target, overriding the global proxy
+	Proxy string `yaml:"proxy" json:"proxy"`
+	// Headers are sent with every request, e.g. API keys of RPC providers
+	Headers     map[string]string `yaml:"headers" json:"headers"`
+	BearerToken string            `yaml:"bearer_token" json:"bearer_token"`
+	BasicAuth   *BasicAuth        `yaml:"basic_auth" json:"basic_auth"`
+
+	// MinVersion is the oldest expected node version, older nodes are reported as outdated
+	MinVersion string `yaml:"min_version" json:"min_version"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+
+	// Addresses whose balances are exported, e.g. fee-paying operator wallets
+	Addresses          []string `yaml:"addresses" json:"addresses"`
+	BalanceCheckSecond int      `yaml:"balance_check_second" json:"balance_check_second"`
+
+	CallProbes []*CallProbe `yaml:"call_probes" json:"call_probes"`
+	LogFilter  *LogFilter   `yaml:"log_filter" json:"log_filter"`
+	Archive    *Archive     `yaml:"archive" json:"archive"`
+	Trace      *Trace       `yaml:"trace" json:"trace"`
+	RpcMethods []*RpcMethod `yaml:"rpc_methods" json:"rpc_methods"`
+	Finality   *Finality    `yaml:"finality" json:"finality"`
+	Throughput *Throughput  `yaml:"throughput" json:"throughput"`
+	FeeHistory *FeeHistory  `yaml:"fee_history" json:"fee_history"`
+	NonceWatch *NonceWatch  `yaml:"nonce_watch" json:"nonce_watch"`
+	Canary     *Canary      `yaml:"canary" json:"canary"`
+
+	ReadBenchmark *ReadBenchmark `yaml:"read_benchmark" json:"read_benchmark"`
+
+	ReconnectDrill *ReconnectDrill `yaml:"reconnect_drill" json:"reconnect_drill"`
+
+	Timeouts *Timeouts `yaml:"timeouts" json:"timeouts"`
+	// Transport tunes the HTTP client of the RPC endpoints
+	Transport *Transport `yaml:"transport" json:"transport"`
+	// WsKeepalive pings the websocket endpoint to detect half-open connections
+	WsKeepalive *WsKeepalive `yaml:"ws_keepalive" json:"ws_keepalive"`
+
+	TLS  *TLS  `yaml:"tls" json:"tls"`
+	Ping *Ping `yaml:"ping" json:"ping"`
+
+	NodeExporter *NodeExporter `yaml:"node_exporter" json:"node_exporter"`
+	// NativeMetrics proxies the execution client's /debug/metrics/prometheus endpoint
+	NativeMetrics *NativeMetrics `yaml:"native_metrics" json:"native_metrics"`
+}
+
+// Trace marks an EVM target as a trace node and configures its trace probe
+type Trace struct {
+	// Method is debug_traceBlockByNumber (default) or trace_block
+	Method      string `yaml:"method" json:"method"`
+	CheckSecond int    `yaml:"check_second" json:"check_second"`
+}
+
+// ReadBenchmark runs representative heavy read calls against an EVM target
+type ReadBenchmark struct {
+	CheckSecond int `yaml:"check_second" json:"check_second"`
+	// Calls default to eth_getLogs over the last 1000 blocks and the full latest block
+	Calls []*ReadCall `yaml:"calls" json:"calls"`
+}
+
+// ReadCall is a read benchmark call
+type ReadCall struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is logs, block (full latest block) or call
+	Type string `yaml:"type" json:"type"`
+	// RangeBlocks is the eth_getLogs range ending at the latest block, default 1000
+	RangeBlocks uint64   `yaml:"range_blocks" json:"range_blocks"`
+	Addresses   []string `yaml:"addresses" json:"addresses"`
+	// To and Data are the eth_call target and call data
+	To   string `yaml:"to" json:"to"`
+	Data string `yaml:"data" json:"data"`
+}
+
+// Canary enables synthetic self-transfers that verify the write path of an EVM target
+type Canary struct {
+	// PrivateKeyFile holds the hex private key of a funded account, the account pays the fees
+	PrivateKeyFile string `yaml:"private_key_file" json:"private_key_file"`
+	CheckSecond    int    `yaml:"check_second" json:"check_second"`
+	// TimeoutSecond is how long to wait for inclusion, default 120
+	TimeoutSecond int `yaml:"timeout_second" json:"timeout_second"`
+}
+
+// NonceWatch enables stuck transaction detection for sender addresses of an EVM target
+type NonceWatch struct {
+	// Addresses are the relayer or operator wallets whose pending transactions are watched
+	Addresses   []string `yaml:"addresses" json:"addresses"`
+	CheckSecond int      `yaml:"check_second" json:"check_second"`
+	// MaxStuckSecond is how long a nonce gap may exist without the latest nonce advancing, default 300
+	MaxStuckSecond int `yaml:"max_stuck_second" json:"max_stuck_second"`
+}
+
+// FeeHistory enables priority fee monitoring of an EVM target
+type FeeHistory struct {
+	CheckSecond int `yaml:"check_second" json:"check_second"`
+	// BlockCount is the number of recent blocks passed to eth_feeHistory, default 20
+	BlockCount uint64 `yaml:"block_count" json:"block_count"`
+	// Percentiles are the reward percentiles exported, default 10, 50 and 90
+	Percentiles []float64 `yaml:"percentiles" json:"percentiles"`
+	// MaxSuggestedGwei fails the check when the suggested priority fee is higher, 0 disables it
+	MaxSuggestedGwei float64 `yaml:"max_suggested_gwei" json:"max_suggested_gwei"`
+}
+
+// Throughput enables transaction counting of every new head of an EVM target
+type Throughput struct {
+	// WindowSecond is the window of the rolling TPS, default 60
+	WindowSecond int `yaml:"window_second" json:"window_second"`
+}
+
+// Finality enables tracking of the safe and finalized heads of an EVM target
+type Finality struct {
+	CheckSecond int `yaml:"check_second" json:"check_second"`
+	// MaxLagBlocks fails the finality check when the finalized head is further behind latest, 0 disables it
+	MaxLagBlocks uint64 `yaml:"max_lag_blocks" json:"max_lag_blocks"`
+}
+
+// Ping configures an ICMP echo probe of a target's host
+type Ping struct {
+	// Host defaults to the host of the target's HTTP URL
+	Host          string `yaml:"host" json:"host"`
+	Count         int    `yaml:"count" json:"count"`
+	TimeoutSecond int    `yaml:"timeout_second" json:"timeout_second"`
+	// Privileged uses raw ICMP sockets, which need root or CAP_NET_RAW
+	Privileged  bool `yaml:"privileged" json:"privileged"`
+	CheckSecond int  `yaml:"check_second" json:"check_second"`
+}
+
+// NodeExporter configures scraping the node_exporter running on a target's host
+type NodeExporter struct {
+	// URL is the metrics URL, e.g. http://10.0.0.5:9100/metrics
+	URL string `yaml:"url" json:"url"`
+	// Mountpoint is the mountpoint of the chain data volume, default /
+	Mountpoint  string `yaml:"mountpoint" json:"mountpoint"`
+	CheckSecond int    `yaml:"check_second" json:"check_second"`
+}
+
+// NativeMetrics configures proxying a whitelist of the metrics a node exposes itself
+type NativeMetrics struct {
+	URL string `yaml:"url" json:"url"`
+	// Metrics are metric names or prefixes ending with *, defaulting to a set for the node type
+	Metrics     []string `yaml:"metrics" json:"metrics"`
+	CheckSecond int      `yaml:"check_second" json:"check_second"`
+}
+
+// TLS configures certificate verification for HTTPS and WSS endpoints
+type TLS struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile             string `yaml:"ca_file" json:"ca_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify"`
+	// ServerName overrides the name sent as SNI and verified in the
+	// certificate, e.g. for endpoints addressed by IP
+	ServerName string `yaml:"server_name" json:"server_name"`
+	// CertFile and KeyFile are the PEM client certificate and key presented to endpoints requiring mutual TLS
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// LogFilter configures an eth_subscribe logs subscription
+type LogFilter struct {
+	Addresses []string `yaml:"addresses" json:"addresses"`
+	// Topics follows eth_getLogs semantics, each position is a list of alternatives
+	Topics [][]string `yaml:"topics" json:"topics"`
+}
+
+// CallProbe is a synthetic eth_call whose result is verified against an expected prefix
+type CallProbe struct {
+	Name           string `yaml:"name" json:"name"`
+	To             string `yaml:"to" json:"to"`
+	Data           string `yaml:"data" json:"data"`
+	ExpectedPrefix string `yaml:"expected_prefix" json:"expected_prefix"`
 }
 
 type Cometbft struct {
@@ -20,9 +201,638 @@ type Cometbft struct {
 	HttpURL      string `yaml:"http_url" json:"http_url"`
 	WsEndpoint   string `yaml:"ws_endpoint" json:"ws_endpoint"`
 	CheckSecond  int    `yaml:"check_second" json:"check_second"`
+	DelaySource  string `yaml:"delay_source" json:"delay_source"`
+
+	// HttpURLs are further URLs of the target, tried after http_url
+	HttpURLs []string `yaml:"http_urls" json:"http_urls"`
+	// URLStrategy is failover (default), trying the URLs in order, or round_robin
+	URLStrategy string `yaml:"url_strategy" json:"url_strategy"`
+
+	// Proxy is an http, https or socks5 proxy URL of this target, overriding the global proxy
+	Proxy string `yaml:"proxy" json:"proxy"`
+	// Headers are sent with every request, e.g. API keys of RPC providers
+	Headers     map[string]string `yaml:"headers" json:"headers"`
+	BearerToken string            `yaml:"bearer_token" json:"bearer_token"`
+	BasicAuth   *BasicAuth        `yaml:"basic_auth" json:"basic_auth"`
+
+	// NewBlock subscribes to full blocks instead of headers, exporting transactions and size per block
+	NewBlock bool `yaml:"new_block" json:"new_block"`
+
+	// MinVersion is the oldest expected node version, older nodes are reported as outdated
+	MinVersion string `yaml:"min_version" json:"min_version"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+
+	Staking  *Staking  `yaml:"staking" json:"staking"`
+	Archive  *Archive  `yaml:"archive" json:"archive"`
+	AbciInfo *AbciInfo `yaml:"abci_info" json:"abci_info"`
+	Peers    *Peers    `yaml:"peers" json:"peers"`
+
+	// ValidatorConsensusAddress is the hex consensus address watched in committed evidence
+	ValidatorConsensusAddress string `yaml:"validator_consensus_address" json:"validator_consensus_address"`
+
+	ReconnectDrill *ReconnectDrill `yaml:"reconnect_drill" json:"reconnect_drill"`
+
+	Timeouts *Timeouts `yaml:"timeouts" json:"timeouts"`
+	// Transport tunes the HTTP client of the RPC endpoints
+	Transport *Transport `yaml:"transport" json:"transport"`
+
+	TLS  *TLS  `yaml:"tls" json:"tls"`
+	Ping *Ping `yaml:"ping" json:"ping"`
+
+	NodeExporter *NodeExporter `yaml:"node_exporter" json:"node_exporter"`
+	// NativeMetrics proxies the CometBFT Prometheus endpoint, usually :26660
+	NativeMetrics *NativeMetrics `yaml:"native_metrics" json:"native_metrics"`
+}
+
+// AbciInfo configures /abci_info polling for application-layer stall detection
+type AbciInfo struct {
+	CheckSecond int `yaml:"check_second" json:"check_second"`
+	// StallBlocks is how many consensus blocks may pass without an app hash change, default 10
+	StallBlocks int64 `yaml:"stall_blocks" json:"stall_blocks"`
+}
+
+// Peers configures /net_info polling for peer churn tracking
+type Peers struct {
+	CheckSecond int `yaml:"check_second" json:"check_second"`
+	// ChurnWindowSecond is the window of the churn rate, default 600
+	ChurnWindowSecond int `yaml:"churn_window_second" json:"churn_window_second"`
+}
+
+// CosmosRest is a Cosmos SDK REST API (LCD) endpoint
+type CosmosRest struct {
+	HostName     string `yaml:"hostname" json:"hostname"`
+	ChainName    string `yaml:"chain_name" json:"chain_name"`
+	ProtocolName string `yaml:"protocol_name" json:"protocol_name"`
+	ChainId      string `yaml:"chain_id" json:"chain_id"`
+	NodeVersion  string `yaml:"node_version" json:"node_version"`
+	ApiURL       string `yaml:"api_url" json:"api_url"`
+	CheckSecond  int    `yaml:"check_second" json:"check_second"`
+
+	// MinVersion is the oldest expected node version, older nodes are reported as outdated
+	MinVersion string `yaml:"min_version" json:"min_version"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// Grpc is a Cosmos SDK gRPC endpoint
+type Grpc struct {
+	HostName     string `yaml:"hostname" json:"hostname"`
+	ChainName    string `yaml:"chain_name" json:"chain_name"`
+	ProtocolName string `yaml:"protocol_name" json:"protocol_name"`
+	ChainId      string `yaml:"chain_id" json:"chain_id"`
+	NodeVersion  string `yaml:"node_version" json:"node_version"`
+	// GrpcAddr is the host:port of the gRPC server, e.g. 127.0.0.1:9090
+	GrpcAddr    string `yaml:"grpc_addr" json:"grpc_addr"`
+	TLS         *TLS   `yaml:"tls" json:"tls"`
+	CheckSecond int    `yaml:"check_second" json:"check_second"`
+
+	// MinVersion is the oldest expected node version, older nodes are reported as outdated
+	MinVersion string `yaml:"min_version" json:"min_version"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// JsonRpc is a generic JSON-RPC endpoint checked by calling a single method
+type JsonRpc struct {
+	HostName     string `yaml:"hostname" json:"hostname"`
+	ChainName    string `yaml:"chain_name" json:"chain_name"`
+	ProtocolName string `yaml:"protocol_name" json:"protocol_name"`
+	ChainId      string `yaml:"chain_id" json:"chain_id"`
+	NodeVersion  string `yaml:"node_version" json:"node_version"`
+	RpcURL       string `yaml:"rpc_url" json:"rpc_url"`
+	Method       string `yaml:"method" json:"method"`
+	// Params is a JSON array of call parameters, e.g. '["latest", false]'
+	Params string `yaml:"params" json:"params"`
+	// ResultPath is an optional JSONPath into the response that must be present, e.g. $.result.number
+	ResultPath string `yaml:"result_path" json:"result_path"`
+	// ExpectedValue, if set, must equal the value at ResultPath
+	ExpectedValue string `yaml:"expected_value" json:"expected_value"`
+	CheckSecond   int    `yaml:"check_second" json:"check_second"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// Http is a generic HTTP endpoint such as an explorer, faucet or load balancer health URL
+type Http struct {
+	HostName     string            `yaml:"hostname" json:"hostname"`
+	ChainName    string            `yaml:"chain_name" json:"chain_name"`
+	ProtocolName string            `yaml:"protocol_name" json:"protocol_name"`
+	ChainId      string            `yaml:"chain_id" json:"chain_id"`
+	NodeVersion  string            `yaml:"node_version" json:"node_version"`
+	URL          string            `yaml:"url" json:"url"`
+	Method       string            `yaml:"method" json:"method"`
+	Headers      map[string]string `yaml:"headers" json:"headers"`
+	Body         string            `yaml:"body" json:"body"`
+	// ExpectedStatus lists the accepted status codes, default [200]
+	ExpectedStatus []int  `yaml:"expected_status" json:"expected_status"`
+	BodyRegex      string `yaml:"body_regex" json:"body_regex"`
+	ResultPath     string `yaml:"result_path" json:"result_path"`
+	ExpectedValue  string `yaml:"expected_value" json:"expected_value"`
+	// MaxLatencyMs marks the check failed when the response is slower
+	MaxLatencyMs  int  `yaml:"max_latency_ms" json:"max_latency_ms"`
+	TimeoutSecond int  `yaml:"timeout_second" json:"timeout_second"`
+	TLS           *TLS `yaml:"tls" json:"tls"`
+	CheckSecond   int  `yaml:"check_second" json:"check_second"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// Tcp is a TCP port probed for reachability, e.g. a CometBFT (26656) or EVM (30303) P2P port
+type Tcp struct {
+	HostName      string `yaml:"hostname" json:"hostname"`
+	ChainName     string `yaml:"chain_name" json:"chain_name"`
+	ProtocolName  string `yaml:"protocol_name" json:"protocol_name"`
+	Address       string `yaml:"address" json:"address"`
+	TimeoutSecond int    `yaml:"timeout_second" json:"timeout_second"`
+	CheckSecond   int    `yaml:"check_second" json:"check_second"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// Plugin is a custom checker run as an external command, exchanging JSON over stdin and stdout
+type Plugin struct {
+	HostName     string   `yaml:"hostname" json:"hostname"`
+	ChainName    string   `yaml:"chain_name" json:"chain_name"`
+	ProtocolName string   `yaml:"protocol_name" json:"protocol_name"`
+	Command      string   `yaml:"command" json:"command"`
+	Args         []string `yaml:"args" json:"args"`
+	// Config is passed to the plugin in every request
+	Config        map[string]string `yaml:"config" json:"config"`
+	TimeoutSecond int               `yaml:"timeout_second" json:"timeout_second"`
+	CheckSecond   int               `yaml:"check_second" json:"check_second"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// Heartbeat is a node pushing signed heartbeats to the monitor instead of being probed
+type Heartbeat struct {
+	HostName     string `yaml:"hostname" json:"hostname"`
+	ChainName    string `yaml:"chain_name" json:"chain_name"`
+	ProtocolName string `yaml:"protocol_name" json:"protocol_name"`
+	// Secret is the HMAC-SHA256 key heartbeats are signed with
+	Secret string `yaml:"secret" json:"secret"`
+	// StaleSecond is how long without a heartbeat before the node is unhealthy, default 60
+	StaleSecond int `yaml:"stale_second" json:"stale_second"`
+
+	FailureDomain string `yaml:"failure_domain" json:"failure_domain"`
+
+	// Enabled defaults to true, disabled targets are not monitored
+	Enabled     *bool                `yaml:"enabled" json:"enabled"`
+	Maintenance []*MaintenanceWindow `yaml:"maintenance" json:"maintenance"`
+}
+
+// Staking configures validator staking state monitoring via the Cosmos SDK REST API
+type Staking struct {
+	ApiURL           string `yaml:"api_url" json:"api_url"`
+	ValidatorAddress string `yaml:"validator_address" json:"validator_address"`
+	// ConsensusAddress is the bech32 consensus (valcons) address used for slashing signing info
+	ConsensusAddress string `yaml:"consensus_address" json:"consensus_address"`
+	CheckSecond      int    `yaml:"check_second" json:"check_second"`
+}
+
+// Archive configures a probe that checks whether a node can serve deep history
+type Archive struct {
+	// BlockNumber is the historical block queried, default 1
+	BlockNumber uint64 `yaml:"block_number" json:"block_number"`
+	CheckSecond int    `yaml:"check_second" json:"check_second"`
+}
+
+// RpcMethod is a JSON-RPC method whose availability is probed
+type RpcMethod struct {
+	Method string `yaml:"method" json:"method"`
+	// Params is a JSON array of call parameters, e.g. '[{"fromBlock": "latest"}]'
+	Params string `yaml:"params" json:"params"`
+}
+
+// BasicAuth are the credentials of HTTP basic authentication
+type BasicAuth struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// Timeouts bounds the operations of a checker, so a wedged endpoint cannot hang a check
+type Timeouts struct {
+	// ConnectSecond bounds dialing and identifying the node, default 10
+	ConnectSecond int `yaml:"connect_second" json:"connect_second"`
+	// CallSecond bounds each check call, default 30
+	CallSecond int `yaml:"call_second" json:"call_second"`
+	// HandshakeSecond bounds the websocket handshake and subscription requests, default 12
+	HandshakeSecond int `yaml:"handshake_second" json:"handshake_second"`
+}
+
+// WsKeepalive configures the websocket ping frames sent on an idle connection.
+// A connection receiving nothing within the pong timeout of a ping is closed
+// and reconnected.
+type WsKeepalive struct {
+	Disabled bool `yaml:"disabled" json:"disabled"`
+	// PingIntervalSecond defaults to 10
+	PingIntervalSecond int `yaml:"ping_interval_second" json:"ping_interval_second"`
+	// PongTimeoutSecond defaults to 5
+	PongTimeoutSecond int `yaml:"pong_timeout_second" json:"pong_timeout_second"`
+}
+
+// Transport tunes the HTTP client of a target, e.g. for distant RPC gateways.
+// Unset fields keep the Go defaults.
+type Transport struct {
+	// HTTP2 set to false keeps requests on HTTP/1.1, default true
+	HTTP2 *bool `yaml:"http2" json:"http2"`
+	// KeepAliveSecond is the TCP keep-alive interval, default 30
+	KeepAliveSecond int `yaml:"keep_alive_second" json:"keep_alive_second"`
+	// IdleConnTimeoutSecond closes connections idle for longer, default 90
+	IdleConnTimeoutSecond int `yaml:"idle_conn_timeout_second" json:"idle_conn_timeout_second"`
+	// TLSHandshakeTimeoutSecond bounds the TLS handshake, default 10
+	TLSHandshakeTimeoutSecond int `yaml:"tls_handshake_timeout_second" json:"tls_handshake_timeout_second"`
+	// MaxIdleConns limits the idle connections kept open, default 100
+	MaxIdleConns int `yaml:"max_idle_conns" json:"max_idle_conns"`
+	// MaxIdleConnsPerHost limits the idle connections to the endpoint, default 2
+	MaxIdleConnsPerHost int `yaml:"max_idle_conns_per_host" json:"max_idle_conns_per_host"`
+}
+
+// ReconnectDrill periodically drops the head subscription and verifies it recovers within the SLA
+type ReconnectDrill struct {
+	IntervalSecond int `yaml:"interval_second" json:"interval_second"`
+	SlaSecond      int `yaml:"sla_second" json:"sla_second"`
+}
+
+// FileSD configures Prometheus-style file based discovery of targets. Target
+// files use the target sections of the main config (evm, cometbft, ...).
+type FileSD struct {
+	// Files are glob patterns of JSON or YAML target files
+	Files         []string `yaml:"files" json:"files"`
+	RefreshSecond int      `yaml:"refresh_second" json:"refresh_second"`
+}
+
+// DNSSD configures target discovery from DNS SRV records. Every record is
+// rendered through Template, where ${host}, ${port} and ${address} are replaced.
+type DNSSD struct {
+	// Names are SRV names, e.g. _storyrpc._tcp.example.com
+	Names []string `yaml:"names" json:"names"`
+	// Kind is the target section the records become, e.g. evm or cometbft
+	Kind          string                      `yaml:"kind" json:"kind"`
+	Template      map[interface{}]interface{} `yaml:"template" json:"-"`
+	RefreshSecond int                         `yaml:"refresh_second" json:"refresh_second"`
+}
+
+// Reference is a trusted public endpoint that monitored nodes of the same chain are compared against
+type Reference struct {
+	Name      string `yaml:"name" json:"name"`
+	ChainName string `yaml:"chain_name" json:"chain_name"`
+	// Type is evm (JSON-RPC, default) or cometbft (RPC)
+	Type        string `yaml:"type" json:"type"`
+	URL         string `yaml:"url" json:"url"`
+	CheckSecond int    `yaml:"check_second" json:"check_second"`
+}
+
+// HA configures leader election between monitor replicas
+type HA struct {
+	// Backend is kubernetes or redis
+	Backend string `yaml:"backend" json:"backend"`
+	// Identity of this replica, default the hostname
+	Identity    string           `yaml:"identity" json:"identity"`
+	LeaseSecond int              `yaml:"lease_second" json:"lease_second"`
+	Kubernetes  *KubernetesLease `yaml:"kubernetes" json:"kubernetes"`
+	Redis       *RedisLock       `yaml:"redis" json:"redis"`
+}
+
+// KubernetesLease is a coordination.k8s.io/v1 Lease managed with the in-cluster service account
+type KubernetesLease struct {
+	// Namespace defaults to the namespace of the pod
+	Namespace string `yaml:"namespace" json:"namespace"`
+	Name      string `yaml:"name" json:"name"`
+}
+
+// RedisLock is a Redis key held with an expiry
+type RedisLock struct {
+	Address  string `yaml:"address" json:"address"`
+	Password string `yaml:"password" json:"password"`
+	// Key defaults to storymonitor:leader
+	Key string `yaml:"key" json:"key"`
+}
+
+// MaintenanceWindow is a planned maintenance of a target, either a one-off
+// start/end range or a recurring cron schedule lasting duration_minute
+type MaintenanceWindow struct {
+	// Start and End are RFC3339 times of a one-off window
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+	// Cron is "minute hour day-of-month month day-of-week" starting a recurring window
+	Cron           string `yaml:"cron" json:"cron"`
+	DurationMinute int    `yaml:"duration_minute" json:"duration_minute"`
+	// Timezone of the cron schedule, defaults to UTC
+	Timezone string `yaml:"timezone" json:"timezone"`
+}
+
+// Scheduling spreads the checks of many targets so they do not all fire at once
+type Scheduling struct {
+	// Checkers start after a random delay of up to this many seconds, defaults
+	// to 5, a negative value disables the startup jitter
+	StartJitterSecond int `yaml:"start_jitter_second" json:"start_jitter_second"`
+	// Periodic checks start at a random offset within their interval unless disabled
+	DisablePhaseOffset bool `yaml:"disable_phase_offset" json:"disable_phase_offset"`
+	// Limits concurrent in-flight outbound requests of all checkers, 0 means unlimited
+	MaxConcurrentRequests int `yaml:"max_concurrent_requests" json:"max_concurrent_requests"`
+}
+
+// Shutdown modes
+const (
+	// ShutdownForce abandons checkers still running after the timeout
+	ShutdownForce = "force"
+	// ShutdownWait reports checkers still running after the timeout and keeps waiting
+	ShutdownWait = "wait"
+)
+
+// Shutdown configures how long the controller waits for checkers to exit
+type Shutdown struct {
+	// TimeoutSecond defaults to 30
+	TimeoutSecond int `yaml:"timeout_second" json:"timeout_second"`
+	// Mode is force (default) or wait
+	Mode string `yaml:"mode" json:"mode"`
+}
+
+// Anomaly configures the detection of unusual block intervals against a
+// rolling baseline of each chain
+type Anomaly struct {
+	// Alpha is the weight of a new interval in the moving average and variance, default 0.05
+	Alpha float64 `yaml:"alpha" json:"alpha"`
+	// Factor is the number of standard deviations an interval may deviate, default 4
+	Factor float64 `yaml:"factor" json:"factor"`
+	// WarmupBlocks are observed before scoring a chain, default 100
+	WarmupBlocks int `yaml:"warmup_blocks" json:"warmup_blocks"`
+	// Alert raises an alert for a chain while its intervals are anomalous
+	Alert bool `yaml:"alert" json:"alert"`
+}
+
+// HeadBuffer configures the memory-mapped ring buffer of recent head events
+type HeadBuffer struct {
+	Path  string `yaml:"path" json:"path"`
+	Slots int    `yaml:"slots" json:"slots"`
+}
+
+// SLA configures downtime tracking for uptime reports
+type SLA struct {
+	// Path of the downtime log, empty keeps downtime in memory only
+	Path string `yaml:"path" json:"path"`
+	// RetentionDay defaults to 90
+	RetentionDay int `yaml:"retention_day" json:"retention_day"`
+}
+
+// SLO is an availability or latency objective of the checks of a chain, or of
+// a single node when hostname is set. With latency_ms a check only counts as
+// good when it is healthy and answered within latency_ms.
+type SLO struct {
+	Name      string `yaml:"name" json:"name"`
+	ChainName string `yaml:"chain_name" json:"chain_name"`
+	HostName  string `yaml:"hostname" json:"hostname"`
+	// Check limits the objective to one check, e.g. http, empty counts all checks
+	Check string `yaml:"check" json:"check"`
+	// Objective is the percentage of good checks, e.g. 99.9
+	Objective float64 `yaml:"objective" json:"objective"`
+	LatencyMs int     `yaml:"latency_ms" json:"latency_ms"`
+}
+
+// EventLog configures the JSON lines log of health transitions
+type EventLog struct {
+	Path string `yaml:"path" json:"path"`
+	// The log is rotated once it exceeds MaxSizeMB, default 100, keeping
+	// MaxBackups older files, default 5
+	MaxSizeMB  int `yaml:"max_size_mb" json:"max_size_mb"`
+	MaxBackups int `yaml:"max_backups" json:"max_backups"`
+}
+
+// History configures the embedded database of check outcomes, heads and transitions
+type History struct {
+	Path string `yaml:"path" json:"path"`
+	// RetentionDay defaults to 7
+	RetentionDay int `yaml:"retention_day" json:"retention_day"`
+}
+
+// Alerting configures incident grouping and notifications
+type Alerting struct {
+	// Alerts on the same node or failure domain within this window join one incident
+	GroupWindowSecond int `yaml:"group_window_second" json:"group_window_second"`
+	// Open incidents are notified again at this interval until acknowledged or resolved
+	RepeatIntervalSecond int `yaml:"repeat_interval_second" json:"repeat_interval_second"`
+	// Resolved incidents are kept this long, and at most this many, defaults to 24 and 1000
+	ResolvedRetentionHour int         `yaml:"resolved_retention_hour" json:"resolved_retention_hour"`
+	MaxResolvedIncidents  int         `yaml:"max_resolved_incidents" json:"max_resolved_incidents"`
+	AuditLog              string      `yaml:"audit_log" json:"audit_log"`
+	Notifiers             []*Notifier `yaml:"notifiers" json:"notifiers"`
+	// Rules hold back and deduplicate the alerts of matching checks, the
+	// first matching rule applies
+	Rules []*AlertingRule `yaml:"rules" json:"rules"`
+	// Routes select the notifiers of each severity, severities without a
+	// route are sent to all notifiers
+	Routes []*AlertRoute `yaml:"routes" json:"routes"`
+	// Timezone of route schedules without their own, defaults to UTC
+	Timezone string `yaml:"timezone" json:"timezone"`
+	// Thresholds alert on slow or lagging nodes, see Threshold
+	Thresholds []*Threshold `yaml:"thresholds" json:"thresholds"`
+}
+
+// Threshold sets the limits of the nodes of a chain, or of a single node when
+// hostname is set. Node entries override the limits of their chain, zero
+// disables a limit.
+type Threshold struct {
+	ChainName string `yaml:"chain_name" json:"chain_name"`
+	HostName  string `yaml:"hostname" json:"hostname"`
+	// MaxBlockDelaySecond is the delay between a block's timestamp and its arrival
+	MaxBlockDelaySecond int `yaml:"max_block_delay_second" json:"max_block_delay_second"`
+	// MaxResponseTimeMs applies to every check of the node
+	MaxResponseTimeMs int `yaml:"max_response_time_ms" json:"max_response_time_ms"`
+	// MaxHeightLagBlocks is how far a node may fall behind the highest node of its chain
+	MaxHeightLagBlocks uint64 `yaml:"max_height_lag_blocks" json:"max_height_lag_blocks"`
+}
+
+// AlertRoute sends the incidents of a severity to the named notifiers
+type AlertRoute struct {
+	Severity  string   `yaml:"severity" json:"severity"`
+	Notifiers []string `yaml:"notifiers" json:"notifiers"`
+	// To overrides the recipients of the email notifiers of the route
+	To []string `yaml:"to" json:"to"`
+	// Schedule limits the route to these windows, e.g. a cron "0 22 * * *"
+	// with duration_minute 600 notifies from 22:00 to 08:00
+	Schedule []*MaintenanceWindow `yaml:"schedule" json:"schedule"`
+}
+
+// AlertingRule configures when the alerts of matching checks fire and resolve
+type AlertingRule struct {
+	// Check and ChainName select the checks of the rule, empty matches all
+	Check     string `yaml:"check" json:"check"`
+	ChainName string `yaml:"chain_name" json:"chain_name"`
+	// Severity is info, warning or critical (default)
+	Severity string `yaml:"severity" json:"severity"`
+	// ForSecond is how long a check fails before its alert fires
+	ForSecond int `yaml:"for_second" json:"for_second"`
+	// HoldSecond is how long a check stays healthy before its alert resolves
+	HoldSecond int `yaml:"hold_second" json:"hold_second"`
+	// RepeatIntervalSecond overrides repeat_interval_second for incidents with alerts of the rule
+	RepeatIntervalSecond int `yaml:"repeat_interval_second" json:"repeat_interval_second"`
+	// A check changing health FlapThreshold times within FlapWindowSecond,
+	// default 600, is flapping: its alert fires once and resolves after the
+	// check stayed healthy for the window
+	FlapThreshold    int `yaml:"flap_threshold" json:"flap_threshold"`
+	FlapWindowSecond int `yaml:"flap_window_second" json:"flap_window_second"`
+}
+
+// AlertRules are the thresholds of the Prometheus alerting rules rendered by
+// the rules subcommand
+type AlertRules struct {
+	// GroupName of the rule group, default story-nodes
+	GroupName string `yaml:"group_name" json:"group_name"`
+	// Severity label of the rules, default critical
+	Severity string `yaml:"severity" json:"severity"`
+	// HealthForSecond is how long a check fails before NodeDown fires, default 120
+	HealthForSecond int `yaml:"health_for_second" json:"health_for_second"`
+	// BlockDelaySecond is the block processing delay threshold, default 10
+	BlockDelaySecond int `yaml:"block_delay_second" json:"block_delay_second"`
+	// HeightLagBlocks is how far a node may fall behind the highest node of its chain, default 10
+	HeightLagBlocks int `yaml:"height_lag_blocks" json:"height_lag_blocks"`
+	// CertExpiryDay warns this many days before a certificate expires, default 14
+	CertExpiryDay int `yaml:"cert_expiry_day" json:"cert_expiry_day"`
+	// GasUtilizationPercent is the block gas utilization threshold, default 90
+	GasUtilizationPercent int `yaml:"gas_utilization_percent" json:"gas_utilization_percent"`
+	// ForSecond is how long delay and lag thresholds are exceeded before firing, default 300
+	ForSecond int `yaml:"for_second" json:"for_second"`
+}
+
+// Metrics configures the names and labels of the exported metrics
+type Metrics struct {
+	// Namespace replaces the story_node prefix of the metric names
+	Namespace string `yaml:"namespace" json:"namespace"`
+	// ConstLabels are added to every exported metric, e.g. env: prod
+	ConstLabels map[string]string `yaml:"const_labels" json:"const_labels"`
+}
+
+// DeadManSwitch is an external heartbeat URL, e.g. of healthchecks.io, pinged
+// as long as the checks of its group run
+type DeadManSwitch struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
+	// ChainName limits the group to the checks of one chain, empty covers the checks of all chains
+	ChainName string `yaml:"chain_name" json:"chain_name"`
+	// IntervalSecond is the ping interval, default 60
+	IntervalSecond int `yaml:"interval_second" json:"interval_second"`
+}
+
+// Admin configures access to the admin API
+type Admin struct {
+	// Token is required as a bearer token on admin API requests
+	Token string `yaml:"token" json:"token"`
+	// SlackSigningSecret enables Slack slash commands on /api/v1/chat/slack
+	SlackSigningSecret string `yaml:"slack_signing_secret" json:"slack_signing_secret"`
+}
+
+// Notifier configures a notification channel, fields are interpreted by type
+type Notifier struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	URL  string `yaml:"url" json:"url"`
+	// Template is a Go text/template of the message, see the README for its data
+	Template string `yaml:"template" json:"template"`
+	// Vars are passed to the template, e.g. a runbook base URL
+	Vars map[string]string `yaml:"vars" json:"vars"`
+	// Secret signs the requests of Feishu/Lark bots with signature verification enabled
+	Secret string `yaml:"secret" json:"secret"`
+	// APIKey authenticates the OpsGenie notifier
+	APIKey string `yaml:"api_key" json:"api_key"`
+	// Teams receive the alerts of the OpsGenie notifier
+	Teams []string `yaml:"teams" json:"teams"`
+	// Priorities map severities to OpsGenie priorities P1 to P5
+	Priorities map[string]string `yaml:"priorities" json:"priorities"`
+	// SMTP configures the email notifier
+	SMTP *SMTP `yaml:"smtp" json:"smtp"`
+	// Twilio configures the SMS notifier
+	Twilio *Twilio `yaml:"twilio" json:"twilio"`
+}
+
+// Twilio configures the account and recipients of the SMS notifier
+type Twilio struct {
+	AccountSID string   `yaml:"account_sid" json:"account_sid"`
+	AuthToken  string   `yaml:"auth_token" json:"auth_token"`
+	From       string   `yaml:"from" json:"from"`
+	To         []string `yaml:"to" json:"to"`
+	// MaxPerHour limits the messages to each recipient, default 5
+	MaxPerHour int `yaml:"max_per_hour" json:"max_per_hour"`
+}
+
+// SMTP configures the server and messages of an email notifier
+type SMTP struct {
+	Host string `yaml:"host" json:"host"`
+	// Port defaults to 587, or 465 with tls
+	Port     int    `yaml:"port" json:"port"`
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+	// TLS is starttls (default), tls for implicit TLS or none
+	TLS  string   `yaml:"tls" json:"tls"`
+	From string   `yaml:"from" json:"from"`
+	To   []string `yaml:"to" json:"to"`
+	// Subject and Body are Go templates rendered with the notification
+	Subject string `yaml:"subject" json:"subject"`
+	Body    string `yaml:"body" json:"body"`
 }
 
 type NodeConfig struct {
-	Evm      []*Evm      `yaml:"evm" json:"evm"`
-	Cometbft []*Cometbft `yaml:"cometbft" json:"cometbft"`
+	Evm        []*Evm        `yaml:"evm" json:"evm"`
+	Cometbft   []*Cometbft   `yaml:"cometbft" json:"cometbft"`
+	CosmosRest []*CosmosRest `yaml:"cosmosrest" json:"cosmosrest"`
+	Grpc       []*Grpc       `yaml:"grpc" json:"grpc"`
+	JsonRpc    []*JsonRpc    `yaml:"jsonrpc" json:"jsonrpc"`
+	Http       []*Http       `yaml:"http" json:"http"`
+	Tcp        []*Tcp        `yaml:"tcp" json:"tcp"`
+	Plugin     []*Plugin     `yaml:"plugin" json:"plugin"`
+	Heartbeat  []*Heartbeat  `yaml:"heartbeat" json:"heartbeat"`
+
+	FileSD []*FileSD `yaml:"file_sd_configs" json:"file_sd_configs"`
+	DNSSD  []*DNSSD  `yaml:"dns_sd_configs" json:"dns_sd_configs"`
+
+	References []*Reference `yaml:"references" json:"references"`
+	// DeadManSwitches are pinged while the checks of their group run
+	DeadManSwitches []*DeadManSwitch `yaml:"dead_man_switches" json:"dead_man_switches"`
+	// Proxy is an http, https or socks5 proxy URL of outbound requests, default from HTTP_PROXY and HTTPS_PROXY
+	Proxy string `yaml:"proxy" json:"proxy"`
+
+	// MinVersions are the oldest expected node versions by chain_name, min_version of a target takes precedence
+	MinVersions map[string]string `yaml:"min_versions" json:"min_versions"`
+	HeadBuffer  *HeadBuffer       `yaml:"head_buffer" json:"head_buffer"`
+	Anomaly     *Anomaly          `yaml:"anomaly" json:"anomaly"`
+	Scheduling  *Scheduling       `yaml:"scheduling" json:"scheduling"`
+	Shutdown    *Shutdown         `yaml:"shutdown" json:"shutdown"`
+	Alerting    *Alerting         `yaml:"alerting" json:"alerting"`
+	AlertRules  *AlertRules       `yaml:"alert_rules" json:"alert_rules"`
+	SLA         *SLA              `yaml:"sla" json:"sla"`
+	SLOs        []*SLO            `yaml:"slos" json:"slos"`
+	History     *History          `yaml:"history" json:"history"`
+	EventLog    *EventLog         `yaml:"event_log" json:"event_log"`
+	Admin       *Admin            `yaml:"admin" json:"admin"`
+	Metrics     *Metrics          `yaml:"metrics" json:"metrics"`
+	HA          *HA               `yaml:"ha" json:"ha"`
 }
@@ -0,0 +1,208 @@
+package cosmosrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+)
+
+type nodeInfoResponse struct {
+	DefaultNodeInfo struct {
+		Network string `json:"network"`
+		Version string `json:"version"`
+		Moniker string `json:"moniker"`
+	} `json:"default_node_info"`
+	ApplicationVersion struct {
+		Version string `json:"version"`
+	} `json:"application_version"`
+}
+
+type syncingResponse struct {
+	Syncing bool `json:"syncing"`
+}
+
+type latestBlockResponse struct {
+	Block struct {
+		Header struct {
+			Height string    `json:"height"`
+			Time   time.Time `json:"time"`
+		} `json:"header"`
+	} `json:"block"`
+}
+
+type CosmosRestCheckerImpl struct {
+	*conf.CosmosRest
+	base.BaseChecker
+
+	ctx context.Context
+
+	client     *base.Client
+	lastHeight int64
+}
+
+func NewCosmosRestCheckerImpl(ctx context.Context, conf *conf.CosmosRest) base.CheckerTrait {
+	checker := &CosmosRestCheckerImpl{
+		CosmosRest: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ChainId:      conf.ChainId,
+			NodeVersion:  conf.NodeVersion,
+			ProtocolName: conf.ProtocolName,
+
+			ExpectedChainId: conf.ChainId,
+			MinVersion:      conf.MinVersion,
+			FailureDomain:   conf.FailureDomain,
+			Maintenance:     maintenance.New(conf.Maintenance),
+		},
+		ctx:    ctx,
+		client: base.NewClient(ctx, &http.Client{Timeout: 10 * time.Second}),
+	}
+
+	// Set default check interval
+	if checker.CheckSecond == 0 {
+		checker.CheckSecond = 5
+	}
+
+	base.RegisterTargetEndpoint("cosmosrest", "rest", conf.ChainName, conf.HostName, conf.ApiURL)
+	return checker
+}
+
+func (chain *CosmosRestCheckerImpl) fetchJSON(path string, out interface{}) error {
+	url := strings.TrimRight(chain.ApiURL, "/") + path
+	body, err := chain.client.Fetch(url, http.MethodGet, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+func (chain *CosmosRestCheckerImpl) checkNodeInfo() error {
+	var info nodeInfoResponse
+	if err := chain.fetchJSON("/cosmos/base/tendermint/v1beta1/node_info", &info); err != nil {
+		return err
+	}
+	chain.CosmosRest.ChainId = info.DefaultNodeInfo.Network
+	chain.CosmosRest.NodeVersion = info.ApplicationVersion.Version
+	chain.BaseChecker.ChainId = info.DefaultNodeInfo.Network
+	chain.SetNodeVersion(info.ApplicationVersion.Version)
+	chain.CheckChainId(info.DefaultNodeInfo.Network)
+	return nil
+}
+
+func (chain *CosmosRestCheckerImpl) checkSyncing() error {
+	var syncing syncingResponse
+	if err := chain.fetchJSON("/cosmos/base/tendermint/v1beta1/syncing", &syncing); err != nil {
+		return err
+	}
+	value := float64(0)
+	if syncing.Syncing {
+		value = 1
+	}
+	base.NodeSyncing.WithLabelValues(chain.AddLabelValues()...).Set(value)
+	return nil
+}
+
+func (chain *CosmosRestCheckerImpl) checkLatestBlock() error {
+	var block latestBlockResponse
+	if err := chain.fetchJSON("/cosmos/base/tendermint/v1beta1/blocks/latest", &block); err != nil {
+		return err
+	}
+	height, err := strconv.ParseInt(block.Block.Header.Height, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid block height %q: %w", block.Block.Header.Height, err)
+	}
+	base.LatestBlockHeight.WithLabelValues(chain.AddLabelValues()...).Set(float64(height))
+
+	if height > chain.lastHeight {
+		chain.lastHeight = height
+		chain.UpdateLastBlockTime()
+		delaySecond := chain.RecordBlockDelay(block.Block.Header.Time)
+		glog.V(5).Infof("[checkLatestBlock] %s REST BlockNumber %d Delay %.2f s", chain.CosmosRest.HostName, height, delaySecond)
+	}
+	return nil
+}
+
+func (chain *CosmosRestCheckerImpl) check() {
+	healthy := true
+	for _, op := range []struct {
+		endpointType string
+		check        func() error
+	}{
+		{"rest_node_info", chain.checkNodeInfo},
+		{"rest_syncing", chain.checkSyncing},
+		{"rest_latest_block", chain.checkLatestBlock},
+	} {
+		chain.HealthCheckOperation(op.endpointType, func() error {
+			err := op.check()
+			if err != nil {
+				healthy = false
+				glog.Errorf("[check] Node %s %s fail: %v", chain.CosmosRest.HostName, op.endpointType, err)
+			}
+			return err
+		})
+	}
+
+	chain.RecordConnectionAttempt("rest", healthy)
+	if healthy {
+		chain.SetState(base.StateSubscribed)
+	} else {
+		chain.SetState(base.StateDegraded)
+	}
+}
+
+func (chain *CosmosRestCheckerImpl) Start() {
+	glog.Infof("[CosmosRest] Starting checker for %s (%s)", chain.CosmosRest.HostName, chain.CosmosRest.ChainName)
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 5) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		chain.check()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[CosmosRest] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+func (chain *CosmosRestCheckerImpl) GetHostName() string {
+	return chain.CosmosRest.HostName
+}
+
+func (chain *CosmosRestCheckerImpl) GetChainId() string {
+	return chain.CosmosRest.ChainId
+}
+
+func (chain *CosmosRestCheckerImpl) GetNodeVersion() string {
+	return chain.CosmosRest.NodeVersion
+}
+
+func (chain *CosmosRestCheckerImpl) GetChainName() string {
+	return chain.CosmosRest.ChainName
+}
+
+func (chain *CosmosRestCheckerImpl) GetProtocolName() string {
+	return chain.CosmosRest.ProtocolName
+}
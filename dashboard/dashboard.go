@@ -0,0 +1,233 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+// Target is a monitored node as it appears in the metric labels
+type Target struct {
+	Kind      string
+	ChainName string
+	HostName  string
+
+	// Optional checks of the target that export their own metrics
+	Ping       bool
+	Balances   bool
+	Staking    bool
+	Finality   bool
+	Throughput bool
+	Reference  bool
+}
+
+// Targets lists the statically configured targets grouped by chain, chains
+// and targets keep the order of the config
+func Targets(config *conf.NodeConfig) ([]string, map[string][]Target) {
+	var chains []string
+	byChain := make(map[string][]Target)
+	references := make(map[string]bool)
+	for _, r := range config.References {
+		references[r.ChainName] = true
+	}
+	add := func(t Target) {
+		if _, ok := byChain[t.ChainName]; !ok {
+			chains = append(chains, t.ChainName)
+		}
+		t.Reference = references[t.ChainName]
+		byChain[t.ChainName] = append(byChain[t.ChainName], t)
+	}
+
+	for _, c := range config.Evm {
+		add(Target{Kind: "evm", ChainName: c.ChainName, HostName: c.HostName, Ping: c.Ping != nil, Balances: len(c.Addresses) > 0, Finality: c.Finality != nil, Throughput: c.Throughput != nil})
+	}
+	for _, c := range config.Cometbft {
+		add(Target{Kind: "cometbft", ChainName: c.ChainName, HostName: c.HostName, Ping: c.Ping != nil, Staking: c.Staking != nil, Throughput: c.NewBlock})
+	}
+	for _, c := range config.CosmosRest {
+		add(Target{Kind: "cosmosrest", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	for _, c := range config.Grpc {
+		add(Target{Kind: "grpc", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	for _, c := range config.JsonRpc {
+		add(Target{Kind: "jsonrpc", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	for _, c := range config.Http {
+		add(Target{Kind: "http", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	for _, c := range config.Tcp {
+		add(Target{Kind: "tcp", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	for _, c := range config.Plugin {
+		add(Target{Kind: "plugin", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	for _, c := range config.Heartbeat {
+		add(Target{Kind: "heartbeat", ChainName: c.ChainName, HostName: c.HostName})
+	}
+	return chains, byChain
+}
+
+// query is a panel query, the selector of the target is filled into %s
+type query struct {
+	expr   string
+	legend string
+}
+
+type panelSpec struct {
+	title   string
+	unit    string
+	queries []query
+}
+
+// panels returns the panels of a target, matching the metrics its checkers export
+func panels(t Target) []panelSpec {
+	specs := []panelSpec{
+		{title: "Health", unit: "bool_yes_no", queries: []query{{"story_node_health_status{%s}", "{{endpoint_type}}"}}},
+		{title: "Response time", unit: "ms", queries: []query{
+			{"story_node_endpoint_response_time_milliseconds{%s}", "{{endpoint_type}}"},
+			{"story_node_response_time_threshold_milliseconds{%s}", "threshold"},
+		}},
+	}
+	switch t.Kind {
+	case "evm", "cometbft":
+		specs = append(specs,
+			panelSpec{title: "Block height", unit: "none", queries: []query{{"story_node_latest_block_height{%s}", "height"}}},
+			panelSpec{title: "Block delay", unit: "s", queries: []query{
+				{"story_node_block_processing_delay_seconds{%s}", "processing delay"},
+				{"story_node_block_arrival_interval_seconds{%s}", "arrival interval"},
+				{"story_node_block_delay_threshold_seconds{%s}", "threshold"},
+			}},
+			panelSpec{title: "Subscription reconnects", unit: "none", queries: []query{{"increase(story_node_subscription_reconnects_total{%s}[1h])", "{{subscription}}"}}},
+		)
+	case "tcp":
+		specs = append(specs, panelSpec{title: "TCP connect", unit: "ms", queries: []query{{"story_node_tcp_connect_duration_milliseconds{%s}", "{{address}}"}}})
+	}
+	if t.Kind == "evm" {
+		specs = append(specs, panelSpec{title: "Gas utilization", unit: "percent", queries: []query{{"story_node_block_gas_utilization_percent{%s}", "utilization"}}})
+	}
+	if t.Kind == "cometbft" {
+		specs = append(specs, panelSpec{title: "Mempool", unit: "none", queries: []query{{"story_node_mempool_txs{%s}", "txs"}}})
+	}
+	if t.Throughput {
+		specs = append(specs, panelSpec{title: "Throughput", unit: "none", queries: []query{
+			{"story_node_tps{%s}", "tps"},
+			{"story_node_block_txs{%s}", "txs per block"},
+		}})
+	}
+	if t.Finality {
+		specs = append(specs, panelSpec{title: "Finality lag", unit: "none", queries: []query{{"story_node_finality_lag_blocks{%s}", "{{tag}}"}}})
+	}
+	if t.Reference {
+		specs = append(specs, panelSpec{title: "Lag vs reference", unit: "none", queries: []query{{"story_node_lag_vs_reference_blocks{%s}", "blocks"}}})
+	}
+	if t.Ping {
+		specs = append(specs, panelSpec{title: "Ping", unit: "ms", queries: []query{{"story_node_ping_rtt_milliseconds{%s}", "rtt"}}})
+	}
+	if t.Balances {
+		specs = append(specs, panelSpec{title: "Balances", unit: "none", queries: []query{{"story_node_account_balance_ether{%s}", "{{address}}"}}})
+	}
+	if t.Staking {
+		specs = append(specs, panelSpec{title: "Missed blocks", unit: "none", queries: []query{{"story_node_staking_missed_blocks{%s}", "{{validator}}"}}})
+	}
+	return specs
+}
+
+// selector matches the series of a target, label values are escaped for PromQL
+func selector(t Target) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+	return fmt.Sprintf(`chain_name="%s",hostname="%s"`, escape(t.ChainName), escape(t.HostName))
+}
+
+const (
+	panelWidth  = 8
+	panelHeight = 8
+	gridWidth   = 24
+)
+
+var datasource = map[string]string{"type": "prometheus", "uid": "${datasource}"}
+
+// Generate renders a Grafana dashboard with one row per chain and a set of
+// panels per target, ready to be imported
+func Generate(config *conf.NodeConfig, title string) ([]byte, error) {
+	chains, byChain := Targets(config)
+	namespace := ""
+	if config.Metrics != nil {
+		namespace = config.Metrics.Namespace
+	}
+
+	var out []map[string]interface{}
+	id, y := 1, 0
+	for _, chain := range chains {
+		out = append(out, map[string]interface{}{
+			"id":        id,
+			"type":      "row",
+			"title":     chain,
+			"collapsed": false,
+			"gridPos":   map[string]int{"h": 1, "w": gridWidth, "x": 0, "y": y},
+			"panels":    []interface{}{},
+		})
+		id++
+		y++
+
+		for _, t := range byChain[chain] {
+			x := 0
+			for _, spec := range panels(t) {
+				if x+panelWidth > gridWidth {
+					x = 0
+					y += panelHeight
+				}
+				targets := make([]map[string]interface{}, 0, len(spec.queries))
+				for i, q := range spec.queries {
+					targets = append(targets, map[string]interface{}{
+						"datasource":   datasource,
+						"expr":         base.RenameMetric(fmt.Sprintf(q.expr, selector(t)), namespace),
+						"legendFormat": q.legend,
+						"refId":        string(rune('A' + i)),
+					})
+				}
+				out = append(out, map[string]interface{}{
+					"id":         id,
+					"type":       "timeseries",
+					"title":      fmt.Sprintf("%s %s", t.HostName, spec.title),
+					"datasource": datasource,
+					"gridPos":    map[string]int{"h": panelHeight, "w": panelWidth, "x": x, "y": y},
+					"fieldConfig": map[string]interface{}{
+						"defaults":  map[string]interface{}{"unit": spec.unit},
+						"overrides": []interface{}{},
+					},
+					"targets": targets,
+				})
+				id++
+				x += panelWidth
+			}
+			y += panelHeight
+		}
+	}
+	if out == nil {
+		out = []map[string]interface{}{}
+	}
+
+	dashboard := map[string]interface{}{
+		"title":         title,
+		"uid":           "storymonitor",
+		"editable":      true,
+		"schemaVersion": 38,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"tags":          []string{"storymonitor"},
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{{
+				"name":  "datasource",
+				"label": "Data source",
+				"type":  "datasource",
+				"query": "prometheus",
+			}},
+		},
+		"panels": out,
+	}
+	return json.MarshalIndent(dashboard, "", "  ")
+}
@@ -0,0 +1,67 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"storymonitor/conf"
+)
+
+func TestGenerate(t *testing.T) {
+	config := &conf.NodeConfig{
+		Evm: []*conf.Evm{
+			{ChainName: "story", HostName: "evm-01", Addresses: []string{"0x01"}},
+		},
+		Cometbft: []*conf.Cometbft{
+			{ChainName: "story", HostName: "cometbft-01"},
+		},
+		Tcp: []*conf.Tcp{
+			{ChainName: "odyssey", HostName: "p2p-01"},
+		},
+	}
+	data, err := Generate(config, "Story")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dashboard struct {
+		Panels []struct {
+			Type    string `json:"type"`
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []string
+	exprs := make(map[string]bool)
+	for _, p := range dashboard.Panels {
+		if p.Type == "row" {
+			rows = append(rows, p.Title)
+		}
+		for _, target := range p.Targets {
+			exprs[target.Expr] = true
+		}
+	}
+	if strings.Join(rows, ",") != "story,odyssey" {
+		t.Errorf("expected one row per chain in config order, got %v", rows)
+	}
+	for _, expr := range []string{
+		`story_node_health_status{chain_name="story",hostname="evm-01"}`,
+		`story_node_account_balance_ether{chain_name="story",hostname="evm-01"}`,
+		`story_node_mempool_txs{chain_name="story",hostname="cometbft-01"}`,
+		`story_node_tcp_connect_duration_milliseconds{chain_name="odyssey",hostname="p2p-01"}`,
+	} {
+		if !exprs[expr] {
+			t.Errorf("missing query %s", expr)
+		}
+	}
+	if exprs[`story_node_account_balance_ether{chain_name="story",hostname="cometbft-01"}`] {
+		t.Error("unexpected balance panel for a target without addresses")
+	}
+}
@@ -0,0 +1,130 @@
+// Package deadman pings external dead man's switch services such as
+// healthchecks.io while the checks of a group keep running, so the service
+// raises the alarm when the monitor dies or the checks of a chain stall.
+package deadman
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+)
+
+// Monitor receives the check outcomes of all checkers and pings the switches
+// whose group had checks running within the last interval
+type Monitor struct {
+	switches []*conf.DeadManSwitch
+
+	mu        sync.Mutex
+	lastAny   time.Time
+	lastChain map[string]time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMonitor(switches []*conf.DeadManSwitch) *Monitor {
+	return &Monitor{
+		switches:  switches,
+		lastChain: make(map[string]time.Time),
+	}
+}
+
+// AppendCheck records that a check ran, it implements base.CheckSink
+func (m *Monitor) AppendCheck(chainName, hostName, check string, healthy bool, duration time.Duration, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastAny = at
+	m.lastChain[chainName] = at
+}
+
+// lastCheck returns when a check of the group of s last ran
+func (m *Monitor) lastCheck(s *conf.DeadManSwitch) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s.ChainName == "" {
+		return m.lastAny
+	}
+	return m.lastChain[s.ChainName]
+}
+
+func (m *Monitor) run(ctx context.Context, s *conf.DeadManSwitch) {
+	defer m.wg.Done()
+
+	cli := base.NewClient(ctx, &http.Client{Timeout: 10 * time.Second})
+	interval := time.Duration(s.IntervalSecond) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := base.CheckSecondToTicker(s.IntervalSecond, 60)
+	defer ticker.Stop()
+
+	for {
+		if !base.WaitForContextOrTicker(ctx, ticker) {
+			glog.V(5).Info("[deadman] Received stop signal, exited")
+			return
+		}
+
+		// Without recent checks the ping is withheld, letting the service alarm
+		if last := m.lastCheck(s); time.Since(last) > interval {
+			glog.Warningf("[deadman] No checks of %s ran in the last %s, withholding the ping of %s", group(s), interval, s.Name)
+			base.DeadManPings.WithLabelValues(s.Name, "withheld").Inc()
+			continue
+		}
+		if _, err := cli.Fetch(s.URL, http.MethodGet, nil, nil); err != nil {
+			glog.Errorf("[deadman] Ping of %s fail: %v", s.Name, err)
+			base.DeadManPings.WithLabelValues(s.Name, "fail").Inc()
+			continue
+		}
+		base.DeadManPings.WithLabelValues(s.Name, "success").Inc()
+	}
+}
+
+func group(s *conf.DeadManSwitch) string {
+	if s.ChainName == "" {
+		return "any chain"
+	}
+	return s.ChainName
+}
+
+// Start pings every switch in its own goroutine until Stop
+func (m *Monitor) Start(parent context.Context) {
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(parent)
+	for _, s := range m.switches {
+		m.wg.Add(1)
+		go m.run(ctx, s)
+	}
+}
+
+func (m *Monitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// Validate checks the dead man's switch configuration
+func Validate(switches []*conf.DeadManSwitch) error {
+	for i, s := range switches {
+		if s == nil {
+			return fmt.Errorf("dead_man_switches[%d]: is empty", i)
+		}
+		if s.URL == "" {
+			return fmt.Errorf("dead_man_switches[%d]: url is required", i)
+		}
+		if s.Name == "" && s.ChainName == "" {
+			s.Name = fmt.Sprintf("all-%d", i)
+		} else if s.Name == "" {
+			s.Name = fmt.Sprintf("%s-%d", s.ChainName, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package deadman
+
+import (
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+func TestLastCheck(t *testing.T) {
+	all := &conf.DeadManSwitch{URL: "https://hc-ping.com/all"}
+	story := &conf.DeadManSwitch{URL: "https://hc-ping.com/story", ChainName: "story"}
+	if err := Validate([]*conf.DeadManSwitch{all, story}); err != nil {
+		t.Fatal(err)
+	}
+	if all.Name != "all-0" || story.Name != "story-1" {
+		t.Errorf("default names %q and %q", all.Name, story.Name)
+	}
+
+	m := NewMonitor([]*conf.DeadManSwitch{all, story})
+	at := time.Now()
+	m.AppendCheck("other", "node-1", "block_retrieval", true, time.Millisecond, at)
+	if got := m.lastCheck(all); !got.Equal(at) {
+		t.Errorf("last check of all chains = %v, want %v", got, at)
+	}
+	if got := m.lastCheck(story); !got.IsZero() {
+		t.Errorf("last check of story = %v, want none", got)
+	}
+
+	if err := Validate([]*conf.DeadManSwitch{{ChainName: "story"}}); err == nil {
+		t.Error("switch without url accepted")
+	}
+}
@@ -0,0 +1,193 @@
+// Package dnssd discovers targets from DNS SRV records. Every record is
+// rendered through a target template and the resulting targets are handed to
+// the controller, which adds and removes checkers as the records change.
+package dnssd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+	"gopkg.in/yaml.v2"
+)
+
+// Record is a resolved SRV target
+type Record struct {
+	Host string
+	Port uint16
+}
+
+// Resolver looks up the SRV records of a name
+type Resolver func(ctx context.Context, name string) ([]Record, error)
+
+// LookupSRV resolves SRV records with the default resolver. A name without
+// records resolves to no targets rather than an error.
+func LookupSRV(ctx context.Context, name string) ([]Record, error) {
+	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(addrs))
+	for _, addr := range addrs {
+		records = append(records, Record{Host: strings.TrimSuffix(addr.Target, "."), Port: addr.Port})
+	}
+	return records, nil
+}
+
+// Watcher periodically resolves the configured SRV names
+type Watcher struct {
+	configs  []*conf.DNSSD
+	resolve  Resolver
+	apply    func(source string, cfg *conf.NodeConfig)
+	validate func(cfg *conf.NodeConfig) error
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWatcher(configs []*conf.DNSSD, apply func(string, *conf.NodeConfig), validate func(*conf.NodeConfig) error) *Watcher {
+	return &Watcher{
+		configs:  configs,
+		resolve:  LookupSRV,
+		apply:    apply,
+		validate: validate,
+	}
+}
+
+// replace substitutes ${host}, ${port} and ${address} in all string values of a template
+func replace(value interface{}, r *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Replace(v)
+	case map[interface{}]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for key, item := range v {
+			out[key] = replace(item, r)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = replace(item, r)
+		}
+		return out
+	}
+	return value
+}
+
+// Render builds the targets of the records from the config template. The
+// hostname defaults to the record host when the template does not set one.
+func Render(c *conf.DNSSD, records []Record) (*conf.NodeConfig, error) {
+	targets := make([]interface{}, 0, len(records))
+	for _, record := range records {
+		port := strconv.Itoa(int(record.Port))
+		r := strings.NewReplacer(
+			"${host}", record.Host,
+			"${port}", port,
+			"${address}", net.JoinHostPort(record.Host, port),
+		)
+		target, _ := replace(c.Template, r).(map[interface{}]interface{})
+		if target == nil {
+			target = make(map[interface{}]interface{})
+		}
+		if _, ok := target["hostname"]; !ok {
+			target["hostname"] = record.Host
+		}
+		targets = append(targets, target)
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{c.Kind: targets})
+	if err != nil {
+		return nil, err
+	}
+	cfg := &conf.NodeConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to render %s targets: %w", c.Kind, err)
+	}
+	return cfg, nil
+}
+
+// Refresh resolves every name and applies its targets. A failed lookup keeps
+// the previously applied targets so a DNS outage does not drop monitoring.
+func (w *Watcher) Refresh(ctx context.Context) {
+	for _, c := range w.configs {
+		for _, name := range c.Names {
+			records, err := w.resolve(ctx, name)
+			if err != nil {
+				glog.Errorf("[dnssd] Lookup of %s fail: %v", name, err)
+				continue
+			}
+			sort.Slice(records, func(i, j int) bool {
+				if records[i].Host != records[j].Host {
+					return records[i].Host < records[j].Host
+				}
+				return records[i].Port < records[j].Port
+			})
+
+			cfg, err := Render(c, records)
+			if err == nil && w.validate != nil {
+				err = w.validate(cfg)
+			}
+			if err != nil {
+				glog.Errorf("[dnssd] Ignoring records of %s: %v", name, err)
+				continue
+			}
+
+			glog.V(5).Infof("[dnssd] %s resolved to %d targets", name, len(records))
+			w.apply("dns:"+name, cfg)
+		}
+	}
+}
+
+// refreshInterval is the shortest refresh interval of all configs
+func (w *Watcher) refreshInterval() int {
+	interval := 0
+	for _, c := range w.configs {
+		if c.RefreshSecond > 0 && (interval == 0 || c.RefreshSecond < interval) {
+			interval = c.RefreshSecond
+		}
+	}
+	return interval
+}
+
+// Start resolves all names and keeps re-resolving them until Stop
+func (w *Watcher) Start(parent context.Context) {
+	var ctx context.Context
+	ctx, w.cancel = context.WithCancel(parent)
+
+	w.Refresh(ctx)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := base.CheckSecondToTicker(w.refreshInterval(), 60)
+		defer ticker.Stop()
+
+		for base.WaitForContextOrTicker(ctx, ticker) {
+			w.Refresh(ctx)
+		}
+		glog.V(5).Info("[dnssd] Received stop signal, exited")
+	}()
+}
+
+func (w *Watcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
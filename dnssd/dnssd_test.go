@@ -0,0 +1,61 @@
+package dnssd
+
+import (
+	"context"
+	"testing"
+
+	"storymonitor/conf"
+)
+
+func TestRender(t *testing.T) {
+	c := &conf.DNSSD{
+		Kind: "evm",
+		Template: map[interface{}]interface{}{
+			"chain_name": "story",
+			"http_url":   "http://${host}:${port}",
+			"ws_url":     "ws://${host}:8546",
+		},
+	}
+	cfg, err := Render(c, []Record{{Host: "rpc-1.example.com", Port: 8545}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Evm) != 1 {
+		t.Fatalf("expected 1 evm target, got %d", len(cfg.Evm))
+	}
+	evm := cfg.Evm[0]
+	if evm.HostName != "rpc-1.example.com" || evm.HttpURL != "http://rpc-1.example.com:8545" || evm.WsURL != "ws://rpc-1.example.com:8546" {
+		t.Errorf("unexpected target %+v", evm)
+	}
+}
+
+func TestRefreshKeepsTargetsOnLookupError(t *testing.T) {
+	c := &conf.DNSSD{
+		Names:    []string{"_storyrpc._tcp.example.com"},
+		Kind:     "tcp",
+		Template: map[interface{}]interface{}{"chain_name": "story", "address": "${address}"},
+	}
+
+	applied := make(map[string]*conf.NodeConfig)
+	w := NewWatcher([]*conf.DNSSD{c}, func(source string, cfg *conf.NodeConfig) {
+		applied[source] = cfg
+	}, nil)
+
+	w.resolve = func(ctx context.Context, name string) ([]Record, error) {
+		return []Record{{Host: "b", Port: 2}, {Host: "a", Port: 1}}, nil
+	}
+	w.Refresh(context.Background())
+	cfg := applied["dns:_storyrpc._tcp.example.com"]
+	if cfg == nil || len(cfg.Tcp) != 2 || cfg.Tcp[0].Address != "a:1" {
+		t.Fatalf("unexpected targets %+v", cfg)
+	}
+
+	delete(applied, "dns:_storyrpc._tcp.example.com")
+	w.resolve = func(ctx context.Context, name string) ([]Record, error) {
+		return nil, context.DeadlineExceeded
+	}
+	w.Refresh(context.Background())
+	if _, ok := applied["dns:_storyrpc._tcp.example.com"]; ok {
+		t.Error("targets applied after failed lookup")
+	}
+}
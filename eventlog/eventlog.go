@@ -0,0 +1,184 @@
+// Package eventlog appends the health transitions of all checks to a JSON
+// lines file, so postmortems can reconstruct exact transition times instead
+// of relying on metrics at scrape interval resolution.
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+)
+
+// States of a check in the event log
+const (
+	StateUnknown   = "unknown"
+	StateHealthy   = "healthy"
+	StateUnhealthy = "unhealthy"
+)
+
+// Event is a health transition as written to the event log
+type Event struct {
+	Time         time.Time `json:"time"`
+	ChainName    string    `json:"chain_name"`
+	HostName     string    `json:"hostname"`
+	EndpointType string    `json:"endpoint_type"`
+	OldState     string    `json:"old_state"`
+	NewState     string    `json:"new_state"`
+	Error        string    `json:"error,omitempty"`
+}
+
+func state(healthy bool) string {
+	if healthy {
+		return StateHealthy
+	}
+	return StateUnhealthy
+}
+
+// eventOf converts a health transition to an event
+func eventOf(t base.HealthTransition) Event {
+	old := state(!t.Healthy)
+	if t.Initial {
+		old = StateUnknown
+	}
+	return Event{
+		Time:         t.Time,
+		ChainName:    t.ChainName,
+		HostName:     t.HostName,
+		EndpointType: t.Check,
+		OldState:     old,
+		NewState:     state(t.Healthy),
+		Error:        t.Error,
+	}
+}
+
+// Log appends events to a file and rotates it once it exceeds the maximum
+// size, keeping a number of older files as path.1, path.2 and so on
+type Log struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens the event log for appending
+func Open(c *conf.EventLog) (*Log, error) {
+	l := &Log{
+		path:       c.Path,
+		maxSize:    100 << 20,
+		maxBackups: 5,
+	}
+	if c.MaxSizeMB > 0 {
+		l.maxSize = int64(c.MaxSizeMB) << 20
+	}
+	if c.MaxBackups > 0 {
+		l.maxBackups = c.MaxBackups
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the current file, the caller must hold l.mu or be the constructor
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open event log %s: %w", l.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat event log %s: %w", l.path, err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file to path.1, shifting older files and
+// dropping the oldest, and opens a new file. The caller must hold l.mu.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		glog.Warningf("[eventlog] Failed to close %s: %v", l.path, err)
+	}
+	l.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", l.path, l.maxBackups))
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		glog.Warningf("[eventlog] Failed to rotate %s: %v", l.path, err)
+	}
+	return l.open()
+}
+
+// Append writes an event to the log
+func (l *Log) Append(e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// HandleTransition writes a health transition, it is registered with
+// base.ConsumeTransitions
+func (l *Log) HandleTransition(t base.HealthTransition) {
+	if err := l.Append(eventOf(t)); err != nil {
+		glog.Errorf("[eventlog] Failed to write transition of %s (%s) %s: %v", t.HostName, t.ChainName, t.Check, err)
+	}
+}
+
+// Close closes the event log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Validate checks the event log config
+func Validate(c *conf.EventLog) error {
+	if c == nil {
+		return nil
+	}
+	if c.Path == "" {
+		return fmt.Errorf("event_log: path is required")
+	}
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 {
+		return fmt.Errorf("event_log: max_size_mb and max_backups must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+func TestEventOf(t *testing.T) {
+	at := time.Now()
+	e := eventOf(base.HealthTransition{ChainName: "story", HostName: "node-1", Check: "block_retrieval", Initial: true, Error: "timeout", Time: at})
+	if e.OldState != StateUnknown || e.NewState != StateUnhealthy || e.Error != "timeout" || e.EndpointType != "block_retrieval" {
+		t.Errorf("initial failure = %+v", e)
+	}
+	e = eventOf(base.HealthTransition{ChainName: "story", HostName: "node-1", Check: "block_retrieval", Healthy: true, Time: at})
+	if e.OldState != StateUnhealthy || e.NewState != StateHealthy {
+		t.Errorf("recovery = %+v", e)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	l, err := Open(&conf.EventLog{Path: path, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// Rotate after every event
+	l.maxSize = 1
+
+	for i := 0; i < 4; i++ {
+		if err := l.Append(Event{HostName: "node-1", NewState: StateUnhealthy}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := 0
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e Event
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			lines++
+		}
+		f.Close()
+		if lines != 1 {
+			t.Errorf("%s has %d events, want 1", name, lines)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("backup beyond max_backups kept: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+// Package events is the internal pub/sub of the monitor. Checkers publish
+// health transitions, state changes, block arrivals and check errors, and
+// notifiers, the API and the event log consume them, so features do not
+// have to instrument the checkers themselves.
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind is the type of an event
+type Kind string
+
+const (
+	// KindTransition is a check changing between healthy and unhealthy
+	KindTransition Kind = "transition"
+	// KindState is a checker changing its connection state
+	KindState Kind = "state"
+	// KindBlock is a new head observed by a checker
+	KindBlock Kind = "block"
+	// KindError is a failed check
+	KindError Kind = "error"
+)
+
+// Event is published by a checker. Fields not relevant to the kind are empty.
+type Event struct {
+	Kind          Kind      `json:"kind"`
+	Time          time.Time `json:"time"`
+	ChainName     string    `json:"chain_name"`
+	HostName      string    `json:"hostname"`
+	FailureDomain string    `json:"failure_domain,omitempty"`
+
+	// Check is the check of a transition or error
+	Check string `json:"check,omitempty"`
+	// Healthy is the new health of a transition
+	Healthy bool `json:"healthy"`
+	// Initial is set on the first observation of a check
+	Initial bool   `json:"initial,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	// State and PreviousState are the checker states of a state change
+	State         string `json:"state,omitempty"`
+	PreviousState string `json:"previous_state,omitempty"`
+
+	// Height, Hash and BlockTime describe a new head
+	Height    uint64    `json:"height,omitempty"`
+	Hash      string    `json:"hash,omitempty"`
+	BlockTime time.Time `json:"block_time,omitempty"`
+}
+
+// lossless are the kinds never dropped for a slow consumer, they are rare
+// and a missed transition leaves an alert firing or unnoticed
+var lossless = map[Kind]bool{
+	KindTransition: true,
+}
+
+// Subscription receives the events of its kinds in a buffered channel.
+// Events are dropped while the buffer is full, so a slow consumer does not
+// stall the checkers, except transitions, which queue until the consumer
+// catches up.
+type Subscription struct {
+	name    string
+	kinds   map[Kind]bool
+	ch      chan Event
+	dropped atomic.Uint64
+
+	mu sync.Mutex
+	// pending are the lossless events waiting for room in the buffer, in
+	// order, forwarded by a goroutine while not empty
+	pending []Event
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// deliver sends an event without blocking and reports whether it was
+// delivered or queued. Once events queue, later events queue behind them
+// so the consumer sees them in order.
+func (s *Subscription) deliver(e Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		select {
+		case s.ch <- e:
+			return true
+		default:
+		}
+	}
+	if !lossless[e.Kind] {
+		return false
+	}
+	s.pending = append(s.pending, e)
+	if len(s.pending) == 1 {
+		s.wg.Add(1)
+		go s.forward()
+	}
+	return true
+}
+
+// forward moves the pending events into the buffer as the consumer reads
+// it, until none are left or the subscription is removed
+func (s *Subscription) forward() {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		e := s.pending[0]
+		s.mu.Unlock()
+
+		select {
+		case s.ch <- e:
+		case <-s.done:
+			return
+		}
+
+		s.mu.Lock()
+		s.pending[0] = Event{}
+		s.pending = s.pending[1:]
+		empty := len(s.pending) == 0
+		if empty {
+			s.pending = nil
+		}
+		s.mu.Unlock()
+		if empty {
+			return
+		}
+	}
+}
+
+// C returns the channel of the subscription, it is closed on Unsubscribe
+func (s *Subscription) C() <-chan Event {
+	return s.ch
+}
+
+// Name returns the name of the subscriber
+func (s *Subscription) Name() string {
+	return s.name
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Bus delivers published events to all subscriptions of their kind
+type Bus struct {
+	mu      sync.RWMutex
+	subs    []*Subscription
+	dropped atomic.Uint64
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a subscription to the given kinds, all kinds if none are given
+func (b *Bus) Subscribe(name string, buffer int, kinds ...Kind) *Subscription {
+	s := &Subscription{
+		name: name,
+		ch:   make(chan Event, buffer),
+		done: make(chan struct{}),
+	}
+	if len(kinds) > 0 {
+		s.kinds = make(map[Kind]bool, len(kinds))
+		for _, kind := range kinds {
+			s.kinds[kind] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, s)
+	return s
+}
+
+// Unsubscribe removes a subscription and closes its channel, discarding the
+// events still queued for it
+func (b *Bus) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, sub := range b.subs {
+		if sub == s {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			close(s.done)
+			s.wg.Wait()
+			close(s.ch)
+			return
+		}
+	}
+}
+
+// Publish delivers an event without blocking and returns the number of
+// subscriptions that dropped it
+func (b *Bus) Publish(e Event) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	dropped := 0
+	for _, s := range b.subs {
+		if s.kinds != nil && !s.kinds[e.Kind] {
+			continue
+		}
+		if !s.deliver(e) {
+			s.dropped.Add(1)
+			b.dropped.Add(1)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Dropped returns the number of events dropped by all subscriptions, including removed ones
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Default is the bus the checkers publish to
+var Default = NewBus()
+
+// Publish delivers an event on the default bus
+func Publish(e Event) int {
+	return Default.Publish(e)
+}
+
+// Subscribe adds a subscription to the default bus
+func Subscribe(name string, buffer int, kinds ...Kind) *Subscription {
+	return Default.Subscribe(name, buffer, kinds...)
+}
+
+// Consume calls handler with the events of a subscription in a goroutine
+// until ctx is done, then unsubscribes from bus
+func Consume(ctx context.Context, bus *Bus, s *Subscription, handler func(Event)) {
+	go func() {
+		defer bus.Unsubscribe(s)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-s.ch:
+				if !ok {
+					return
+				}
+				handler(e)
+			}
+		}
+	}()
+}
+
+// Recorder keeps the most recent events, e.g. for the API
+type Recorder struct {
+	mu     sync.Mutex
+	size   int
+	events []Event
+}
+
+func NewRecorder(size int) *Recorder {
+	return &Recorder{size: size}
+}
+
+// Record adds an event, dropping the oldest beyond the size
+func (r *Recorder) Record(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	if len(r.events) > r.size {
+		r.events = r.events[len(r.events)-r.size:]
+	}
+}
+
+// Events returns the recorded events, oldest first
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPublish(t *testing.T) {
+	bus := NewBus()
+	blocks := bus.Subscribe("blocks", 1, KindBlock)
+	all := bus.Subscribe("all", 4)
+
+	bus.Publish(Event{Kind: KindTransition, HostName: "node-1", Check: "block_retrieval"})
+	bus.Publish(Event{Kind: KindBlock, HostName: "node-1", Height: 10})
+	// The buffer of blocks is full
+	if n := bus.Publish(Event{Kind: KindBlock, HostName: "node-1", Height: 11}); n != 1 {
+		t.Errorf("publish dropped the event for %d subscriptions, want 1", n)
+	}
+
+	if e := <-blocks.C(); e.Height != 10 {
+		t.Errorf("block = %+v", e)
+	}
+	if n := blocks.Dropped(); n != 1 {
+		t.Errorf("dropped %d blocks, want 1", n)
+	}
+	if n := len(all.C()); n != 3 {
+		t.Errorf("all received %d events, want 3", n)
+	}
+	if n := bus.Dropped(); n != 1 {
+		t.Errorf("bus dropped %d events, want 1", n)
+	}
+
+	bus.Unsubscribe(all)
+	if _, ok := <-all.C(); !ok {
+		t.Error("buffered events lost on unsubscribe")
+	}
+}
+
+func TestPublishTransitions(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe("alerts", 1, KindTransition, KindBlock)
+
+	bus.Publish(Event{Kind: KindTransition, Check: "http"})
+	// Transitions queue behind the full buffer, blocks are dropped
+	bus.Publish(Event{Kind: KindTransition, Check: "ws"})
+	bus.Publish(Event{Kind: KindBlock, Height: 10})
+	bus.Publish(Event{Kind: KindTransition, Check: "peers"})
+
+	for _, check := range []string{"http", "ws", "peers"} {
+		select {
+		case e := <-sub.C():
+			if e.Check != check {
+				t.Errorf("transition = %+v, want check %s", e, check)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("transition %s not delivered", check)
+		}
+	}
+	if n := sub.Dropped(); n != 1 {
+		t.Errorf("dropped %d events, want 1", n)
+	}
+
+	// Removing a subscription with queued transitions stops its forwarder
+	bus.Publish(Event{Kind: KindTransition, Check: "http"})
+	bus.Publish(Event{Kind: KindTransition, Check: "ws"})
+	bus.Unsubscribe(sub)
+	for range sub.C() {
+	}
+}
+
+func TestConsume(t *testing.T) {
+	bus := NewBus()
+	recorder := NewRecorder(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	sub := bus.Subscribe("recorder", 8)
+	Consume(ctx, bus, sub, func(e Event) {
+		recorder.Record(e)
+		if e.Height == 3 {
+			close(done)
+		}
+	})
+	for height := uint64(1); height <= 3; height++ {
+		bus.Publish(Event{Kind: KindBlock, Height: height})
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("events not consumed")
+	}
+	got := recorder.Events()
+	if len(got) != 2 || got[0].Height != 2 || got[1].Height != 3 {
+		t.Errorf("recorded %+v, want heights 2 and 3", got)
+	}
+}
@@ -0,0 +1,54 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+)
+
+// checkArchive queries state at a historical block, which only archive nodes can serve
+func (chain *EvmCheckerImpl) checkArchive() error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	blockNumber := chain.Archive.BlockNumber
+	if blockNumber == 0 {
+		blockNumber = 1
+	}
+	ctx, cancel := chain.callContext()
+	defer cancel()
+	_, err := chain.http.BalanceAt(ctx, common.Address{}, new(big.Int).SetUint64(blockNumber))
+	return err
+}
+
+func (chain *EvmCheckerImpl) archiveCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Archive.CheckSecond, 300) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Archive.CheckSecond, 300)
+	defer ticker.Stop()
+	loop := chain.StartLoop("archive")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("archive", func() error {
+			err := chain.checkArchive()
+			if err != nil {
+				glog.Errorf("[archiveCheck] Node %s historical state query fail: %v", chain.Evm.HostName, err)
+			}
+			chain.RecordArchiveAvailable(err == nil)
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[archiveCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package evm
+
+import (
+	"math/big"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/golang/glog"
+)
+
+func (chain *EvmCheckerImpl) checkBalances() {
+	if chain.http == nil {
+		return
+	}
+
+	for _, address := range chain.Addresses {
+		ctx, cancel := chain.callContext()
+		wei, err := chain.http.BalanceAt(ctx, common.HexToAddress(address), nil)
+		cancel()
+		if err != nil {
+			glog.Errorf("[checkBalances] Node %s get balance of %s fail: %v", chain.Evm.HostName, address, err)
+			continue
+		}
+
+		weiFloat, _ := new(big.Float).SetInt(wei).Float64()
+		ether, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether)).Float64()
+		base.AccountBalanceWei.WithLabelValues(chain.AddLabelValues(address)...).Set(weiFloat)
+		base.AccountBalanceEther.WithLabelValues(chain.AddLabelValues(address)...).Set(ether)
+		glog.V(5).Infof("[checkBalances] Node %s address %s balance %.6f ether", chain.Evm.HostName, address, ether)
+	}
+}
+
+func (chain *EvmCheckerImpl) balanceCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.BalanceCheckSecond, 60) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.BalanceCheckSecond, 60)
+	defer ticker.Stop()
+	loop := chain.StartLoop("balances")
+	defer loop.Stop()
+
+	for {
+		chain.checkBalances()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[balanceCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
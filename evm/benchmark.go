@@ -0,0 +1,164 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/glog"
+)
+
+// Read benchmark call types
+const (
+	readCallLogs  = "logs"
+	readCallBlock = "block"
+	readCallCall  = "call"
+)
+
+// defaultReadCalls is the suite run when no calls are configured
+var defaultReadCalls = []*conf.ReadCall{
+	{Name: "get_logs", Type: readCallLogs},
+	{Name: "get_block_full", Type: readCallBlock},
+}
+
+// runReadCall executes one benchmark call and returns the number of items it returned
+func (chain *EvmCheckerImpl) runReadCall(call *conf.ReadCall) (int, error) {
+	ctx, cancel := chain.callContext()
+	defer cancel()
+	switch call.Type {
+	case readCallLogs:
+		latest, err := chain.http.BlockNumber(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("block number: %w", err)
+		}
+		rangeBlocks := call.RangeBlocks
+		if rangeBlocks == 0 {
+			rangeBlocks = 1000
+		}
+		var from uint64
+		if latest > rangeBlocks {
+			from = latest - rangeBlocks
+		}
+		query := ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(latest),
+		}
+		for _, address := range call.Addresses {
+			query.Addresses = append(query.Addresses, common.HexToAddress(address))
+		}
+		logs, err := chain.http.FilterLogs(ctx, query)
+		return len(logs), err
+	case readCallBlock:
+		block, err := chain.http.BlockByNumber(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		return len(block.Transactions()), nil
+	case readCallCall:
+		to := common.HexToAddress(call.To)
+		data, err := hexutil.Decode(call.Data)
+		if err != nil {
+			return 0, fmt.Errorf("invalid call data: %w", err)
+		}
+		result, err := chain.http.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+		return len(result), err
+	}
+	return 0, fmt.Errorf("unknown type %q", call.Type)
+}
+
+// checkReadBenchmark runs the read calls one after another and records their latency
+func (chain *EvmCheckerImpl) checkReadBenchmark() error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	calls := chain.ReadBenchmark.Calls
+	if len(calls) == 0 {
+		calls = defaultReadCalls
+	}
+
+	var failed []string
+	for _, call := range calls {
+		startTime := time.Now()
+		items, err := chain.runReadCall(call)
+		duration := time.Since(startTime)
+
+		success := float64(1)
+		if err != nil {
+			success = 0
+			failed = append(failed, call.Name)
+			glog.Errorf("[checkReadBenchmark] Node %s read call %s fail: %v", chain.Evm.HostName, call.Name, err)
+		} else {
+			base.ReadCallDuration.WithLabelValues(chain.AddLabelValues(call.Name)...).Observe(duration.Seconds())
+			glog.V(5).Infof("[checkReadBenchmark] Node %s read call %s returned %d items in %s", chain.Evm.HostName, call.Name, items, duration)
+		}
+		base.ReadCallSuccess.WithLabelValues(chain.AddLabelValues(call.Name)...).Set(success)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("read calls failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (chain *EvmCheckerImpl) readBenchmarkCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.ReadBenchmark.CheckSecond, 900) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.ReadBenchmark.CheckSecond, 900)
+	defer ticker.Stop()
+	loop := chain.StartLoop("read_benchmark")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("read_benchmark", chain.checkReadBenchmark)
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[readBenchmarkCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+// ValidateReadBenchmark checks the read calls of an EVM target
+func ValidateReadBenchmark(benchmark *conf.ReadBenchmark) error {
+	if benchmark == nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	for i, call := range benchmark.Calls {
+		if call == nil || call.Name == "" {
+			return fmt.Errorf("read_benchmark.calls[%d]: name is required", i)
+		}
+		if names[call.Name] {
+			return fmt.Errorf("read_benchmark.calls[%d]: duplicate name %q", i, call.Name)
+		}
+		names[call.Name] = true
+		switch call.Type {
+		case readCallLogs:
+			for _, address := range call.Addresses {
+				if !common.IsHexAddress(address) {
+					return fmt.Errorf("read_benchmark.calls[%d]: invalid address %q", i, address)
+				}
+			}
+		case readCallBlock:
+		case readCallCall:
+			if !common.IsHexAddress(call.To) {
+				return fmt.Errorf("read_benchmark.calls[%d]: invalid to address %q", i, call.To)
+			}
+			if _, err := hexutil.Decode(call.Data); err != nil {
+				return fmt.Errorf("read_benchmark.calls[%d]: invalid data: %w", i, err)
+			}
+		default:
+			return fmt.Errorf("read_benchmark.calls[%d]: type must be logs, block or call", i)
+		}
+	}
+	return nil
+}
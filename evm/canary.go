@@ -0,0 +1,230 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/glog"
+)
+
+// canaryGas is the gas of a plain value transfer
+const canaryGas = 21000
+
+// canarySender is the account of a canary key on a chain. The nodes of a
+// chain often share a key, so their canaries take turns and the account
+// tracks the next nonce, which a lagging node may report too low.
+type canarySender struct {
+	turn chan struct{}
+	// next is the nonce after the last included canary, zero if unknown
+	next uint64
+}
+
+type canarySenderKey struct {
+	chainName string
+	address   common.Address
+}
+
+var (
+	canarySendersMu sync.Mutex
+	canarySenders   = make(map[canarySenderKey]*canarySender)
+)
+
+// canarySenderOf returns the shared account of address on the chain of the checker
+func (chain *EvmCheckerImpl) canarySenderOf(address common.Address) *canarySender {
+	canarySendersMu.Lock()
+	defer canarySendersMu.Unlock()
+	key := canarySenderKey{chain.Evm.ChainName, address}
+	sender, ok := canarySenders[key]
+	if !ok {
+		sender = &canarySender{turn: make(chan struct{}, 1)}
+		canarySenders[key] = sender
+	}
+	return sender
+}
+
+// acquire waits for the turn of the checker to send from the account
+func (s *canarySender) acquire(ctx context.Context) error {
+	select {
+	case s.turn <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *canarySender) release() {
+	<-s.turn
+}
+
+// buildCanaryTx returns a zero value self-transfer of from with nonce
+// nonce, or the pending nonce reported by the node if higher, paying the
+// suggested tip and twice the current base fee
+func (chain *EvmCheckerImpl) buildCanaryTx(from *ecdsa.PrivateKey, nonce uint64) (*types.Transaction, error) {
+	ctx, cancel := chain.callContext()
+	defer cancel()
+	address := crypto.PubkeyToAddress(from.PublicKey)
+
+	chainID, err := chain.http.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	pending, err := chain.http.PendingNonceAt(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("pending nonce: %w", err)
+	}
+	nonce = max(nonce, pending)
+	head, err := chain.http.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("latest header: %w", err)
+	}
+
+	var tx *types.Transaction
+	if head.BaseFee == nil {
+		gasPrice, err := chain.http.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("gas price: %w", err)
+		}
+		tx = types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: canaryGas, To: &address, Value: new(big.Int)})
+	} else {
+		tip, err := chain.http.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("gas tip: %w", err)
+		}
+		feeCap := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+		tx = types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: tip, GasFeeCap: feeCap, Gas: canaryGas, To: &address, Value: new(big.Int)})
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), from)
+}
+
+// waitForReceipt polls the receipt of hash until it is included or ctx ends
+func (chain *EvmCheckerImpl) waitForReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		receipt, err := chain.http.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendCanary submits a canary transaction and measures its inclusion
+// latency. It waits for the other checkers sending from the same key, so
+// their transactions do not replace each other.
+func (chain *EvmCheckerImpl) sendCanary(from *ecdsa.PrivateKey) (time.Duration, error) {
+	if chain.http == nil {
+		return 0, fmt.Errorf("http client not available")
+	}
+
+	sender := chain.canarySenderOf(crypto.PubkeyToAddress(from.PublicKey))
+	if err := sender.acquire(chain.ctx); err != nil {
+		return 0, err
+	}
+	defer sender.release()
+
+	tx, err := chain.buildCanaryTx(from, sender.next)
+	if err != nil {
+		return 0, err
+	}
+	sendCtx, cancelSend := chain.callContext()
+	defer cancelSend()
+	submitted := time.Now()
+	if err := chain.http.SendTransaction(sendCtx, tx); err != nil {
+		// The nonce may be taken by a transaction of another process, ask the node again
+		sender.next = 0
+		return 0, fmt.Errorf("send transaction: %w", err)
+	}
+
+	timeout := 120 * time.Second
+	if chain.Canary.TimeoutSecond > 0 {
+		timeout = time.Duration(chain.Canary.TimeoutSecond) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(chain.ctx, timeout)
+	defer cancel()
+	receipt, err := chain.waitForReceipt(ctx, tx.Hash())
+	if err != nil {
+		sender.next = 0
+		return 0, fmt.Errorf("transaction %s not included: %w", tx.Hash().Hex(), err)
+	}
+	sender.next = tx.Nonce() + 1
+	latency := time.Since(submitted)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return latency, fmt.Errorf("transaction %s reverted in block %d", tx.Hash().Hex(), receipt.BlockNumber.Uint64())
+	}
+	glog.V(5).Infof("[sendCanary] Node %s canary %s included in block %d after %s",
+		chain.Evm.HostName, tx.Hash().Hex(), receipt.BlockNumber.Uint64(), latency)
+	return latency, nil
+}
+
+func (chain *EvmCheckerImpl) canaryCheck() {
+	key, err := crypto.LoadECDSA(chain.Canary.PrivateKeyFile)
+	if err != nil {
+		glog.Errorf("[canaryCheck] Node %s load canary key fail: %v", chain.Evm.HostName, err)
+		return
+	}
+	glog.Infof("[canaryCheck] Node %s sends canary transactions from %s", chain.Evm.HostName, crypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Canary.CheckSecond, 300) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Canary.CheckSecond, 300)
+	defer ticker.Stop()
+	loop := chain.StartLoop("canary")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("canary", func() error {
+			latency, err := chain.sendCanary(key)
+			result := "success"
+			if err != nil {
+				result = "failure"
+				glog.Errorf("[canaryCheck] Node %s canary transaction fail: %v", chain.Evm.HostName, err)
+			} else {
+				base.CanaryInclusionLatency.WithLabelValues(chain.AddLabelValues()...).Set(latency.Seconds())
+				base.CanaryInclusionLatencyHistogram.WithLabelValues(chain.AddLabelValues()...).Observe(latency.Seconds())
+			}
+			base.CanaryTransactions.WithLabelValues(chain.AddLabelValues(result)...).Inc()
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[canaryCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+// ValidateCanary checks that the canary key of an EVM target can be loaded
+func ValidateCanary(canary *conf.Canary) error {
+	if canary == nil {
+		return nil
+	}
+	if canary.PrivateKeyFile == "" {
+		return fmt.Errorf("canary.private_key_file is required")
+	}
+	if _, err := crypto.LoadECDSA(canary.PrivateKeyFile); err != nil {
+		return fmt.Errorf("canary.private_key_file: %w", err)
+	}
+	return nil
+}
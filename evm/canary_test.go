@@ -0,0 +1,39 @@
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"storymonitor/conf"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCanarySender(t *testing.T) {
+	address := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	node1 := &EvmCheckerImpl{Evm: &conf.Evm{ChainName: "story", HostName: "node-01"}}
+	node2 := &EvmCheckerImpl{Evm: &conf.Evm{ChainName: "story", HostName: "node-02"}}
+	other := &EvmCheckerImpl{Evm: &conf.Evm{ChainName: "aeneid", HostName: "node-01"}}
+
+	sender := node1.canarySenderOf(address)
+	if node2.canarySenderOf(address) != sender {
+		t.Error("expected the nodes of a chain to share the account of a key")
+	}
+	if other.canarySenderOf(address) == sender {
+		t.Error("expected each chain to track its own nonce")
+	}
+
+	if err := sender.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sender.acquire(ctx); err == nil {
+		t.Error("expected a second canary to wait for its turn")
+	}
+	sender.release()
+	if err := sender.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sender.release()
+}
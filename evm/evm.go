@@ -2,14 +2,13 @@ package evm
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"storymonitor/base"
 	"storymonitor/conf"
-
-	"github.com/gorilla/websocket"
+	"storymonitor/maintenance"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -26,6 +25,17 @@ type EvmCheckerImpl struct {
 
 	http *client.Client
 	ws   *client.Client
+
+	txRate *base.TxRate
+
+	// httpURLs and wsURLs select the URL among http_url(s) and ws_url(s)
+	httpURLs *base.Failover
+	wsURLs   *base.Failover
+	// switchHttp asks the health check to fail over to another HTTP URL
+	switchHttp atomic.Bool
+
+	// blockNumber validates that eth_blockNumber never goes backwards
+	blockNumber base.Monotonic
 }
 
 func NewEvmCheckerImpl(ctx context.Context, conf *conf.Evm) base.CheckerTrait {
@@ -37,70 +47,169 @@ func NewEvmCheckerImpl(ctx context.Context, conf *conf.Evm) base.CheckerTrait {
 			ChainId:      conf.ChainId,
 			NodeVersion:  conf.NodeVersion,
 			ProtocolName: conf.ProtocolName,
+			DelaySource:  conf.DelaySource,
+
+			ExpectedChainId: conf.ChainId,
+			MinVersion:      conf.MinVersion,
+			FailureDomain:   conf.FailureDomain,
+			Maintenance:     maintenance.New(conf.Maintenance),
 		},
 		ctx: ctx,
 	}
 
+	checker.initURLs()
+	if checker.httpURLs.Configured() {
+		base.RegisterTargetEndpoint("evm", "http", conf.ChainName, conf.HostName, checker.httpURLs.Active())
+	}
+	if checker.wsURLs.Configured() {
+		base.RegisterTargetEndpoint("evm", "ws", conf.ChainName, conf.HostName, checker.wsURLs.Active())
+	}
+	if conf.IpcPath != "" {
+		base.RegisterTargetEndpoint("evm", "ipc", conf.ChainName, conf.HostName, conf.IpcPath)
+	}
+
 	// Set default check interval
 	if checker.CheckSecond == 0 {
 		checker.CheckSecond = 5
 	}
 
+	if conf.Throughput != nil {
+		window := 60 * time.Second
+		if conf.Throughput.WindowSecond > 0 {
+			window = time.Duration(conf.Throughput.WindowSecond) * time.Second
+		}
+		checker.txRate = base.NewTxRate(window)
+	}
+
 	checker.updateClient()
 	return checker
 }
 
+// callContext bounds a check call by the call timeout of the target
+func (chain *EvmCheckerImpl) callContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(chain.ctx, base.CallTimeout(chain.Timeouts))
+}
+
+// initURLs creates the URL selection from the config unless it exists, so
+// checkers created without NewEvmCheckerImpl can connect too
+func (chain *EvmCheckerImpl) initURLs() {
+	if chain.httpURLs == nil {
+		chain.httpURLs = base.NewFailover("http", append([]string{chain.Evm.HttpURL}, chain.Evm.HttpURLs...), chain.Evm.URLStrategy)
+	}
+	if chain.wsURLs == nil {
+		chain.wsURLs = base.NewFailover("ws", append([]string{chain.Evm.WsURL}, chain.Evm.WsURLs...), chain.Evm.URLStrategy)
+	}
+}
+
 func (chain *EvmCheckerImpl) updateClient() {
-	var (
-		err         error
-		c           *rpc.Client
-		nodeVersion string
-	)
+	chain.initURLs()
 
-	nodeName := chain.Evm.HostName
+	// Attempt WebSocket connection, trying the URLs in the order of the strategy
+	for _, url := range chain.wsURLs.Candidates() {
+		if chain.dialWs(url) {
+			break
+		}
+	}
 
-	// Attempt WebSocket connection
-	if chain.WsURL != "" {
-		dialer := websocket.Dialer{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-			HandshakeTimeout: 12 * time.Second,
+	// Attempt HTTP connection
+	for _, url := range chain.httpURLs.Candidates() {
+		if chain.dialHttp(url) {
+			break
 		}
-		c, err = rpc.DialOptions(chain.ctx, chain.WsURL, rpc.WithWebsocketDialer(dialer))
+	}
+
+	// Attempt IPC connection, it takes the roles of the endpoints not configured
+	if chain.IpcPath != "" {
+		ctx, cancel := context.WithTimeout(chain.ctx, base.ConnectTimeout(chain.Timeouts))
+		defer cancel()
+
+		c, err := rpc.DialIPC(ctx, chain.IpcPath)
 		if err != nil {
-			chain.RecordConnectionAttempt("ws", false)
-			glog.Errorf("[updateClient] Node %s ws %s connect fail: %v", nodeName, chain.WsURL, err)
+			if !chain.httpURLs.Configured() {
+				chain.http = nil
+			}
+			chain.RecordConnectionAttempt("ipc", false)
+			glog.Errorf("[updateClient] Node %s ipc %s connect fail: %v", chain.Evm.HostName, chain.IpcPath, err)
 		} else {
-			chain.RecordConnectionAttempt("ws", true)
-			glog.V(5).Infof("[updateClient] Node %s ws %s connect success", nodeName, chain.WsURL)
-			chain.ws = client.NewClient(c)
+			chain.RecordConnectionAttempt("ipc", true)
+			glog.V(5).Infof("[updateClient] Node %s ipc %s connect success", chain.Evm.HostName, chain.IpcPath)
+			ipc := client.NewClient(c)
+			if !chain.wsURLs.Configured() {
+				chain.ws = ipc
+			}
+			if !chain.httpURLs.Configured() {
+				chain.http = ipc
+				chain.identify(ctx)
+			}
 		}
-		// websocket.PingMessage
 	}
+}
 
-	// Attempt HTTP connection
-	if chain.HttpURL != "" {
-		if chain.http, err = client.DialContext(chain.ctx, chain.HttpURL); err != nil {
-			chain.RecordConnectionAttempt("http", false)
-			glog.Errorf("[updateClient] Node %s http %s connect fail: %v", nodeName, chain.HttpURL, err)
-		} else {
-			chain.RecordConnectionAttempt("http", true)
-			glog.V(5).Infof("[updateClient] Node %s http %s connect success", nodeName, chain.HttpURL)
+// dialWs connects the websocket client to url
+func (chain *EvmCheckerImpl) dialWs(url string) bool {
+	ctx, cancel := context.WithTimeout(chain.ctx, base.ConnectTimeout(chain.Timeouts))
+	defer cancel()
 
-			// Get chain ID
-			if chainID, err := chain.http.NetworkID(chain.ctx); err == nil {
-				chain.Evm.ChainId = chainID.String()
-				chain.BaseChecker.ChainId = chainID.String()
-			}
+	opts, err := chain.wsDialOptions(url)
+	var c *rpc.Client
+	if err == nil {
+		c, err = rpc.DialOptions(ctx, url, opts...)
+	}
+	chain.wsURLs.Record(&chain.BaseChecker, url, err == nil)
+	if err != nil {
+		chain.RecordConnectionAttempt("ws", false)
+		glog.Errorf("[updateClient] Node %s ws %s connect fail: %v", chain.Evm.HostName, url, err)
+		return false
+	}
+	chain.RecordConnectionAttempt("ws", true)
+	glog.V(5).Infof("[updateClient] Node %s ws %s connect success", chain.Evm.HostName, url)
+	chain.ws = client.NewClient(c)
+	return true
+}
 
-			// Get node version
-			if err := chain.http.Client().CallContext(chain.ctx, &nodeVersion, "web3_clientVersion"); err == nil {
-				chain.Evm.NodeVersion = nodeVersion
-				chain.BaseChecker.NodeVersion = nodeVersion
-			}
+// dialHttp connects the HTTP client to url. With several URLs the node must
+// answer eth_chainId, since an HTTP dial does not reach the node.
+func (chain *EvmCheckerImpl) dialHttp(url string) bool {
+	ctx, cancel := context.WithTimeout(chain.ctx, base.ConnectTimeout(chain.Timeouts))
+	defer cancel()
+
+	opts, err := chain.httpDialOptions()
+	var c *rpc.Client
+	if err == nil {
+		c, err = rpc.DialOptions(ctx, url, opts...)
+	}
+	if err == nil && chain.httpURLs.Multiple() {
+		if _, err = client.NewClient(c).ChainID(ctx); err != nil {
+			c.Close()
 		}
 	}
+	chain.httpURLs.Record(&chain.BaseChecker, url, err == nil)
+	if err != nil {
+		chain.http = nil
+		chain.RecordConnectionAttempt("http", false)
+		glog.Errorf("[updateClient] Node %s http %s connect fail: %v", chain.Evm.HostName, url, err)
+		return false
+	}
+	chain.RecordConnectionAttempt("http", true)
+	glog.V(5).Infof("[updateClient] Node %s http %s connect success", chain.Evm.HostName, url)
+	chain.http = client.NewClient(c)
+	chain.identify(ctx)
+	return true
+}
+
+// identify fetches the chain ID and client version of a newly connected node
+func (chain *EvmCheckerImpl) identify(ctx context.Context) {
+	if chainID, err := chain.http.NetworkID(ctx); err == nil {
+		chain.Evm.ChainId = chainID.String()
+		chain.BaseChecker.ChainId = chainID.String()
+		chain.CheckChainId(chainID.String())
+	}
+
+	var nodeVersion string
+	if err := chain.http.Client().CallContext(ctx, &nodeVersion, "web3_clientVersion"); err == nil {
+		chain.Evm.NodeVersion = nodeVersion
+		chain.SetNodeVersion(nodeVersion)
+	}
 }
 
 func (chain *EvmCheckerImpl) subscribeNewHead() (sub ethereum.Subscription, headers chan *types.Header, err error) {
@@ -110,30 +219,48 @@ func (chain *EvmCheckerImpl) subscribeNewHead() (sub ethereum.Subscription, head
 		return nil, nil, fmt.Errorf("websocket connection not available for node %s", nodeName)
 	}
 
-	sub, err = chain.ws.SubscribeNewHead(chain.ctx, headers)
+	ctx, cancel := context.WithTimeout(chain.ctx, base.HandshakeTimeout(chain.Timeouts))
+	defer cancel()
+	sub, err = chain.ws.SubscribeNewHead(ctx, headers)
 	if err != nil {
-		glog.Errorf("[subscribeNewHead] Node %s ws %s subscribe newhead fail: %v", nodeName, chain.WsURL, err)
+		glog.Errorf("[subscribeNewHead] Node %s ws %s subscribe newhead fail: %v", nodeName, chain.wsURLs.Active(), err)
+		return sub, headers, err
 	}
-	return sub, headers, err
+	chain.RecordSubscribed(base.SubscriptionHeads)
+	return sub, headers, nil
 }
 
 func (chain *EvmCheckerImpl) checkGetBlockByNumber() {
 	chain.HealthCheckOperation("block_retrieval", func() error {
-		_, err := chain.http.BlockNumber(chain.ctx)
+		ctx, cancel := chain.callContext()
+		defer cancel()
+		number, err := chain.http.BlockNumber(ctx)
 		if err != nil {
+			chain.SetState(base.StateDegraded)
+			if chain.httpURLs.Multiple() {
+				chain.switchHttp.Store(true)
+			}
 			return err
 		}
 
-		return nil
+		chain.SetState(base.StateSubscribed)
+		return chain.blockNumber.Check("block number", number)
 	})
 }
 
 func (chain *EvmCheckerImpl) clientHealthCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 5) {
+		return
+	}
+
 	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
 	defer ticker.Stop()
+	loop := chain.StartLoop("client")
+	defer loop.Stop()
 
+	lastVersionCheck := time.Now()
 	for {
-		if !base.WaitForContextOrTicker(chain.ctx, ticker) {
+		if !loop.Wait(chain.ctx, ticker) {
 			glog.V(5).Info("[clientHealthCheck] Received stop signal, exited")
 			return
 		}
@@ -141,22 +268,68 @@ func (chain *EvmCheckerImpl) clientHealthCheck() {
 		if chain.http == nil {
 			glog.V(5).Infof("[clientHealthCheck] node: %s rebuilding chain client", chain.Evm.HostName)
 			chain.updateClient()
+			lastVersionCheck = time.Now()
+		} else if chain.switchHttp.Swap(false) {
+			glog.Warningf("[clientHealthCheck] node: %s http %s failing, trying the other URLs", chain.Evm.HostName, chain.httpURLs.Active())
+			for _, url := range chain.httpURLs.Candidates() {
+				if chain.dialHttp(url) {
+					break
+				}
+			}
+			lastVersionCheck = time.Now()
 		} else {
 			glog.V(5).Infof("[clientHealthCheck] node: %s, chain: %s, connection normal", chain.Evm.HostName, chain.Evm.ChainName)
+			if time.Since(lastVersionCheck) >= versionRefreshInterval {
+				chain.refreshNodeVersion()
+				lastVersionCheck = time.Now()
+			}
 		}
 	}
 }
 
+// versionRefreshInterval is how often the client version of a connected node is refreshed
+const versionRefreshInterval = time.Minute
+
+// refreshNodeVersion fetches the client version, so upgrades are noticed without a reconnect
+func (chain *EvmCheckerImpl) refreshNodeVersion() {
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	var nodeVersion string
+	if err := chain.http.Client().CallContext(ctx, &nodeVersion, "web3_clientVersion"); err != nil {
+		glog.V(2).Infof("[refreshNodeVersion] Node %s web3_clientVersion fail: %v", chain.Evm.HostName, err)
+		return
+	}
+	chain.Evm.NodeVersion = nodeVersion
+	chain.SetNodeVersion(nodeVersion)
+}
+
 func (chain *EvmCheckerImpl) subscribe() {
 	nodeName := chain.Evm.HostName
 	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
 	defer ticker.Stop()
+	loop := chain.StartLoop("subscribe")
+	defer loop.Stop()
 
 	var sub ethereum.Subscription
 	var headers chan *types.Header
 	var err error
 
 	ensureSubscription := func() {
+		defer func() {
+			switch {
+			case sub != nil:
+				if chain.GetState() != base.StateDegraded {
+					chain.SetState(base.StateSubscribed)
+				}
+			case chain.http != nil:
+				// Reachable over HTTP but without a head subscription
+				chain.SetState(base.StateDegraded)
+			default:
+				chain.SetState(base.StateDown)
+			}
+		}()
+
 		// First ensure we have a WebSocket client
 		if chain.ws == nil {
 			chain.updateClient()
@@ -182,8 +355,11 @@ func (chain *EvmCheckerImpl) subscribe() {
 		}
 	}
 
+	chain.SetState(base.StateConnecting)
 	ensureSubscription()
 
+	drill := base.NewDrill(chain.ctx, chain.ReconnectDrill)
+
 	for {
 		select {
 		case <-chain.ctx.Done():
@@ -196,22 +372,43 @@ func (chain *EvmCheckerImpl) subscribe() {
 		case header := <-headers:
 			if header == nil {
 				glog.Warningf("[subscribe] Received nil header for node %s, reconnecting", nodeName)
+				chain.SetState(base.StateConnecting)
 				if sub != nil {
 					sub.Unsubscribe()
 					sub = nil
+					chain.RecordUnsubscribed(base.SubscriptionHeads)
 				}
 				chain.updateClient()
 				ensureSubscription()
 				continue
 			}
 
+			drill.Recovered(&chain.BaseChecker)
 			chain.UpdateLastBlockTime()
-			delaySecond := float64(time.Now().Unix() - int64(header.Time))
-			chain.RecordBlockProcessingDelay(delaySecond)
+			chain.RecordHead(header.Number.Uint64(), header.Hash(), time.Unix(int64(header.Time), 0))
+			delaySecond := chain.RecordBlockDelay(time.Unix(int64(header.Time), 0))
+			chain.RecordBlockGas(header.GasUsed, header.GasLimit)
 			glog.V(5).Infof("[subscribe] %s Node BlockNumber %d Delay %.2f s", nodeName, header.Number.Uint64(), delaySecond)
 			chain.checkGetBlockByNumber()
+			if chain.txRate != nil {
+				chain.recordThroughput(header)
+			}
+
+		case tickAt := <-ticker.C:
+			loop.Tick(tickAt)
+			drill.CheckTimeout(&chain.BaseChecker)
+			ensureSubscription()
+			loop.End()
 
-		case <-ticker.C:
+		case <-drill.Channel():
+			drill.Begin(&chain.BaseChecker)
+			chain.SetState(base.StateConnecting)
+			if sub != nil {
+				sub.Unsubscribe()
+				sub = nil
+				chain.RecordUnsubscribed(base.SubscriptionHeads)
+			}
+			chain.updateClient()
 			ensureSubscription()
 
 		case err, ok := <-sub.Err():
@@ -224,8 +421,10 @@ func (chain *EvmCheckerImpl) subscribe() {
 				if sub != nil {
 					sub.Unsubscribe()
 					sub = nil
+					chain.RecordUnsubscribed(base.SubscriptionHeads)
 				}
 				// Force reconnect on subscription errors
+				chain.SetState(base.StateConnecting)
 				chain.updateClient()
 				ensureSubscription()
 			}
@@ -239,6 +438,76 @@ func (chain *EvmCheckerImpl) Start() {
 	// Start health check
 	go chain.clientHealthCheck()
 
+	// Start synthetic contract call probes
+	if len(chain.CallProbes) > 0 {
+		go chain.callProbeCheck()
+	}
+
+	// Start logs subscription monitoring
+	if chain.LogFilter != nil {
+		go chain.subscribeLogs()
+	}
+
+	// Start archive data availability probe
+	if chain.Archive != nil {
+		go chain.archiveCheck()
+	}
+
+	// Start trace namespace probe
+	if chain.Trace != nil {
+		go chain.traceCheck()
+	}
+
+	// Start RPC method capability probing
+	if len(chain.RpcMethods) > 0 {
+		go chain.methodCheck()
+	}
+
+	// Start safe and finalized head tracking
+	if chain.Finality != nil {
+		go chain.finalityCheck()
+	}
+
+	// Start priority fee monitoring
+	if chain.FeeHistory != nil {
+		go chain.feeCheck()
+	}
+
+	// Start stuck transaction detection
+	if chain.NonceWatch != nil && len(chain.NonceWatch.Addresses) > 0 {
+		go chain.nonceCheck()
+	}
+
+	// Start read path benchmark
+	if chain.ReadBenchmark != nil {
+		go chain.readBenchmarkCheck()
+	}
+
+	// Start canary transactions
+	if chain.Canary != nil {
+		go chain.canaryCheck()
+	}
+
+	// Start account balance monitoring
+	if len(chain.Addresses) > 0 {
+		go chain.balanceCheck()
+	}
+
+	// Start network latency probe
+	if chain.Ping != nil {
+		go chain.PingCheck(chain.ctx, chain.Ping, base.PingHost(chain.Ping, chain.httpURLs.Active(), chain.wsURLs.Active()))
+	}
+
+	// Start host metrics scraping
+	if chain.NodeExporter != nil {
+		go chain.NodeExporterCheck(chain.ctx, chain.NodeExporter, chain.Proxy)
+	}
+
+	// Start native metrics proxying
+	if chain.NativeMetrics != nil {
+		go chain.NativeMetricsCheck(chain.ctx, chain.NativeMetrics, chain.Proxy, DefaultNativeMetrics)
+	}
+
 	// Start block subscription
 	chain.subscribe()
 }
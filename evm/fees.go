@@ -0,0 +1,103 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/golang/glog"
+)
+
+// defaultFeePercentiles are the reward percentiles requested when none are configured
+var defaultFeePercentiles = []float64{10, 50, 90}
+
+// toGwei converts wei to gwei
+func toGwei(wei *big.Int) float64 {
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.GWei)).Float64()
+	return gwei
+}
+
+// checkFees exports the priority fee percentiles of recent blocks from
+// eth_feeHistory and the priority fee suggested by the node
+func (chain *EvmCheckerImpl) checkFees() error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	blockCount := chain.FeeHistory.BlockCount
+	if blockCount == 0 {
+		blockCount = 20
+	}
+	percentiles := chain.FeeHistory.Percentiles
+	if len(percentiles) == 0 {
+		percentiles = defaultFeePercentiles
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	history, err := chain.http.FeeHistory(ctx, blockCount, nil, percentiles)
+	if err != nil {
+		return fmt.Errorf("eth_feeHistory: %w", err)
+	}
+	for i, percentile := range percentiles {
+		sum, n := new(big.Int), 0
+		for _, rewards := range history.Reward {
+			if i < len(rewards) && rewards[i] != nil {
+				sum.Add(sum, rewards[i])
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		average := toGwei(sum) / float64(n)
+		base.PriorityFee.WithLabelValues(chain.AddLabelValues(strconv.FormatFloat(percentile, 'f', -1, 64))...).Set(average)
+	}
+	if len(history.BaseFee) > 0 {
+		// The last base fee is the one of the next block
+		base.BaseFee.WithLabelValues(chain.AddLabelValues()...).Set(toGwei(history.BaseFee[len(history.BaseFee)-1]))
+	}
+
+	tip, err := chain.http.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("eth_maxPriorityFeePerGas: %w", err)
+	}
+	suggested := toGwei(tip)
+	base.SuggestedPriorityFee.WithLabelValues(chain.AddLabelValues()...).Set(suggested)
+	glog.V(5).Infof("[checkFees] Node %s suggested priority fee %.4f gwei", chain.Evm.HostName, suggested)
+
+	if chain.FeeHistory.MaxSuggestedGwei > 0 && suggested > chain.FeeHistory.MaxSuggestedGwei {
+		return fmt.Errorf("suggested priority fee %.4f gwei above threshold %.4f gwei", suggested, chain.FeeHistory.MaxSuggestedGwei)
+	}
+	return nil
+}
+
+func (chain *EvmCheckerImpl) feeCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.FeeHistory.CheckSecond, 60) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.FeeHistory.CheckSecond, 60)
+	defer ticker.Stop()
+	loop := chain.StartLoop("fees")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("fee_history", func() error {
+			err := chain.checkFees()
+			if err != nil {
+				glog.Errorf("[feeCheck] Node %s fee probe fail: %v", chain.Evm.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[feeCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package evm
+
+import (
+	"fmt"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/glog"
+)
+
+// finalityTags are the block tags queried by the finality probe, latest first
+var finalityTags = []string{"latest", "safe", "finalized"}
+
+// checkFinality fetches the latest, safe and finalized headers in one batch
+// and exports how far the safe and finalized heads trail latest
+func (chain *EvmCheckerImpl) checkFinality() error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	headers := make([]*types.Header, len(finalityTags))
+	batch := make([]rpc.BatchElem, len(finalityTags))
+	for i, tag := range finalityTags {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{tag, false},
+			Result: &headers[i],
+		}
+	}
+	ctx, cancel := chain.callContext()
+	defer cancel()
+	if err := chain.http.Client().BatchCallContext(ctx, batch); err != nil {
+		return err
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return fmt.Errorf("%s block: %w", finalityTags[i], elem.Error)
+		}
+		if headers[i] == nil {
+			return fmt.Errorf("%s block not found", finalityTags[i])
+		}
+	}
+
+	latest := headers[0]
+	base.FinalityBlockHeight.WithLabelValues(chain.AddLabelValues("latest")...).Set(float64(latest.Number.Uint64()))
+	var finalizedLag uint64
+	for i, tag := range finalityTags[1:] {
+		header := headers[i+1]
+		var lag uint64
+		if latest.Number.Uint64() > header.Number.Uint64() {
+			lag = latest.Number.Uint64() - header.Number.Uint64()
+		}
+		base.FinalityBlockHeight.WithLabelValues(chain.AddLabelValues(tag)...).Set(float64(header.Number.Uint64()))
+		base.FinalityLagBlocks.WithLabelValues(chain.AddLabelValues(tag)...).Set(float64(lag))
+		base.FinalityLagSeconds.WithLabelValues(chain.AddLabelValues(tag)...).Set(float64(latest.Time) - float64(header.Time))
+		if tag == "finalized" {
+			finalizedLag = lag
+		}
+	}
+	glog.V(5).Infof("[checkFinality] Node %s latest %d safe %d finalized %d", chain.Evm.HostName,
+		latest.Number.Uint64(), headers[1].Number.Uint64(), headers[2].Number.Uint64())
+
+	if chain.Finality.MaxLagBlocks > 0 && finalizedLag > chain.Finality.MaxLagBlocks {
+		return fmt.Errorf("finalized head is %d blocks behind latest, threshold %d", finalizedLag, chain.Finality.MaxLagBlocks)
+	}
+	return nil
+}
+
+func (chain *EvmCheckerImpl) finalityCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Finality.CheckSecond, 30) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Finality.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := chain.StartLoop("finality")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("finality", func() error {
+			err := chain.checkFinality()
+			if err != nil {
+				glog.Errorf("[finalityCheck] Node %s finality probe fail: %v", chain.Evm.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[finalityCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
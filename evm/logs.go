@@ -0,0 +1,97 @@
+package evm
+
+import (
+	"context"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+)
+
+func (chain *EvmCheckerImpl) logFilterQuery() ethereum.FilterQuery {
+	query := ethereum.FilterQuery{}
+	for _, address := range chain.LogFilter.Addresses {
+		query.Addresses = append(query.Addresses, common.HexToAddress(address))
+	}
+	for _, alternatives := range chain.LogFilter.Topics {
+		var topics []common.Hash
+		for _, topic := range alternatives {
+			topics = append(topics, common.HexToHash(topic))
+		}
+		query.Topics = append(query.Topics, topics)
+	}
+	return query
+}
+
+func (chain *EvmCheckerImpl) subscribeLogs() {
+	nodeName := chain.Evm.HostName
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
+	defer ticker.Stop()
+	loop := chain.StartLoop("logs")
+	defer loop.Stop()
+
+	var (
+		sub       ethereum.Subscription
+		logs      chan types.Log
+		subErr    <-chan error
+		lastEvent = time.Now()
+	)
+
+	ensureSubscription := func() {
+		if sub != nil || chain.ws == nil {
+			return
+		}
+		logs = make(chan types.Log)
+		ctx, cancel := context.WithTimeout(chain.ctx, base.HandshakeTimeout(chain.Timeouts))
+		s, err := chain.ws.SubscribeFilterLogs(ctx, chain.logFilterQuery(), logs)
+		cancel()
+		if err != nil {
+			glog.Errorf("[subscribeLogs] Node %s ws %s subscribe logs fail: %v", nodeName, chain.wsURLs.Active(), err)
+			chain.RecordHealthStatus("logs_subscription", false)
+			return
+		}
+		sub = s
+		subErr = sub.Err()
+		chain.RecordHealthStatus("logs_subscription", true)
+		chain.RecordSubscribed(base.SubscriptionLogs)
+	}
+
+	ensureSubscription()
+
+	for {
+		select {
+		case <-chain.ctx.Done():
+			glog.V(5).Info("[subscribeLogs] Received stop signal, exited")
+			if sub != nil {
+				sub.Unsubscribe()
+			}
+			return
+
+		case log := <-logs:
+			lastEvent = time.Now()
+			base.LogEventsReceived.WithLabelValues(chain.AddLabelValues()...).Inc()
+			base.LogLastEventAge.WithLabelValues(chain.AddLabelValues()...).Set(0)
+			glog.V(5).Infof("[subscribeLogs] %s log from %s at block %d", nodeName, log.Address.Hex(), log.BlockNumber)
+
+		case err := <-subErr:
+			glog.Errorf("[subscribeLogs] Logs subscription error for node %s: %v", nodeName, err)
+			chain.RecordHealthStatus("logs_subscription", false)
+			if sub != nil {
+				sub.Unsubscribe()
+				sub = nil
+				subErr = nil
+				chain.RecordUnsubscribed(base.SubscriptionLogs)
+			}
+
+		case tickAt := <-ticker.C:
+			loop.Tick(tickAt)
+			base.LogLastEventAge.WithLabelValues(chain.AddLabelValues()...).Set(time.Since(lastEvent).Seconds())
+			ensureSubscription()
+			loop.End()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/glog"
+)
+
+// methodNotFound is the JSON-RPC error code for unknown or disabled methods
+const methodNotFound = -32601
+
+// methodParams decodes the configured JSON array of call parameters
+func methodParams(m *conf.RpcMethod) ([]interface{}, error) {
+	if m.Params == "" {
+		return nil, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(m.Params), &raw); err != nil {
+		return nil, fmt.Errorf("params must be a JSON array: %w", err)
+	}
+	params := make([]interface{}, len(raw))
+	for i := range raw {
+		params[i] = raw[i]
+	}
+	return params, nil
+}
+
+// probeMethod reports whether the node serves a method. Errors other than
+// "method not found" still prove the method exists. A transport failure is
+// returned as an error since it says nothing about the method.
+func (chain *EvmCheckerImpl) probeMethod(m *conf.RpcMethod) (bool, error) {
+	params, err := methodParams(m)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	var result json.RawMessage
+	err = chain.http.Client().CallContext(ctx, &result, m.Method, params...)
+	if err == nil {
+		return true, nil
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		if rpcErr.ErrorCode() == methodNotFound {
+			return false, nil
+		}
+		msg := strings.ToLower(rpcErr.Error())
+		if strings.Contains(msg, "does not exist") || strings.Contains(msg, "not available") || strings.Contains(msg, "not supported") {
+			return false, nil
+		}
+		return true, nil
+	}
+	return false, err
+}
+
+func (chain *EvmCheckerImpl) checkMethods() {
+	if chain.http == nil {
+		return
+	}
+
+	for _, m := range chain.RpcMethods {
+		available, err := chain.probeMethod(m)
+		if err != nil {
+			glog.Errorf("[checkMethods] Node %s probe %s fail: %v", chain.Evm.HostName, m.Method, err)
+			continue
+		}
+
+		value := float64(0)
+		if available {
+			value = 1
+		} else {
+			glog.Warningf("[checkMethods] Node %s does not serve %s", chain.Evm.HostName, m.Method)
+		}
+		base.RPCMethodAvailable.WithLabelValues(chain.AddLabelValues(m.Method)...).Set(value)
+	}
+}
+
+func (chain *EvmCheckerImpl) methodCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, 0, 300) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(0, 300)
+	defer ticker.Stop()
+	loop := chain.StartLoop("methods")
+	defer loop.Stop()
+
+	for {
+		chain.checkMethods()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[methodCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+// ValidateRpcMethods checks the configured method probes
+func ValidateRpcMethods(methods []*conf.RpcMethod) error {
+	for i, m := range methods {
+		if m == nil || m.Method == "" {
+			return fmt.Errorf("rpc_methods[%d]: method is required", i)
+		}
+		if _, err := methodParams(m); err != nil {
+			return fmt.Errorf("rpc_methods[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
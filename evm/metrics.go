@@ -0,0 +1,15 @@
+package evm
+
+// DefaultNativeMetrics are the geth metrics proxied when native_metrics lists
+// none, covering the database size, peering, the transaction pool and heads
+var DefaultNativeMetrics = []string{
+	"eth_db_chaindata_disk_size",
+	"p2p_peers",
+	"txpool_pending",
+	"txpool_queued",
+	"txpool_slots",
+	"chain_head_block",
+	"chain_head_header",
+	"chain_head_finalized",
+	"chain_head_safe",
+}
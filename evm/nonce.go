@@ -0,0 +1,113 @@
+package evm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+)
+
+// nonceState is the last observed latest nonce of an address and since when
+// its pending transactions have not been mined
+type nonceState struct {
+	latest     uint64
+	stuckSince time.Time
+}
+
+// update records the nonces of an address and returns how long the gap has
+// existed without the latest nonce advancing
+func (s *nonceState) update(latest, pending uint64, now time.Time) time.Duration {
+	if pending <= latest {
+		s.latest = latest
+		s.stuckSince = time.Time{}
+		return 0
+	}
+	if s.stuckSince.IsZero() || latest != s.latest {
+		s.stuckSince = now
+	}
+	s.latest = latest
+	return now.Sub(s.stuckSince)
+}
+
+// checkNonces compares the latest and pending nonces of the watched addresses
+func (chain *EvmCheckerImpl) checkNonces(states map[string]*nonceState) error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	maxStuck := 300 * time.Second
+	if chain.NonceWatch.MaxStuckSecond > 0 {
+		maxStuck = time.Duration(chain.NonceWatch.MaxStuckSecond) * time.Second
+	}
+
+	var stuck []string
+	now := time.Now()
+	for _, address := range chain.NonceWatch.Addresses {
+		account := common.HexToAddress(address)
+		ctx, cancel := chain.callContext()
+		latest, err := chain.http.NonceAt(ctx, account, nil)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("latest nonce of %s: %w", address, err)
+		}
+		pending, err := chain.http.PendingNonceAt(ctx, account)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("pending nonce of %s: %w", address, err)
+		}
+
+		state, ok := states[address]
+		if !ok {
+			state = &nonceState{}
+			states[address] = state
+		}
+		age := state.update(latest, pending, now)
+
+		var gap uint64
+		if pending > latest {
+			gap = pending - latest
+		}
+		base.NonceGap.WithLabelValues(chain.AddLabelValues(address)...).Set(float64(gap))
+		base.NonceGapAge.WithLabelValues(chain.AddLabelValues(address)...).Set(age.Seconds())
+		glog.V(5).Infof("[checkNonces] Node %s address %s latest %d pending %d", chain.Evm.HostName, address, latest, pending)
+
+		if age > maxStuck {
+			stuck = append(stuck, fmt.Sprintf("%s (%d txs for %s)", address, gap, age.Round(time.Second)))
+		}
+	}
+	if len(stuck) > 0 {
+		return fmt.Errorf("pending transactions stuck: %s", strings.Join(stuck, ", "))
+	}
+	return nil
+}
+
+func (chain *EvmCheckerImpl) nonceCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.NonceWatch.CheckSecond, 30) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.NonceWatch.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := chain.StartLoop("nonce")
+	defer loop.Stop()
+
+	states := make(map[string]*nonceState)
+	for {
+		chain.HealthCheckOperation("nonce", func() error {
+			err := chain.checkNonces(states)
+			if err != nil {
+				glog.Errorf("[nonceCheck] Node %s nonce check fail: %v", chain.Evm.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[nonceCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
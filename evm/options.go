@@ -0,0 +1,82 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+// Option configures an EVM checker created with NewEvmChecker
+type Option func(*options)
+
+type options struct {
+	conf      conf.Evm
+	callbacks []base.HealthCallback
+}
+
+// WithConfig starts from a copy of c, the other options override its fields
+func WithConfig(c *conf.Evm) Option {
+	return func(o *options) {
+		o.conf = *c
+	}
+}
+
+// WithNode sets the chain_name and hostname labels of the node
+func WithNode(chainName, hostName string) Option {
+	return func(o *options) {
+		o.conf.ChainName = chainName
+		o.conf.HostName = hostName
+	}
+}
+
+// WithHTTPURL sets the HTTP JSON-RPC endpoint
+func WithHTTPURL(url string) Option {
+	return func(o *options) {
+		o.conf.HttpURL = url
+	}
+}
+
+// WithWsURL sets the WebSocket JSON-RPC endpoint used for head subscriptions
+func WithWsURL(url string) Option {
+	return func(o *options) {
+		o.conf.WsURL = url
+	}
+}
+
+// WithCheckInterval sets the interval of the health checks, rounded to seconds
+func WithCheckInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.conf.CheckSecond = int(interval.Round(time.Second) / time.Second)
+	}
+}
+
+// WithHealthCallback adds a callback receiving every check outcome of the checker
+func WithHealthCallback(fn base.HealthCallback) Option {
+	return func(o *options) {
+		o.callbacks = append(o.callbacks, fn)
+	}
+}
+
+// NewEvmChecker creates an EVM checker for programs embedding the monitor.
+// The checker runs once Start is called and stops with ctx.
+func NewEvmChecker(ctx context.Context, opts ...Option) (*EvmCheckerImpl, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.conf.ChainName == "" || o.conf.HostName == "" {
+		return nil, fmt.Errorf("chain name and host name are required")
+	}
+	if o.conf.HttpURL == "" && o.conf.WsURL == "" && o.conf.IpcPath == "" {
+		return nil, fmt.Errorf("an HTTP, WebSocket or IPC endpoint is required")
+	}
+
+	checker := NewEvmCheckerImpl(ctx, &o.conf).(*EvmCheckerImpl)
+	for _, fn := range o.callbacks {
+		checker.OnHealthCheck(fn)
+	}
+	return checker, nil
+}
@@ -0,0 +1,111 @@
+package evm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/glog"
+)
+
+func (chain *EvmCheckerImpl) runCallProbe(probe *conf.CallProbe) error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	data, err := hexutil.Decode(probe.Data)
+	if err != nil {
+		return fmt.Errorf("invalid call data: %w", err)
+	}
+	to := common.HexToAddress(probe.To)
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+	result, err := chain.http.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return err
+	}
+
+	if probe.ExpectedPrefix != "" {
+		expected, err := hexutil.Decode(probe.ExpectedPrefix)
+		if err != nil {
+			return fmt.Errorf("invalid expected prefix: %w", err)
+		}
+		if !bytes.HasPrefix(result, expected) {
+			return fmt.Errorf("unexpected result %s", hexutil.Encode(result))
+		}
+	}
+	return nil
+}
+
+func (chain *EvmCheckerImpl) checkCallProbes() {
+	for _, probe := range chain.CallProbes {
+		startTime := time.Now()
+		err := chain.runCallProbe(probe)
+		duration := time.Since(startTime)
+
+		success := float64(1)
+		if err != nil {
+			success = 0
+			glog.Errorf("[checkCallProbes] Node %s probe %s fail: %v", chain.Evm.HostName, probe.Name, err)
+		}
+		base.ContractProbeSuccess.WithLabelValues(chain.AddLabelValues(probe.Name)...).Set(success)
+		base.ContractProbeDuration.WithLabelValues(chain.AddLabelValues(probe.Name)...).Set(float64(duration.Milliseconds()))
+	}
+}
+
+func (chain *EvmCheckerImpl) callProbeCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 5) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
+	defer ticker.Stop()
+	loop := chain.StartLoop("call_probes")
+	defer loop.Stop()
+
+	for {
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[callProbeCheck] Received stop signal, exited")
+			return
+		}
+		chain.checkCallProbes()
+	}
+}
+
+// validateCallProbe checks a probe definition before the checker starts
+func validateCallProbe(probe *conf.CallProbe) error {
+	if probe.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !common.IsHexAddress(probe.To) {
+		return fmt.Errorf("invalid to address %q", probe.To)
+	}
+	if _, err := hexutil.Decode(probe.Data); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	if probe.ExpectedPrefix != "" && !strings.HasPrefix(probe.ExpectedPrefix, "0x") {
+		return fmt.Errorf("expected_prefix must be 0x-prefixed hex")
+	}
+	return nil
+}
+
+// ValidateCallProbes checks all probe definitions of an EVM target
+func ValidateCallProbes(probes []*conf.CallProbe) error {
+	for i, probe := range probes {
+		if probe == nil {
+			return fmt.Errorf("call_probes[%d] is empty", i)
+		}
+		if err := validateCallProbe(probe); err != nil {
+			return fmt.Errorf("call_probes[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package evm
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+)
+
+// recordThroughput counts the transactions of a new head and updates the rolling TPS
+func (chain *EvmCheckerImpl) recordThroughput(header *types.Header) {
+	if chain.http == nil {
+		return
+	}
+	ctx, cancel := chain.callContext()
+	defer cancel()
+	txs, err := chain.http.TransactionCount(ctx, header.Hash())
+	if err != nil {
+		glog.Errorf("[recordThroughput] Node %s transaction count of block %d fail: %v", chain.Evm.HostName, header.Number.Uint64(), err)
+		return
+	}
+	tps := chain.txRate.Add(time.Unix(int64(header.Time), 0), int(txs))
+	chain.RecordBlockTxs(int(txs))
+	chain.RecordTPS(tps)
+	glog.V(5).Infof("[recordThroughput] Node %s BlockNumber %d Txs %d TPS %.2f", chain.Evm.HostName, header.Number.Uint64(), txs, tps)
+}
@@ -0,0 +1,72 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/glog"
+)
+
+const (
+	TraceMethodDebug  = "debug_traceBlockByNumber"
+	TraceMethodParity = "trace_block"
+)
+
+// checkTrace traces the latest block through the configured trace namespace,
+// only tracing top-level calls to keep the probe cheap
+func (chain *EvmCheckerImpl) checkTrace() error {
+	if chain.http == nil {
+		return fmt.Errorf("http client not available")
+	}
+
+	ctx, cancel := chain.callContext()
+	defer cancel()
+
+	number, err := chain.http.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	block := hexutil.EncodeUint64(number)
+
+	var result json.RawMessage
+	switch chain.Trace.Method {
+	case TraceMethodParity:
+		err = chain.http.Client().CallContext(ctx, &result, TraceMethodParity, block)
+	default:
+		tracer := map[string]interface{}{
+			"tracer":       "callTracer",
+			"tracerConfig": map[string]interface{}{"onlyTopCall": true},
+		}
+		err = chain.http.Client().CallContext(ctx, &result, TraceMethodDebug, block, tracer)
+	}
+	return err
+}
+
+func (chain *EvmCheckerImpl) traceCheck() {
+	if !base.WaitForPhaseOffset(chain.ctx, chain.Trace.CheckSecond, 60) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.Trace.CheckSecond, 60)
+	defer ticker.Stop()
+	loop := chain.StartLoop("trace")
+	defer loop.Stop()
+
+	for {
+		chain.HealthCheckOperation("trace", func() error {
+			err := chain.checkTrace()
+			if err != nil {
+				glog.Errorf("[traceCheck] Node %s trace probe fail: %v", chain.Evm.HostName, err)
+			}
+			return err
+		})
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[traceCheck] Received stop signal, exited")
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package evm
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+
+	"storymonitor/base"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/glog"
+	"github.com/gorilla/websocket"
+)
+
+// headerOption sets the configured headers on HTTP requests and the websocket handshake
+func (chain *EvmCheckerImpl) headerOption() rpc.ClientOption {
+	return rpc.WithHeaders(base.TargetHeader(chain.Headers, chain.BearerToken, chain.BasicAuth))
+}
+
+// wsDialOptions returns the RPC client options for the websocket endpoint rawURL
+func (chain *EvmCheckerImpl) wsDialOptions(rawURL string) ([]rpc.ClientOption, error) {
+	tlsConfig, err := base.NewTLSConfig(chain.TLS)
+	if err != nil {
+		return nil, err
+	}
+	chain.WatchCertExpiry("ws", tlsConfig)
+
+	dialer := websocket.Dialer{
+		NetDialContext:   base.LimitDial(nil),
+		Proxy:            base.ProxyFunc(chain.Proxy),
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: base.HandshakeTimeout(chain.Timeouts),
+	}
+	if err := chain.keepalive(&dialer, rawURL); err != nil {
+		return nil, err
+	}
+	return []rpc.ClientOption{rpc.WithWebsocketDialer(dialer), chain.headerOption()}, nil
+}
+
+// keepalive wraps the connections of dialer in a websocket keepalive. Pings
+// are written on the websocket stream, so TLS is dialed here rather than by
+// the dialer. Connections through a proxy, whose handshake runs on the same
+// connection, keep the 30 second keepalive of go-ethereum.
+func (chain *EvmCheckerImpl) keepalive(dialer *websocket.Dialer, rawURL string) error {
+	interval, timeout, enabled := base.WsKeepalive(chain.WsKeepalive)
+	if !enabled {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	// The dialer selects the proxy by the http(s) URL of the endpoint
+	httpURL := *u
+	switch u.Scheme {
+	case "ws":
+		httpURL.Scheme = "http"
+	case "wss":
+		httpURL.Scheme = "https"
+	default:
+		return nil
+	}
+	if proxyURL, err := dialer.Proxy(&http.Request{URL: &httpURL}); err != nil || proxyURL != nil {
+		glog.V(2).Infof("[keepalive] Node %s ws %s is dialed through a proxy, keeping the default keepalive", chain.Evm.HostName, rawURL)
+		return nil
+	}
+
+	dial := dialer.NetDialContext
+	wrap := func(conn net.Conn) net.Conn {
+		return base.NewKeepaliveConn(conn, interval, timeout, func() {
+			glog.Warningf("[keepalive] Node %s ws %s sent nothing within %s of a ping, closing the connection", chain.Evm.HostName, rawURL, timeout)
+		})
+	}
+	if u.Scheme == "ws" {
+		dialer.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return wrap(conn), nil
+		}
+		return nil
+	}
+
+	tlsConfig := dialer.TLSClientConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+	dialer.NetDialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return wrap(tlsConn), nil
+	}
+	return nil
+}
+
+// httpDialOptions returns the RPC client options for the HTTP endpoint
+func (chain *EvmCheckerImpl) httpDialOptions() ([]rpc.ClientOption, error) {
+	transport := base.NewProxyTransport(chain.Proxy)
+	base.ApplyTransport(transport, chain.Transport, base.ConnectTimeout(chain.Timeouts))
+	tlsConfig, err := base.NewTLSConfig(chain.TLS)
+	if err != nil {
+		return nil, err
+	}
+	chain.WatchCertExpiry("http", tlsConfig)
+	transport.TLSClientConfig = tlsConfig
+	// Requests share the outbound concurrency limiter with the other checkers
+	return []rpc.ClientOption{rpc.WithHTTPClient(&http.Client{Transport: base.LimitTransport(transport)}), chain.headerOption()}, nil
+}
@@ -0,0 +1,179 @@
+// Package filesd implements Prometheus-style file based service discovery:
+// target files matching the configured globs are re-read when they change and
+// their targets are handed to the controller.
+package filesd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+	"gopkg.in/yaml.v2"
+)
+
+// ApplyFunc replaces the targets of a source file, an empty config removes them
+type ApplyFunc func(source string, cfg *conf.NodeConfig)
+
+// ValidateFunc checks the targets read from a file before they are applied
+type ValidateFunc func(cfg *conf.NodeConfig) error
+
+// Watcher polls target files and applies additions, changes and removals
+type Watcher struct {
+	configs  []*conf.FileSD
+	apply    ApplyFunc
+	validate ValidateFunc
+
+	// seen holds the modification time of every file last read, applied the
+	// files whose targets are currently applied
+	seen    map[string]time.Time
+	applied map[string]bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWatcher(configs []*conf.FileSD, apply ApplyFunc, validate ValidateFunc) *Watcher {
+	return &Watcher{
+		configs:  configs,
+		apply:    apply,
+		validate: validate,
+		seen:     make(map[string]time.Time),
+		applied:  make(map[string]bool),
+	}
+}
+
+// Load reads a target file, JSON files are decoded as JSON and all others as YAML
+func Load(path string) (*conf.NodeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &conf.NodeConfig{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, cfg)
+	} else {
+		err = yaml.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// files returns the current files matching any of the configured globs
+func (w *Watcher) files() map[string]time.Time {
+	files := make(map[string]time.Time)
+	for _, c := range w.configs {
+		for _, pattern := range c.Files {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				glog.Errorf("[filesd] Invalid pattern %s: %v", pattern, err)
+				continue
+			}
+			for _, path := range matches {
+				info, err := os.Stat(path)
+				if err != nil || info.IsDir() {
+					continue
+				}
+				files[path] = info.ModTime()
+			}
+		}
+	}
+	return files
+}
+
+// Refresh applies every new or modified file and removes the targets of deleted files.
+// A file that fails to parse or validate keeps its previously applied targets.
+func (w *Watcher) Refresh() {
+	current := w.files()
+
+	paths := make([]string, 0, len(current))
+	for path := range current {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		modTime := current[path]
+		if seen, ok := w.seen[path]; ok && seen.Equal(modTime) {
+			continue
+		}
+		// A broken file is not read again until it changes
+		w.seen[path] = modTime
+
+		cfg, err := Load(path)
+		if err == nil && w.validate != nil {
+			err = w.validate(cfg)
+		}
+		if err != nil {
+			glog.Errorf("[filesd] Ignoring %s: %v", path, err)
+			continue
+		}
+
+		glog.Infof("[filesd] Applying targets from %s", path)
+		w.apply(path, cfg)
+		w.applied[path] = true
+	}
+
+	for path := range w.seen {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		if w.applied[path] {
+			glog.Infof("[filesd] %s removed, dropping its targets", path)
+			w.apply(path, &conf.NodeConfig{})
+		}
+		delete(w.seen, path)
+		delete(w.applied, path)
+	}
+}
+
+// refreshInterval is the shortest refresh interval of all configs
+func (w *Watcher) refreshInterval() int {
+	interval := 0
+	for _, c := range w.configs {
+		if c.RefreshSecond > 0 && (interval == 0 || c.RefreshSecond < interval) {
+			interval = c.RefreshSecond
+		}
+	}
+	return interval
+}
+
+// Start applies all target files and keeps watching them until Stop
+func (w *Watcher) Start(parent context.Context) {
+	var ctx context.Context
+	ctx, w.cancel = context.WithCancel(parent)
+
+	w.Refresh()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := base.CheckSecondToTicker(w.refreshInterval(), 30)
+		defer ticker.Stop()
+
+		for base.WaitForContextOrTicker(ctx, ticker) {
+			w.Refresh()
+		}
+		glog.V(5).Info("[filesd] Received stop signal, exited")
+	}()
+}
+
+func (w *Watcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
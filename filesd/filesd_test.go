@@ -0,0 +1,65 @@
+package filesd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+func TestRefresh(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "a.yaml")
+	jsonPath := filepath.Join(dir, "b.json")
+
+	if err := os.WriteFile(yamlPath, []byte("tcp:\n  - hostname: node-a\n    chain_name: story\n    address: 127.0.0.1:26656\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(`{"tcp":[{"hostname":"node-b","chain_name":"story","address":"127.0.0.1:30303"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := make(map[string]*conf.NodeConfig)
+	w := NewWatcher([]*conf.FileSD{{Files: []string{filepath.Join(dir, "*")}}}, func(source string, cfg *conf.NodeConfig) {
+		applied[source] = cfg
+	}, nil)
+
+	w.Refresh()
+	if len(applied) != 2 {
+		t.Fatalf("expected 2 applied files, got %d", len(applied))
+	}
+	if got := applied[jsonPath].Tcp[0].HostName; got != "node-b" {
+		t.Errorf("unexpected json target %s", got)
+	}
+
+	// Unchanged files are not applied again
+	delete(applied, yamlPath)
+	w.Refresh()
+	if _, ok := applied[yamlPath]; ok {
+		t.Error("unchanged file applied again")
+	}
+
+	// Modified files are applied again
+	if err := os.WriteFile(yamlPath, []byte("tcp: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(yamlPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	w.Refresh()
+	if cfg, ok := applied[yamlPath]; !ok || len(cfg.Tcp) != 0 {
+		t.Error("modified file not applied")
+	}
+
+	// Removed files drop their targets
+	if err := os.Remove(jsonPath); err != nil {
+		t.Fatal(err)
+	}
+	w.Refresh()
+	if cfg := applied[jsonPath]; cfg == nil || len(cfg.Tcp) != 0 {
+		t.Error("removed file targets not dropped")
+	}
+}
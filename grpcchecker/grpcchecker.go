@@ -0,0 +1,214 @@
+package grpcchecker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	methodGetNodeInfo    = "/cosmos.base.tendermint.v1beta1.Service/GetNodeInfo"
+	methodGetLatestBlock = "/cosmos.base.tendermint.v1beta1.Service/GetLatestBlock"
+)
+
+type GrpcCheckerImpl struct {
+	*conf.Grpc
+	base.BaseChecker
+
+	ctx context.Context
+
+	conn       *grpc.ClientConn
+	lastHeight int64
+}
+
+func NewGrpcCheckerImpl(ctx context.Context, conf *conf.Grpc) base.CheckerTrait {
+	checker := &GrpcCheckerImpl{
+		Grpc: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ChainId:      conf.ChainId,
+			NodeVersion:  conf.NodeVersion,
+			ProtocolName: conf.ProtocolName,
+
+			ExpectedChainId: conf.ChainId,
+			MinVersion:      conf.MinVersion,
+			FailureDomain:   conf.FailureDomain,
+			Maintenance:     maintenance.New(conf.Maintenance),
+		},
+		ctx: ctx,
+	}
+
+	// Set default check interval
+	if checker.CheckSecond == 0 {
+		checker.CheckSecond = 5
+	}
+
+	base.RegisterTargetEndpoint("grpc", "grpc", conf.ChainName, conf.HostName, conf.GrpcAddr)
+	return checker
+}
+
+func (chain *GrpcCheckerImpl) dial() error {
+	if chain.conn != nil {
+		return nil
+	}
+
+	creds := insecure.NewCredentials()
+	if chain.TLS != nil {
+		tlsConfig, err := base.NewTLSConfig(chain.TLS)
+		if err != nil {
+			return err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.DialContext(chain.ctx, chain.GrpcAddr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to dial %s: %w", chain.GrpcAddr, err)
+	}
+	chain.conn = conn
+	return nil
+}
+
+func (chain *GrpcCheckerImpl) invoke(method string) ([]byte, error) {
+	release, err := base.AcquireOutbound(chain.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(chain.ctx, 10*time.Second)
+	defer cancel()
+
+	var resp []byte
+	if err := chain.conn.Invoke(ctx, method, []byte{}, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (chain *GrpcCheckerImpl) checkNodeInfo() error {
+	resp, err := chain.invoke(methodGetNodeInfo)
+	if err != nil {
+		return err
+	}
+	network, version := decodeNodeInfo(resp)
+	chain.Grpc.ChainId = network
+	chain.Grpc.NodeVersion = version
+	chain.BaseChecker.ChainId = network
+	chain.SetNodeVersion(version)
+	chain.CheckChainId(network)
+	return nil
+}
+
+func (chain *GrpcCheckerImpl) checkLatestBlock() error {
+	resp, err := chain.invoke(methodGetLatestBlock)
+	if err != nil {
+		return err
+	}
+	height, _, blockTime, err := decodeLatestBlock(resp)
+	if err != nil {
+		return err
+	}
+	base.LatestBlockHeight.WithLabelValues(chain.AddLabelValues()...).Set(float64(height))
+
+	if height > chain.lastHeight {
+		chain.lastHeight = height
+		chain.UpdateLastBlockTime()
+		delaySecond := chain.RecordBlockDelay(blockTime)
+		glog.V(5).Infof("[checkLatestBlock] %s gRPC BlockNumber %d Delay %.2f s", chain.Grpc.HostName, height, delaySecond)
+	}
+	return nil
+}
+
+func (chain *GrpcCheckerImpl) check() {
+	if err := chain.dial(); err != nil {
+		glog.Errorf("[check] Node %s gRPC dial fail: %v", chain.Grpc.HostName, err)
+		chain.RecordConnectionAttempt("grpc", false)
+		chain.SetState(base.StateDown)
+		return
+	}
+
+	healthy := true
+	for _, op := range []struct {
+		endpointType string
+		check        func() error
+	}{
+		{"grpc_node_info", chain.checkNodeInfo},
+		{"grpc_latest_block", chain.checkLatestBlock},
+	} {
+		chain.HealthCheckOperation(op.endpointType, func() error {
+			err := op.check()
+			if err != nil {
+				healthy = false
+				glog.Errorf("[check] Node %s %s fail: %v", chain.Grpc.HostName, op.endpointType, err)
+			}
+			return err
+		})
+	}
+
+	chain.RecordConnectionAttempt("grpc", healthy)
+	if healthy {
+		chain.SetState(base.StateSubscribed)
+	} else {
+		chain.SetState(base.StateDegraded)
+	}
+}
+
+func (chain *GrpcCheckerImpl) Start() {
+	glog.Infof("[Grpc] Starting checker for %s (%s)", chain.Grpc.HostName, chain.Grpc.ChainName)
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 5) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 5)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		chain.check()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			if chain.conn != nil {
+				chain.conn.Close()
+			}
+			glog.V(5).Info("[Grpc] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+func (chain *GrpcCheckerImpl) GetHostName() string {
+	return chain.Grpc.HostName
+}
+
+func (chain *GrpcCheckerImpl) GetChainId() string {
+	return chain.Grpc.ChainId
+}
+
+func (chain *GrpcCheckerImpl) GetNodeVersion() string {
+	return chain.Grpc.NodeVersion
+}
+
+func (chain *GrpcCheckerImpl) GetChainName() string {
+	return chain.Grpc.ChainName
+}
+
+func (chain *GrpcCheckerImpl) GetProtocolName() string {
+	return chain.Grpc.ProtocolName
+}
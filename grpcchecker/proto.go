@@ -0,0 +1,116 @@
+package grpcchecker
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// rawCodec passes pre-encoded protobuf messages through gRPC unchanged, so the
+// checker can call Cosmos SDK services without depending on generated types
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// field returns the raw value of the last occurrence of a field in a message
+func field(msg []byte, num protowire.Number) (protowire.Type, []byte, bool) {
+	var (
+		found   bool
+		typ     protowire.Type
+		content []byte
+	)
+	for len(msg) > 0 {
+		n, t, l := protowire.ConsumeTag(msg)
+		if l < 0 {
+			return 0, nil, false
+		}
+		msg = msg[l:]
+		vl := protowire.ConsumeFieldValue(n, t, msg)
+		if vl < 0 {
+			return 0, nil, false
+		}
+		if n == num {
+			found, typ, content = true, t, msg[:vl]
+		}
+		msg = msg[vl:]
+	}
+	return typ, content, found
+}
+
+// message returns a nested message field following the path of field numbers
+func message(msg []byte, path ...protowire.Number) ([]byte, bool) {
+	for _, num := range path {
+		typ, content, ok := field(msg, num)
+		if !ok || typ != protowire.BytesType {
+			return nil, false
+		}
+		msg, _ = protowire.ConsumeBytes(content)
+	}
+	return msg, true
+}
+
+func stringField(msg []byte, num protowire.Number) string {
+	typ, content, ok := field(msg, num)
+	if !ok || typ != protowire.BytesType {
+		return ""
+	}
+	b, _ := protowire.ConsumeBytes(content)
+	return string(b)
+}
+
+func varintField(msg []byte, num protowire.Number) uint64 {
+	typ, content, ok := field(msg, num)
+	if !ok || typ != protowire.VarintType {
+		return 0
+	}
+	v, _ := protowire.ConsumeVarint(content)
+	return v
+}
+
+// timestamp decodes a google.protobuf.Timestamp message
+func timestamp(msg []byte) time.Time {
+	return time.Unix(int64(varintField(msg, 1)), int64(varintField(msg, 2)))
+}
+
+// decodeLatestBlock extracts the header height, chain id and time from a
+// cosmos.base.tendermint.v1beta1.GetLatestBlockResponse. Newer SDK versions
+// populate sdk_block (field 3), older ones only block (field 2).
+func decodeLatestBlock(resp []byte) (int64, string, time.Time, error) {
+	header, ok := message(resp, 3, 1)
+	if !ok {
+		header, ok = message(resp, 2, 1)
+	}
+	if !ok {
+		return 0, "", time.Time{}, fmt.Errorf("response has no block header")
+	}
+	ts, _ := message(header, 4)
+	return int64(varintField(header, 3)), stringField(header, 2), timestamp(ts), nil
+}
+
+// decodeNodeInfo extracts the network and application version from a
+// cosmos.base.tendermint.v1beta1.GetNodeInfoResponse
+func decodeNodeInfo(resp []byte) (string, string) {
+	nodeInfo, _ := message(resp, 1)
+	appVersion, _ := message(resp, 2)
+	return stringField(nodeInfo, 4), stringField(appVersion, 3)
+}
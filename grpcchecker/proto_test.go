@@ -0,0 +1,60 @@
+package grpcchecker
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func TestDecodeLatestBlock(t *testing.T) {
+	blockTime := time.Unix(1700000000, 500)
+
+	var ts []byte
+	ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, uint64(blockTime.Unix()))
+	ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, uint64(blockTime.Nanosecond()))
+
+	var header []byte
+	header = protowire.AppendTag(header, 2, protowire.BytesType)
+	header = protowire.AppendString(header, "story-1")
+	header = protowire.AppendTag(header, 3, protowire.VarintType)
+	header = protowire.AppendVarint(header, 12345)
+	header = appendMessage(header, 4, ts)
+
+	block := appendMessage(nil, 1, header)
+	resp := appendMessage(nil, 1, []byte{})
+	resp = appendMessage(resp, 2, block)
+
+	height, chainID, got, err := decodeLatestBlock(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 12345 || chainID != "story-1" || !got.Equal(blockTime) {
+		t.Errorf("unexpected decode height=%d chain=%s time=%v", height, chainID, got)
+	}
+}
+
+func TestDecodeNodeInfo(t *testing.T) {
+	var nodeInfo []byte
+	nodeInfo = protowire.AppendTag(nodeInfo, 4, protowire.BytesType)
+	nodeInfo = protowire.AppendString(nodeInfo, "story-1")
+
+	var appVersion []byte
+	appVersion = protowire.AppendTag(appVersion, 3, protowire.BytesType)
+	appVersion = protowire.AppendString(appVersion, "v1.0.0")
+
+	resp := appendMessage(nil, 1, nodeInfo)
+	resp = appendMessage(resp, 2, appVersion)
+
+	network, version := decodeNodeInfo(resp)
+	if network != "story-1" || version != "v1.0.0" {
+		t.Errorf("unexpected decode network=%s version=%s", network, version)
+	}
+}
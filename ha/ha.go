@@ -0,0 +1,181 @@
+// Package ha implements leader election between monitor replicas, so only the
+// leader runs checks and sends alerts while the others stand by.
+package ha
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"github.com/golang/glog"
+)
+
+// Backends
+const (
+	BackendKubernetes = "kubernetes"
+	BackendRedis      = "redis"
+)
+
+// Elector acquires and renews a lease on behalf of one replica
+type Elector interface {
+	// Acquire takes the lease if it is free or expired, or renews it if held by
+	// this replica, and reports whether this replica holds it afterwards
+	Acquire(ctx context.Context) (bool, error)
+	// Release gives up the lease if held, so a standby can take over at once
+	Release(ctx context.Context) error
+}
+
+// Leader runs the election loop and reports leadership changes
+type Leader struct {
+	elector  Elector
+	identity string
+	lease    time.Duration
+
+	onStarted func()
+	onStopped func()
+
+	mu       sync.RWMutex
+	isLeader bool
+	// renewed is the time of the last successful acquire or renewal
+	renewed time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLeader creates the elector of the configured backend. onStarted and
+// onStopped are called when this replica gains and loses leadership.
+func NewLeader(c *conf.HA, onStarted, onStopped func()) (*Leader, error) {
+	identity := c.Identity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine identity: %w", err)
+		}
+		identity = hostname
+	}
+	lease := time.Duration(c.LeaseSecond) * time.Second
+	if lease <= 0 {
+		lease = 15 * time.Second
+	}
+
+	var elector Elector
+	var err error
+	switch c.Backend {
+	case BackendKubernetes:
+		elector, err = NewKubernetesElector(c.Kubernetes, identity, lease)
+	case BackendRedis:
+		elector, err = NewRedisElector(c.Redis, identity, lease)
+	default:
+		err = fmt.Errorf("unknown backend %q, expected %s or %s", c.Backend, BackendKubernetes, BackendRedis)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Leader{
+		elector:   elector,
+		identity:  identity,
+		lease:     lease,
+		onStarted: onStarted,
+		onStopped: onStopped,
+	}, nil
+}
+
+// IsLeader reports whether this replica currently holds the lease
+func (l *Leader) IsLeader() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.isLeader
+}
+
+func (l *Leader) setLeader(leader bool) {
+	l.mu.Lock()
+	changed := l.isLeader != leader
+	l.isLeader = leader
+	l.mu.Unlock()
+
+	value := float64(0)
+	if leader {
+		value = 1
+	}
+	base.HALeader.Set(value)
+
+	if !changed {
+		return
+	}
+	if leader {
+		glog.Infof("[ha] %s became leader", l.identity)
+		l.onStarted()
+	} else {
+		glog.Warningf("[ha] %s lost leadership, standing by", l.identity)
+		l.onStopped()
+	}
+}
+
+func (l *Leader) tryAcquire(ctx context.Context) {
+	acquireCtx, cancel := context.WithTimeout(ctx, l.lease/3)
+	defer cancel()
+
+	leader, err := l.elector.Acquire(acquireCtx)
+	if err != nil {
+		glog.Errorf("[ha] Lease acquire fail: %v", err)
+		// Keep leading through transient errors while the lease is certainly still
+		// ours, step down before another replica may take it over
+		l.mu.RLock()
+		leader = l.isLeader && time.Since(l.renewed) < l.lease*2/3
+		l.mu.RUnlock()
+	} else if leader {
+		l.mu.Lock()
+		l.renewed = time.Now()
+		l.mu.Unlock()
+	}
+	l.setLeader(leader)
+}
+
+// Start runs the election loop until Stop. The lease is renewed every third
+// of its duration, a standby retries at the same interval.
+func (l *Leader) Start(parent context.Context) {
+	var ctx context.Context
+	ctx, l.cancel = context.WithCancel(parent)
+
+	l.tryAcquire(ctx)
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(l.lease / 3)
+		defer ticker.Stop()
+
+		for base.WaitForContextOrTicker(ctx, ticker) {
+			l.tryAcquire(ctx)
+		}
+		glog.V(5).Info("[ha] Received stop signal, exited")
+	}()
+}
+
+// Stop ends the election loop and releases the lease if held
+func (l *Leader) Stop() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+
+	if !l.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.elector.Release(ctx); err != nil {
+		glog.Errorf("[ha] Lease release fail: %v", err)
+	} else {
+		glog.Infof("[ha] %s released leadership", l.identity)
+	}
+	l.setLeader(false)
+}
@@ -0,0 +1,175 @@
+package ha
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+// fakeLeaseServer stores a single lease with resourceVersion based conflict detection
+func fakeLeaseServer(t *testing.T) *httptest.Server {
+	var (
+		mu      sync.Mutex
+		lease   *leaseObject
+		version int
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			if lease == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(lease)
+		case http.MethodPost, http.MethodPut:
+			var in leaseObject
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				t.Errorf("decode lease: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if (r.Method == http.MethodPost && lease != nil) ||
+				(r.Method == http.MethodPut && (lease == nil || in.Metadata.ResourceVersion != lease.Metadata.ResourceVersion)) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			version++
+			in.Metadata.ResourceVersion = strconv.Itoa(version)
+			lease = &in
+			json.NewEncoder(w).Encode(lease)
+		}
+	}))
+}
+
+func TestKubernetesElector(t *testing.T) {
+	srv := fakeLeaseServer(t)
+	defer srv.Close()
+
+	ctx := context.Background()
+	a := newKubernetesElector(srv.URL, "default", "storymonitor", "", srv.Client(), "replica-a", 15*time.Second)
+	b := newKubernetesElector(srv.URL, "default", "storymonitor", "", srv.Client(), "replica-b", 15*time.Second)
+
+	if ok, err := a.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("replica-a acquire: %v %v", ok, err)
+	}
+	if ok, err := b.Acquire(ctx); err != nil || ok {
+		t.Fatalf("replica-b acquired a held lease: %v %v", ok, err)
+	}
+	if ok, err := a.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("replica-a renew: %v %v", ok, err)
+	}
+
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("replica-b acquire after release: %v %v", ok, err)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	holder := "replica-a"
+	duration := int32(10)
+	renewed := time.Now().Add(-time.Minute).UTC().Format(microTime)
+	spec := leaseSpec{HolderIdentity: &holder, LeaseDurationSeconds: &duration, RenewTime: &renewed}
+	if !expired(spec, time.Now()) {
+		t.Error("lease renewed a minute ago with 10s duration should be expired")
+	}
+	renewed = time.Now().UTC().Format(microTime)
+	if expired(spec, time.Now()) {
+		t.Error("freshly renewed lease should not be expired")
+	}
+}
+
+// fakeRedis serves SET NX and the holder-checked EVAL scripts of a single key
+func fakeRedis(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu     sync.Mutex
+		holder string
+	)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+					args := make([]string, n)
+					for i := range args {
+						r.ReadString('\n')
+						arg, _ := r.ReadString('\n')
+						args[i] = strings.TrimSuffix(arg, "\r\n")
+					}
+
+					mu.Lock()
+					switch {
+					case args[0] == "SET" && holder == "":
+						holder = args[2]
+						conn.Write([]byte("+OK\r\n"))
+					case args[0] == "SET":
+						conn.Write([]byte("$-1\r\n"))
+					case args[0] == "EVAL" && holder == args[4]:
+						if strings.Contains(args[1], "del") {
+							holder = ""
+						}
+						conn.Write([]byte(":1\r\n"))
+					default:
+						conn.Write([]byte(":0\r\n"))
+					}
+					mu.Unlock()
+				}
+			}(conn)
+		}
+	}()
+	return ln
+}
+
+func TestRedisElector(t *testing.T) {
+	ln := fakeRedis(t)
+	defer ln.Close()
+
+	ctx := context.Background()
+	a, _ := NewRedisElector(&conf.RedisLock{Address: ln.Addr().String()}, "replica-a", 15*time.Second)
+	b, _ := NewRedisElector(&conf.RedisLock{Address: ln.Addr().String()}, "replica-b", 15*time.Second)
+
+	if ok, err := a.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("replica-a acquire: %v %v", ok, err)
+	}
+	if ok, err := b.Acquire(ctx); err != nil || ok {
+		t.Fatalf("replica-b acquired a held lock: %v %v", ok, err)
+	}
+	if ok, err := a.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("replica-a renew: %v %v", ok, err)
+	}
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("replica-b acquire after release: %v %v", ok, err)
+	}
+}
@@ -0,0 +1,242 @@
+package ha
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"storymonitor/conf"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// microTime is the Kubernetes MicroTime wire format
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+type leaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32  `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32  `json:"leaseTransitions,omitempty"`
+}
+
+type leaseObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion,omitempty"`
+	} `json:"metadata"`
+	Spec leaseSpec `json:"spec"`
+}
+
+// KubernetesElector holds a coordination.k8s.io/v1 Lease through the API server
+type KubernetesElector struct {
+	// leasesURL is the collection URL, leaseURL the URL of the lease itself
+	leasesURL string
+	leaseURL  string
+	name      string
+
+	token    string
+	client   *http.Client
+	identity string
+	lease    time.Duration
+}
+
+// NewKubernetesElector uses the in-cluster service account to talk to the API server
+func NewKubernetesElector(c *conf.KubernetesLease, identity string, lease time.Duration) (*KubernetesElector, error) {
+	if c == nil || c.Name == "" {
+		return nil, fmt.Errorf("kubernetes.name is required")
+	}
+
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes cluster")
+	}
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in service account CA")
+	}
+
+	namespace := c.Namespace
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes.namespace is required outside a pod: %w", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return newKubernetesElector(
+		"https://"+net.JoinHostPort(host, port),
+		namespace, c.Name, strings.TrimSpace(string(token)),
+		&http.Client{Timeout: 10 * time.Second, Transport: transport},
+		identity, lease,
+	), nil
+}
+
+func newKubernetesElector(apiServer, namespace, name, token string, client *http.Client, identity string, lease time.Duration) *KubernetesElector {
+	leasesURL := fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", apiServer, namespace)
+	return &KubernetesElector{
+		leasesURL: leasesURL,
+		leaseURL:  leasesURL + "/" + name,
+		name:      name,
+		token:     token,
+		client:    client,
+		identity:  identity,
+		lease:     lease,
+	}
+}
+
+func (e *KubernetesElector) do(ctx context.Context, method, url string, in interface{}, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, nil
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode lease: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// expired reports whether the lease holder missed its renewal
+func expired(spec leaseSpec, now time.Time) bool {
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	renewed, err := time.Parse(microTime, *spec.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(renewed.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second))
+}
+
+func (e *KubernetesElector) Acquire(ctx context.Context) (bool, error) {
+	var lease leaseObject
+	status, err := e.do(ctx, http.MethodGet, e.leaseURL, nil, &lease)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	nowStr := now.UTC().Format(microTime)
+	duration := int32(e.lease / time.Second)
+
+	switch {
+	case status == http.StatusNotFound:
+		lease = leaseObject{APIVersion: "coordination.k8s.io/v1", Kind: "Lease"}
+		lease.Metadata.Name = e.name
+		lease.Spec = leaseSpec{
+			HolderIdentity:       &e.identity,
+			LeaseDurationSeconds: &duration,
+			AcquireTime:          &nowStr,
+			RenewTime:            &nowStr,
+		}
+		status, err = e.do(ctx, http.MethodPost, e.leasesURL, &lease, nil)
+	case status >= http.StatusBadRequest:
+		return false, fmt.Errorf("get lease: unexpected status %d", status)
+	case lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == e.identity:
+		lease.Spec.RenewTime = &nowStr
+		lease.Spec.LeaseDurationSeconds = &duration
+		status, err = e.do(ctx, http.MethodPut, e.leaseURL, &lease, nil)
+	case expired(lease.Spec, now):
+		transitions := int32(0)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions
+		}
+		transitions++
+		lease.Spec.HolderIdentity = &e.identity
+		lease.Spec.LeaseDurationSeconds = &duration
+		lease.Spec.AcquireTime = &nowStr
+		lease.Spec.RenewTime = &nowStr
+		lease.Spec.LeaseTransitions = &transitions
+		status, err = e.do(ctx, http.MethodPut, e.leaseURL, &lease, nil)
+	default:
+		// Held by another replica
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case status == http.StatusConflict:
+		// Another replica updated the lease first
+		return false, nil
+	case status >= http.StatusBadRequest:
+		return false, fmt.Errorf("update lease: unexpected status %d", status)
+	}
+	return true, nil
+}
+
+func (e *KubernetesElector) Release(ctx context.Context) error {
+	var lease leaseObject
+	status, err := e.do(ctx, http.MethodGet, e.leaseURL, nil, &lease)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != e.identity {
+		return nil
+	}
+
+	empty := ""
+	lease.Spec.HolderIdentity = &empty
+	status, err = e.do(ctx, http.MethodPut, e.leaseURL, &lease, nil)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest && status != http.StatusConflict {
+		return fmt.Errorf("release lease: unexpected status %d", status)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package ha
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"storymonitor/conf"
+)
+
+const (
+	// renewScript extends the lock only if this replica still holds it
+	renewScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	// releaseScript deletes the lock only if this replica still holds it
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// RedisElector holds a lock key with an expiry in Redis
+type RedisElector struct {
+	address  string
+	password string
+	key      string
+	identity string
+	lease    time.Duration
+}
+
+func NewRedisElector(c *conf.RedisLock, identity string, lease time.Duration) (*RedisElector, error) {
+	if c == nil || c.Address == "" {
+		return nil, fmt.Errorf("redis.address is required")
+	}
+	key := c.Key
+	if key == "" {
+		key = "storymonitor:leader"
+	}
+	return &RedisElector{
+		address:  c.Address,
+		password: c.Password,
+		key:      key,
+		identity: identity,
+		lease:    lease,
+	}, nil
+}
+
+// redisConn is a minimal RESP client sufficient for the lock commands
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (e *RedisElector) dial(ctx context.Context) (*redisConn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	if e.password != "" {
+		if _, err := rc.do("AUTH", e.password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+	return rc, nil
+}
+
+// do sends a command and returns the reply, nil for a null bulk string
+func (rc *redisConn) do(args ...string) (interface{}, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := rc.conn.Write([]byte(sb.String())); err != nil {
+		return nil, err
+	}
+	return rc.read()
+}
+
+func (rc *redisConn) read() (interface{}, error) {
+	line, err := rc.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rc.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	}
+	return nil, fmt.Errorf("unsupported reply %q", line)
+}
+
+func (rc *redisConn) Close() error {
+	return rc.conn.Close()
+}
+
+func (e *RedisElector) Acquire(ctx context.Context) (bool, error) {
+	rc, err := e.dial(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer rc.Close()
+
+	ttl := strconv.FormatInt(e.lease.Milliseconds(), 10)
+	reply, err := rc.do("SET", e.key, e.identity, "NX", "PX", ttl)
+	if err != nil {
+		return false, err
+	}
+	if reply == "OK" {
+		return true, nil
+	}
+
+	reply, err = rc.do("EVAL", renewScript, "1", e.key, e.identity, ttl)
+	if err != nil {
+		return false, err
+	}
+	return reply == int64(1), nil
+}
+
+func (e *RedisElector) Release(ctx context.Context) error {
+	rc, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	_, err = rc.do("EVAL", releaseScript, "1", e.key, e.identity)
+	return err
+}
@@ -0,0 +1,232 @@
+package heads
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+)
+
+// Head is a block head observed by a node
+type Head struct {
+	Height     uint64
+	Hash       [32]byte
+	BlockTime  time.Time
+	ReceivedAt time.Time
+}
+
+type nodeHeads struct {
+	latest   Head
+	byHeight map[uint64][32]byte
+	heights  []uint64
+}
+
+// firstSeen records when any node of a chain first observed each recent height
+type firstSeen struct {
+	byHeight map[uint64]time.Time
+	heights  []uint64
+}
+
+// Tracker keeps the recent heads of every monitored node, grouped by chain name
+type Tracker struct {
+	mu        sync.RWMutex
+	chains    map[string]map[string]*nodeHeads
+	firstSeen map[string]*firstSeen
+	depth     int
+
+	// Nodes without a new head within staleAfter are excluded from the quorum
+	staleAfter time.Duration
+}
+
+func NewTracker(depth int, staleAfter time.Duration) *Tracker {
+	if depth <= 0 {
+		depth = 64
+	}
+	return &Tracker{
+		chains:     make(map[string]map[string]*nodeHeads),
+		firstSeen:  make(map[string]*firstSeen),
+		depth:      depth,
+		staleAfter: staleAfter,
+	}
+}
+
+// AppendHead records a head of a node, it implements base.HeadSink
+func (t *Tracker) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nodes, ok := t.chains[chainName]
+	if !ok {
+		nodes = make(map[string]*nodeHeads)
+		t.chains[chainName] = nodes
+	}
+	node, ok := nodes[hostName]
+	if !ok {
+		node = &nodeHeads{byHeight: make(map[uint64][32]byte, t.depth)}
+		nodes[hostName] = node
+	}
+
+	node.latest = Head{Height: height, Hash: hash, BlockTime: blockTime, ReceivedAt: receivedAt}
+	if _, ok := node.byHeight[height]; !ok {
+		node.heights = append(node.heights, height)
+	}
+	node.byHeight[height] = hash
+	for len(node.heights) > t.depth {
+		delete(node.byHeight, node.heights[0])
+		node.heights = node.heights[1:]
+	}
+
+	if delay, ok := t.propagationDelay(chainName, height, receivedAt); ok {
+		values := []string{chainName, hostName}
+		base.BlockPropagationDelay.WithLabelValues(values...).Set(delay.Seconds())
+		base.BlockPropagationDelayHistogram.WithLabelValues(values...).Observe(delay.Seconds())
+	}
+}
+
+// propagationDelay returns how long after the first node of the chain a head
+// at height was received. Heights older than the tracked window are ignored.
+// It must be called with the lock held.
+func (t *Tracker) propagationDelay(chainName string, height uint64, receivedAt time.Time) (time.Duration, bool) {
+	seen, ok := t.firstSeen[chainName]
+	if !ok {
+		seen = &firstSeen{byHeight: make(map[uint64]time.Time, t.depth)}
+		t.firstSeen[chainName] = seen
+	}
+
+	first, ok := seen.byHeight[height]
+	if !ok {
+		if len(seen.heights) >= t.depth && height < seen.heights[0] {
+			return 0, false
+		}
+		seen.byHeight[height] = receivedAt
+		seen.heights = append(seen.heights, height)
+		sort.Slice(seen.heights, func(i, j int) bool { return seen.heights[i] < seen.heights[j] })
+		for len(seen.heights) > t.depth {
+			delete(seen.byHeight, seen.heights[0])
+			seen.heights = seen.heights[1:]
+		}
+		return 0, true
+	}
+	if receivedAt.Before(first) {
+		return 0, true
+	}
+	return receivedAt.Sub(first), true
+}
+
+// Latest returns the latest head of every node of a chain, keyed by hostname
+func (t *Tracker) Latest(chainName string) map[string]Head {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[string]Head)
+	for host, node := range t.chains[chainName] {
+		result[host] = node.latest
+	}
+	return result
+}
+
+// Chains returns the names of all chains with at least one observed head
+func (t *Tracker) Chains() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	names := make([]string, 0, len(t.chains))
+	for name := range t.chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Quorum is the head agreed on by a majority of a chain's nodes
+type Quorum struct {
+	Chain    string
+	Height   uint64
+	Hash     [32]byte
+	Nodes    int
+	Required int
+
+	// Agreeing nodes have seen Hash at Height
+	Agreeing []string
+	// Disagreeing nodes have seen a different hash at Height
+	Disagreeing []string
+	// Missing nodes have no head at Height, either lagging or stale
+	Missing []string
+}
+
+// Quorum returns the highest head agreed on by more than half of the chain's nodes
+func (t *Tracker) Quorum(chainName string) (*Quorum, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	nodes, ok := t.chains[chainName]
+	if !ok {
+		return nil, fmt.Errorf("unknown chain %s", chainName)
+	}
+
+	now := time.Now()
+	hosts := make([]string, 0, len(nodes))
+	live := make([]string, 0, len(nodes))
+	for host, node := range nodes {
+		hosts = append(hosts, host)
+		if t.staleAfter <= 0 || now.Sub(node.latest.ReceivedAt) <= t.staleAfter {
+			live = append(live, host)
+		}
+	}
+	sort.Strings(hosts)
+	sort.Strings(live)
+
+	required := len(nodes)/2 + 1
+	if len(live) < required {
+		return nil, fmt.Errorf("only %d of %d nodes are live, %d required", len(live), len(nodes), required)
+	}
+
+	// Candidate heights are the heights known by live nodes, highest first
+	candidates := make(map[uint64]struct{})
+	for _, host := range live {
+		for _, height := range nodes[host].heights {
+			candidates[height] = struct{}{}
+		}
+	}
+	heights := make([]uint64, 0, len(candidates))
+	for height := range candidates {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+
+	for _, height := range heights {
+		votes := make(map[[32]byte]int)
+		for _, host := range live {
+			if hash, ok := nodes[host].byHeight[height]; ok {
+				votes[hash]++
+			}
+		}
+		for hash, count := range votes {
+			if count < required {
+				continue
+			}
+			q := &Quorum{
+				Chain:    chainName,
+				Height:   height,
+				Hash:     hash,
+				Nodes:    len(nodes),
+				Required: required,
+			}
+			for _, host := range hosts {
+				seen, ok := nodes[host].byHeight[height]
+				switch {
+				case !ok:
+					q.Missing = append(q.Missing, host)
+				case seen == hash:
+					q.Agreeing = append(q.Agreeing, host)
+				default:
+					q.Disagreeing = append(q.Disagreeing, host)
+				}
+			}
+			return q, nil
+		}
+	}
+	return nil, fmt.Errorf("no head agreed by %d of %d nodes", required, len(nodes))
+}
@@ -0,0 +1,87 @@
+package heads
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuorum(t *testing.T) {
+	tracker := NewTracker(8, 0)
+	now := time.Now()
+	good, bad := [32]byte{1}, [32]byte{2}
+
+	tracker.AppendHead("story", "node-01", 100, good, now, now)
+	tracker.AppendHead("story", "node-01", 101, [32]byte{3}, now, now)
+	tracker.AppendHead("story", "node-02", 100, good, now, now)
+	tracker.AppendHead("story", "node-03", 100, bad, now, now)
+	tracker.AppendHead("story", "node-04", 99, good, now, now)
+
+	q, err := tracker.Quorum("story")
+	if err == nil {
+		t.Fatalf("expected no quorum with 2 of 4 agreeing, got height %d", q.Height)
+	}
+
+	tracker.AppendHead("story", "node-04", 100, good, now, now)
+	q, err = tracker.Quorum("story")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Height != 100 || q.Hash != good {
+		t.Errorf("expected quorum at height 100, got %d", q.Height)
+	}
+	if len(q.Agreeing) != 3 || len(q.Disagreeing) != 1 || q.Disagreeing[0] != "node-03" {
+		t.Errorf("unexpected agreement agreeing=%v disagreeing=%v", q.Agreeing, q.Disagreeing)
+	}
+}
+
+func TestQuorumStaleNodes(t *testing.T) {
+	tracker := NewTracker(8, time.Minute)
+	now := time.Now()
+
+	tracker.AppendHead("story", "node-01", 100, [32]byte{1}, now, now)
+	tracker.AppendHead("story", "node-02", 100, [32]byte{1}, now, now.Add(-time.Hour))
+
+	if _, err := tracker.Quorum("story"); err == nil {
+		t.Error("expected no quorum when half of the nodes are stale")
+	}
+	if _, err := tracker.Quorum("unknown"); err == nil {
+		t.Error("expected error for unknown chain")
+	}
+}
+
+func TestTrackerDepth(t *testing.T) {
+	tracker := NewTracker(2, 0)
+	now := time.Now()
+	for height := uint64(1); height <= 5; height++ {
+		tracker.AppendHead("story", "node-01", height, [32]byte{byte(height)}, now, now)
+	}
+
+	node := tracker.chains["story"]["node-01"]
+	if len(node.byHeight) != 2 || len(node.heights) != 2 {
+		t.Errorf("expected 2 retained heights, got %d", len(node.byHeight))
+	}
+	if tracker.Latest("story")["node-01"].Height != 5 {
+		t.Errorf("expected latest height 5")
+	}
+}
+
+func TestPropagationDelay(t *testing.T) {
+	tracker := NewTracker(2, 0)
+	now := time.Now()
+
+	tracker.AppendHead("story", "node-01", 100, [32]byte{1}, now, now)
+	tracker.AppendHead("story", "node-02", 100, [32]byte{1}, now, now.Add(300*time.Millisecond))
+
+	if delay, ok := tracker.propagationDelay("story", 100, now.Add(time.Second)); !ok || delay != time.Second {
+		t.Errorf("expected 1s delay after the first node, got %v %v", delay, ok)
+	}
+	if delay, ok := tracker.propagationDelay("story", 101, now.Add(time.Second)); !ok || delay != 0 {
+		t.Errorf("expected the first node of a height to have no delay, got %v %v", delay, ok)
+	}
+
+	// Heights below the tracked window are ignored
+	tracker.AppendHead("story", "node-01", 102, [32]byte{2}, now, now)
+	if _, ok := tracker.propagationDelay("story", 99, now); ok {
+		t.Error("expected height below the window to be ignored")
+	}
+}
@@ -0,0 +1,242 @@
+// Package heartbeat monitors nodes that cannot be probed, e.g. behind NAT, from
+// signed heartbeats the nodes push to the monitor
+package heartbeat
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body keyed with the target's secret
+const SignatureHeader = "X-Heartbeat-Signature"
+
+// maxClockSkew bounds how far the timestamp of a heartbeat may be from the monitor's clock
+const maxClockSkew = 5 * time.Minute
+
+var (
+	// ErrUnknownHost is returned for heartbeats of hosts without a heartbeat target
+	ErrUnknownHost = errors.New("unknown heartbeat host")
+	// ErrInvalidSignature is returned for heartbeats with a missing or wrong signature
+	ErrInvalidSignature = errors.New("invalid heartbeat signature")
+	// ErrAmbiguousChain is returned for heartbeats without chain_name whose
+	// signature verifies for several chains of the host
+	ErrAmbiguousChain = errors.New("heartbeat matches several chains, set chain_name")
+)
+
+// Beat is the JSON body of a heartbeat
+type Beat struct {
+	// ChainName selects the target when several chains share the hostname
+	ChainName string `json:"chain_name"`
+	// Timestamp is the unix time the heartbeat was sent, heartbeats must be newer than the last accepted one
+	Timestamp   int64  `json:"timestamp"`
+	Height      int64  `json:"height"`
+	BlockTime   int64  `json:"block_time"`
+	NodeVersion string `json:"node_version"`
+}
+
+var (
+	receiversMu sync.RWMutex
+	receivers   = make(map[string][]*HeartbeatCheckerImpl)
+)
+
+type HeartbeatCheckerImpl struct {
+	*conf.Heartbeat
+	base.BaseChecker
+
+	ctx context.Context
+
+	mu            sync.Mutex
+	lastBeat      time.Time
+	lastTimestamp int64
+	lastHeight    int64
+}
+
+func NewHeartbeatCheckerImpl(ctx context.Context, conf *conf.Heartbeat) base.CheckerTrait {
+	checker := &HeartbeatCheckerImpl{
+		Heartbeat: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ProtocolName: conf.ProtocolName,
+
+			FailureDomain: conf.FailureDomain,
+			Maintenance:   maintenance.New(conf.Maintenance),
+		},
+		ctx: ctx,
+	}
+
+	// Set defaults
+	if checker.StaleSecond == 0 {
+		checker.StaleSecond = 60
+	}
+	return checker
+}
+
+// Sign returns the signature of a heartbeat body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Receive accepts a heartbeat of host pushed to the API
+func Receive(host string, body []byte, signature string) error {
+	var beat Beat
+	if err := json.Unmarshal(body, &beat); err != nil {
+		return fmt.Errorf("invalid heartbeat: %w", err)
+	}
+
+	// Without chain_name, the chains of the host are told apart by their secrets
+	signature = strings.TrimPrefix(signature, "sha256=")
+	receiversMu.RLock()
+	var checker *HeartbeatCheckerImpl
+	known, verified := false, 0
+	for _, c := range receivers[host] {
+		if beat.ChainName != "" && beat.ChainName != c.Heartbeat.ChainName {
+			continue
+		}
+		known = true
+		if hmac.Equal([]byte(Sign(c.Secret, body)), []byte(signature)) {
+			checker = c
+			verified++
+		}
+	}
+	receiversMu.RUnlock()
+	switch {
+	case !known:
+		return ErrUnknownHost
+	case verified == 0:
+		return ErrInvalidSignature
+	case verified > 1:
+		return ErrAmbiguousChain
+	}
+	return checker.accept(&beat, time.Now())
+}
+
+// accept records a heartbeat whose signature was verified
+func (chain *HeartbeatCheckerImpl) accept(beat *Beat, now time.Time) error {
+	sent := time.Unix(beat.Timestamp, 0)
+	if skew := now.Sub(sent); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("heartbeat timestamp %d is too far from the monitor's clock", beat.Timestamp)
+	}
+
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	// Replayed heartbeats would keep a dead node looking alive
+	if beat.Timestamp <= chain.lastTimestamp {
+		return fmt.Errorf("heartbeat timestamp %d is not newer than the last one", beat.Timestamp)
+	}
+	chain.lastTimestamp = beat.Timestamp
+	chain.lastBeat = now
+
+	if beat.NodeVersion != "" {
+		chain.SetNodeVersion(beat.NodeVersion)
+	}
+	if beat.Height > 0 {
+		base.LatestBlockHeight.WithLabelValues(chain.AddLabelValues()...).Set(float64(beat.Height))
+		if beat.Height > chain.lastHeight {
+			chain.lastHeight = beat.Height
+			chain.UpdateLastBlockTime()
+			if beat.BlockTime > 0 {
+				chain.RecordBlockDelay(time.Unix(beat.BlockTime, 0))
+			}
+		}
+	}
+	glog.V(5).Infof("[Heartbeat] Node %s (%s) heartbeat at height %d", chain.Heartbeat.HostName, chain.Heartbeat.ChainName, beat.Height)
+	return nil
+}
+
+// checkFreshness reports the heartbeat unhealthy once none arrived for stale_second
+func (chain *HeartbeatCheckerImpl) checkFreshness() {
+	chain.HealthCheckOperation("heartbeat", func() error {
+		chain.mu.Lock()
+		lastBeat := chain.lastBeat
+		chain.mu.Unlock()
+
+		if lastBeat.IsZero() {
+			chain.SetState(base.StateConnecting)
+			return fmt.Errorf("no heartbeat received yet")
+		}
+		if age := time.Since(lastBeat); age > time.Duration(chain.StaleSecond)*time.Second {
+			chain.SetState(base.StateDown)
+			return fmt.Errorf("last heartbeat %s ago", age.Round(time.Second))
+		}
+		chain.SetState(base.StateSubscribed)
+		return nil
+	})
+}
+
+func (chain *HeartbeatCheckerImpl) Start() {
+	glog.Infof("[Heartbeat] Starting checker for %s (%s)", chain.Heartbeat.HostName, chain.Heartbeat.ChainName)
+
+	receiversMu.Lock()
+	receivers[chain.Heartbeat.HostName] = append(receivers[chain.Heartbeat.HostName], chain)
+	receiversMu.Unlock()
+	defer func() {
+		receiversMu.Lock()
+		defer receiversMu.Unlock()
+		list := receivers[chain.Heartbeat.HostName]
+		for i, c := range list {
+			if c == chain {
+				receivers[chain.Heartbeat.HostName] = append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(receivers[chain.Heartbeat.HostName]) == 0 {
+			delete(receivers, chain.Heartbeat.HostName)
+		}
+	}()
+
+	// Freshness is evaluated a few times per stale period
+	checkSecond := chain.StaleSecond / 4
+	if checkSecond < 1 {
+		checkSecond = 1
+	}
+	ticker := base.CheckSecondToTicker(checkSecond, 15)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[Heartbeat] Received stop signal, exited")
+			return
+		}
+		chain.checkFreshness()
+	}
+}
+
+func (chain *HeartbeatCheckerImpl) GetHostName() string {
+	return chain.Heartbeat.HostName
+}
+
+func (chain *HeartbeatCheckerImpl) GetChainId() string {
+	return ""
+}
+
+func (chain *HeartbeatCheckerImpl) GetNodeVersion() string {
+	return chain.BaseChecker.NodeVersion
+}
+
+func (chain *HeartbeatCheckerImpl) GetChainName() string {
+	return chain.Heartbeat.ChainName
+}
+
+func (chain *HeartbeatCheckerImpl) GetProtocolName() string {
+	return chain.Heartbeat.ProtocolName
+}
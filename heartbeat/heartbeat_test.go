@@ -0,0 +1,77 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+func TestReceive(t *testing.T) {
+	checker := NewHeartbeatCheckerImpl(context.Background(), &conf.Heartbeat{
+		HostName:  "nat-node",
+		ChainName: "story",
+		Secret:    "s3cret",
+	}).(*HeartbeatCheckerImpl)
+	receivers["nat-node"] = []*HeartbeatCheckerImpl{checker}
+	defer delete(receivers, "nat-node")
+
+	now := time.Now().Unix()
+	body := []byte(fmt.Sprintf(`{"timestamp":%d,"height":100,"block_time":%d}`, now, now))
+
+	if err := Receive("other-node", body, Sign("s3cret", body)); !errors.Is(err, ErrUnknownHost) {
+		t.Errorf("unknown host: got %v", err)
+	}
+	if err := Receive("nat-node", body, Sign("wrong", body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong secret: got %v", err)
+	}
+	if err := Receive("nat-node", body, "sha256="+Sign("s3cret", body)); err != nil {
+		t.Fatalf("valid heartbeat: %v", err)
+	}
+	if checker.lastHeight != 100 || checker.lastBeat.IsZero() {
+		t.Errorf("heartbeat not recorded: height %d", checker.lastHeight)
+	}
+	if err := Receive("nat-node", body, Sign("s3cret", body)); err == nil {
+		t.Error("replayed heartbeat accepted")
+	}
+
+	old := []byte(fmt.Sprintf(`{"timestamp":%d,"height":101}`, now-3600))
+	if err := Receive("nat-node", old, Sign("s3cret", old)); err == nil {
+		t.Error("heartbeat with an old timestamp accepted")
+	}
+}
+
+func TestReceiveSharedHost(t *testing.T) {
+	newChecker := func(chainName, secret string) *HeartbeatCheckerImpl {
+		return NewHeartbeatCheckerImpl(context.Background(), &conf.Heartbeat{
+			HostName:  "shared-node",
+			ChainName: chainName,
+			Secret:    secret,
+		}).(*HeartbeatCheckerImpl)
+	}
+	story, aeneid, odyssey := newChecker("story", "story-secret"), newChecker("aeneid", "aeneid-secret"), newChecker("odyssey", "aeneid-secret")
+	receivers["shared-node"] = []*HeartbeatCheckerImpl{story, aeneid}
+	defer delete(receivers, "shared-node")
+
+	now := time.Now().Unix()
+	body := []byte(fmt.Sprintf(`{"timestamp":%d,"height":200}`, now))
+	if err := Receive("shared-node", body, Sign("aeneid-secret", body)); err != nil {
+		t.Fatalf("heartbeat without chain_name: %v", err)
+	}
+	if aeneid.lastHeight != 200 || story.lastHeight != 0 {
+		t.Errorf("expected the heartbeat on the chain of its secret, got story %d aeneid %d", story.lastHeight, aeneid.lastHeight)
+	}
+
+	receivers["shared-node"] = append(receivers["shared-node"], odyssey)
+	body = []byte(fmt.Sprintf(`{"timestamp":%d,"height":201}`, now+1))
+	if err := Receive("shared-node", body, Sign("aeneid-secret", body)); !errors.Is(err, ErrAmbiguousChain) {
+		t.Errorf("chains sharing a secret: got %v", err)
+	}
+	body = []byte(fmt.Sprintf(`{"chain_name":"odyssey","timestamp":%d,"height":201}`, now+1))
+	if err := Receive("shared-node", body, Sign("aeneid-secret", body)); err != nil || odyssey.lastHeight != 201 {
+		t.Errorf("heartbeat with chain_name: %v, height %d", err, odyssey.lastHeight)
+	}
+}
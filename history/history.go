@@ -0,0 +1,359 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/ringbuf"
+
+	"github.com/golang/glog"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Sample types
+const (
+	TypeCheck      = "check"
+	TypeHead       = "head"
+	TypeTransition = "transition"
+)
+
+// Sample is a recorded observation of a node
+type Sample struct {
+	Time      time.Time `json:"time"`
+	ChainName string    `json:"chain_name"`
+	HostName  string    `json:"hostname"`
+	Type      string    `json:"type"`
+	// Check and Healthy are set for check outcomes and health transitions
+	Check   string `json:"check,omitempty"`
+	Healthy bool   `json:"healthy"`
+	// LatencyMs is the duration of a check
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	// Height is set for heads
+	Height uint64 `json:"height,omitempty"`
+}
+
+// Filter selects samples in Query, empty fields match everything
+type Filter struct {
+	ChainName string
+	HostName  string
+	Type      string
+	From      time.Time
+	To        time.Time
+}
+
+var samplesBucket = []byte("samples")
+
+const (
+	queueSize   = 4096
+	flushPeriod = time.Second
+	pruneEvery  = time.Hour
+)
+
+// Store persists samples of all nodes to an embedded bbolt database. Samples
+// are queued by the sinks and written in batches, so checkers never block on disk.
+type Store struct {
+	db        *bolt.DB
+	retention time.Duration
+	queue     chan Sample
+	dropped   atomic.Uint64
+
+	// heads reads the heads from the head buffer instead of the queue, nil
+	// without a head buffer. It is only used by the writer.
+	heads        *ringbuf.Reader
+	headsDropped uint64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Open opens or creates the database at path. Samples older than retention
+// are pruned every hour.
+func Open(path string, retention time.Duration) (*Store, error) {
+	db, err := bolt.Open(path, 0o640, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{samplesBucket, silencesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{
+		db:        db,
+		retention: retention,
+		queue:     make(chan Sample, queueSize),
+	}, nil
+}
+
+// targetBucket is the name of a node's bucket inside the samples bucket
+func targetBucket(chainName, hostName string) []byte {
+	return []byte(chainName + "/" + hostName)
+}
+
+// sampleKey orders samples by time, the sequence keeps samples recorded in the same nanosecond apart
+func sampleKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// add queues a sample, dropping it if the writer falls behind
+func (s *Store) add(sample Sample) {
+	select {
+	case s.queue <- sample:
+	default:
+		if dropped := s.dropped.Add(1); dropped%1000 == 1 {
+			glog.Warningf("[history] Write queue full, %d samples dropped so far", dropped)
+		}
+	}
+}
+
+// AppendCheck records the outcome of a health check, it implements base.CheckSink
+func (s *Store) AppendCheck(chainName, hostName, check string, healthy bool, duration time.Duration, at time.Time) {
+	s.add(Sample{
+		Time:      at,
+		ChainName: chainName,
+		HostName:  hostName,
+		Type:      TypeCheck,
+		Check:     check,
+		Healthy:   healthy,
+		LatencyMs: float64(duration.Microseconds()) / 1000,
+	})
+}
+
+// AppendHead records a new head, it implements base.HeadSink
+func (s *Store) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	s.add(headSample(chainName, hostName, height, receivedAt))
+}
+
+func headSample(chainName, hostName string, height uint64, receivedAt time.Time) Sample {
+	return Sample{
+		Time:      receivedAt,
+		ChainName: chainName,
+		HostName:  hostName,
+		Type:      TypeHead,
+		Height:    height,
+	}
+}
+
+// ReadHeads makes the writer take the heads from a reader of the head buffer,
+// so the store is not registered as a head sink. It must be called before
+// Start, and the ring must stay mapped until Stop returns.
+func (s *Store) ReadHeads(reader *ringbuf.Reader) {
+	s.heads = reader
+}
+
+// HandleTransition records a health transition, it is registered as a base transition handler
+func (s *Store) HandleTransition(t base.HealthTransition) {
+	s.add(Sample{
+		Time:      t.Time,
+		ChainName: t.ChainName,
+		HostName:  t.HostName,
+		Type:      TypeTransition,
+		Check:     t.Check,
+		Healthy:   t.Healthy,
+	})
+}
+
+// write stores a batch of samples in one transaction
+func (s *Store) write(batch []Sample) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		samples := tx.Bucket(samplesBucket)
+		for _, sample := range batch {
+			b, err := samples.CreateBucketIfNotExists(targetBucket(sample.ChainName, sample.HostName))
+			if err != nil {
+				return err
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(sample)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(sampleKey(sample.Time, seq), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// flush writes all queued samples and the heads added to the head buffer
+func (s *Store) flush() {
+	n := len(s.queue)
+	batch := make([]Sample, 0, n)
+	for i := 0; i < n; i++ {
+		batch = append(batch, <-s.queue)
+	}
+	if s.heads != nil {
+		var ev ringbuf.HeadEvent
+		for s.heads.Next(&ev) {
+			batch = append(batch, headSample(ev.ChainName, ev.HostName, ev.Height, ev.ReceivedAt))
+		}
+		if dropped := s.heads.Dropped(); dropped > s.headsDropped {
+			glog.Warningf("[history] Head buffer overwrote %d heads before they were written", dropped-s.headsDropped)
+			s.headsDropped = dropped
+		}
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := s.write(batch); err != nil {
+		glog.Errorf("[history] Failed to write %d samples: %v", len(batch), err)
+	}
+}
+
+// Prune deletes samples older than the retention
+func (s *Store) Prune(now time.Time) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	cutoff := timeKey(now.Add(-s.retention))
+
+	deleted := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		samples := tx.Bucket(samplesBucket)
+		return samples.ForEach(func(name, _ []byte) error {
+			b := samples.Bucket(name)
+			if b == nil {
+				return nil
+			}
+			// Collect first, deleting while iterating makes the cursor skip keys
+			var expired [][]byte
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil && bytes.Compare(k[:8], cutoff) < 0; k, _ = c.Next() {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			for _, k := range expired {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			deleted += len(expired)
+			return nil
+		})
+	})
+	if err == nil && deleted > 0 {
+		glog.V(2).Infof("[history] Pruned %d samples older than %s", deleted, s.retention)
+	}
+	return err
+}
+
+// Query returns the samples matching the filter ordered by time
+func (s *Store) Query(f Filter) ([]Sample, error) {
+	to := f.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	var result []Sample
+	err := s.db.View(func(tx *bolt.Tx) error {
+		samples := tx.Bucket(samplesBucket)
+		return samples.ForEach(func(name, _ []byte) error {
+			chainName, hostName, _ := strings.Cut(string(name), "/")
+			if (f.ChainName != "" && f.ChainName != chainName) || (f.HostName != "" && f.HostName != hostName) {
+				return nil
+			}
+			b := samples.Bucket(name)
+			if b == nil {
+				return nil
+			}
+
+			c := b.Cursor()
+			k, v := c.First()
+			if !f.From.IsZero() {
+				k, v = c.Seek(timeKey(f.From))
+			}
+			for ; k != nil; k, v = c.Next() {
+				if int64(binary.BigEndian.Uint64(k)) > to.UnixNano() {
+					break
+				}
+				var sample Sample
+				if err := json.Unmarshal(v, &sample); err != nil {
+					return err
+				}
+				if f.Type == "" || f.Type == sample.Type {
+					result = append(result, sample)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result, nil
+}
+
+// Start writes queued samples every second and prunes expired samples every hour
+func (s *Store) Start(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		if err := s.Prune(time.Now()); err != nil {
+			glog.Errorf("[history] Failed to prune samples: %v", err)
+		}
+		flushTicker := time.NewTicker(flushPeriod)
+		defer flushTicker.Stop()
+		pruneTicker := time.NewTicker(pruneEvery)
+		defer pruneTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.flush()
+				return
+			case <-flushTicker.C:
+				s.flush()
+			case now := <-pruneTicker.C:
+				if err := s.Prune(now); err != nil {
+					glog.Errorf("[history] Failed to prune samples: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop writes the remaining samples and closes the database
+func (s *Store) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	if err := s.db.Close(); err != nil {
+		glog.Errorf("[history] Failed to close database: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"storymonitor/ringbuf"
+)
+
+func openStore(t *testing.T, retention time.Duration) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"), retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.db.Close() })
+	return store
+}
+
+func TestQuery(t *testing.T) {
+	store := openStore(t, 0)
+
+	now := time.Now()
+	store.AppendCheck("story", "node-01", "http", true, 12*time.Millisecond, now.Add(-3*time.Minute))
+	store.AppendHead("story", "node-01", 100, [32]byte{}, now, now.Add(-2*time.Minute))
+	store.AppendCheck("story", "node-02", "http", false, time.Second, now.Add(-time.Minute))
+	store.flush()
+
+	all, err := store.Query(Filter{ChainName: "story"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(all))
+	}
+	if all[0].Type != TypeCheck || all[1].Type != TypeHead || all[1].Height != 100 {
+		t.Errorf("unexpected order: %+v", all)
+	}
+	if all[0].LatencyMs != 12 {
+		t.Errorf("expected 12ms latency, got %v", all[0].LatencyMs)
+	}
+
+	node1, _ := store.Query(Filter{HostName: "node-01", Type: TypeHead})
+	if len(node1) != 1 || node1[0].HostName != "node-01" {
+		t.Errorf("unexpected node-01 heads: %+v", node1)
+	}
+
+	recent, _ := store.Query(Filter{From: now.Add(-90 * time.Second), To: now})
+	if len(recent) != 1 || recent[0].HostName != "node-02" {
+		t.Errorf("unexpected samples in range: %+v", recent)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	store := openStore(t, time.Hour)
+
+	now := time.Now()
+	store.AppendCheck("story", "node-01", "http", true, time.Millisecond, now.Add(-2*time.Hour))
+	store.AppendCheck("story", "node-01", "http", true, time.Millisecond, now.Add(-30*time.Minute))
+	store.flush()
+
+	if err := store.Prune(now); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || samples[0].Time.Before(now.Add(-time.Hour)) {
+		t.Errorf("expected only the recent sample, got %+v", samples)
+	}
+}
+
+func TestReadHeads(t *testing.T) {
+	store := openStore(t, 0)
+	ring, err := ringbuf.Open(filepath.Join(t.TempDir(), "heads.ring"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ring.Close()
+	store.ReadHeads(ring.NewReader())
+
+	now := time.Now()
+	ring.AppendHead("story", "node-01", 100, [32]byte{}, now, now.Add(-time.Minute))
+	ring.AppendHead("story", "node-01", 101, [32]byte{}, now, now)
+	store.flush()
+
+	heads, err := store.Query(Filter{Type: TypeHead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 2 || heads[0].Height != 100 || heads[1].Height != 101 || heads[1].HostName != "node-01" {
+		t.Errorf("unexpected heads read from the ring: %+v", heads)
+	}
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"encoding/json"
+
+	"storymonitor/base"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var silencesBucket = []byte("silences")
+
+// SaveSilences replaces the stored silences, so they survive restarts
+func (s *Store) SaveSilences(silences []base.Silence) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(silencesBucket); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucket(silencesBucket)
+		if err != nil {
+			return err
+		}
+		for _, silence := range silences {
+			value, err := json.Marshal(silence)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(silence.ID), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Silences returns the stored silences, including those expired since
+func (s *Store) Silences() ([]base.Silence, error) {
+	var silences []base.Silence
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(silencesBucket).ForEach(func(_, value []byte) error {
+			var silence base.Silence
+			if err := json.Unmarshal(value, &silence); err != nil {
+				return err
+			}
+			silences = append(silences, silence)
+			return nil
+		})
+	})
+	return silences, err
+}
@@ -0,0 +1,43 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"storymonitor/base"
+)
+
+func TestSilences(t *testing.T) {
+	store := openStore(t, time.Hour)
+	now := time.Now()
+	active := base.Silence{ID: "7", HostName: "restored-node", CreatedBy: "alice", StartsAt: now, EndsAt: now.Add(time.Hour)}
+	ended := base.Silence{ID: "9", HostName: "ended-node", CreatedBy: "alice", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}
+
+	if err := store.SaveSilences([]base.Silence{active, ended}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveSilences([]base.Silence{active, ended}); err != nil {
+		t.Fatal(err)
+	}
+	silences, err := store.Silences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(silences) != 2 {
+		t.Fatalf("expected saving to replace the silences, got %+v", silences)
+	}
+
+	base.RestoreSilences(silences)
+	t.Cleanup(func() { base.ExpireSilence(active.ID) })
+	if !base.IsSilenced("story", "restored-node") || base.IsSilenced("story", "ended-node") {
+		t.Error("expected only the active silence to be restored")
+	}
+	added, err := base.AddSilence(base.Silence{HostName: "new-node", EndsAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { base.ExpireSilence(added.ID) })
+	if added.ID != "10" {
+		t.Errorf("new silence got ID %s, want 10 after the restored IDs", added.ID)
+	}
+}
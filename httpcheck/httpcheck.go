@@ -0,0 +1,223 @@
+package httpcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/jsonpath"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+)
+
+// maxBodyBytes bounds how much of a response body is read for assertions
+const maxBodyBytes = 1 << 20
+
+type HttpCheckerImpl struct {
+	*conf.Http
+	base.BaseChecker
+
+	ctx context.Context
+
+	cli       *http.Client
+	bodyRegex *regexp.Regexp
+	path      jsonpath.Path
+}
+
+func NewHttpCheckerImpl(ctx context.Context, conf *conf.Http) base.CheckerTrait {
+	checker := &HttpCheckerImpl{
+		Http: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ChainId:      conf.ChainId,
+			NodeVersion:  conf.NodeVersion,
+			ProtocolName: conf.ProtocolName,
+
+			FailureDomain: conf.FailureDomain,
+			Maintenance:   maintenance.New(conf.Maintenance),
+		},
+		ctx: ctx,
+	}
+
+	// Set defaults
+	if checker.CheckSecond == 0 {
+		checker.CheckSecond = 30
+	}
+	if checker.Method == "" {
+		checker.Method = http.MethodGet
+	}
+	if len(checker.ExpectedStatus) == 0 {
+		checker.ExpectedStatus = []int{http.StatusOK}
+	}
+	timeout := 10 * time.Second
+	if checker.TimeoutSecond > 0 {
+		timeout = time.Duration(checker.TimeoutSecond) * time.Second
+	}
+
+	// The config has been validated at startup, see Validate
+	transport := base.NewProxyTransport("")
+	transport.TLSClientConfig, _ = base.NewTLSConfig(conf.TLS)
+	checker.cli = &http.Client{Timeout: timeout, Transport: transport}
+	if conf.BodyRegex != "" {
+		checker.bodyRegex = regexp.MustCompile(conf.BodyRegex)
+	}
+	if conf.ResultPath != "" {
+		checker.path, _ = jsonpath.Parse(conf.ResultPath)
+	}
+
+	base.RegisterTargetEndpoint("http", "http", conf.ChainName, conf.HostName, conf.URL)
+	return checker
+}
+
+func (chain *HttpCheckerImpl) expectedStatus(code int) bool {
+	for _, expected := range chain.ExpectedStatus {
+		if code == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func (chain *HttpCheckerImpl) request() error {
+	client := base.NewHTTPClient(chain.cli)
+	client.Header = make(map[string]string, len(chain.Headers))
+	for key, value := range chain.Headers {
+		client.SetHeader(key, value)
+	}
+	if chain.Body != "" {
+		client.Payload = []byte(chain.Body)
+	}
+
+	// Wait for the outbound limiter before timing, queueing is not response latency
+	release, err := base.AcquireOutbound(chain.ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	startTime := time.Now()
+	resp, err := client.Req(chain.ctx, chain.URL, chain.Method, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	chain.RecordCertExpiry("http", resp.TLS)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	duration := time.Since(startTime)
+
+	if !chain.expectedStatus(resp.StatusCode) {
+		return fmt.Errorf("unexpected status %d, expected %v", resp.StatusCode, chain.ExpectedStatus)
+	}
+	if chain.bodyRegex != nil && !chain.bodyRegex.Match(body) {
+		return fmt.Errorf("body does not match %s", chain.BodyRegex)
+	}
+	if chain.path != nil {
+		if err := chain.path.Check(body, chain.ExpectedValue); err != nil {
+			return err
+		}
+	}
+	if chain.MaxLatencyMs > 0 && duration > time.Duration(chain.MaxLatencyMs)*time.Millisecond {
+		return fmt.Errorf("response took %v, threshold %dms", duration.Round(time.Millisecond), chain.MaxLatencyMs)
+	}
+	return nil
+}
+
+func (chain *HttpCheckerImpl) check() {
+	var healthy bool
+	chain.HealthCheckOperation("http", func() error {
+		err := chain.request()
+		if err != nil {
+			glog.Errorf("[check] Node %s %s %s fail: %v", chain.Http.HostName, chain.Method, chain.URL, err)
+		}
+		healthy = err == nil
+		return err
+	})
+
+	chain.RecordConnectionAttempt("http", healthy)
+	if healthy {
+		// Without blocks to follow, the last update time tracks the last successful request
+		chain.UpdateLastBlockTime()
+		chain.SetState(base.StateSubscribed)
+	} else {
+		chain.SetState(base.StateDegraded)
+	}
+}
+
+func (chain *HttpCheckerImpl) Start() {
+	glog.Infof("[Http] Starting checker for %s (%s) %s %s", chain.Http.HostName, chain.Http.ChainName, chain.Method, chain.URL)
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 30) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		chain.check()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[Http] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+func (chain *HttpCheckerImpl) GetHostName() string {
+	return chain.Http.HostName
+}
+
+func (chain *HttpCheckerImpl) GetChainId() string {
+	return chain.Http.ChainId
+}
+
+func (chain *HttpCheckerImpl) GetNodeVersion() string {
+	return chain.Http.NodeVersion
+}
+
+func (chain *HttpCheckerImpl) GetChainName() string {
+	return chain.Http.ChainName
+}
+
+func (chain *HttpCheckerImpl) GetProtocolName() string {
+	return chain.Http.ProtocolName
+}
+
+// Validate checks an http target's assertions
+func Validate(c *conf.Http) error {
+	if c.BodyRegex != "" {
+		if _, err := regexp.Compile(c.BodyRegex); err != nil {
+			return fmt.Errorf("invalid body_regex: %w", err)
+		}
+	}
+	if c.ResultPath != "" {
+		if _, err := jsonpath.Parse(c.ResultPath); err != nil {
+			return err
+		}
+	} else if c.ExpectedValue != "" {
+		return fmt.Errorf("expected_value requires result_path")
+	}
+	for _, code := range c.ExpectedStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid expected_status %d", code)
+		}
+	}
+	if _, err := base.NewTLSConfig(c.TLS); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Package jsonpath implements the JSONPath subset used by response assertions:
+// a leading $, dotted keys, bracketed indexes and bracketed quoted keys, e.g.
+// $.result.blocks[0]['hash']
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is one segment of a path, either an object key or an array index
+type step struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// Path is a parsed JSONPath
+type Path []step
+
+// Parse parses a JSONPath expression
+func Parse(path string) (Path, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+	p = p[1:]
+
+	steps := Path{}
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			steps = append(steps, step{key: p[:end], isKey: true})
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unterminated bracket", path)
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, step{key: inner[1 : len(inner)-1], isKey: true})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("path %q has an invalid index %q", path, inner)
+			}
+			steps = append(steps, step{index: index})
+		default:
+			return nil, fmt.Errorf("path %q has an unexpected character %q", path, p[0])
+		}
+	}
+	return steps, nil
+}
+
+// Lookup evaluates the path against a decoded JSON document
+func (p Path) Lookup(doc interface{}) (interface{}, bool) {
+	cur := doc
+	for _, s := range p {
+		if s.isKey {
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = obj[s.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		arr, ok := cur.([]interface{})
+		if !ok || s.index >= len(arr) {
+			return nil, false
+		}
+		cur = arr[s.index]
+	}
+	return cur, true
+}
+
+// Check decodes a JSON body and verifies the path is present and non-null.
+// If expected is not empty the value must also equal it, see Format.
+func (p Path) Check(body []byte, expected string) error {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	value, ok := p.Lookup(doc)
+	if !ok || value == nil {
+		return fmt.Errorf("path %s not found", p)
+	}
+	if expected != "" && Format(value) != expected {
+		return fmt.Errorf("path %s is %s, expected %s", p, Format(value), expected)
+	}
+	return nil
+}
+
+// String renders the path back to JSONPath syntax
+func (p Path) String() string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, s := range p {
+		if s.isKey {
+			sb.WriteString("['" + s.key + "']")
+		} else {
+			sb.WriteString("[" + strconv.Itoa(s.index) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// Format renders a JSON value for comparison, strings are returned without quotes
+func Format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
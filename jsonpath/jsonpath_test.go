@@ -0,0 +1,63 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"result":{"blocks":[{"hash":"0xab","n":7}],"ok":true}}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{"$.result.ok", "true", true},
+		{"$.result.blocks[0].hash", "0xab", true},
+		{"$.result.blocks[0]['n']", "7", true},
+		{"$.result.blocks[1]", "", false},
+		{"$.result.missing", "", false},
+		{"$", "", true},
+	} {
+		p, err := Parse(tc.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		v, ok := p.Lookup(doc)
+		if ok != tc.found {
+			t.Errorf("%s: found %v, want %v", tc.path, ok, tc.found)
+			continue
+		}
+		if ok && tc.want != "" && Format(v) != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.path, Format(v), tc.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := Parse("$.result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check([]byte(`{"result":true}`), "true"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := p.Check([]byte(`{"result":false}`), "true"); err == nil {
+		t.Error("expected mismatch error")
+	}
+	if err := p.Check([]byte(`{"result":null}`), ""); err == nil {
+		t.Error("expected not found error for null")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, path := range []string{"result", "$.a[", "$.a[x]", "$..a", "$a"} {
+		if _, err := Parse(path); err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/jsonpath"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+)
+
+type request struct {
+	JsonRpc string            `json:"jsonrpc"`
+	ID      int               `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type JsonRpcCheckerImpl struct {
+	*conf.JsonRpc
+	base.BaseChecker
+
+	ctx context.Context
+
+	client *base.Client
+	params []json.RawMessage
+	path   jsonpath.Path
+}
+
+func NewJsonRpcCheckerImpl(ctx context.Context, conf *conf.JsonRpc) base.CheckerTrait {
+	checker := &JsonRpcCheckerImpl{
+		JsonRpc: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ChainId:      conf.ChainId,
+			NodeVersion:  conf.NodeVersion,
+			ProtocolName: conf.ProtocolName,
+
+			FailureDomain: conf.FailureDomain,
+			Maintenance:   maintenance.New(conf.Maintenance),
+		},
+		ctx:    ctx,
+		client: base.NewClient(ctx, &http.Client{Timeout: 10 * time.Second}),
+	}
+
+	// Set default check interval
+	if checker.CheckSecond == 0 {
+		checker.CheckSecond = 15
+	}
+
+	// The config has been validated at startup, see Validate
+	checker.params, _ = decodeParams(conf.Params)
+	if conf.ResultPath != "" {
+		checker.path, _ = jsonpath.Parse(conf.ResultPath)
+	}
+
+	base.RegisterTargetEndpoint("jsonrpc", "jsonrpc", conf.ChainName, conf.HostName, conf.RpcURL)
+	return checker
+}
+
+func decodeParams(params string) ([]json.RawMessage, error) {
+	raw := []json.RawMessage{}
+	if params == "" {
+		return raw, nil
+	}
+	if err := json.Unmarshal([]byte(params), &raw); err != nil {
+		return nil, fmt.Errorf("params must be a JSON array: %w", err)
+	}
+	return raw, nil
+}
+
+func (chain *JsonRpcCheckerImpl) call() error {
+	body, err := chain.client.Fetch(chain.RpcURL, http.MethodPost, request{
+		JsonRpc: "2.0",
+		ID:      1,
+		Method:  chain.Method,
+		Params:  chain.params,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("rpc error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+	if chain.path == nil {
+		return nil
+	}
+	return chain.path.Check(body, chain.ExpectedValue)
+}
+
+func (chain *JsonRpcCheckerImpl) check() {
+	var healthy bool
+	chain.HealthCheckOperation("jsonrpc_"+chain.Method, func() error {
+		err := chain.call()
+		if err != nil {
+			glog.Errorf("[check] Node %s %s fail: %v", chain.JsonRpc.HostName, chain.Method, err)
+		}
+		healthy = err == nil
+		return err
+	})
+
+	chain.RecordConnectionAttempt("jsonrpc", healthy)
+	if healthy {
+		// Without blocks to follow, the last update time tracks the last successful call
+		chain.UpdateLastBlockTime()
+		chain.SetState(base.StateSubscribed)
+	} else {
+		chain.SetState(base.StateDegraded)
+	}
+}
+
+func (chain *JsonRpcCheckerImpl) Start() {
+	glog.Infof("[JsonRpc] Starting checker for %s (%s) method %s", chain.JsonRpc.HostName, chain.JsonRpc.ChainName, chain.Method)
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 15) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 15)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		chain.check()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[JsonRpc] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+func (chain *JsonRpcCheckerImpl) GetHostName() string {
+	return chain.JsonRpc.HostName
+}
+
+func (chain *JsonRpcCheckerImpl) GetChainId() string {
+	return chain.JsonRpc.ChainId
+}
+
+func (chain *JsonRpcCheckerImpl) GetNodeVersion() string {
+	return chain.JsonRpc.NodeVersion
+}
+
+func (chain *JsonRpcCheckerImpl) GetChainName() string {
+	return chain.JsonRpc.ChainName
+}
+
+func (chain *JsonRpcCheckerImpl) GetProtocolName() string {
+	return chain.JsonRpc.ProtocolName
+}
+
+// Validate checks a jsonrpc target's method, params and result path
+func Validate(c *conf.JsonRpc) error {
+	if c.Method == "" {
+		return fmt.Errorf("method is required")
+	}
+	if _, err := decodeParams(c.Params); err != nil {
+		return err
+	}
+	if c.ResultPath != "" {
+		if _, err := jsonpath.Parse(c.ResultPath); err != nil {
+			return err
+		}
+	} else if c.ExpectedValue != "" {
+		return fmt.Errorf("expected_value requires result_path")
+	}
+	return nil
+}
@@ -4,18 +4,43 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
 	"syscall"
 	"time"
 
-	_ "storymonitor/base"
+	"storymonitor/alert"
+	"storymonitor/anomaly"
+	"storymonitor/api"
+	"storymonitor/base"
 	"storymonitor/conf"
+	"storymonitor/deadman"
+	"storymonitor/dnssd"
+	"storymonitor/eventlog"
+	"storymonitor/events"
+	evmchecker "storymonitor/evm"
+	"storymonitor/filesd"
+	"storymonitor/ha"
+	"storymonitor/heads"
+	"storymonitor/history"
+	"storymonitor/httpcheck"
+	"storymonitor/jsonrpc"
+	"storymonitor/maintenance"
+	"storymonitor/reference"
+	"storymonitor/ringbuf"
 	"storymonitor/sched"
+	"storymonitor/sla"
+	"storymonitor/slo"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gopkg.in/yaml.v2"
 )
@@ -50,10 +75,122 @@ func loadConf(path string) error {
 }
 
 func validateConfig(config *conf.NodeConfig) error {
-	if len(config.Evm) == 0 && len(config.Cometbft) == 0 {
+	hasTargets := len(config.Evm) > 0 || len(config.Cometbft) > 0 || len(config.CosmosRest) > 0 || len(config.Grpc) > 0 ||
+		len(config.JsonRpc) > 0 || len(config.Http) > 0 || len(config.Tcp) > 0 || len(config.Plugin) > 0 ||
+		len(config.Heartbeat) > 0
+	if !hasTargets && len(config.FileSD) == 0 && len(config.DNSSD) == 0 {
 		return fmt.Errorf("no monitoring targets configured")
 	}
 
+	if err := validateTargets(config); err != nil {
+		return err
+	}
+
+	// Beacon support removed for Story protocol-only monitor
+
+	for i, sd := range config.FileSD {
+		if sd == nil || len(sd.Files) == 0 {
+			return fmt.Errorf("file_sd_configs[%d]: files is required", i)
+		}
+		for _, pattern := range sd.Files {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("file_sd_configs[%d]: invalid pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+
+	for i, sd := range config.DNSSD {
+		if sd == nil || len(sd.Names) == 0 {
+			return fmt.Errorf("dns_sd_configs[%d]: names is required", i)
+		}
+		if !slices.Contains(sched.Kinds, sd.Kind) {
+			return fmt.Errorf("dns_sd_configs[%d]: unknown kind %q", i, sd.Kind)
+		}
+		if _, err := dnssd.Render(sd, []dnssd.Record{{Host: "localhost", Port: 1}}); err != nil {
+			return fmt.Errorf("dns_sd_configs[%d]: %w", i, err)
+		}
+	}
+
+	if err := reference.Validate(config.References); err != nil {
+		return err
+	}
+	if err := deadman.Validate(config.DeadManSwitches); err != nil {
+		return err
+	}
+	if err := eventlog.Validate(config.EventLog); err != nil {
+		return err
+	}
+	if err := anomaly.Validate(config.Anomaly); err != nil {
+		return err
+	}
+	if err := slo.Validate(config.SLOs); err != nil {
+		return err
+	}
+
+	if s := config.Shutdown; s != nil {
+		if s.TimeoutSecond < 0 {
+			return fmt.Errorf("shutdown: timeout_second must not be negative")
+		}
+		if s.Mode != "" && s.Mode != conf.ShutdownForce && s.Mode != conf.ShutdownWait {
+			return fmt.Errorf("shutdown: unknown mode %q, expected %s or %s", s.Mode, conf.ShutdownForce, conf.ShutdownWait)
+		}
+	}
+
+	if config.Proxy != "" {
+		if _, err := base.ParseProxy(config.Proxy); err != nil {
+			return fmt.Errorf("proxy: %w", err)
+		}
+	}
+
+	for chainName, version := range config.MinVersions {
+		if err := validateMinVersion(version); err != nil {
+			return fmt.Errorf("min_versions[%s]: %w", chainName, err)
+		}
+	}
+
+	if config.HA != nil {
+		switch config.HA.Backend {
+		case ha.BackendKubernetes:
+			if config.HA.Kubernetes == nil || config.HA.Kubernetes.Name == "" {
+				return fmt.Errorf("ha: kubernetes.name is required")
+			}
+		case ha.BackendRedis:
+			if config.HA.Redis == nil || config.HA.Redis.Address == "" {
+				return fmt.Errorf("ha: redis.address is required")
+			}
+		default:
+			return fmt.Errorf("ha: unknown backend %q", config.HA.Backend)
+		}
+	}
+
+	if config.History != nil && config.History.Path == "" {
+		return fmt.Errorf("history: path is required")
+	}
+	if config.HeadBuffer != nil && config.HeadBuffer.Path == "" {
+		return fmt.Errorf("head_buffer: path is required")
+	}
+	if config.Metrics != nil {
+		if err := base.ValidateMetricsNamespace(config.Metrics.Namespace, config.Metrics.ConstLabels); err != nil {
+			return fmt.Errorf("metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateMinVersion checks that a min_version contains a semantic version
+func validateMinVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	if _, ok := base.ParseVersion(version); !ok {
+		return fmt.Errorf("min_version %q is not a semantic version", version)
+	}
+	return nil
+}
+
+// validateTargets checks the target sections of the main config or of a file_sd target file
+func validateTargets(config *conf.NodeConfig) error {
 	// Validate EVM configurations
 	for i, evm := range config.Evm {
 		if evm.HostName == "" {
@@ -65,6 +202,62 @@ func validateConfig(config *conf.NodeConfig) error {
 		if evm.ChainName == "" {
 			return fmt.Errorf("evm[%d]: chain_name is required", i)
 		}
+		if err := base.ValidateDelaySource(evm.DelaySource); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if err := base.ValidateURLStrategy(evm.URLStrategy); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		for _, address := range evm.Addresses {
+			if !common.IsHexAddress(address) {
+				return fmt.Errorf("evm[%d]: invalid address %q", i, address)
+			}
+		}
+		if err := evmchecker.ValidateCallProbes(evm.CallProbes); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if err := evmchecker.ValidateRpcMethods(evm.RpcMethods); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if err := evmchecker.ValidateCanary(evm.Canary); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if err := evmchecker.ValidateReadBenchmark(evm.ReadBenchmark); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if evm.Trace != nil {
+			switch evm.Trace.Method {
+			case "", evmchecker.TraceMethodDebug, evmchecker.TraceMethodParity:
+			default:
+				return fmt.Errorf("evm[%d]: unknown trace.method %q", i, evm.Trace.Method)
+			}
+		}
+		if evm.LogFilter != nil && evm.WsURL == "" {
+			return fmt.Errorf("evm[%d]: log_filter requires ws_url", i)
+		}
+		if err := maintenance.Validate(evm.Maintenance); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if err := validateMinVersion(evm.MinVersion); err != nil {
+			return fmt.Errorf("evm[%d]: %w", i, err)
+		}
+		if evm.Proxy != "" {
+			if _, err := base.ParseProxy(evm.Proxy); err != nil {
+				return fmt.Errorf("evm[%d]: %w", i, err)
+			}
+		}
+		if _, err := base.NewTLSConfig(evm.TLS); err != nil {
+			return fmt.Errorf("evm[%d]: tls: %w", i, err)
+		}
+		if evm.BearerToken != "" && evm.BasicAuth != nil {
+			return fmt.Errorf("evm[%d]: bearer_token and basic_auth are mutually exclusive", i)
+		}
+		if evm.NodeExporter != nil && evm.NodeExporter.URL == "" {
+			return fmt.Errorf("evm[%d]: node_exporter.url is required", i)
+		}
+		if evm.NativeMetrics != nil && evm.NativeMetrics.URL == "" {
+			return fmt.Errorf("evm[%d]: native_metrics.url is required", i)
+		}
 	}
 
 	// Validate CometBFT configurations
@@ -78,20 +271,186 @@ func validateConfig(config *conf.NodeConfig) error {
 		if cometbft.ChainName == "" {
 			return fmt.Errorf("cometbft[%d]: chain_name is required", i)
 		}
+		if err := base.ValidateDelaySource(cometbft.DelaySource); err != nil {
+			return fmt.Errorf("cometbft[%d]: %w", i, err)
+		}
+		if err := base.ValidateURLStrategy(cometbft.URLStrategy); err != nil {
+			return fmt.Errorf("cometbft[%d]: %w", i, err)
+		}
+		if staking := cometbft.Staking; staking != nil {
+			if staking.ApiURL == "" {
+				return fmt.Errorf("cometbft[%d]: staking.api_url is required", i)
+			}
+			if staking.ValidatorAddress == "" {
+				return fmt.Errorf("cometbft[%d]: staking.validator_address is required", i)
+			}
+		}
+		if err := maintenance.Validate(cometbft.Maintenance); err != nil {
+			return fmt.Errorf("cometbft[%d]: %w", i, err)
+		}
+		if err := validateMinVersion(cometbft.MinVersion); err != nil {
+			return fmt.Errorf("cometbft[%d]: %w", i, err)
+		}
+		if cometbft.Proxy != "" {
+			if _, err := base.ParseProxy(cometbft.Proxy); err != nil {
+				return fmt.Errorf("cometbft[%d]: %w", i, err)
+			}
+		}
+		if _, err := base.NewTLSConfig(cometbft.TLS); err != nil {
+			return fmt.Errorf("cometbft[%d]: tls: %w", i, err)
+		}
+		if cometbft.BearerToken != "" && cometbft.BasicAuth != nil {
+			return fmt.Errorf("cometbft[%d]: bearer_token and basic_auth are mutually exclusive", i)
+		}
+		if cometbft.NodeExporter != nil && cometbft.NodeExporter.URL == "" {
+			return fmt.Errorf("cometbft[%d]: node_exporter.url is required", i)
+		}
+		if cometbft.NativeMetrics != nil && cometbft.NativeMetrics.URL == "" {
+			return fmt.Errorf("cometbft[%d]: native_metrics.url is required", i)
+		}
 	}
 
-	// Beacon support removed for Story protocol-only monitor
+	// Validate Cosmos SDK REST configurations
+	for i, rest := range config.CosmosRest {
+		if rest.HostName == "" {
+			return fmt.Errorf("cosmosrest[%d]: hostname is required", i)
+		}
+		if rest.ApiURL == "" {
+			return fmt.Errorf("cosmosrest[%d]: api_url is required", i)
+		}
+		if rest.ChainName == "" {
+			return fmt.Errorf("cosmosrest[%d]: chain_name is required", i)
+		}
+		if err := maintenance.Validate(rest.Maintenance); err != nil {
+			return fmt.Errorf("cosmosrest[%d]: %w", i, err)
+		}
+		if err := validateMinVersion(rest.MinVersion); err != nil {
+			return fmt.Errorf("cosmosrest[%d]: %w", i, err)
+		}
+	}
+
+	// Validate Cosmos SDK gRPC configurations
+	for i, g := range config.Grpc {
+		if g.HostName == "" {
+			return fmt.Errorf("grpc[%d]: hostname is required", i)
+		}
+		if g.GrpcAddr == "" {
+			return fmt.Errorf("grpc[%d]: grpc_addr is required", i)
+		}
+		if g.ChainName == "" {
+			return fmt.Errorf("grpc[%d]: chain_name is required", i)
+		}
+		if err := maintenance.Validate(g.Maintenance); err != nil {
+			return fmt.Errorf("grpc[%d]: %w", i, err)
+		}
+		if err := validateMinVersion(g.MinVersion); err != nil {
+			return fmt.Errorf("grpc[%d]: %w", i, err)
+		}
+	}
+
+	// Validate generic JSON-RPC configurations
+	for i, rpc := range config.JsonRpc {
+		if rpc.HostName == "" {
+			return fmt.Errorf("jsonrpc[%d]: hostname is required", i)
+		}
+		if rpc.RpcURL == "" {
+			return fmt.Errorf("jsonrpc[%d]: rpc_url is required", i)
+		}
+		if rpc.ChainName == "" {
+			return fmt.Errorf("jsonrpc[%d]: chain_name is required", i)
+		}
+		if err := jsonrpc.Validate(rpc); err != nil {
+			return fmt.Errorf("jsonrpc[%d]: %w", i, err)
+		}
+		if err := maintenance.Validate(rpc.Maintenance); err != nil {
+			return fmt.Errorf("jsonrpc[%d]: %w", i, err)
+		}
+	}
+
+	// Validate generic HTTP configurations
+	for i, h := range config.Http {
+		if h.HostName == "" {
+			return fmt.Errorf("http[%d]: hostname is required", i)
+		}
+		if h.URL == "" {
+			return fmt.Errorf("http[%d]: url is required", i)
+		}
+		if h.ChainName == "" {
+			return fmt.Errorf("http[%d]: chain_name is required", i)
+		}
+		if err := httpcheck.Validate(h); err != nil {
+			return fmt.Errorf("http[%d]: %w", i, err)
+		}
+		if err := maintenance.Validate(h.Maintenance); err != nil {
+			return fmt.Errorf("http[%d]: %w", i, err)
+		}
+	}
+
+	// Validate TCP probe configurations
+	for i, t := range config.Tcp {
+		if t.HostName == "" {
+			return fmt.Errorf("tcp[%d]: hostname is required", i)
+		}
+		if t.ChainName == "" {
+			return fmt.Errorf("tcp[%d]: chain_name is required", i)
+		}
+		if _, _, err := net.SplitHostPort(t.Address); err != nil {
+			return fmt.Errorf("tcp[%d]: address must be host:port: %w", i, err)
+		}
+		if err := maintenance.Validate(t.Maintenance); err != nil {
+			return fmt.Errorf("tcp[%d]: %w", i, err)
+		}
+	}
+
+	// Validate plugin configurations
+	for i, p := range config.Plugin {
+		if p.HostName == "" {
+			return fmt.Errorf("plugin[%d]: hostname is required", i)
+		}
+		if p.ChainName == "" {
+			return fmt.Errorf("plugin[%d]: chain_name is required", i)
+		}
+		if p.Command == "" {
+			return fmt.Errorf("plugin[%d]: command is required", i)
+		}
+		if err := maintenance.Validate(p.Maintenance); err != nil {
+			return fmt.Errorf("plugin[%d]: %w", i, err)
+		}
+	}
+
+	// Validate heartbeat configurations
+	for i, h := range config.Heartbeat {
+		if h.HostName == "" {
+			return fmt.Errorf("heartbeat[%d]: hostname is required", i)
+		}
+		if h.ChainName == "" {
+			return fmt.Errorf("heartbeat[%d]: chain_name is required", i)
+		}
+		if h.Secret == "" {
+			return fmt.Errorf("heartbeat[%d]: secret is required", i)
+		}
+		if err := maintenance.Validate(h.Maintenance); err != nil {
+			return fmt.Errorf("heartbeat[%d]: %w", i, err)
+		}
+	}
 
 	return nil
 }
 
-func setupHTTPServer() *http.Server {
+func setupHTTPServer(lifecycle *sched.Lifecycle, apiServer *api.Server) *http.Server {
+	gatherer := prometheus.DefaultGatherer
+	if ac.Metrics != nil {
+		gatherer = base.ConstLabelsGatherer(gatherer, ac.Metrics.ConstLabels)
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	mux.HandleFunc("/ready", lifecycle.ReadyHandler)
+	apiServer.Register(mux)
 
 	return &http.Server{
 		Addr:         ":3002",
@@ -102,47 +461,265 @@ func setupHTTPServer() *http.Server {
 	}
 }
 
-func startPprofServer() {
-	pprofServer := &http.Server{
+// serverSubsystem runs an http.Server as a lifecycle subsystem
+func serverSubsystem(name string, server *http.Server, dependsOn ...string) *sched.Subsystem {
+	var (
+		mu     sync.Mutex
+		runErr error
+	)
+
+	return &sched.Subsystem{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func() error {
+			ln, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			glog.Infof("%s server listening on %s", name, server.Addr)
+			go func() {
+				if err := server.Serve(ln); err != http.ErrServerClosed {
+					glog.Errorf("%s server error: %v", name, err)
+					mu.Lock()
+					runErr = err
+					mu.Unlock()
+				}
+			}()
+			return nil
+		},
+		Stop: func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := server.Shutdown(ctx); err != nil {
+				glog.Errorf("Error during %s server shutdown: %v", name, err)
+			} else {
+				glog.Infof("%s server shutdown completed", name)
+			}
+		},
+		Ready: func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			return runErr
+		},
+	}
+}
+
+// headBufferSubsystem publishes checker heads into the head event ring buffer
+func headBufferSubsystem(ring *ringbuf.Ring) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "head_buffer",
+		Start: func() error {
+			base.RegisterHeadSink(ring)
+			return nil
+		},
+		Stop: func() {
+			// Checkers may still be running when their shutdown timed out,
+			// no head is written into the mapping once the sink is removed
+			base.UnregisterHeadSink(ring)
+			if err := ring.Close(); err != nil {
+				glog.Errorf("Error closing head buffer: %v", err)
+			}
+		},
+	}
+}
+
+// historySubsystem persists check outcomes, heads and health transitions of
+// all nodes. With a head buffer, the heads are read from the ring.
+func historySubsystem(ctx context.Context, store *history.Store, ring *ringbuf.Ring) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "history",
+		Start: func() error {
+			base.RegisterCheckSink(store)
+			if ring != nil {
+				store.ReadHeads(ring.NewReader())
+			} else {
+				base.RegisterHeadSink(store)
+			}
+			base.ConsumeTransitions(ctx, "history", store.HandleTransition)
+			store.Start(ctx)
+			return nil
+		},
+		// Checkers are stopped by now, the remaining samples are flushed
+		Stop: store.Stop,
+	}
+}
+
+// alertingSubsystem groups health transitions into incidents and delivers notifications
+func alertingSubsystem(ctx context.Context, manager *alert.Manager) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "alerting",
+		Start: func() error {
+			base.ConsumeTransitions(ctx, "alerting", manager.HandleTransition)
+			manager.Start(ctx)
+			return nil
+		},
+		Stop: manager.Stop,
+	}
+}
+
+// slaSubsystem records downtime intervals of all nodes for uptime reports
+func slaSubsystem(ctx context.Context, downtime *sla.Tracker) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "sla",
+		Start: func() error {
+			base.ConsumeTransitions(ctx, "sla", downtime.HandleTransition)
+			return nil
+		},
+		Stop: func() {
+			if err := downtime.Close(); err != nil {
+				glog.Errorf("Error closing downtime log: %v", err)
+			}
+		},
+	}
+}
+
+// eventLogSubsystem appends the health transitions of all nodes to the event log
+func eventLogSubsystem(ctx context.Context, log *eventlog.Log) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "event_log",
+		Start: func() error {
+			base.ConsumeTransitions(ctx, "event_log", log.HandleTransition)
+			return nil
+		},
+		Stop: func() {
+			if err := log.Close(); err != nil {
+				glog.Errorf("Error closing event log: %v", err)
+			}
+		},
+	}
+}
+
+// fileSDSubsystem watches file_sd target files and hot-applies their targets to the controller
+func fileSDSubsystem(ctx context.Context, configs []*conf.FileSD, controller *sched.Controller) *sched.Subsystem {
+	watcher := filesd.NewWatcher(configs, controller.ApplyTargets, validateTargets)
+	return &sched.Subsystem{
+		Name:      "file_sd",
+		DependsOn: []string{"controller"},
+		Start: func() error {
+			watcher.Start(ctx)
+			return nil
+		},
+		Stop: watcher.Stop,
+	}
+}
+
+// dnsSDSubsystem re-resolves DNS SRV names and hot-applies the discovered targets to the controller
+func dnsSDSubsystem(ctx context.Context, configs []*conf.DNSSD, controller *sched.Controller) *sched.Subsystem {
+	watcher := dnssd.NewWatcher(configs, controller.ApplyTargets, validateTargets)
+	return &sched.Subsystem{
+		Name:      "dns_sd",
+		DependsOn: []string{"controller"},
+		Start: func() error {
+			watcher.Start(ctx)
+			return nil
+		},
+		Stop: watcher.Stop,
+	}
+}
+
+// haSubsystem runs leader election, only the leader's controller runs checks
+func haSubsystem(ctx context.Context, leader *ha.Leader) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name:      "ha",
+		DependsOn: []string{"controller"},
+		Start: func() error {
+			leader.Start(ctx)
+			return nil
+		},
+		// Releasing the lease on shutdown lets a standby take over at once
+		Stop: leader.Stop,
+	}
+}
+
+// referenceSubsystem compares tracked node heads against public reference endpoints
+func referenceSubsystem(ctx context.Context, monitor *reference.Monitor) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "reference",
+		Start: func() error {
+			monitor.Start(ctx)
+			return nil
+		},
+		Stop: monitor.Stop,
+	}
+}
+
+func deadManSubsystem(ctx context.Context, monitor *deadman.Monitor) *sched.Subsystem {
+	return &sched.Subsystem{
+		Name: "dead_man_switch",
+		Start: func() error {
+			monitor.Start(ctx)
+			return nil
+		},
+		Stop: monitor.Stop,
+	}
+}
+
+func newDowntimeTracker(config *conf.SLA) (*sla.Tracker, error) {
+	if config == nil {
+		return sla.NewTracker("", 90*24*time.Hour)
+	}
+	retention := config.RetentionDay
+	if retention <= 0 {
+		retention = 90
+	}
+	return sla.NewTracker(config.Path, time.Duration(retention)*24*time.Hour)
+}
+
+func newAlertManager(config *conf.Alerting) (*alert.Manager, error) {
+	if config == nil {
+		return alert.NewManager(0, 0, nil, nil), nil
+	}
+
+	var notifiers []alert.Notifier
+	for i, nc := range config.Notifiers {
+		if nc == nil {
+			continue
+		}
+		notifier, err := alert.NewNotifier(nc)
+		if err != nil {
+			return nil, fmt.Errorf("alerting.notifiers[%d]: %w", i, err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	rules, err := alert.NewRules(config.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("alerting.%w", err)
+	}
+	routes, err := alert.NewRoutes(config.Routes, notifiers, config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("alerting.%w", err)
+	}
+	audit, err := alert.NewAuditLog(config.AuditLog)
+	if err != nil {
+		return nil, err
+	}
+	return alert.NewManager(
+		time.Duration(config.GroupWindowSecond)*time.Second,
+		time.Duration(config.RepeatIntervalSecond)*time.Second,
+		notifiers,
+		audit,
+		alert.WithRules(rules),
+		alert.WithRoutes(routes),
+		alert.WithRetention(time.Duration(config.ResolvedRetentionHour)*time.Hour, config.MaxResolvedIncidents),
+	), nil
+}
+
+func setupPprofServer() *http.Server {
+	return &http.Server{
 		Addr:         "localhost:6062",
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 5 * time.Second,
 		IdleTimeout:  15 * time.Second,
 	}
-
-	go func() {
-		if err := pprofServer.ListenAndServe(); err != http.ErrServerClosed {
-			glog.Errorf("pprof server error: %v", err)
-		}
-	}()
 }
 
-func gracefulShutdown(ctx context.Context, cancel context.CancelFunc, controller *sched.Controller, server *http.Server) {
+func waitForSignal() {
 	// Wait for interrupt signals
 	term := make(chan os.Signal, 1)
 	signal.Notify(term, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 
 	sig := <-term
 	glog.Infof("Received signal %v, starting graceful shutdown...", sig)
-
-	// Create shutdown timeout context
-	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 30*time.Second)
-	defer shutdownCancel()
-
-	// Stop controller
-	glog.Info("Stopping controller...")
-	controller.Stop()
-
-	// Cancel application context
-	cancel()
-
-	// Shutdown HTTP server
-	glog.Info("Shutting down HTTP server...")
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		glog.Errorf("Error during server shutdown: %v", err)
-	} else {
-		glog.Info("HTTP server shutdown completed")
-	}
 }
 
 func main() {
@@ -153,35 +730,210 @@ func main() {
 		glog.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Subcommands render artifacts from the config or probe the monitor and
+	// exit, with status 1 on failure
+	if flag.NArg() > 0 {
+		if err := runCommand(flag.Arg(0), flag.Args()[1:], os.Stdout); err != nil {
+			glog.Errorf("Failed to run %s: %v", flag.Arg(0), err)
+			fmt.Fprintln(os.Stderr, err)
+			glog.Flush()
+			os.Exit(1)
+		}
+		return
+	}
+
 	glog.Infof("Loaded config from %s", confPath)
-	glog.Infof("Monitoring %d EVM chains, %d CometBFT chains",
-		len(ac.Evm), len(ac.Cometbft))
+	glog.Infof("Monitoring %d EVM chains, %d CometBFT chains, %d Cosmos REST endpoints, %d gRPC endpoints, %d JSON-RPC endpoints, %d HTTP endpoints, %d TCP ports, %d plugins, %d heartbeat nodes",
+		len(ac.Evm), len(ac.Cometbft), len(ac.CosmosRest), len(ac.Grpc), len(ac.JsonRpc), len(ac.Http), len(ac.Tcp), len(ac.Plugin), len(ac.Heartbeat))
+
+	// Export the metrics of the checkers through the default registry
+	namespace := ""
+	if ac.Metrics != nil {
+		namespace = ac.Metrics.Namespace
+	}
+	if err := base.RegisterMetrics(prometheus.DefaultRegisterer, namespace); err != nil {
+		glog.Fatalf("Failed to register metrics: %v", err)
+	}
 
 	// Create application context
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Create controller
-	controller := sched.NewController(ctx, &ac)
+	// Track recent heads of all nodes for cross-node comparison
+	tracker := heads.NewTracker(64, 2*time.Minute)
+	base.RegisterHeadSink(tracker)
 
-	// Setup HTTP server
-	server := setupHTTPServer()
+	// Score block intervals against the baseline of their chain
+	if ac.Anomaly != nil {
+		base.RegisterHeadSink(anomaly.NewDetector(ac.Anomaly))
+	}
 
-	// Start pprof server
-	startPprofServer()
+	// Export the error budget burn rates of the SLOs
+	if len(ac.SLOs) > 0 {
+		base.RegisterCheckSink(slo.NewTracker(ac.SLOs))
+	}
 
-	// Start graceful shutdown handler
-	go gracefulShutdown(ctx, cancel, controller, server)
+	// Route outbound requests through the proxy before any client is created
+	if err := base.SetProxy(ac.Proxy); err != nil {
+		glog.Fatalf("Failed to set proxy: %v", err)
+	}
 
-	// Start controller
-	glog.Info("Starting blockchain monitor...")
-	controller.Start()
+	// Create alert manager
+	alerts, err := newAlertManager(ac.Alerting)
+	if err != nil {
+		glog.Fatalf("Failed to create alert manager: %v", err)
+	}
+
+	// Raise alerts for nodes crossing their block delay, response time and
+	// height lag thresholds
+	if ac.Alerting != nil && len(ac.Alerting.Thresholds) > 0 {
+		thresholds, err := alert.NewThresholdWatcher(ac.Alerting.Thresholds)
+		if err != nil {
+			glog.Fatalf("Failed to create threshold watcher: alerting.%v", err)
+		}
+		base.RegisterHeadSink(thresholds)
+		base.RegisterCheckSink(thresholds)
+	}
+
+	// Track downtime of all nodes for uptime reports
+	downtime, err := newDowntimeTracker(ac.SLA)
+	if err != nil {
+		glog.Fatalf("Failed to create downtime tracker: %v", err)
+	}
+
+	// Spread periodic checks of targets sharing the same interval and bound
+	// the outbound requests in flight
+	if ac.Scheduling != nil {
+		base.SetPhaseOffset(!ac.Scheduling.DisablePhaseOffset)
+		base.SetConcurrencyLimit(ac.Scheduling.MaxConcurrentRequests)
+	}
+
+	// Flag nodes running older versions than expected for their chain
+	base.SetMinVersions(ac.MinVersions)
 
-	// Start HTTP server
-	glog.Infof("HTTP server listening on %s", server.Addr)
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
-		glog.Errorf("HTTP server error: %v", err)
+	// Open the history database of check results
+	var store *history.Store
+	if ac.History != nil {
+		retention := ac.History.RetentionDay
+		if retention <= 0 {
+			retention = 7
+		}
+		if store, err = history.Open(ac.History.Path, time.Duration(retention)*24*time.Hour); err != nil {
+			glog.Fatalf("Failed to open history: %v", err)
+		}
+		// Silences created through the API are kept in the history database
+		silences, err := store.Silences()
+		if err != nil {
+			glog.Fatalf("Failed to load silences: %v", err)
+		}
+		base.RestoreSilences(silences)
 	}
 
+	// Map the ring buffer of head events, shared with the history writer
+	var ring *ringbuf.Ring
+	if ac.HeadBuffer != nil {
+		slots := ac.HeadBuffer.Slots
+		if slots <= 0 {
+			slots = 4096
+		}
+		if ring, err = ringbuf.Open(ac.HeadBuffer.Path, slots); err != nil {
+			glog.Fatalf("Failed to open head buffer: %v", err)
+		}
+	}
+
+	// Keep recent events of all checkers for the API, heads are left out as
+	// they would crowd out everything else
+	recent := events.NewRecorder(1000)
+	events.Consume(ctx, events.Default, events.Subscribe("api", 1024, events.KindTransition, events.KindState, events.KindError), recent.Record)
+
+	// Create controller, in HA mode it stands by until the lease is acquired
+	// and connects to no node before
+	var controllerOpts []sched.Option
+	if ac.HA != nil {
+		controllerOpts = append(controllerOpts, sched.WithStandby())
+		alerts.SetActive(false)
+	}
+	controller := sched.NewController(ctx, &ac, controllerOpts...)
+
+	// Declare subsystems, the lifecycle manager starts them in dependency order
+	lifecycle := sched.NewLifecycle()
+	controllerSubsystem := controller.Subsystem()
+	controllerSubsystem.DependsOn = append(controllerSubsystem.DependsOn, "alerting", "sla")
+	subsystems := []*sched.Subsystem{
+		controllerSubsystem,
+		alertingSubsystem(ctx, alerts),
+		slaSubsystem(ctx, downtime),
+		serverSubsystem("http", setupHTTPServer(lifecycle, api.NewServer(controller, tracker, alerts, downtime, store, recent, ac.Admin)), "controller"),
+		serverSubsystem("pprof", setupPprofServer()),
+	}
+	if ring != nil {
+		controllerSubsystem.DependsOn = append(controllerSubsystem.DependsOn, "head_buffer")
+		subsystems = append(subsystems, headBufferSubsystem(ring))
+	}
+	if store != nil {
+		controllerSubsystem.DependsOn = append(controllerSubsystem.DependsOn, "history")
+		historySub := historySubsystem(ctx, store, ring)
+		if ring != nil {
+			// The history writer reads the ring until it stops
+			historySub.DependsOn = append(historySub.DependsOn, "head_buffer")
+		}
+		subsystems = append(subsystems, historySub)
+	}
+	if ac.EventLog != nil {
+		eventLog, err := eventlog.Open(ac.EventLog)
+		if err != nil {
+			glog.Fatalf("Failed to open event log: %v", err)
+		}
+		controllerSubsystem.DependsOn = append(controllerSubsystem.DependsOn, "event_log")
+		subsystems = append(subsystems, eventLogSubsystem(ctx, eventLog))
+	}
+	if len(ac.FileSD) > 0 {
+		subsystems = append(subsystems, fileSDSubsystem(ctx, ac.FileSD, controller))
+	}
+	if len(ac.DNSSD) > 0 {
+		subsystems = append(subsystems, dnsSDSubsystem(ctx, ac.DNSSD, controller))
+	}
+	if ac.HA != nil {
+		// Only the leader raises alerts, a replica stepping down forgets the
+		// incidents its checks opened
+		onStarted := func() {
+			alerts.SetActive(true)
+			controller.SetActive(true)
+		}
+		onStopped := func() {
+			controller.SetActive(false)
+			alerts.SetActive(false)
+		}
+		leader, err := ha.NewLeader(ac.HA, onStarted, onStopped)
+		if err != nil {
+			glog.Fatalf("Failed to set up HA: %v", err)
+		}
+		subsystems = append(subsystems, haSubsystem(ctx, leader))
+	}
+	if len(ac.References) > 0 {
+		subsystems = append(subsystems, referenceSubsystem(ctx, reference.NewMonitor(ac.References, tracker)))
+	}
+	if len(ac.DeadManSwitches) > 0 {
+		monitor := deadman.NewMonitor(ac.DeadManSwitches)
+		base.RegisterCheckSink(monitor)
+		subsystems = append(subsystems, deadManSubsystem(ctx, monitor))
+	}
+	for _, s := range subsystems {
+		if err := lifecycle.Register(s); err != nil {
+			glog.Fatalf("Failed to register subsystem: %v", err)
+		}
+	}
+
+	glog.Info("Starting blockchain monitor...")
+	if err := lifecycle.Start(); err != nil {
+		glog.Fatalf("Failed to start: %v", err)
+	}
+
+	waitForSignal()
+
+	// Stop subsystems in reverse dependency order
+	lifecycle.Stop()
+	cancel()
+
 	glog.Info("Application shutdown completed")
 }
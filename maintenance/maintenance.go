@@ -0,0 +1,139 @@
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"storymonitor/conf"
+)
+
+// window is one parsed maintenance window, either a one-off range or a
+// recurring cron schedule
+type window struct {
+	start, end time.Time
+
+	cron     *cronSpec
+	duration time.Duration
+	location *time.Location
+
+	// Cron windows only change at minute boundaries, the result is cached per minute
+	mu           sync.Mutex
+	cachedMinute time.Time
+	cached       bool
+}
+
+func parseWindow(c *conf.MaintenanceWindow) (*window, error) {
+	if c == nil {
+		return nil, fmt.Errorf("maintenance window is empty")
+	}
+
+	if c.Cron != "" {
+		if c.Start != "" || c.End != "" {
+			return nil, fmt.Errorf("maintenance window takes either cron or start/end")
+		}
+		spec, err := parseCron(c.Cron)
+		if err != nil {
+			return nil, err
+		}
+		if c.DurationMinute <= 0 {
+			return nil, fmt.Errorf("maintenance cron %q requires duration_minute", c.Cron)
+		}
+		location := time.UTC
+		if c.Timezone != "" {
+			if location, err = time.LoadLocation(c.Timezone); err != nil {
+				return nil, fmt.Errorf("invalid maintenance timezone %q: %w", c.Timezone, err)
+			}
+		}
+		return &window{
+			cron:     spec,
+			duration: time.Duration(c.DurationMinute) * time.Minute,
+			location: location,
+		}, nil
+	}
+
+	start, err := time.Parse(time.RFC3339, c.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance start %q: %w", c.Start, err)
+	}
+	end, err := time.Parse(time.RFC3339, c.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance end %q: %w", c.End, err)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("maintenance end %s is not after start %s", c.End, c.Start)
+	}
+	return &window{start: start, end: end}, nil
+}
+
+// active reports whether t falls into the window. A cron window is active for
+// duration after each minute the schedule fires.
+func (w *window) active(t time.Time) bool {
+	if w.cron == nil {
+		return !t.Before(w.start) && t.Before(w.end)
+	}
+
+	minute := t.Truncate(time.Minute)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if minute.Equal(w.cachedMinute) {
+		return w.cached
+	}
+
+	t = t.In(w.location)
+	from := t.Add(-w.duration)
+	active := false
+	for m := t.Truncate(time.Minute); m.After(from); m = m.Add(-time.Minute) {
+		if w.cron.matches(m) {
+			active = true
+			break
+		}
+	}
+	w.cachedMinute = minute
+	w.cached = active
+	return active
+}
+
+// Validate checks the maintenance windows of a target
+func Validate(windows []*conf.MaintenanceWindow) error {
+	for i, c := range windows {
+		if _, err := parseWindow(c); err != nil {
+			return fmt.Errorf("maintenance[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Schedule is the set of maintenance windows of a target. A nil Schedule is
+// never in maintenance.
+type Schedule struct {
+	windows []*window
+}
+
+// New returns the schedule of a target's maintenance windows, or nil if it has
+// none. Invalid windows are skipped, they are rejected at startup by Validate.
+func New(windows []*conf.MaintenanceWindow) *Schedule {
+	s := &Schedule{}
+	for _, c := range windows {
+		if w, err := parseWindow(c); err == nil {
+			s.windows = append(s.windows, w)
+		}
+	}
+	if len(s.windows) == 0 {
+		return nil
+	}
+	return s
+}
+
+// Active reports whether t falls into any maintenance window
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil {
+		return false
+	}
+	for _, w := range s.windows {
+		if w.active(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestOneOffWindow(t *testing.T) {
+	s := New([]*conf.MaintenanceWindow{{Start: "2024-05-01T10:00:00Z", End: "2024-05-01T12:00:00Z"}})
+
+	cases := map[string]bool{
+		"2024-05-01T09:59:59Z": false,
+		"2024-05-01T10:00:00Z": true,
+		"2024-05-01T11:30:00Z": true,
+		"2024-05-01T12:00:00Z": false,
+	}
+	for at, want := range cases {
+		if got := s.Active(mustTime(t, at)); got != want {
+			t.Errorf("Active(%s) = %v, want %v", at, got, want)
+		}
+	}
+}
+
+func TestCronWindow(t *testing.T) {
+	// Sundays at 02:30 for 90 minutes
+	s := New([]*conf.MaintenanceWindow{{Cron: "30 2 * * 0", DurationMinute: 90}})
+
+	cases := map[string]bool{
+		"2024-05-05T02:29:00Z": false,
+		"2024-05-05T02:30:00Z": true,
+		"2024-05-05T03:59:59Z": true,
+		"2024-05-05T04:00:00Z": false,
+		"2024-05-06T02:45:00Z": false, // Monday
+	}
+	for at, want := range cases {
+		if got := s.Active(mustTime(t, at)); got != want {
+			t.Errorf("Active(%s) = %v, want %v", at, got, want)
+		}
+	}
+}
+
+func TestCronTimezone(t *testing.T) {
+	s := New([]*conf.MaintenanceWindow{{Cron: "0 9 * * 1-5", DurationMinute: 30, Timezone: "Asia/Tokyo"}})
+
+	if !s.Active(mustTime(t, "2024-05-06T00:10:00Z")) {
+		t.Error("expected 09:10 JST on a Monday to be in maintenance")
+	}
+	if s.Active(mustTime(t, "2024-05-06T09:10:00Z")) {
+		t.Error("expected 09:10 UTC to be outside maintenance")
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	spec, err := parseCron("*/15 0-6/2 1,15 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.minute != 1<<0|1<<15|1<<30|1<<45 {
+		t.Errorf("unexpected minute set %b", spec.minute)
+	}
+	if spec.hour != 1<<0|1<<2|1<<4|1<<6 {
+		t.Errorf("unexpected hour set %b", spec.hour)
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := [][]*conf.MaintenanceWindow{
+		{{Cron: "0 2 * * *"}},
+		{{Start: "2024-05-01T12:00:00Z", End: "2024-05-01T10:00:00Z"}},
+		{{Start: "yesterday", End: "2024-05-01T10:00:00Z"}},
+		{{Cron: "0 2 * * *", DurationMinute: 10, Start: "2024-05-01T10:00:00Z"}},
+		{{Cron: "0 2 * * *", DurationMinute: 10, Timezone: "Mars/Olympus"}},
+		{nil},
+	}
+	for i, windows := range invalid {
+		if err := Validate(windows); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+
+	if err := Validate([]*conf.MaintenanceWindow{{Cron: "0 2 * * *", DurationMinute: 10}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if New(nil) != nil {
+		t.Error("expected nil schedule without windows")
+	}
+	var s *Schedule
+	if s.Active(time.Now()) {
+		t.Error("nil schedule must never be in maintenance")
+	}
+}
@@ -0,0 +1,230 @@
+// Package plugincheck runs custom checkers as external commands. On every
+// check the command gets a Request as JSON on stdin and writes a Response as
+// JSON to stdout, the monitor records it through the base helpers like the
+// built-in checkers.
+package plugincheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+)
+
+// Request is written to the stdin of the plugin
+type Request struct {
+	ChainName string            `json:"chain_name"`
+	HostName  string            `json:"hostname"`
+	Config    map[string]string `json:"config"`
+}
+
+// Response is read from the stdout of the plugin
+type Response struct {
+	// Checks are reported as health checks with the check name as endpoint_type
+	Checks []Check `json:"checks"`
+	// BlockHeight and BlockTime are the head of the node, if the plugin follows one
+	BlockHeight int64 `json:"block_height"`
+	BlockTime   int64 `json:"block_time"`
+	// Metrics are exported as story_node_plugin_metric{metric="<name>"}
+	Metrics []Metric `json:"metrics"`
+	// NodeVersion and ChainId are exported in the info labels when set
+	NodeVersion string `json:"node_version"`
+	ChainId     string `json:"chain_id"`
+}
+
+// Check is one health check performed by the plugin
+type Check struct {
+	Name       string `json:"name"`
+	Healthy    bool   `json:"healthy"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error"`
+}
+
+// Metric is one value reported by the plugin
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+type PluginCheckerImpl struct {
+	*conf.Plugin
+	base.BaseChecker
+
+	ctx context.Context
+
+	timeout    time.Duration
+	lastHeight int64
+}
+
+func NewPluginCheckerImpl(ctx context.Context, conf *conf.Plugin) base.CheckerTrait {
+	checker := &PluginCheckerImpl{
+		Plugin: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ProtocolName: conf.ProtocolName,
+
+			FailureDomain: conf.FailureDomain,
+			Maintenance:   maintenance.New(conf.Maintenance),
+		},
+		ctx:     ctx,
+		timeout: 30 * time.Second,
+	}
+
+	// Set defaults
+	if checker.CheckSecond == 0 {
+		checker.CheckSecond = 30
+	}
+	if checker.TimeoutSecond > 0 {
+		checker.timeout = time.Duration(checker.TimeoutSecond) * time.Second
+	}
+
+	base.RegisterTargetEndpoint("plugin", "plugin", conf.ChainName, conf.HostName, conf.Command)
+	return checker
+}
+
+// Run executes the plugin command once and decodes its response. A plugin
+// exiting with an error fails, with the last line of its stderr as reason.
+func Run(ctx context.Context, command string, args []string, request *Request) (*Response, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if lines := strings.Split(strings.TrimSpace(stderr.String()), "\n"); lines[len(lines)-1] != "" {
+			return nil, fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+		}
+		return nil, err
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("invalid plugin response: %w", err)
+	}
+	return &response, nil
+}
+
+// record exports the response of the plugin
+func (chain *PluginCheckerImpl) record(response *Response) {
+	for _, c := range response.Checks {
+		if c.Name == "" {
+			continue
+		}
+		if !c.Healthy {
+			glog.Warningf("[record] Node %s plugin check %s unhealthy: %s", chain.Plugin.HostName, c.Name, c.Error)
+		}
+		chain.RecordHealthStatus(c.Name, c.Healthy)
+		if c.DurationMs > 0 {
+			chain.RecordResponseTime(c.Name, time.Duration(c.DurationMs)*time.Millisecond)
+		}
+	}
+
+	for _, m := range response.Metrics {
+		base.PluginMetric.WithLabelValues(chain.AddLabelValues(m.Name)...).Set(m.Value)
+	}
+
+	if response.ChainId != "" {
+		chain.BaseChecker.ChainId = response.ChainId
+	}
+	if response.NodeVersion != "" {
+		chain.SetNodeVersion(response.NodeVersion)
+	}
+
+	if response.BlockHeight > 0 {
+		base.LatestBlockHeight.WithLabelValues(chain.AddLabelValues()...).Set(float64(response.BlockHeight))
+		if response.BlockHeight > chain.lastHeight {
+			chain.lastHeight = response.BlockHeight
+			chain.UpdateLastBlockTime()
+			if response.BlockTime > 0 {
+				chain.RecordBlockDelay(time.Unix(response.BlockTime, 0))
+			}
+		}
+	}
+}
+
+func (chain *PluginCheckerImpl) check() {
+	state := base.StateDown
+	chain.HealthCheckOperation("plugin", func() error {
+		ctx, cancel := context.WithTimeout(chain.ctx, chain.timeout)
+		defer cancel()
+
+		response, err := Run(ctx, chain.Command, chain.Args, &Request{
+			ChainName: chain.Plugin.ChainName,
+			HostName:  chain.Plugin.HostName,
+			Config:    chain.Config,
+		})
+		if err != nil {
+			glog.Errorf("[check] Node %s plugin %s fail: %v", chain.Plugin.HostName, chain.Command, err)
+			return err
+		}
+		chain.record(response)
+
+		// The plugin ran, its own checks decide whether the node is healthy
+		state = base.StateSubscribed
+		for _, c := range response.Checks {
+			if !c.Healthy {
+				state = base.StateDegraded
+			}
+		}
+		return nil
+	})
+	chain.SetState(state)
+}
+
+func (chain *PluginCheckerImpl) Start() {
+	glog.Infof("[Plugin] Starting checker for %s (%s) %s", chain.Plugin.HostName, chain.Plugin.ChainName, chain.Command)
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 30) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		chain.check()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[Plugin] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+func (chain *PluginCheckerImpl) GetHostName() string {
+	return chain.Plugin.HostName
+}
+
+func (chain *PluginCheckerImpl) GetChainId() string {
+	return chain.BaseChecker.ChainId
+}
+
+func (chain *PluginCheckerImpl) GetNodeVersion() string {
+	return chain.BaseChecker.NodeVersion
+}
+
+func (chain *PluginCheckerImpl) GetChainName() string {
+	return chain.Plugin.ChainName
+}
+
+func (chain *PluginCheckerImpl) GetProtocolName() string {
+	return chain.Plugin.ProtocolName
+}
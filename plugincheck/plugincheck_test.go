@@ -0,0 +1,35 @@
+package plugincheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	// The script echoes the hostname of the request back as a check name
+	script := `read request; host=$(echo "$request" | sed 's/.*"hostname":"\([^"]*\)".*/\1/')
+echo "{\"checks\":[{\"name\":\"$host\",\"healthy\":true}],\"block_height\":42,\"metrics\":[{\"name\":\"lag\",\"value\":3}]}"`
+	response, err := Run(context.Background(), "sh", []string{"-c", script}, &Request{ChainName: "story", HostName: "indexer-1"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(response.Checks) != 1 || response.Checks[0].Name != "indexer-1" || !response.Checks[0].Healthy {
+		t.Errorf("unexpected checks %+v", response.Checks)
+	}
+	if response.BlockHeight != 42 || len(response.Metrics) != 1 || response.Metrics[0].Value != 3 {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	_, err := Run(context.Background(), "sh", []string{"-c", "echo 'indexer unreachable' >&2; exit 2"}, &Request{})
+	if err == nil || !strings.Contains(err.Error(), "indexer unreachable") {
+		t.Fatalf("Run = %v, want the stderr reason", err)
+	}
+
+	_, err = Run(context.Background(), "sh", []string{"-c", "cat >/dev/null; echo not json"}, &Request{})
+	if err == nil || !strings.Contains(err.Error(), "invalid plugin response") {
+		t.Fatalf("Run = %v, want an invalid response error", err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"storymonitor/sched"
+)
+
+type probeStats struct {
+	Active   bool                 `json:"active"`
+	Checkers []sched.CheckerStats `json:"checkers"`
+}
+
+// runProbe queries the stats of the running monitor and fails unless every
+// checker of the target is running, for container and Kubernetes exec probes
+func runProbe(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("probe", flag.ContinueOnError)
+	fs.SetOutput(out)
+	host := fs.String("target", "", "hostname of the target to probe")
+	chain := fs.String("chain", "", "chain_name of the target, any chain if empty")
+	addr := fs.String("url", "http://127.0.0.1:3002", "base URL of the running monitor")
+	timeout := fs.Duration("timeout", 5*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *host == "" {
+		return fmt.Errorf("probe: --target is required")
+	}
+
+	cli := &http.Client{Timeout: *timeout}
+	resp, err := cli.Get(strings.TrimSuffix(*addr, "/") + "/stats")
+	if err != nil {
+		return fmt.Errorf("probe: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("probe: monitor responded with status %d", resp.StatusCode)
+	}
+
+	var stats probeStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return fmt.Errorf("probe: invalid stats response: %w", err)
+	}
+	return probeTarget(&stats, *chain, *host, out)
+}
+
+// probeTarget checks the checkers of chain/host in the stats
+func probeTarget(stats *probeStats, chain, host string, out io.Writer) error {
+	// A standby monitor runs no checkers and is ready to take over
+	if !stats.Active {
+		_, err := fmt.Fprintf(out, "%s: monitor on standby\n", host)
+		return err
+	}
+
+	found := 0
+	for _, c := range stats.Checkers {
+		if c.HostName != host || (chain != "" && c.ChainName != chain) {
+			continue
+		}
+		found++
+		if c.Lifecycle != sched.LifecycleRunning {
+			detail := ""
+			if c.LastError != nil {
+				detail = fmt.Sprintf(", last error in %s: %s", c.LastError.Check, c.LastError.Error)
+			}
+			return fmt.Errorf("%s checker of %s (%s) is %s%s", c.Kind, host, c.ChainName, c.Lifecycle, detail)
+		}
+	}
+	if found == 0 {
+		return fmt.Errorf("no checkers of target %s", host)
+	}
+	_, err := fmt.Fprintf(out, "%s: %d checkers running\n", host, found)
+	return err
+}
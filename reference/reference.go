@@ -0,0 +1,238 @@
+// Package reference polls public reference endpoints and compares every
+// monitored node against them, so a node that is healthy by its own account
+// but behind the network is still caught.
+package reference
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/heads"
+
+	"github.com/golang/glog"
+)
+
+const (
+	TypeEvm      = "evm"
+	TypeCometbft = "cometbft"
+)
+
+// Head is the latest block reported by a reference endpoint
+type Head struct {
+	Height    uint64
+	BlockTime time.Time
+}
+
+type evmBlockResponse struct {
+	Result *struct {
+		Number    string `json:"number"`
+		Timestamp string `json:"timestamp"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type cometbftStatusResponse struct {
+	Result struct {
+		SyncInfo struct {
+			LatestBlockHeight string    `json:"latest_block_height"`
+			LatestBlockTime   time.Time `json:"latest_block_time"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+// Monitor polls reference endpoints and exports the lag of tracked nodes behind them
+type Monitor struct {
+	refs    []*conf.Reference
+	tracker *heads.Tracker
+
+	mu     sync.Mutex
+	latest map[*conf.Reference]Head
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewMonitor(refs []*conf.Reference, tracker *heads.Tracker) *Monitor {
+	return &Monitor{
+		refs:    refs,
+		tracker: tracker,
+		latest:  make(map[*conf.Reference]Head),
+	}
+}
+
+func parseHex(s string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+}
+
+func fetchEvm(cli *base.Client, url string) (Head, error) {
+	body, err := cli.Fetch(url, http.MethodPost, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_getBlockByNumber",
+		"params":  []interface{}{"latest", false},
+	}, nil)
+	if err != nil {
+		return Head{}, err
+	}
+
+	var resp evmBlockResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Head{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.Error != nil {
+		return Head{}, fmt.Errorf("rpc error: %s", resp.Error.Message)
+	}
+	if resp.Result == nil {
+		return Head{}, fmt.Errorf("empty result")
+	}
+	height, err := parseHex(resp.Result.Number)
+	if err != nil {
+		return Head{}, fmt.Errorf("invalid block number %q: %w", resp.Result.Number, err)
+	}
+	timestamp, err := parseHex(resp.Result.Timestamp)
+	if err != nil {
+		return Head{}, fmt.Errorf("invalid block timestamp %q: %w", resp.Result.Timestamp, err)
+	}
+	return Head{Height: height, BlockTime: time.Unix(int64(timestamp), 0)}, nil
+}
+
+func fetchCometbft(cli *base.Client, url string) (Head, error) {
+	body, err := cli.Fetch(strings.TrimRight(url, "/")+"/status", http.MethodGet, nil, nil)
+	if err != nil {
+		return Head{}, err
+	}
+
+	var resp cometbftStatusResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Head{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	height, err := strconv.ParseUint(resp.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return Head{}, fmt.Errorf("invalid block height %q: %w", resp.Result.SyncInfo.LatestBlockHeight, err)
+	}
+	return Head{Height: height, BlockTime: resp.Result.SyncInfo.LatestBlockTime}, nil
+}
+
+// Fetch queries the latest head of a reference endpoint
+func Fetch(cli *base.Client, ref *conf.Reference) (Head, error) {
+	if ref.Type == TypeCometbft {
+		return fetchCometbft(cli, ref.URL)
+	}
+	return fetchEvm(cli, ref.URL)
+}
+
+// Head returns the most advanced head among a chain's reference endpoints
+func (m *Monitor) Head(chainName string) (Head, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var best Head
+	var found bool
+	for ref, head := range m.latest {
+		if ref.ChainName == chainName && (!found || head.Height > best.Height) {
+			best, found = head, true
+		}
+	}
+	return best, found
+}
+
+// compare exports the lag of every tracked node of the chain behind the reference
+func (m *Monitor) compare(chainName string) {
+	ref, ok := m.Head(chainName)
+	if !ok {
+		return
+	}
+	for host, head := range m.tracker.Latest(chainName) {
+		blocks := float64(ref.Height) - float64(head.Height)
+		base.LagVsReferenceBlocks.WithLabelValues(chainName, host).Set(blocks)
+		if !ref.BlockTime.IsZero() && !head.BlockTime.IsZero() {
+			base.LagVsReferenceSeconds.WithLabelValues(chainName, host).Set(ref.BlockTime.Sub(head.BlockTime).Seconds())
+		}
+		if blocks > 0 {
+			glog.V(5).Infof("[reference] Node %s (%s) is %.0f blocks behind the reference", host, chainName, blocks)
+		}
+	}
+}
+
+func (m *Monitor) poll(ctx context.Context, ref *conf.Reference) {
+	defer m.wg.Done()
+
+	cli := base.NewClient(ctx, &http.Client{Timeout: 10 * time.Second})
+	if !base.WaitForPhaseOffset(ctx, ref.CheckSecond, 10) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(ref.CheckSecond, 10)
+	defer ticker.Stop()
+
+	for {
+		head, err := Fetch(cli, ref)
+		if err != nil {
+			glog.Errorf("[reference] Reference %s for %s fail: %v", ref.Name, ref.ChainName, err)
+		} else {
+			base.ReferenceBlockHeight.WithLabelValues(ref.ChainName, ref.Name).Set(float64(head.Height))
+			m.mu.Lock()
+			m.latest[ref] = head
+			m.mu.Unlock()
+			m.compare(ref.ChainName)
+		}
+
+		if !base.WaitForContextOrTicker(ctx, ticker) {
+			glog.V(5).Info("[reference] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+// Start polls every reference endpoint in its own goroutine until Stop
+func (m *Monitor) Start(parent context.Context) {
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(parent)
+	for _, ref := range m.refs {
+		m.wg.Add(1)
+		go m.poll(ctx, ref)
+	}
+}
+
+func (m *Monitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// Validate checks the reference endpoint configuration
+func Validate(refs []*conf.Reference) error {
+	for i, ref := range refs {
+		if ref == nil {
+			return fmt.Errorf("references[%d]: is empty", i)
+		}
+		if ref.ChainName == "" {
+			return fmt.Errorf("references[%d]: chain_name is required", i)
+		}
+		if ref.URL == "" {
+			return fmt.Errorf("references[%d]: url is required", i)
+		}
+		switch ref.Type {
+		case "":
+			ref.Type = TypeEvm
+		case TypeEvm, TypeCometbft:
+		default:
+			return fmt.Errorf("references[%d]: unknown type %q, expected %s or %s", i, ref.Type, TypeEvm, TypeCometbft)
+		}
+		if ref.Name == "" {
+			ref.Name = fmt.Sprintf("%s-%d", ref.ChainName, i)
+		}
+	}
+	return nil
+}
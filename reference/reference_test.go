@@ -0,0 +1,55 @@
+package reference
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status" {
+			w.Write([]byte(`{"result":{"sync_info":{"latest_block_height":"120","latest_block_time":"2024-01-02T03:04:05Z"}}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x64","timestamp":"0x65000000"}}`))
+	}))
+	defer srv.Close()
+
+	cli := base.NewClient(context.Background(), srv.Client())
+
+	head, err := Fetch(cli, &conf.Reference{Type: TypeEvm, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Height != 100 || head.BlockTime.Unix() != 0x65000000 {
+		t.Errorf("unexpected evm head %+v", head)
+	}
+
+	head, err = Fetch(cli, &conf.Reference{Type: TypeCometbft, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Height != 120 || !head.BlockTime.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected cometbft head %+v", head)
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	refs := []*conf.Reference{{ChainName: "story", URL: "http://localhost"}}
+	if err := Validate(refs); err != nil {
+		t.Fatal(err)
+	}
+	if refs[0].Type != TypeEvm || refs[0].Name != "story-0" {
+		t.Errorf("unexpected defaults %+v", refs[0])
+	}
+
+	if err := Validate([]*conf.Reference{{ChainName: "story", URL: "http://localhost", Type: "beacon"}}); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
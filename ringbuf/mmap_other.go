@@ -0,0 +1,21 @@
+//go:build !unix
+
+package ringbuf
+
+import (
+	"io"
+	"os"
+)
+
+// mmap falls back to an in-memory copy of the file on platforms without mmap
+func mmap(f *os.File, size int) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+func munmap(data []byte) error {
+	return nil
+}
@@ -0,0 +1,213 @@
+package ringbuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+const (
+	magic = 0x53544d48 // "STMH"
+
+	headerSize = 64
+	slotSize   = 128
+
+	// Slot layout
+	offSeq        = 0
+	offReceivedAt = 8
+	offBlockTime  = 16
+	offHeight     = 24
+	offHash       = 32
+	offChainName  = 64
+	offHostName   = 96
+	nameSize      = 32
+)
+
+// HeadEvent is a fixed-size head record stored in the ring buffer
+type HeadEvent struct {
+	Seq        uint64
+	ReceivedAt time.Time
+	BlockTime  time.Time
+	Height     uint64
+	Hash       [32]byte
+	ChainName  string
+	HostName   string
+}
+
+// Ring is a single-producer-per-slot, multi-reader ring buffer of head events
+// backed by a memory-mapped file. Writers never allocate, so the subscription
+// loop is decoupled from the latency of whoever persists the events.
+type Ring struct {
+	file  *os.File
+	data  []byte
+	slots uint64
+}
+
+// Open maps the ring buffer file at path with the given number of slots,
+// creating or resizing it when needed
+func Open(path string, slots int) (*Ring, error) {
+	if slots <= 0 {
+		return nil, fmt.Errorf("ring buffer slots must be positive")
+	}
+
+	size := headerSize + slots*slotSize
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ring buffer %s: %w", path, err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.Size() != int64(size) {
+		if err := f.Truncate(int64(size)); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to resize ring buffer %s: %w", path, err)
+		}
+	}
+
+	data, err := mmap(f, size)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to map ring buffer %s: %w", path, err)
+	}
+
+	r := &Ring{file: f, data: data, slots: uint64(slots)}
+	if binary.LittleEndian.Uint32(data[0:4]) != magic || binary.LittleEndian.Uint64(data[8:16]) != uint64(slots) {
+		// Fresh or incompatible file, reset header and slots
+		for i := range data {
+			data[i] = 0
+		}
+		binary.LittleEndian.PutUint32(data[0:4], magic)
+		binary.LittleEndian.PutUint64(data[8:16], uint64(slots))
+	}
+	return r, nil
+}
+
+// Close unmaps and closes the ring buffer file
+func (r *Ring) Close() error {
+	if err := munmap(r.data); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+func (r *Ring) word(off int) *uint64 {
+	return (*uint64)(unsafe.Pointer(&r.data[off]))
+}
+
+func (r *Ring) head() *uint64 {
+	return r.word(16)
+}
+
+// Head returns the sequence number the next event will be written with
+func (r *Ring) Head() uint64 {
+	return atomic.LoadUint64(r.head())
+}
+
+// AppendHead writes a head event into the next slot, it implements base.HeadSink
+func (r *Ring) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	seq := atomic.AddUint64(r.head(), 1) - 1
+	off := headerSize + int(seq%r.slots)*slotSize
+	slot := r.data[off : off+slotSize]
+
+	// Odd stamp marks the slot as being written
+	atomic.StoreUint64(r.word(off+offSeq), seq*2+1)
+	binary.LittleEndian.PutUint64(slot[offReceivedAt:], uint64(receivedAt.UnixNano()))
+	binary.LittleEndian.PutUint64(slot[offBlockTime:], uint64(blockTime.UnixNano()))
+	binary.LittleEndian.PutUint64(slot[offHeight:], height)
+	copy(slot[offHash:offHash+32], hash[:])
+	putName(slot[offChainName:offChainName+nameSize], chainName)
+	putName(slot[offHostName:offHostName+nameSize], hostName)
+	atomic.StoreUint64(r.word(off+offSeq), seq*2+2)
+}
+
+func putName(dst []byte, name string) {
+	n := copy(dst, name)
+	for i := n; i < len(dst); i++ {
+		dst[i] = 0
+	}
+}
+
+func getName(src []byte) string {
+	for i, c := range src {
+		if c == 0 {
+			return string(src[:i])
+		}
+	}
+	return string(src)
+}
+
+// read copies the slot holding seq into ev, it returns false and whether the
+// slot was already overwritten when the event is not readable
+func (r *Ring) read(seq uint64, ev *HeadEvent) (ok bool, overwritten bool) {
+	off := headerSize + int(seq%r.slots)*slotSize
+	stamp := atomic.LoadUint64(r.word(off + offSeq))
+	if stamp != seq*2+2 {
+		return false, stamp > seq*2+2
+	}
+
+	slot := r.data[off : off+slotSize]
+	ev.Seq = seq
+	ev.ReceivedAt = time.Unix(0, int64(binary.LittleEndian.Uint64(slot[offReceivedAt:])))
+	ev.BlockTime = time.Unix(0, int64(binary.LittleEndian.Uint64(slot[offBlockTime:])))
+	ev.Height = binary.LittleEndian.Uint64(slot[offHeight:])
+	copy(ev.Hash[:], slot[offHash:offHash+32])
+	ev.ChainName = getName(slot[offChainName : offChainName+nameSize])
+	ev.HostName = getName(slot[offHostName : offHostName+nameSize])
+
+	// Slot changed while copying
+	if atomic.LoadUint64(r.word(off+offSeq)) != stamp {
+		return false, true
+	}
+	return true, false
+}
+
+// Reader consumes events from a Ring in order, skipping events that were
+// overwritten before they could be read
+type Reader struct {
+	ring    *Ring
+	next    uint64
+	dropped uint64
+}
+
+// NewReader returns a reader positioned at the current head of the ring
+func (r *Ring) NewReader() *Reader {
+	return &Reader{ring: r, next: r.Head()}
+}
+
+// Next reads the next available event into ev, it returns false when caught up
+func (rd *Reader) Next(ev *HeadEvent) bool {
+	for {
+		head := rd.ring.Head()
+		if rd.next >= head {
+			return false
+		}
+		if head-rd.next > rd.ring.slots {
+			rd.dropped += head - rd.next - rd.ring.slots
+			rd.next = head - rd.ring.slots
+		}
+		ok, overwritten := rd.ring.read(rd.next, ev)
+		if ok {
+			rd.next++
+			return true
+		}
+		if !overwritten {
+			// Slot is still being written
+			return false
+		}
+		rd.dropped++
+		rd.next++
+	}
+}
+
+// Dropped returns the number of events that were overwritten before being read
+func (rd *Reader) Dropped() uint64 {
+	return rd.dropped
+}
@@ -0,0 +1,81 @@
+package ringbuf
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRingAppendAndRead(t *testing.T) {
+	ring, err := Open(filepath.Join(t.TempDir(), "heads.ring"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ring.Close()
+
+	reader := ring.NewReader()
+	blockTime := time.Unix(1700000000, 0)
+	for i := uint64(1); i <= 3; i++ {
+		ring.AppendHead("story", "node-01", i, [32]byte{byte(i)}, blockTime, time.Now())
+	}
+
+	var ev HeadEvent
+	for i := uint64(1); i <= 3; i++ {
+		if !reader.Next(&ev) {
+			t.Fatalf("expected event %d", i)
+		}
+		if ev.Height != i || ev.Hash[0] != byte(i) || ev.ChainName != "story" || ev.HostName != "node-01" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+		if !ev.BlockTime.Equal(blockTime) {
+			t.Errorf("expected block time %v, got %v", blockTime, ev.BlockTime)
+		}
+	}
+	if reader.Next(&ev) {
+		t.Errorf("expected reader to be caught up")
+	}
+}
+
+func TestRingOverwrite(t *testing.T) {
+	ring, err := Open(filepath.Join(t.TempDir(), "heads.ring"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ring.Close()
+
+	reader := ring.NewReader()
+	for i := uint64(1); i <= 5; i++ {
+		ring.AppendHead("story", "node-01", i, [32]byte{}, time.Now(), time.Now())
+	}
+
+	var ev HeadEvent
+	var heights []uint64
+	for reader.Next(&ev) {
+		heights = append(heights, ev.Height)
+	}
+	if len(heights) != 2 || heights[0] != 4 || heights[1] != 5 {
+		t.Errorf("expected heights [4 5], got %v", heights)
+	}
+	if reader.Dropped() != 3 {
+		t.Errorf("expected 3 dropped events, got %d", reader.Dropped())
+	}
+}
+
+func TestRingReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heads.ring")
+	ring, err := Open(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring.AppendHead("story", "node-01", 10, [32]byte{}, time.Now(), time.Now())
+	ring.Close()
+
+	ring, err = Open(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ring.Close()
+	if ring.Head() != 1 {
+		t.Errorf("expected head 1 after reopen, got %d", ring.Head())
+	}
+}
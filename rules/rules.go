@@ -0,0 +1,152 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+
+	"gopkg.in/yaml.v2"
+)
+
+type ruleFile struct {
+	Groups []group `yaml:"groups"`
+}
+
+type group struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+func orDefault(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// promDuration formats seconds as a Prometheus duration
+func promDuration(seconds int) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// Generate renders Prometheus alerting rules for the metrics exported by the
+// checkers under namespace, with the thresholds of c. A nil c uses the defaults.
+func Generate(c *conf.AlertRules, namespace string) ([]byte, error) {
+	if c == nil {
+		c = &conf.AlertRules{}
+	}
+	name := c.GroupName
+	if name == "" {
+		name = "story-nodes"
+	}
+	severity := c.Severity
+	if severity == "" {
+		severity = "critical"
+	}
+	labels := map[string]string{"severity": severity}
+
+	healthFor := promDuration(orDefault(c.HealthForSecond, 120))
+	thresholdFor := promDuration(orDefault(c.ForSecond, 300))
+	blockDelay := orDefault(c.BlockDelaySecond, 10)
+	heightLag := orDefault(c.HeightLagBlocks, 10)
+	certExpiry := orDefault(c.CertExpiryDay, 14)
+	gasUtilization := orDefault(c.GasUtilizationPercent, 90)
+
+	file := ruleFile{Groups: []group{{
+		Name: name,
+		Rules: []rule{
+			{
+				Alert:  "NodeDown",
+				Expr:   "story_node_health_status == 0",
+				For:    healthFor,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": "{{ $labels.endpoint_type }} check of {{ $labels.hostname }} ({{ $labels.chain_name }}) is failing",
+				},
+			},
+			{
+				Alert:  "HighBlockDelay",
+				Expr:   fmt.Sprintf("story_node_block_processing_delay_seconds > %d", blockDelay),
+				For:    thresholdFor,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("Block processing delay on {{ $labels.hostname }} is {{ $value }}s, above %ds", blockDelay),
+				},
+			},
+			{
+				Alert:  "BlockHeightLag",
+				Expr:   fmt.Sprintf("max by (chain_name) (story_node_latest_block_height) - on (chain_name) group_right story_node_latest_block_height > %d", heightLag),
+				For:    thresholdFor,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("{{ $labels.hostname }} is {{ $value }} blocks behind the highest node of {{ $labels.chain_name }}, above %d", heightLag),
+				},
+			},
+			{
+				Alert:  "BlocksRunningFull",
+				Expr:   fmt.Sprintf("avg_over_time(story_node_block_gas_utilization_percent[%s]) > %d", thresholdFor, gasUtilization),
+				For:    thresholdFor,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("Blocks on {{ $labels.hostname }} ({{ $labels.chain_name }}) use {{ $value | humanize }}%% of the gas limit, above %d%%", gasUtilization),
+				},
+			},
+			// Multi-window burn rate alerts, a budget burning at 14.4 is gone
+			// in two days, at 6 in five days
+			{
+				Alert:  "SLOFastBurn",
+				Expr:   `story_node_slo_burn_rate{window="1h"} > 14.4 and ignoring (window) story_node_slo_burn_rate{window="5m"} > 14.4`,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": "{{ $labels.hostname }} ({{ $labels.chain_name }}) burns the error budget of SLO {{ $labels.slo }} {{ $value | humanize }} times faster than allowed",
+				},
+			},
+			{
+				Alert:  "SLOSlowBurn",
+				Expr:   `story_node_slo_burn_rate{window="6h"} > 6 and ignoring (window) story_node_slo_burn_rate{window="1h"} > 6`,
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": "{{ $labels.hostname }} ({{ $labels.chain_name }}) burns the error budget of SLO {{ $labels.slo }} {{ $value | humanize }} times faster than allowed",
+				},
+			},
+			{
+				Alert:  "NodeVersionOutdated",
+				Expr:   "story_node_version_outdated == 1",
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": "{{ $labels.hostname }} ({{ $labels.chain_name }}) runs a version older than its min_version",
+				},
+			},
+			{
+				Alert:  "CertificateExpiry",
+				Expr:   fmt.Sprintf("(story_node_tls_cert_expiry_timestamp_seconds - time()) / 86400 < %d", certExpiry),
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": "TLS certificate of {{ $labels.hostname }} expires in {{ $value | humanize }} days",
+				},
+			},
+		},
+	}}}
+	for i := range file.Groups[0].Rules {
+		file.Groups[0].Rules[i].Expr = base.RenameMetric(file.Groups[0].Rules[i].Expr, namespace)
+	}
+	return yaml.Marshal(file)
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"storymonitor/conf"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestGenerate(t *testing.T) {
+	data, err := Generate(&conf.AlertRules{BlockDelaySecond: 30, HealthForSecond: 90, CertExpiryDay: 7}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Groups) != 1 || file.Groups[0].Name != "story-nodes" {
+		t.Fatalf("unexpected groups %+v", file.Groups)
+	}
+
+	byName := make(map[string]rule)
+	for _, r := range file.Groups[0].Rules {
+		byName[r.Alert] = r
+	}
+	cases := map[string]struct{ expr, forDuration string }{
+		"NodeDown":          {"story_node_health_status == 0", "90s"},
+		"HighBlockDelay":    {"story_node_block_processing_delay_seconds > 30", "5m"},
+		"CertificateExpiry": {"(story_node_tls_cert_expiry_timestamp_seconds - time()) / 86400 < 7", ""},
+	}
+	for name, want := range cases {
+		r, ok := byName[name]
+		if !ok {
+			t.Errorf("missing rule %s", name)
+			continue
+		}
+		if r.Expr != want.expr || r.For != want.forDuration {
+			t.Errorf("%s: got expr %q for %q, want %q for %q", name, r.Expr, r.For, want.expr, want.forDuration)
+		}
+		if r.Labels["severity"] != "critical" {
+			t.Errorf("%s: expected default severity, got %v", name, r.Labels)
+		}
+	}
+	for _, name := range []string{"BlockHeightLag", "SLOFastBurn", "SLOSlowBurn"} {
+		if _, ok := byName[name]; !ok {
+			t.Errorf("missing rule %s", name)
+		}
+	}
+}
+
+func TestGenerateNamespace(t *testing.T) {
+	data, err := Generate(nil, "eth_node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range file.Groups[0].Rules {
+		if strings.Contains(r.Expr, "story_node_") || !strings.Contains(r.Expr, "eth_node_") {
+			t.Errorf("%s: expr %q does not use the namespace", r.Alert, r.Expr)
+		}
+	}
+}
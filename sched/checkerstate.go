@@ -0,0 +1,89 @@
+package sched
+
+import (
+	"sync/atomic"
+
+	"storymonitor/base"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Checker lifecycle states as seen by the controller
+const (
+	LifecycleStarting     = "starting"
+	LifecycleRunning      = "running"
+	LifecycleReconnecting = "reconnecting"
+	LifecycleFailed       = "failed"
+	LifecycleStopped      = "stopped"
+)
+
+var lifecycleStates = []string{LifecycleStarting, LifecycleRunning, LifecycleReconnecting, LifecycleFailed, LifecycleStopped}
+
+// Phases of a checker goroutine
+const (
+	phaseRunning int32 = iota
+	phaseExited
+	phasePanicked
+)
+
+// checkerRun tracks one run of a checker, from creation until it is halted
+type checkerRun struct {
+	// key is the target the run belongs to
+	key   string
+	phase atomic.Int32
+	// connected is set once the checker reached a running state, later
+	// connecting states are reconnects
+	connected atomic.Bool
+}
+
+// lifecycleState derives the lifecycle state of a target from its goroutine
+// phase and the connection state reported by the checker
+func lifecycleState(m *managedChecker) string {
+	if m.checker == nil {
+		return LifecycleStopped
+	}
+	switch m.run.phase.Load() {
+	case phasePanicked:
+		return LifecycleFailed
+	case phaseExited:
+		return LifecycleStopped
+	}
+
+	switch m.checker.GetState() {
+	case base.StateSubscribed, base.StateDegraded:
+		m.run.connected.Store(true)
+		return LifecycleRunning
+	case base.StateDown:
+		return LifecycleFailed
+	}
+	if m.run.connected.Load() {
+		return LifecycleReconnecting
+	}
+	return LifecycleStarting
+}
+
+// updateLifecycleStates exports the lifecycle state of every target
+func (c *Controller) updateLifecycleStates() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, m := range c.checkers {
+		current := lifecycleState(m)
+		for _, state := range lifecycleStates {
+			value := float64(0)
+			if state == current {
+				value = 1
+			}
+			base.CheckerLifecycleState.WithLabelValues(m.target.chainName, m.target.hostName, m.kind, state).Set(value)
+		}
+	}
+}
+
+// deleteLifecycleState drops the lifecycle series of a removed target
+func deleteLifecycleState(m *managedChecker) {
+	base.CheckerLifecycleState.DeletePartialMatch(prometheus.Labels{
+		"chain_name": m.target.chainName,
+		"hostname":   m.target.hostName,
+		"kind":       m.kind,
+	})
+}
@@ -0,0 +1,157 @@
+package sched
+
+import (
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// divergenceDepth is the number of recent heights compared per chain
+	divergenceDepth = 64
+	// checkBlockHash is the endpoint type of the hash agreement check
+	checkBlockHash = "block_hash"
+)
+
+// healthRecorder is implemented by every checker through base.BaseChecker
+type healthRecorder interface {
+	RecordHealthStatus(endpointType string, healthy bool)
+}
+
+// hashReport is a hash seen by a node at a height
+type hashReport struct {
+	hash     [32]byte
+	recorder healthRecorder
+}
+
+// chainHashes holds the hashes reported by the nodes of one chain ID, nodes
+// are keyed by chain_name/hostname
+type chainHashes struct {
+	byHeight  map[uint64]map[string]hashReport
+	heights   []uint64
+	divergent map[string]bool
+}
+
+// divergence compares the block hashes reported at the same height by nodes
+// of the same chain ID. A node disagreeing with the majority, or every node
+// when there is no majority, fails the block_hash check until it agrees again.
+type divergence struct {
+	controller *Controller
+
+	mu     sync.Mutex
+	chains map[string]*chainHashes
+}
+
+func newDivergence(controller *Controller) *divergence {
+	return &divergence{
+		controller: controller,
+		chains:     make(map[string]*chainHashes),
+	}
+}
+
+// checker returns the running checker of a node, nil if it is not managed
+func (c *Controller) checker(chainName, hostName string) base.CheckerTrait {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, m := range c.checkers {
+		if m.checker != nil && m.checker.GetChainName() == chainName && m.checker.GetHostName() == hostName {
+			return m.checker
+		}
+	}
+	return nil
+}
+
+// AppendHead compares a new head with the heads of the other nodes, it implements base.HeadSink
+func (d *divergence) AppendHead(chainName, hostName string, height uint64, hash [32]byte, blockTime, receivedAt time.Time) {
+	checker := d.controller.checker(chainName, hostName)
+	if checker == nil {
+		return
+	}
+	recorder, ok := checker.(healthRecorder)
+	if !ok {
+		return
+	}
+	group := checker.GetChainId()
+	if group == "" {
+		group = "chain_name:" + chainName
+	}
+	node := chainName + "/" + hostName
+
+	d.mu.Lock()
+	chain, ok := d.chains[group]
+	if !ok {
+		chain = &chainHashes{
+			byHeight:  make(map[uint64]map[string]hashReport),
+			divergent: make(map[string]bool),
+		}
+		d.chains[group] = chain
+	}
+	reports, ok := chain.byHeight[height]
+	if !ok {
+		reports = make(map[string]hashReport)
+		chain.byHeight[height] = reports
+		chain.heights = append(chain.heights, height)
+		for len(chain.heights) > divergenceDepth {
+			delete(chain.byHeight, chain.heights[0])
+			chain.heights = chain.heights[1:]
+		}
+	}
+	reports[node] = hashReport{hash: hash, recorder: recorder}
+	if len(reports) < 2 {
+		d.mu.Unlock()
+		return
+	}
+
+	votes := make(map[[32]byte]int)
+	for _, r := range reports {
+		votes[r.hash]++
+	}
+	var majority [32]byte
+	best, tied := 0, false
+	for h, count := range votes {
+		switch {
+		case count > best:
+			majority, best, tied = h, count, false
+		case count == best:
+			tied = true
+		}
+	}
+
+	type update struct {
+		node      string
+		recorder  healthRecorder
+		divergent bool
+	}
+	updates := make([]update, 0, len(reports))
+	var started []string
+	for n, r := range reports {
+		isDivergent := len(votes) > 1 && (tied || r.hash != majority)
+		if isDivergent && !chain.divergent[n] {
+			started = append(started, n+"="+hex.EncodeToString(r.hash[:]))
+			chainName, hostName, _ := strings.Cut(n, "/")
+			base.BlockHashDivergences.WithLabelValues(chainName, hostName).Inc()
+		}
+		chain.divergent[n] = isDivergent
+		updates = append(updates, update{n, r.recorder, isDivergent})
+	}
+	d.mu.Unlock()
+
+	if len(started) > 0 {
+		glog.Warningf("[divergence] Nodes of chain %s disagree on block %d: %s", group, height, strings.Join(started, ", "))
+	}
+	for _, u := range updates {
+		chainName, hostName, _ := strings.Cut(u.node, "/")
+		value := float64(0)
+		if u.divergent {
+			value = 1
+		}
+		base.BlockHashDivergent.WithLabelValues(chainName, hostName).Set(value)
+		u.recorder.RecordHealthStatus(checkBlockHash, !u.divergent)
+	}
+}
@@ -0,0 +1,194 @@
+package sched
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// Subsystem describes a long-running part of the monitor that is started
+// and stopped by the Lifecycle manager.
+type Subsystem struct {
+	Name      string
+	DependsOn []string
+
+	// Start must not block; long-running work should be spawned in goroutines
+	Start func() error
+	// Stop is called in reverse start order during shutdown
+	Stop func()
+	// Ready reports whether the subsystem is able to serve, nil means ready
+	Ready func() error
+}
+
+// Lifecycle starts subsystems in dependency order and stops them in reverse
+type Lifecycle struct {
+	mu         sync.RWMutex
+	subsystems map[string]*Subsystem
+	started    []*Subsystem
+}
+
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{
+		subsystems: make(map[string]*Subsystem),
+	}
+}
+
+// Register adds a subsystem, it must be called before Start
+func (l *Lifecycle) Register(s *Subsystem) error {
+	if s == nil || s.Name == "" {
+		return fmt.Errorf("subsystem name is required")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.subsystems[s.Name]; ok {
+		return fmt.Errorf("subsystem %s already registered", s.Name)
+	}
+	l.subsystems[s.Name] = s
+	return nil
+}
+
+// order returns subsystems sorted so that every subsystem follows its dependencies
+func (l *Lifecycle) order() ([]*Subsystem, error) {
+	names := make([]string, 0, len(l.subsystems))
+	for name := range l.subsystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	ordered := make([]*Subsystem, 0, len(names))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		s, ok := l.subsystems[name]
+		if !ok {
+			return fmt.Errorf("subsystem %s depends on unknown subsystem %s", path[len(path)-1], name)
+		}
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle detected: %v -> %s", path, name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range s.DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, s)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Start starts all registered subsystems in dependency order. If one fails,
+// the already started subsystems are stopped and the error is returned.
+func (l *Lifecycle) Start() error {
+	l.mu.Lock()
+	ordered, err := l.order()
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range ordered {
+		glog.Infof("[Lifecycle] Starting subsystem %s", s.Name)
+		if s.Start != nil {
+			if err := s.Start(); err != nil {
+				l.Stop()
+				return fmt.Errorf("failed to start subsystem %s: %w", s.Name, err)
+			}
+		}
+		l.mu.Lock()
+		l.started = append(l.started, s)
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop stops started subsystems in reverse start order
+func (l *Lifecycle) Stop() {
+	l.mu.Lock()
+	started := l.started
+	l.started = nil
+	l.mu.Unlock()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		s := started[i]
+		glog.Infof("[Lifecycle] Stopping subsystem %s", s.Name)
+		if s.Stop != nil {
+			s.Stop()
+		}
+	}
+}
+
+// Readiness returns the readiness error of every registered subsystem, keyed by name
+func (l *Lifecycle) Readiness() map[string]error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	running := make(map[string]bool, len(l.started))
+	for _, s := range l.started {
+		running[s.Name] = true
+	}
+
+	result := make(map[string]error, len(l.subsystems))
+	for name, s := range l.subsystems {
+		switch {
+		case !running[name]:
+			result[name] = fmt.Errorf("not started")
+		case s.Ready != nil:
+			result[name] = s.Ready()
+		default:
+			result[name] = nil
+		}
+	}
+	return result
+}
+
+// ReadyHandler serves per-subsystem readiness, responding 503 if any subsystem is not ready
+func (l *Lifecycle) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	type status struct {
+		Ready bool   `json:"ready"`
+		Error string `json:"error,omitempty"`
+	}
+
+	ready := true
+	subsystems := make(map[string]status)
+	for name, err := range l.Readiness() {
+		if err != nil {
+			ready = false
+			subsystems[name] = status{Error: err.Error()}
+		} else {
+			subsystems[name] = status{Ready: true}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ready":      ready,
+		"subsystems": subsystems,
+	})
+}
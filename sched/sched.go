@@ -2,64 +2,456 @@ package sched
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"storymonitor/base"
-	"storymonitor/cometbft"
 	"storymonitor/conf"
-	"storymonitor/evm"
+	"storymonitor/sdnotify"
 
 	"github.com/golang/glog"
 )
 
+// SourceConfig is the source of targets from the main config file
+const SourceConfig = "config"
+
+// ErrTargetNotFound is returned for operations on a node without targets
+var ErrTargetNotFound = errors.New("target not found")
+
+// managedChecker is a target together with where it came from and its checker,
+// which is nil while the controller is on standby
+type managedChecker struct {
+	checker base.CheckerTrait
+	ctx     context.Context
+	cancel  context.CancelFunc
+	run     *checkerRun
+	// created is when the current checker was created, runs counts how
+	// often a checker was created for the target
+	created time.Time
+	runs    int
+
+	target target
+	kind   string
+	key    string
+	source string
+	spec   string
+}
+
 type Controller struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	checkers []base.CheckerTrait
+	checkers []*managedChecker
 	conf     *conf.NodeConfig
+	// divergence compares block hashes across nodes of the same chain ID
+	divergence *divergence
 
 	// WaitGroup for managing goroutine lifecycle
 	wg sync.WaitGroup
 
 	// Flag to indicate if controller is stopped
+	started bool
 	stopped bool
-	mu      sync.RWMutex
+	// active is false while on standby in HA mode, standby controllers keep their targets without running checkers
+	active bool
+	mu     sync.RWMutex
+
+	// healthCallbacks receive the check outcomes of every checker the controller creates
+	healthCallbacks []HealthCallback
+
+	// live holds the runs whose goroutine has not exited yet, including
+	// those of halted checkers
+	liveMu sync.Mutex
+	live   map[*checkerRun]struct{}
 }
 
-func NewController(parent context.Context, conf *conf.NodeConfig) *Controller {
+// HealthCallback receives a check outcome of the node chainName/hostName
+type HealthCallback func(chainName, hostName, check string, healthy bool, duration time.Duration, at time.Time)
+
+// Option configures a Controller
+type Option func(*Controller)
+
+// WithHealthCallback passes the check outcomes of all checkers to fn, for
+// programs embedding the controller. fn is called synchronously from the
+// checker loops and must not block.
+func WithHealthCallback(fn HealthCallback) Option {
+	return func(c *Controller) {
+		c.healthCallbacks = append(c.healthCallbacks, fn)
+	}
+}
+
+// WithStandby creates the controller on standby, its checkers are only
+// created once SetActive(true) is called. It is used in HA mode, so a
+// replica does not connect to the nodes before it becomes leader.
+func WithStandby() Option {
+	return func(c *Controller) {
+		c.active = false
+	}
+}
+
+func NewController(parent context.Context, conf *conf.NodeConfig, opts ...Option) *Controller {
 	ctx, cancel := context.WithCancel(parent)
 	c := &Controller{
 		ctx:    ctx,
 		cancel: cancel,
 		conf:   conf,
+		active: true,
+		live:   make(map[*checkerRun]struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.divergence = newDivergence(c)
+
+	// Create checkers of all targets in the main config
+	for _, t := range targetsOf(c.conf) {
+		c.addChecker(SourceConfig, t)
+	}
+	if c.active {
+		c.runAll(c.checkers)
+	}
+
+	glog.Infof("Created %d checkers total", len(c.checkers))
+	return c
+}
+
+// addChecker adds a target without a checker, the caller runs it with
+// runAll after releasing c.mu. The caller must hold c.mu or be the
+// constructor.
+func (c *Controller) addChecker(source string, t target) *managedChecker {
+	glog.Infof("Creating %s checker %s from %s", t.kind, t.key, source)
+
+	m := &managedChecker{
+		target: t,
+		kind:   t.kind,
+		key:    t.key,
+		source: source,
+		spec:   t.spec(),
+	}
+	c.checkers = append(c.checkers, m)
+	return m
+}
+
+// preparedChecker is a checker created for a target but not installed yet
+type preparedChecker struct {
+	checker base.CheckerTrait
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// prepare creates the checker of a target. Checkers may dial their node
+// while being created, so it must be called without c.mu held.
+func (c *Controller) prepare(t target) *preparedChecker {
+	ctx, cancel := context.WithCancel(c.ctx)
+	checker := newChecker(ctx, t)
+	for _, fn := range c.healthCallbacks {
+		chainName, hostName := t.chainName, t.hostName
+		checker.OnHealthCheck(func(check string, healthy bool, duration time.Duration, at time.Time) {
+			fn(chainName, hostName, check, healthy, duration, at)
+		})
+	}
+	return &preparedChecker{checker: checker, ctx: ctx, cancel: cancel}
+}
+
+// runAll creates the checkers of targets without holding c.mu, then
+// installs them
+func (c *Controller) runAll(targets []*managedChecker) {
+	prepared := make([]*preparedChecker, len(targets))
+	for i, m := range targets {
+		prepared[i] = c.prepare(m.target)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, m := range targets {
+		c.install(m, prepared[i])
+	}
+}
+
+// install makes p the checker of a target and starts it if the controller
+// is running. p is discarded if the controller went on standby or stopped,
+// or the target was removed or got another checker while p was created.
+// The caller must hold c.mu.
+func (c *Controller) install(m *managedChecker, p *preparedChecker) {
+	if c.stopped || !c.active || m.checker != nil || !c.managed(m) {
+		p.cancel()
+		if !c.serving(m.target) {
+			base.UnregisterEndpoints(m.kind, p.checker.GetChainName(), p.checker.GetHostName())
+		}
+		return
+	}
+
+	m.checker = p.checker
+	m.ctx = p.ctx
+	m.cancel = p.cancel
+	m.run = &checkerRun{key: m.key}
+	m.created = time.Now()
+	m.runs++
+
+	if c.started {
+		c.goChecker(m)
+	}
+}
+
+// managed reports whether m is a target of the controller. The caller must
+// hold c.mu.
+func (c *Controller) managed(m *managedChecker) bool {
+	for _, other := range c.checkers {
+		if other == m {
+			return true
+		}
+	}
+	return false
+}
+
+// serving reports whether a checker of the same kind runs for the node of
+// t, its endpoints must be kept. The caller must hold c.mu.
+func (c *Controller) serving(t target) bool {
+	for _, m := range c.checkers {
+		if m.checker != nil && m.kind == t.kind && m.target.chainName == t.chainName && m.target.hostName == t.hostName {
+			return true
+		}
+	}
+	return false
+}
+
+// goChecker runs the checker of a target in a goroutine
+func (c *Controller) goChecker(m *managedChecker) {
+	c.liveMu.Lock()
+	c.live[m.run] = struct{}{}
+	c.liveMu.Unlock()
+
+	c.wg.Add(1)
+	go c.startChecker(m.ctx, m.checker, m.run)
+}
+
+// halt stops the checker of a target and drops its endpoints from the inventory
+func (c *Controller) halt(m *managedChecker) {
+	if m.checker == nil {
+		return
+	}
+	m.cancel()
+	base.UnregisterEndpoints(m.kind, m.checker.GetChainName(), m.checker.GetHostName())
+	m.checker = nil
+}
+
+// removeChecker stops the checker of a removed target
+func (c *Controller) removeChecker(m *managedChecker) {
+	glog.Infof("Removing %s checker %s from %s", m.kind, m.key, m.source)
+	c.halt(m)
+	deleteLifecycleState(m)
+}
+
+// deleteNodeMetrics drops the series of the nodes of removed targets that
+// have no other target left. The caller must hold c.mu.
+func (c *Controller) deleteNodeMetrics(removed []*managedChecker) {
+	for _, r := range removed {
+		monitored := false
+		for _, m := range c.checkers {
+			if m.target.chainName == r.target.chainName && m.target.hostName == r.target.hostName {
+				monitored = true
+				break
+			}
+		}
+		if !monitored {
+			base.DeleteNodeMetrics(r.target.chainName, r.target.hostName)
+		}
+	}
+}
+
+// SetActive runs or stops all checkers, it is used to switch between leader
+// and standby in HA mode. The checkers are created without holding c.mu, so
+// the block lifetime loop and the stats keep going while they connect.
+func (c *Controller) SetActive(active bool) {
+	c.mu.Lock()
+	if c.stopped || c.active == active {
+		c.mu.Unlock()
+		return
+	}
+	c.active = active
+
+	if active {
+		targets := append([]*managedChecker(nil), c.checkers...)
+		c.mu.Unlock()
+		glog.Infof("[Controller] Activating %d checkers", len(targets))
+		c.runAll(targets)
+		return
+	}
+	defer c.mu.Unlock()
+
+	glog.Infof("[Controller] Standing by, stopping %d checkers", len(c.checkers))
+	var runs []*checkerRun
+	nodes := make(map[[2]string]bool)
+	for _, m := range c.checkers {
+		if m.checker != nil {
+			runs = append(runs, m.run)
+		}
+		nodes[[2]string{m.target.chainName, m.target.hostName}] = true
+		c.halt(m)
+	}
+	c.clearStandbyMetrics(runs, nodes)
+}
+
+// clearStandbyMetrics deletes the series of the nodes of a controller going
+// on standby, so the leader is the only replica exporting them. They are
+// deleted again once the halted runs exited, a checker still stopping may
+// have written after the first deletion. The caller must hold c.mu.
+func (c *Controller) clearStandbyMetrics(runs []*checkerRun, nodes map[[2]string]bool) {
+	deleteSeries := func() {
+		for node := range nodes {
+			base.DeleteNodeMetrics(node[0], node[1])
+		}
+	}
+	deleteSeries()
+
+	timeout, _ := c.shutdown()
+	go func() {
+		c.waitExited(runs, timeout)
+		if c.ctx.Err() == nil && !c.IsActive() {
+			deleteSeries()
+		}
+	}()
+}
+
+// waitExited waits until the goroutines of runs exited, for at most timeout
+// or until the controller is stopped. It reports whether they exited.
+func (c *Controller) waitExited(runs []*checkerRun, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for c.anyLive(runs) {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		select {
+		case <-c.ctx.Done():
+			return !c.anyLive(runs)
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// anyLive reports whether the goroutine of any of runs has not exited
+func (c *Controller) anyLive(runs []*checkerRun) bool {
+	c.liveMu.Lock()
+	defer c.liveMu.Unlock()
+	for _, run := range runs {
+		if _, ok := c.live[run]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Restart tears down the checkers of the node chainName/hostName and creates
+// them again with new connections and subscriptions, other checkers keep
+// running. The new checkers are only started once the old ones exited, so
+// the node never has two checkers of the same target. It returns the number
+// of restarted checkers.
+func (c *Controller) Restart(chainName, hostName string) (int, error) {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return 0, fmt.Errorf("controller stopped")
+	}
+	if !c.active {
+		c.mu.Unlock()
+		return 0, fmt.Errorf("controller is on standby")
 	}
 
-	// Create EVM checkers
-	for i, evmConf := range c.conf.Evm {
-		if evmConf == nil {
-			glog.Errorf("EVM config[%d] is nil, skipping", i)
+	var targets []*managedChecker
+	var runs []*checkerRun
+	for _, m := range c.checkers {
+		if m.target.chainName != chainName || m.target.hostName != hostName {
 			continue
 		}
-		glog.Infof("Creating EVM checker for %s (%s)", evmConf.HostName, evmConf.ChainName)
-		checker := evm.NewEvmCheckerImpl(c.ctx, evmConf)
-		c.checkers = append(c.checkers, checker)
+		glog.Infof("[Restart] Restarting %s checker %s", m.kind, m.key)
+		if m.run != nil {
+			runs = append(runs, m.run)
+		}
+		c.halt(m)
+		targets = append(targets, m)
+	}
+	c.mu.Unlock()
+
+	if len(targets) == 0 {
+		return 0, ErrTargetNotFound
+	}
+	if timeout, _ := c.shutdown(); !c.waitExited(runs, timeout) {
+		return 0, fmt.Errorf("checkers of %s/%s did not exit within %s", chainName, hostName, timeout)
+	}
+	c.runAll(targets)
+	return len(targets), nil
+}
+
+// IsActive reports whether the controller runs its checkers
+func (c *Controller) IsActive() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.active
+}
+
+// ApplyTargets replaces the targets of a source, such as a file_sd file, with
+// the targets of cfg. Unchanged targets keep running, changed targets are
+// restarted and targets missing from cfg are stopped. A target already owned
+// by another source is skipped. The series of nodes left without targets are
+// deleted.
+func (c *Controller) ApplyTargets(source string, cfg *conf.NodeConfig) {
+	desired := make(map[string]target)
+	for _, t := range targetsOf(cfg) {
+		desired[t.key] = t
 	}
 
-	// Create CometBFT checkers
-	for i, cometbftConf := range c.conf.Cometbft {
-		if cometbftConf == nil {
-			glog.Errorf("CometBFT config[%d] is nil, skipping", i)
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return
+	}
+
+	owners := make(map[string]string, len(c.checkers))
+	kept := make([]*managedChecker, 0, len(c.checkers))
+	var removed []*managedChecker
+	for _, m := range c.checkers {
+		if m.source != source {
+			owners[m.key] = m.source
+			kept = append(kept, m)
 			continue
 		}
-		glog.Infof("Creating CometBFT checker for %s (%s)", cometbftConf.HostName, cometbftConf.ChainName)
-		checker := cometbft.NewCometbftCheckerImpl(c.ctx, cometbftConf)
-		c.checkers = append(c.checkers, checker)
+		if t, ok := desired[m.key]; ok && t.spec() == m.spec {
+			delete(desired, m.key)
+			kept = append(kept, m)
+			continue
+		}
+		c.removeChecker(m)
+		removed = append(removed, m)
 	}
+	c.checkers = kept
 
-	glog.Infof("Created %d checkers total", len(c.checkers))
-	return c
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var added []*managedChecker
+	for _, key := range keys {
+		if owner, ok := owners[key]; ok {
+			glog.Warningf("[ApplyTargets] Target %s from %s is already defined by %s, skipping", key, source, owner)
+			continue
+		}
+		added = append(added, c.addChecker(source, desired[key]))
+	}
+	c.deleteNodeMetrics(removed)
+	active := c.active
+	c.mu.Unlock()
+
+	if active {
+		c.runAll(added)
+	}
 }
 
 func (c *Controller) UpdateBlockLifetime() {
@@ -68,15 +460,29 @@ func (c *Controller) UpdateBlockLifetime() {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
+	// Keepalives are sent from this loop, so systemd restarts the monitor
+	// when the loop hangs
+	watchdog := sdnotify.WatchdogInterval() / 2
+	var lastKeepalive time.Time
+
 	for {
 		select {
 		case <-c.ctx.Done():
 			glog.V(5).Info("[UpdateBlockLifetime] Received stop signal, exited")
 			return
-		case <-ticker.C:
-			// Update block lifetime metrics for all checkers
-			for _, checker := range c.checkers {
+		case now := <-ticker.C:
+			if watchdog > 0 && now.Sub(lastKeepalive) >= watchdog {
+				if _, err := sdnotify.Notify(sdnotify.Watchdog); err != nil {
+					glog.Warningf("[UpdateBlockLifetime] Failed to notify systemd watchdog: %v", err)
+				}
+				lastKeepalive = now
+			}
+			c.updateLifecycleStates()
+
+			// Update block lifetime and state duration metrics for all checkers
+			for _, checker := range c.Checkers() {
 				if checker != nil {
+					checker.FlushStateDuration()
 					base.BlockLastUpdateTime.WithLabelValues(
 						checker.GetChainName(),
 						checker.GetHostName(),
@@ -90,15 +496,41 @@ func (c *Controller) UpdateBlockLifetime() {
 	}
 }
 
-func (c *Controller) startChecker(checker base.CheckerTrait) {
+// startJitter returns the maximum random delay before a checker starts
+func (c *Controller) startJitter() time.Duration {
+	seconds := 5
+	if s := c.conf.Scheduling; s != nil && s.StartJitterSecond != 0 {
+		seconds = s.StartJitterSecond
+	}
+	if seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *Controller) startChecker(ctx context.Context, checker base.CheckerTrait, run *checkerRun) {
 	defer c.wg.Done()
+	defer func() {
+		c.liveMu.Lock()
+		delete(c.live, run)
+		c.liveMu.Unlock()
+	}()
 	defer func() {
 		if r := recover(); r != nil {
+			run.phase.Store(phasePanicked)
 			glog.Errorf("Checker %s (%s) panic recovered: %v",
 				checker.GetHostName(), checker.GetChainName(), r)
+			return
 		}
+		run.phase.Store(phaseExited)
 	}()
 
+	// Spread the start of checkers so their connections and first checks do
+	// not all hit the nodes and shared gateways at once
+	if !base.WaitForJitter(ctx, c.startJitter()) {
+		return
+	}
+
 	glog.Infof("[Controller] Starting checker: %s (%s)",
 		checker.GetHostName(), checker.GetChainName())
 
@@ -117,41 +549,79 @@ func (c *Controller) IsStopped() bool {
 
 func (c *Controller) Start() {
 	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.stopped {
-		c.mu.Unlock()
 		glog.Warning("Controller is already stopped, cannot start")
 		return
 	}
-	c.mu.Unlock()
+	c.started = true
 
 	glog.Infof("Starting controller with %d checkers", len(c.checkers))
 
+	// Compare block hashes of nodes on the same chain to detect forks
+	base.RegisterHeadSink(c.divergence)
+
 	// Start block lifetime updater
 	c.wg.Add(1)
 	go c.UpdateBlockLifetime()
 
 	// Start all checkers
-	for _, checker := range c.checkers {
-		if checker != nil {
-			c.wg.Add(1)
-			go c.startChecker(checker)
+	for _, m := range c.checkers {
+		if m.checker != nil {
+			c.goChecker(m)
 		}
 	}
 
 	glog.Info("All checkers started")
+	if _, err := sdnotify.Notify(sdnotify.Ready); err != nil {
+		glog.Warningf("[Controller] Failed to notify systemd: %v", err)
+	}
+}
+
+// shutdown returns the shutdown timeout and mode
+func (c *Controller) shutdown() (time.Duration, string) {
+	timeout, mode := 30*time.Second, conf.ShutdownForce
+	if s := c.conf.Shutdown; s != nil {
+		if s.TimeoutSecond > 0 {
+			timeout = time.Duration(s.TimeoutSecond) * time.Second
+		}
+		if s.Mode != "" {
+			mode = s.Mode
+		}
+	}
+	return timeout, mode
 }
 
-func (c *Controller) Stop() {
+// running returns the targets of checkers whose goroutine has not exited
+func (c *Controller) running() []string {
+	c.liveMu.Lock()
+	defer c.liveMu.Unlock()
+
+	keys := make([]string, 0, len(c.live))
+	for run := range c.live {
+		keys = append(keys, run.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Stop cancels all checkers and waits for them to exit. In force mode it
+// gives up after the shutdown timeout and returns an error naming the
+// checkers still running, in wait mode it logs them and keeps waiting.
+func (c *Controller) Stop() error {
 	c.mu.Lock()
 	if c.stopped {
 		c.mu.Unlock()
 		glog.Info("Controller is already stopped")
-		return
+		return nil
 	}
 	c.stopped = true
 	c.mu.Unlock()
 
 	glog.Info("Stopping controller...")
+	if _, err := sdnotify.Notify(sdnotify.Stopping); err != nil {
+		glog.Warningf("[Controller] Failed to notify systemd: %v", err)
+	}
 
 	// Cancel context to notify all checkers to stop
 	c.cancel()
@@ -163,27 +633,147 @@ func (c *Controller) Stop() {
 		close(done)
 	}()
 
-	select {
-	case <-done:
-		glog.Info("All checkers stopped successfully")
-	case <-time.After(30 * time.Second):
-		glog.Warning("Timeout waiting for checkers to stop")
+	timeout, mode := c.shutdown()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			glog.Info("All checkers stopped successfully")
+			return nil
+		case <-timer.C:
+			stuck := c.running()
+			if mode != conf.ShutdownWait {
+				return fmt.Errorf("%d checkers did not exit within %s: %s", len(stuck), timeout, strings.Join(stuck, ", "))
+			}
+			glog.Warningf("[Stop] Still waiting for %d checkers to exit after %s: %s", len(stuck), timeout, strings.Join(stuck, ", "))
+			timer.Reset(timeout)
+		}
+	}
+}
+
+// Checkers returns all checkers managed by the controller
+func (c *Controller) Checkers() []base.CheckerTrait {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	checkers := make([]base.CheckerTrait, 0, len(c.checkers))
+	for _, m := range c.checkers {
+		if m.checker != nil {
+			checkers = append(checkers, m.checker)
+		}
+	}
+	return checkers
+}
+
+// CheckerStats describes one target of the controller
+type CheckerStats struct {
+	Kind      string `json:"kind"`
+	ChainName string `json:"chain_name"`
+	HostName  string `json:"hostname"`
+	Source    string `json:"source"`
+	// Lifecycle is the lifecycle state, see lifecycleState
+	Lifecycle string `json:"lifecycle"`
+	// State is the connection state reported by the checker
+	State string `json:"state,omitempty"`
+	// Goroutine is the phase of the checker goroutine: running, exited, panicked or none
+	Goroutine     string           `json:"goroutine"`
+	UptimeSeconds float64          `json:"uptime_seconds"`
+	Restarts      int              `json:"restarts"`
+	LastError     *base.CheckError `json:"last_error,omitempty"`
+}
+
+// statsOf returns the stats of a target, the caller must hold c.mu
+func statsOf(m *managedChecker, now time.Time) CheckerStats {
+	stats := CheckerStats{
+		Kind:      m.kind,
+		ChainName: m.target.chainName,
+		HostName:  m.target.hostName,
+		Source:    m.source,
+		Lifecycle: lifecycleState(m),
+		Goroutine: "none",
 	}
+	if m.runs > 1 {
+		stats.Restarts = m.runs - 1
+	}
+	if m.checker == nil {
+		return stats
+	}
+
+	stats.State = m.checker.GetState()
+	switch m.run.phase.Load() {
+	case phaseRunning:
+		stats.Goroutine = "running"
+		stats.UptimeSeconds = now.Sub(m.created).Seconds()
+	case phaseExited:
+		stats.Goroutine = "exited"
+	case phasePanicked:
+		stats.Goroutine = "panicked"
+	}
+	if err, ok := m.checker.LastError(); ok {
+		stats.LastError = &err
+	}
+	return stats
 }
 
-// GetStats returns statistics about the controller
+// GetStats returns statistics about the controller and each of its targets
 func (c *Controller) GetStats() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	stats := map[string]interface{}{
 		"total_checkers": len(c.checkers),
-		"stopped":        c.IsStopped(),
+		"stopped":        c.stopped,
+		"active":         c.active,
 	}
 
 	// Count checkers by type
-	evmCount := len(c.conf.Evm)
-	cometbftCount := len(c.conf.Cometbft)
+	counts := make(map[string]int, len(Kinds))
+	for _, m := range c.checkers {
+		counts[m.kind]++
+	}
+	for _, kind := range Kinds {
+		stats[kind+"_checkers"] = counts[kind]
+	}
 
-	stats["evm_checkers"] = evmCount
-	stats["cometbft_checkers"] = cometbftCount
+	now := time.Now()
+	checkers := make([]CheckerStats, 0, len(c.checkers))
+	for _, m := range c.checkers {
+		checkers = append(checkers, statsOf(m, now))
+	}
+	stats["checkers"] = checkers
 
 	return stats
 }
+
+// Ready reports whether the controller is running
+func (c *Controller) Ready() error {
+	if c.IsStopped() {
+		return fmt.Errorf("controller stopped")
+	}
+	// A standby controller is ready to take over
+	if !c.IsActive() {
+		return nil
+	}
+	if len(c.Checkers()) == 0 {
+		return fmt.Errorf("no checkers configured")
+	}
+	return nil
+}
+
+// Subsystem returns the lifecycle declaration for the controller
+func (c *Controller) Subsystem() *Subsystem {
+	return &Subsystem{
+		Name: "controller",
+		Start: func() error {
+			c.Start()
+			return nil
+		},
+		Stop: func() {
+			if err := c.Stop(); err != nil {
+				glog.Errorf("[Controller] Shutdown incomplete: %v", err)
+			}
+		},
+		Ready: c.Ready,
+	}
+}
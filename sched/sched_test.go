@@ -0,0 +1,195 @@
+package sched
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+func tcpTarget(hostName, address string) *conf.Tcp {
+	return &conf.Tcp{ChainName: "story", HostName: hostName, Address: address}
+}
+
+func (c *Controller) targets() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	targets := make(map[string]string)
+	for _, m := range c.checkers {
+		targets[m.key] = m.source
+	}
+	return targets
+}
+
+func endpointTargets(chainName, owner string) []string {
+	var targets []string
+	for _, ep := range base.Endpoints() {
+		if ep.ChainName == chainName && ep.Owner == owner {
+			targets = append(targets, ep.Target+"/"+ep.Kind)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+func TestApplyTargets(t *testing.T) {
+	c := NewController(context.Background(), &conf.NodeConfig{
+		Tcp: []*conf.Tcp{tcpTarget("config-node", "10.0.0.1:26656")},
+	})
+	defer c.Stop()
+
+	c.ApplyTargets("file_sd", &conf.NodeConfig{
+		Tcp: []*conf.Tcp{
+			tcpTarget("config-node", "10.0.0.9:26656"),
+			tcpTarget("sd-node", "10.0.0.2:26656"),
+			tcpTarget("changed-node", "10.0.0.3:26656"),
+		},
+	})
+	want := map[string]string{
+		"tcp/story/config-node":  SourceConfig,
+		"tcp/story/sd-node":      "file_sd",
+		"tcp/story/changed-node": "file_sd",
+	}
+	if got := c.targets(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("targets = %v, want %v", got, want)
+	}
+
+	// Another target kind monitors the node of the target removed below
+	base.RegisterTargetEndpoint(KindEvm, "http", "story", "sd-node", "https://rpc.example.com")
+	c.mu.RLock()
+	var changed *managedChecker
+	for _, m := range c.checkers {
+		if m.key == "tcp/story/changed-node" {
+			changed = m
+		}
+	}
+	c.mu.RUnlock()
+	base.NodeHealthStatus.WithLabelValues("story", "changed-node", "tcp").Set(1)
+
+	c.ApplyTargets("file_sd", &conf.NodeConfig{
+		Tcp: []*conf.Tcp{tcpTarget("changed-node", "10.0.0.4:26656")},
+	})
+	want = map[string]string{
+		"tcp/story/config-node":  SourceConfig,
+		"tcp/story/changed-node": "file_sd",
+	}
+	if got := c.targets(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("targets after update = %v, want %v", got, want)
+	}
+	c.mu.RLock()
+	for _, m := range c.checkers {
+		if m.key == "tcp/story/changed-node" && m == changed {
+			t.Error("changed target was not recreated")
+		}
+	}
+	c.mu.RUnlock()
+
+	if got, want := endpointTargets("story", "sd-node"), []string{"evm/http"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints of the removed target's node = %v, want %v", got, want)
+	}
+	// The node of a changed target is still monitored, its series are kept
+	if !base.NodeHealthStatus.DeleteLabelValues("story", "changed-node", "tcp") {
+		t.Error("series of a changed target deleted")
+	}
+
+	base.NodeHealthStatus.WithLabelValues("story", "changed-node", "tcp").Set(1)
+	c.ApplyTargets("file_sd", &conf.NodeConfig{})
+	if got := c.targets(); len(got) != 1 {
+		t.Errorf("targets after removing all file_sd targets = %v", got)
+	}
+	if base.NodeHealthStatus.DeleteLabelValues("story", "changed-node", "tcp") {
+		t.Error("series of a removed node still exported")
+	}
+}
+
+func TestStandbyDeletesSeries(t *testing.T) {
+	c := NewController(context.Background(), &conf.NodeConfig{
+		Tcp: []*conf.Tcp{tcpTarget("standby-node", "10.0.0.5:26656")},
+	})
+	defer c.Stop()
+
+	base.NodeHealthStatus.WithLabelValues("story", "standby-node", "tcp").Set(0)
+	c.SetActive(false)
+	if base.NodeHealthStatus.DeleteLabelValues("story", "standby-node", "tcp") {
+		t.Error("standby controller still exports the series of its targets")
+	}
+	if got := c.Checkers(); len(got) != 0 {
+		t.Errorf("standby controller runs %d checkers", len(got))
+	}
+}
+
+func TestWithStandby(t *testing.T) {
+	c := NewController(context.Background(), &conf.NodeConfig{
+		Tcp: []*conf.Tcp{tcpTarget("ha-node", "10.0.0.6:26656")},
+	}, WithStandby())
+	defer c.Stop()
+
+	if c.IsActive() {
+		t.Fatal("controller created with WithStandby is active")
+	}
+	if got := c.Checkers(); len(got) != 0 {
+		t.Fatalf("standby controller created %d checkers", len(got))
+	}
+	if got := endpointTargets("story", "ha-node"); len(got) != 0 {
+		t.Errorf("standby controller registered endpoints %v", got)
+	}
+
+	c.SetActive(true)
+	if got := c.Checkers(); len(got) != 1 {
+		t.Fatalf("active controller runs %d checkers, want 1", len(got))
+	}
+	if got, want := endpointTargets("story", "ha-node"), []string{"tcp/tcp"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("endpoints = %v, want %v", got, want)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	c := NewController(context.Background(), &conf.NodeConfig{
+		Tcp: []*conf.Tcp{
+			tcpTarget("restarted-node", "10.0.0.7:26656"),
+			tcpTarget("other-node", "10.0.0.8:26656"),
+		},
+	})
+	c.Start()
+	defer c.Stop()
+
+	before := make(map[string]*checkerRun)
+	c.mu.RLock()
+	for _, m := range c.checkers {
+		before[m.key] = m.run
+	}
+	c.mu.RUnlock()
+
+	if _, err := c.Restart("story", "unknown-node"); err != ErrTargetNotFound {
+		t.Fatalf("Restart of an unknown node = %v, want %v", err, ErrTargetNotFound)
+	}
+	n, err := c.Restart("story", "restarted-node")
+	if err != nil || n != 1 {
+		t.Fatalf("Restart = %d, %v, want 1 checker", n, err)
+	}
+
+	old := before["tcp/story/restarted-node"]
+	if c.anyLive([]*checkerRun{old}) {
+		t.Error("old checker still running after Restart returned")
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, m := range c.checkers {
+		switch m.key {
+		case "tcp/story/restarted-node":
+			if m.checker == nil || m.run == old || m.runs != 2 {
+				t.Errorf("restarted target has checker %v, %d runs", m.checker, m.runs)
+			}
+			if !c.anyLive([]*checkerRun{m.run}) {
+				t.Error("replacement checker not started")
+			}
+		case "tcp/story/other-node":
+			if m.run != before[m.key] {
+				t.Error("checker of another node was restarted")
+			}
+		}
+	}
+}
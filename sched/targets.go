@@ -0,0 +1,203 @@
+package sched
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"storymonitor/base"
+	"storymonitor/cometbft"
+	"storymonitor/conf"
+	"storymonitor/cosmosrest"
+	"storymonitor/evm"
+	"storymonitor/grpcchecker"
+	"storymonitor/heartbeat"
+	"storymonitor/httpcheck"
+	"storymonitor/jsonrpc"
+	"storymonitor/plugincheck"
+	"storymonitor/tcpprobe"
+
+	"github.com/golang/glog"
+)
+
+// Target kinds, matching the target sections of the config file
+const (
+	KindEvm        = "evm"
+	KindCometbft   = "cometbft"
+	KindCosmosRest = "cosmosrest"
+	KindGrpc       = "grpc"
+	KindJsonRpc    = "jsonrpc"
+	KindHttp       = "http"
+	KindTcp        = "tcp"
+	KindPlugin     = "plugin"
+	KindHeartbeat  = "heartbeat"
+)
+
+// Kinds lists all target kinds
+var Kinds = []string{KindEvm, KindCometbft, KindCosmosRest, KindGrpc, KindJsonRpc, KindHttp, KindTcp, KindPlugin, KindHeartbeat}
+
+// target is one configured monitoring target
+type target struct {
+	kind      string
+	key       string
+	chainName string
+	hostName  string
+	conf      interface{}
+}
+
+func newTarget(kind, chainName, hostName string, c interface{}) target {
+	return target{
+		kind:      kind,
+		key:       fmt.Sprintf("%s/%s/%s", kind, chainName, hostName),
+		chainName: chainName,
+		hostName:  hostName,
+		conf:      c,
+	}
+}
+
+// spec serializes the target config so changed targets can be detected
+func (t target) spec() string {
+	b, _ := json.Marshal(t.conf)
+	return string(b)
+}
+
+// targetsOf returns all targets of a config, skipping nil entries and disabled targets
+func targetsOf(cfg *conf.NodeConfig) []target {
+	var targets []target
+	for i, c := range cfg.Evm {
+		if c == nil {
+			glog.Errorf("EVM config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindEvm, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindEvm, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.Cometbft {
+		if c == nil {
+			glog.Errorf("CometBFT config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindCometbft, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindCometbft, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.CosmosRest {
+		if c == nil {
+			glog.Errorf("CosmosRest config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindCosmosRest, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindCosmosRest, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.Grpc {
+		if c == nil {
+			glog.Errorf("Grpc config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindGrpc, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindGrpc, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.JsonRpc {
+		if c == nil {
+			glog.Errorf("JsonRpc config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindJsonRpc, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindJsonRpc, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.Http {
+		if c == nil {
+			glog.Errorf("Http config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindHttp, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindHttp, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.Tcp {
+		if c == nil {
+			glog.Errorf("Tcp config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindTcp, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindTcp, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.Plugin {
+		if c == nil {
+			glog.Errorf("Plugin config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindPlugin, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindPlugin, c.ChainName, c.HostName, c))
+	}
+	for i, c := range cfg.Heartbeat {
+		if c == nil {
+			glog.Errorf("Heartbeat config[%d] is nil, skipping", i)
+			continue
+		}
+		if skipDisabled(KindHeartbeat, c.ChainName, c.HostName, c.Enabled) {
+			continue
+		}
+		targets = append(targets, newTarget(KindHeartbeat, c.ChainName, c.HostName, c))
+	}
+	return targets
+}
+
+// skipDisabled reports whether a target is disabled with enabled: false
+func skipDisabled(kind, chainName, hostName string, enabled *bool) bool {
+	if enabled == nil || *enabled {
+		return false
+	}
+	glog.V(2).Infof("Target %s/%s/%s is disabled, skipping", kind, chainName, hostName)
+	return true
+}
+
+// newChecker creates the checker implementation of a target. Checkers get a
+// copy of the target config, as they overwrite detected fields such as
+// chain_id and the configured values must survive a restart of the checker.
+func newChecker(ctx context.Context, t target) base.CheckerTrait {
+	switch c := t.conf.(type) {
+	case *conf.Evm:
+		cc := *c
+		return evm.NewEvmCheckerImpl(ctx, &cc)
+	case *conf.Cometbft:
+		cc := *c
+		return cometbft.NewCometbftCheckerImpl(ctx, &cc)
+	case *conf.CosmosRest:
+		cc := *c
+		return cosmosrest.NewCosmosRestCheckerImpl(ctx, &cc)
+	case *conf.Grpc:
+		cc := *c
+		return grpcchecker.NewGrpcCheckerImpl(ctx, &cc)
+	case *conf.JsonRpc:
+		cc := *c
+		return jsonrpc.NewJsonRpcCheckerImpl(ctx, &cc)
+	case *conf.Http:
+		cc := *c
+		return httpcheck.NewHttpCheckerImpl(ctx, &cc)
+	case *conf.Tcp:
+		cc := *c
+		return tcpprobe.NewTcpCheckerImpl(ctx, &cc)
+	case *conf.Plugin:
+		cc := *c
+		return plugincheck.NewPluginCheckerImpl(ctx, &cc)
+	case *conf.Heartbeat:
+		cc := *c
+		return heartbeat.NewHeartbeatCheckerImpl(ctx, &cc)
+	}
+	return nil
+}
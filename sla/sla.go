@@ -0,0 +1,355 @@
+package sla
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+
+	"github.com/golang/glog"
+)
+
+// Event is a health transition of a check as persisted in the downtime log
+type Event struct {
+	Time      time.Time `json:"time"`
+	ChainName string    `json:"chain_name"`
+	HostName  string    `json:"hostname"`
+	Check     string    `json:"check"`
+	Healthy   bool      `json:"healthy"`
+}
+
+// Target identifies a monitored node
+type Target struct {
+	ChainName string `json:"chain_name"`
+	HostName  string `json:"hostname"`
+}
+
+type checkKey struct {
+	target Target
+	check  string
+}
+
+// Interval is a period during which a check of a node was failing, End is
+// zero while the check is still failing
+type Interval struct {
+	Check string    `json:"check"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitempty"`
+}
+
+// Tracker records downtime intervals from health transitions and persists
+// them as JSON lines, so uptime can be reported over arbitrary ranges
+type Tracker struct {
+	mu        sync.Mutex
+	file      *os.File
+	retention time.Duration
+
+	closed map[Target][]Interval
+	open   map[checkKey]time.Time
+}
+
+// NewTracker loads the downtime log at path and appends new transitions to it.
+// An empty path keeps downtime in memory only. Intervals that ended before the
+// retention are dropped and the log is compacted.
+func NewTracker(path string, retention time.Duration) (*Tracker, error) {
+	t := &Tracker{
+		retention: retention,
+		closed:    make(map[Target][]Interval),
+		open:      make(map[checkKey]time.Time),
+	}
+	if path == "" {
+		return t, nil
+	}
+
+	if err := t.load(path); err != nil {
+		return nil, err
+	}
+	t.prune(time.Now())
+	if err := t.compact(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open downtime log %s: %w", path, err)
+	}
+	t.file = f
+	return t, nil
+}
+
+func (t *Tracker) load(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open downtime log %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	var last time.Time
+	for scanner.Scan() {
+		line++
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			glog.Warningf("[sla] Skipping malformed line %d of %s: %v", line, path, err)
+			continue
+		}
+		t.apply(e)
+		if e.Time.After(last) {
+			last = e.Time
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// Checks failing when the monitor stopped are closed at the last recorded
+	// event, checks still failing after the restart open a new interval
+	for key, start := range t.open {
+		t.apply(Event{Time: last, ChainName: key.target.ChainName, HostName: key.target.HostName, Check: key.check, Healthy: true})
+		glog.V(2).Infof("[sla] Closed %s on %s started at %s at the end of the log", key.check, key.target.HostName, start)
+	}
+	return nil
+}
+
+// compact rewrites the log with the retained intervals only
+func (t *Tracker) compact(path string) error {
+	var events []Event
+	for target, intervals := range t.closed {
+		for _, i := range intervals {
+			events = append(events,
+				Event{Time: i.Start, ChainName: target.ChainName, HostName: target.HostName, Check: i.Check},
+				Event{Time: i.End, ChainName: target.ChainName, HostName: target.HostName, Check: i.Check, Healthy: true})
+		}
+	}
+	for key, start := range t.open {
+		events = append(events, Event{Time: start, ChainName: key.target.ChainName, HostName: key.target.HostName, Check: key.check})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to compact downtime log %s: %w", path, err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// apply updates the intervals with a transition, it must be called with the lock held
+func (t *Tracker) apply(e Event) {
+	key := checkKey{Target{e.ChainName, e.HostName}, e.Check}
+	start, failing := t.open[key]
+	switch {
+	case !e.Healthy && !failing:
+		t.open[key] = e.Time
+	case e.Healthy && failing:
+		delete(t.open, key)
+		t.closed[key.target] = append(t.closed[key.target], Interval{Check: e.Check, Start: start, End: e.Time})
+	}
+}
+
+// prune drops intervals that ended before the retention, it must be called with the lock held
+func (t *Tracker) prune(now time.Time) {
+	if t.retention <= 0 {
+		return
+	}
+	cutoff := now.Add(-t.retention)
+	for target, intervals := range t.closed {
+		kept := intervals[:0]
+		for _, i := range intervals {
+			if i.End.After(cutoff) {
+				kept = append(kept, i)
+			}
+		}
+		if len(kept) == 0 {
+			delete(t.closed, target)
+		} else {
+			t.closed[target] = kept
+		}
+	}
+}
+
+// HandleTransition records a health transition, it is registered as a base transition handler
+func (t *Tracker) HandleTransition(tr base.HealthTransition) {
+	e := Event{
+		Time:      tr.Time,
+		ChainName: tr.ChainName,
+		HostName:  tr.HostName,
+		Check:     tr.Check,
+		Healthy:   tr.Healthy,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.apply(e)
+	if e.Healthy {
+		t.prune(e.Time)
+	}
+	if t.file == nil {
+		return
+	}
+	b, _ := json.Marshal(e)
+	if _, err := t.file.Write(append(b, '\n')); err != nil {
+		glog.Errorf("[sla] Failed to write downtime log: %v", err)
+	}
+}
+
+// Uptime is the availability of a node over a time range
+type Uptime struct {
+	ChainName       string     `json:"chain_name"`
+	HostName        string     `json:"hostname"`
+	UptimePercent   float64    `json:"uptime_percent"`
+	DowntimeSeconds float64    `json:"downtime_seconds"`
+	Outages         int        `json:"outages"`
+	Intervals       []Interval `json:"intervals"`
+}
+
+// Report computes the uptime between from and to of the given targets and of
+// every node with recorded downtime. A node is down while any of its checks
+// is failing, time the monitor was not running counts as up.
+func (t *Tracker) Report(targets []Target, from, to time.Time) []Uptime {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[Target]bool, len(targets))
+	for _, target := range targets {
+		seen[target] = true
+	}
+	for target := range t.closed {
+		seen[target] = true
+	}
+	for key := range t.open {
+		seen[key.target] = true
+	}
+
+	now := time.Now()
+	if to.After(now) {
+		to = now
+	}
+	total := to.Sub(from)
+
+	result := make([]Uptime, 0, len(seen))
+	for target := range seen {
+		intervals := append([]Interval(nil), t.closed[target]...)
+		for key, start := range t.open {
+			if key.target == target {
+				intervals = append(intervals, Interval{Check: key.check, Start: start})
+			}
+		}
+
+		u := Uptime{ChainName: target.ChainName, HostName: target.HostName, UptimePercent: 100, Intervals: []Interval{}}
+		var downtime time.Duration
+		for _, merged := range mergeIntervals(intervals, from, to) {
+			downtime += merged.End.Sub(merged.Start)
+			u.Intervals = append(u.Intervals, merged)
+		}
+		u.Outages = len(u.Intervals)
+		u.DowntimeSeconds = downtime.Seconds()
+		if total > 0 {
+			u.UptimePercent = 100 * (1 - float64(downtime)/float64(total))
+		}
+		result = append(result, u)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ChainName != result[j].ChainName {
+			return result[i].ChainName < result[j].ChainName
+		}
+		return result[i].HostName < result[j].HostName
+	})
+	return result
+}
+
+// mergeIntervals clips intervals to [from, to] and merges overlapping ones.
+// Merged intervals list the failing checks joined by commas.
+func mergeIntervals(intervals []Interval, from, to time.Time) []Interval {
+	var clipped []Interval
+	for _, i := range intervals {
+		end := i.End
+		if end.IsZero() || end.After(to) {
+			end = to
+		}
+		start := i.Start
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			clipped = append(clipped, Interval{Check: i.Check, Start: start, End: end})
+		}
+	}
+	sort.Slice(clipped, func(i, j int) bool {
+		return clipped[i].Start.Before(clipped[j].Start)
+	})
+
+	var merged []Interval
+	for _, i := range clipped {
+		if n := len(merged); n > 0 && !i.Start.After(merged[n-1].End) {
+			last := &merged[n-1]
+			if i.End.After(last.End) {
+				last.End = i.End
+			}
+			if !strings.Contains(","+last.Check+",", ","+i.Check+",") {
+				last.Check += "," + i.Check
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// ParseRange parses a report range such as "24h", "7d" or "30d"
+func ParseRange(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid range %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid range %q", s)
+	}
+	return d, nil
+}
+
+// Close closes the downtime log
+func (t *Tracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
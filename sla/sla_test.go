@@ -0,0 +1,123 @@
+package sla
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"storymonitor/base"
+)
+
+func transition(host, check string, healthy bool, at time.Time) base.HealthTransition {
+	return base.HealthTransition{ChainName: "story", HostName: host, Check: check, Healthy: healthy, Time: at}
+}
+
+func TestReportMergesOverlappingChecks(t *testing.T) {
+	tracker, err := NewTracker("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().Add(-10 * time.Hour)
+	tracker.HandleTransition(transition("node-01", "http", false, from.Add(time.Hour)))
+	tracker.HandleTransition(transition("node-01", "ws", false, from.Add(90*time.Minute)))
+	tracker.HandleTransition(transition("node-01", "http", true, from.Add(2*time.Hour)))
+	tracker.HandleTransition(transition("node-01", "ws", true, from.Add(150*time.Minute)))
+
+	report := tracker.Report([]Target{{"story", "node-01"}, {"story", "node-02"}}, from, from.Add(10*time.Hour))
+	if len(report) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(report))
+	}
+
+	node1 := report[0]
+	if node1.Outages != 1 || node1.DowntimeSeconds != 90*60 {
+		t.Errorf("unexpected node-01 report: %+v", node1)
+	}
+	if node1.UptimePercent != 85 {
+		t.Errorf("expected 85%% uptime, got %v", node1.UptimePercent)
+	}
+	if node1.Intervals[0].Check != "http,ws" {
+		t.Errorf("expected merged checks, got %q", node1.Intervals[0].Check)
+	}
+	if report[1].UptimePercent != 100 || report[1].Outages != 0 {
+		t.Errorf("unexpected node-02 report: %+v", report[1])
+	}
+}
+
+func TestReportClipsToRange(t *testing.T) {
+	tracker, _ := NewTracker("", 0)
+
+	from := time.Now().Add(-4 * time.Hour)
+	tracker.HandleTransition(transition("node-01", "http", false, from.Add(-time.Hour)))
+	tracker.HandleTransition(transition("node-01", "http", true, from.Add(time.Hour)))
+	// Still failing, counted up to now
+	tracker.HandleTransition(transition("node-01", "ws", false, time.Now().Add(-time.Hour)))
+
+	report := tracker.Report(nil, from, from.Add(24*time.Hour))
+	if len(report) != 1 {
+		t.Fatalf("expected 1 target, got %d", len(report))
+	}
+	if report[0].Outages != 2 {
+		t.Errorf("expected 2 outages, got %d", report[0].Outages)
+	}
+	if d := report[0].DowntimeSeconds; d < 7199 || d > 7201 {
+		t.Errorf("expected about 2h of downtime, got %vs", d)
+	}
+}
+
+func TestTrackerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "downtime.jsonl")
+	tracker, err := NewTracker(path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	tracker.HandleTransition(transition("node-01", "http", false, now.Add(-48*time.Hour)))
+	tracker.HandleTransition(transition("node-01", "http", true, now.Add(-47*time.Hour)))
+	tracker.HandleTransition(transition("node-01", "http", false, now.Add(-2*time.Hour)))
+	tracker.HandleTransition(transition("node-01", "http", true, now.Add(-time.Hour)))
+	tracker.HandleTransition(transition("node-02", "http", false, now.Add(-30*time.Minute)))
+	if err := tracker.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewTracker(path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+
+	report := reloaded.Report(nil, now.Add(-72*time.Hour), now)
+	if len(report) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(report))
+	}
+	// The interval older than the retention was dropped
+	if report[0].Outages != 1 || report[0].DowntimeSeconds != 3600 {
+		t.Errorf("unexpected node-01 report: %+v", report[0])
+	}
+	// node-02 was failing at shutdown, its interval ends at the last recorded event
+	if report[1].Outages != 0 {
+		t.Errorf("unexpected node-02 report: %+v", report[1])
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	cases := map[string]time.Duration{
+		"24h": 24 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"30d": 30 * 24 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for s, want := range cases {
+		got, err := ParseRange(s)
+		if err != nil || got != want {
+			t.Errorf("ParseRange(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "d", "-1d", "0h", "week"} {
+		if _, err := ParseRange(s); err == nil {
+			t.Errorf("ParseRange(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package slo computes the error budget burn rates of availability and
+// latency objectives from the check outcomes of all checkers, so SLO alerts
+// need no hand-written recording rules.
+package slo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+)
+
+// Window is a burn rate window, exported as the window label
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the burn rate windows of multi-window alerts, a short window
+// confirms a long window is still burning
+var Windows = []Window{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// bucketWidth is the resolution of the windows
+const bucketWidth = time.Minute
+
+type bucket struct {
+	start       int64
+	good, total uint64
+}
+
+// series counts the good and total checks of an SLO on one node per minute,
+// covering the longest window
+type series struct {
+	buckets []bucket
+}
+
+func newSeries() *series {
+	return &series{buckets: make([]bucket, int(Windows[len(Windows)-1].Duration/bucketWidth))}
+}
+
+func (s *series) add(at time.Time, good bool) {
+	start := at.Truncate(bucketWidth).Unix()
+	b := &s.buckets[(start/int64(bucketWidth.Seconds()))%int64(len(s.buckets))]
+	if b.start != start {
+		*b = bucket{start: start}
+	}
+	b.total++
+	if good {
+		b.good++
+	}
+}
+
+// errorRate returns the ratio of bad checks within d before now, false if no
+// check ran
+func (s *series) errorRate(now time.Time, d time.Duration) (float64, bool) {
+	from := now.Add(-d).Unix()
+	var good, total uint64
+	for _, b := range s.buckets {
+		if b.total > 0 && b.start > from {
+			good += b.good
+			total += b.total
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(total-good) / float64(total), true
+}
+
+type seriesKey struct {
+	slo                 *conf.SLO
+	chainName, hostName string
+}
+
+// Tracker counts the checks of every SLO and exports its burn rates. It
+// implements base.CheckSink.
+type Tracker struct {
+	slos []*conf.SLO
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+}
+
+// Validate checks the configured SLOs
+func Validate(slos []*conf.SLO) error {
+	names := make(map[string]bool)
+	for i, s := range slos {
+		if s == nil {
+			continue
+		}
+		if s.Name == "" {
+			return fmt.Errorf("slos[%d]: name is required", i)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("slos[%d]: duplicate name %q", i, s.Name)
+		}
+		names[s.Name] = true
+		if s.Objective <= 0 || s.Objective >= 100 {
+			return fmt.Errorf("slos[%d]: objective must be a percentage between 0 and 100, got %v", i, s.Objective)
+		}
+		if s.LatencyMs < 0 {
+			return fmt.Errorf("slos[%d]: latency_ms must not be negative", i)
+		}
+		if s.HostName != "" && s.ChainName == "" {
+			return fmt.Errorf("slos[%d]: hostname requires chain_name", i)
+		}
+	}
+	return nil
+}
+
+// NewTracker creates a tracker of valid SLOs
+func NewTracker(slos []*conf.SLO) *Tracker {
+	return &Tracker{
+		slos:   slos,
+		series: make(map[seriesKey]*series),
+	}
+}
+
+func matches(s *conf.SLO, chainName, hostName, check string) bool {
+	return (s.ChainName == "" || s.ChainName == chainName) &&
+		(s.HostName == "" || s.HostName == hostName) &&
+		(s.Check == "" || s.Check == check)
+}
+
+// AppendCheck counts a check towards the SLOs it belongs to and updates their burn rates
+func (t *Tracker) AppendCheck(chainName, hostName, check string, healthy bool, duration time.Duration, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.slos {
+		if s == nil || !matches(s, chainName, hostName, check) {
+			continue
+		}
+		key := seriesKey{s, chainName, hostName}
+		ser, ok := t.series[key]
+		if !ok {
+			ser = newSeries()
+			t.series[key] = ser
+			base.SLOObjective.WithLabelValues(chainName, hostName, s.Name).Set(s.Objective / 100)
+		}
+
+		good := healthy && (s.LatencyMs <= 0 || duration <= time.Duration(s.LatencyMs)*time.Millisecond)
+		ser.add(at, good)
+
+		budget := 1 - s.Objective/100
+		for _, w := range Windows {
+			if rate, ok := ser.errorRate(at, w.Duration); ok {
+				base.SLOBurnRate.WithLabelValues(chainName, hostName, s.Name, w.Name).Set(rate / budget)
+			}
+		}
+	}
+}
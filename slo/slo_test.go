@@ -0,0 +1,72 @@
+package slo
+
+import (
+	"testing"
+	"time"
+
+	"storymonitor/conf"
+)
+
+func TestSeriesErrorRate(t *testing.T) {
+	s := newSeries()
+	now := time.Now()
+
+	// One bad check of ten two hours ago, all good within the last five minutes
+	for i := 0; i < 10; i++ {
+		s.add(now.Add(-2*time.Hour), i != 0)
+	}
+	for i := 0; i < 4; i++ {
+		s.add(now.Add(-time.Duration(i)*time.Minute), true)
+	}
+
+	if rate, ok := s.errorRate(now, 5*time.Minute); !ok || rate != 0 {
+		t.Errorf("5m error rate = %v, %v, want 0", rate, ok)
+	}
+	if rate, _ := s.errorRate(now, 6*time.Hour); rate != 1.0/14 {
+		t.Errorf("6h error rate = %v, want %v", rate, 1.0/14)
+	}
+
+	// Checks leave the window after six hours
+	s.add(now.Add(4*time.Hour), false)
+	if rate, _ := s.errorRate(now.Add(4*time.Hour), 6*time.Hour); rate != 1.0/5 {
+		t.Errorf("error rate after 4h = %v, want %v", rate, 1.0/5)
+	}
+	if _, ok := s.errorRate(now.Add(24*time.Hour), time.Hour); ok {
+		t.Error("expected no error rate without recent checks")
+	}
+}
+
+func TestTrackerMatches(t *testing.T) {
+	latency := &conf.SLO{Name: "latency", ChainName: "story", Check: "http", Objective: 99, LatencyMs: 500}
+	tracker := NewTracker([]*conf.SLO{latency})
+	now := time.Now()
+
+	tracker.AppendCheck("story", "node-01", "http", true, time.Second, now)
+	tracker.AppendCheck("story", "node-01", "http", true, 100*time.Millisecond, now)
+	tracker.AppendCheck("story", "node-01", "ws", false, time.Second, now)
+	tracker.AppendCheck("other", "node-02", "http", false, time.Second, now)
+
+	if len(tracker.series) != 1 {
+		t.Fatalf("expected one series, got %d", len(tracker.series))
+	}
+	rate, _ := tracker.series[seriesKey{latency, "story", "node-01"}].errorRate(now, time.Hour)
+	if rate != 0.5 {
+		t.Errorf("expected the slow check to count as bad, error rate %v", rate)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []*conf.SLO{
+		{Objective: 99.9},
+		{Name: "a", Objective: 100},
+		{Name: "a", Objective: 99, HostName: "node-01"},
+	}
+	for _, c := range cases {
+		if err := Validate([]*conf.SLO{c}); err == nil {
+			t.Errorf("expected an error for %+v", c)
+		}
+	}
+	if err := Validate([]*conf.SLO{{Name: "a", Objective: 99.9}, {Name: "a", Objective: 99}}); err == nil {
+		t.Error("expected an error for duplicate names")
+	}
+}
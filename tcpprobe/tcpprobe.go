@@ -0,0 +1,136 @@
+package tcpprobe
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"storymonitor/base"
+	"storymonitor/conf"
+	"storymonitor/maintenance"
+
+	"github.com/golang/glog"
+)
+
+type TcpCheckerImpl struct {
+	*conf.Tcp
+	base.BaseChecker
+
+	ctx context.Context
+
+	timeout time.Duration
+}
+
+func NewTcpCheckerImpl(ctx context.Context, conf *conf.Tcp) base.CheckerTrait {
+	checker := &TcpCheckerImpl{
+		Tcp: conf,
+		BaseChecker: base.BaseChecker{
+			ChainName:    conf.ChainName,
+			HostName:     conf.HostName,
+			ProtocolName: conf.ProtocolName,
+
+			FailureDomain: conf.FailureDomain,
+			Maintenance:   maintenance.New(conf.Maintenance),
+		},
+		ctx:     ctx,
+		timeout: 5 * time.Second,
+	}
+
+	// Set defaults
+	if checker.CheckSecond == 0 {
+		checker.CheckSecond = 30
+	}
+	if checker.TimeoutSecond > 0 {
+		checker.timeout = time.Duration(checker.TimeoutSecond) * time.Second
+	}
+
+	base.RegisterTargetEndpoint("tcp", "tcp", conf.ChainName, conf.HostName, "tcp://"+conf.Address)
+	return checker
+}
+
+func (chain *TcpCheckerImpl) dial() error {
+	release, err := base.AcquireOutbound(chain.ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	dialer := net.Dialer{Timeout: chain.timeout}
+	startTime := time.Now()
+	conn, err := dialer.DialContext(chain.ctx, "tcp", chain.Address)
+	if err != nil {
+		return err
+	}
+	base.TCPConnectDuration.WithLabelValues(chain.AddLabelValues(chain.Address)...).Set(float64(time.Since(startTime).Milliseconds()))
+	return conn.Close()
+}
+
+func (chain *TcpCheckerImpl) check() {
+	var reachable bool
+	chain.HealthCheckOperation("tcp", func() error {
+		err := chain.dial()
+		if err != nil {
+			glog.Errorf("[check] Node %s tcp %s unreachable: %v", chain.Tcp.HostName, chain.Address, err)
+		}
+		reachable = err == nil
+		return err
+	})
+
+	value := float64(0)
+	if reachable {
+		value = 1
+	}
+	base.TCPReachable.WithLabelValues(chain.AddLabelValues(chain.Address)...).Set(value)
+
+	chain.RecordConnectionAttempt("tcp", reachable)
+	if reachable {
+		// Without blocks to follow, the last update time tracks the last successful connect
+		chain.UpdateLastBlockTime()
+		chain.SetState(base.StateSubscribed)
+	} else {
+		chain.SetState(base.StateDown)
+	}
+}
+
+func (chain *TcpCheckerImpl) Start() {
+	glog.Infof("[Tcp] Starting checker for %s (%s) %s", chain.Tcp.HostName, chain.Tcp.ChainName, chain.Address)
+
+	if !base.WaitForPhaseOffset(chain.ctx, chain.CheckSecond, 30) {
+		return
+	}
+
+	ticker := base.CheckSecondToTicker(chain.CheckSecond, 30)
+	defer ticker.Stop()
+	loop := chain.StartLoop("check")
+	defer loop.Stop()
+
+	chain.SetState(base.StateConnecting)
+	for {
+		chain.check()
+
+		if !loop.Wait(chain.ctx, ticker) {
+			glog.V(5).Info("[Tcp] Received stop signal, exited")
+			return
+		}
+	}
+}
+
+func (chain *TcpCheckerImpl) GetHostName() string {
+	return chain.Tcp.HostName
+}
+
+func (chain *TcpCheckerImpl) GetChainId() string {
+	return ""
+}
+
+func (chain *TcpCheckerImpl) GetNodeVersion() string {
+	return ""
+}
+
+func (chain *TcpCheckerImpl) GetChainName() string {
+	return chain.Tcp.ChainName
+}
+
+func (chain *TcpCheckerImpl) GetProtocolName() string {
+	return chain.Tcp.ProtocolName
+}